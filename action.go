@@ -0,0 +1,116 @@
+package comb
+
+// ============================================================================
+// Semantic Actions With Rollback
+//
+
+// actionEntry is a semantic action that has been done (see Action).
+// The entries of a state form a persistent list.
+// So backtracking to an older state drops the newer entries.
+type actionEntry struct {
+	parent *actionEntry
+	depth  int // number of entries in the list up to and including this one
+	undo   func()
+}
+
+// actionLog is the stack of the semantic actions done in a run.
+type actionLog struct {
+	done []*actionEntry
+}
+
+// Action applies the parser p and calls `do` with its output if it succeeds.
+// `undo` is called with the same output if backtracking or error recovery
+// discards the result of p later.
+// So side effects (like inserting into a symbol table) stay consistent
+// when alternatives are retried.
+//
+// Discarded actions are undone in reverse order right before the next parser
+// is applied and at the end of the run (see RunOnState).
+// So parsers applied after backtracking never see discarded actions.
+// An error returned by `do` is saved as a semantic error (like cmb.Map does)
+// and the action isn't done.
+// `undo` can be nil.
+func Action[Output any](p Parser[Output], do func(Output, State) error, undo func(Output)) Parser[Output] {
+	var ap Parser[Output]
+
+	if do == nil {
+		panic("Action is unable to handle a nil `do` function")
+	}
+
+	ap = NewBranchParser[Output](
+		p.Expected(),
+		func() []AnyParser {
+			return []AnyParser{p}
+		}, func(
+			childID int32,
+			childStartState, childState State,
+			childOut interface{},
+			childErr *ParserError,
+			data interface{},
+		) (State, Output, *ParserError, interface{}) {
+			Debugf("Action.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				childStartState = childState
+				childState, childOut, childErr = p.ParseAny(ap.ID(), childStartState)
+			}
+			out, _ := childOut.(Output)
+			if childErr != nil {
+				return childState, out, childErr, nil
+			}
+			childState.syncActions()
+			if err := do(out, childState); err != nil {
+				return childState.SaveError(childState.NewSemanticError("%w", err)), out, nil, nil
+			}
+			return childState.pushAction(func() {
+				if undo != nil {
+					undo(out)
+				}
+			}), out, nil, nil
+		},
+	)
+	return ap
+}
+
+// pushAction returns the state with the done action added.
+func (st State) pushAction(undo func()) State {
+	entry := &actionEntry{parent: st.actions, depth: 1, undo: undo}
+	if st.actions != nil {
+		entry.depth = st.actions.depth + 1
+	}
+	st.actions = entry
+	if log := st.constant.actions; log != nil {
+		log.done = append(log.done, entry)
+	}
+	return st
+}
+
+// syncActions undoes all done actions that aren't part of the state (anymore).
+// The newest action is undone first.
+func (st State) syncActions() {
+	log := st.constant.actions
+	if log == nil {
+		return
+	}
+	for n := len(log.done); n > 0 && !st.hasAction(log.done[n-1]); n = len(log.done) {
+		entry := log.done[n-1]
+		log.done = log.done[:n-1]
+		entry.undo()
+	}
+}
+
+func (st State) hasAction(entry *actionEntry) bool {
+	e := st.actions
+	for e != nil && e.depth > entry.depth {
+		e = e.parent
+	}
+	return e == entry
+}
+
+// withActionLog returns the state with a new (empty) log of done actions.
+// So runs on the same state don't undo the actions of each other.
+func (st State) withActionLog() State {
+	constant := *st.constant
+	constant.actions = &actionLog{}
+	st.constant = &constant
+	return st
+}
@@ -0,0 +1,88 @@
+package comb_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAction(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		input       string
+		wantSymbols []string
+		wantErr     string
+	}{
+		{
+			name:        "kept result should keep its action",
+			input:       "abc;",
+			wantSymbols: []string{"abc"},
+		}, {
+			name:        "backtracking should undo the action",
+			input:       "abc.",
+			wantSymbols: []string{},
+		}, {
+			name:        "failing action should be reported",
+			input:       "bad;",
+			wantSymbols: []string{},
+			wantErr:     "symbol bad is reserved [1:4] bad▶;",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			symbols := []string{}
+			declare := comb.Action(cmb.Alpha1(), func(name string, _ comb.State) error {
+				if name == "bad" {
+					return errors.New("symbol bad is reserved")
+				}
+				symbols = append(symbols, name)
+				return nil
+			}, func(name string) {
+				assert.Equal(t, name, symbols[len(symbols)-1])
+				symbols = symbols[:len(symbols)-1]
+			})
+			p := cmb.FirstSuccessful(cmb.Suffixed(declare, cmb.Char(';')), cmb.Suffixed(cmb.Alpha1(), cmb.Char('.')))
+
+			_, err := comb.RunOnString(tc.input, p)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantSymbols, symbols)
+		})
+	}
+}
+
+func TestActionUndoneBeforeAlternative(t *testing.T) {
+	t.Parallel()
+
+	symbols := map[string]bool{}
+	declare := comb.Action(cmb.Alpha1(), func(name string, _ comb.State) error {
+		symbols[name] = true
+		return nil
+	}, func(name string) {
+		delete(symbols, name)
+	})
+	lookup := cmb.Map(cmb.Alpha1(), func(name string) (bool, error) {
+		return symbols[name], nil
+	})
+	p := cmb.FirstSuccessful(
+		cmb.Map(cmb.Suffixed(declare, cmb.Char(';')), func(string) (bool, error) { return false, nil }),
+		cmb.Suffixed(lookup, cmb.Char('.')),
+	)
+
+	declared, err := comb.RunOnString("abc.", p)
+	assert.NoError(t, err)
+	assert.False(t, declared, "the alternative should not see the discarded declaration")
+	assert.Empty(t, symbols)
+}
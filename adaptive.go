@@ -0,0 +1,140 @@
+package comb
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+// ============================================================================
+// Adaptive Error Recovery With Frequent Anchor Tokens
+//
+
+// TokenHistogram counts how often anchor tokens (like "\n" or ";") occur in an input.
+type TokenHistogram map[string]int
+
+// DefaultAnchors are the anchor tokens counted by LearnTokenHistogram
+// if no candidates are given.
+var DefaultAnchors = []string{"\n", ";", ",", "}", ")", "]"}
+
+// LearnTokenHistogram counts the non-overlapping occurrences of the candidate
+// anchor tokens (or DefaultAnchors) in the input.
+func LearnTokenHistogram(input string, candidates ...string) TokenHistogram {
+	if len(candidates) == 0 {
+		candidates = DefaultAnchors
+	}
+	hist := make(TokenHistogram, len(candidates))
+	for _, c := range candidates {
+		if c != "" {
+			hist[c] = strings.Count(input, c)
+		}
+	}
+	return hist
+}
+
+// WithAdaptiveRecovery returns the state with adaptive error recovery turned on.
+// Recovery prefers to resynchronize right after the most frequent anchor
+// tokens of the histogram (e.g. at the start of the next line or statement)
+// instead of the nearest position any recoverer accepts or a distant safe spot.
+// All recoverers (fast and step recoverers including safe spots) are tried there.
+// Anchors are tried in the order of their frequency (ties are broken by the
+// anchor itself), and the usual byte by byte search is the fallback.
+// So recovery stays deterministic.
+// If hist is nil, the histogram is learned from the input of the state
+// (see LearnTokenHistogram).
+// It has to be called before parsing starts.
+func (st State) WithAdaptiveRecovery(hist TokenHistogram) State {
+	if hist == nil {
+		if st.constant.binary {
+			hist = LearnTokenHistogram(string(st.constant.bytes))
+		} else {
+			hist = LearnTokenHistogram(st.constant.text)
+		}
+	}
+	constant := *st.constant
+	constant.anchors = rankAnchors(hist)
+	st.constant = &constant
+	return st
+}
+
+// rankAnchors returns the anchors that occur at all, the most frequent first.
+func rankAnchors(hist TokenHistogram) []string {
+	anchors := make([]string, 0, len(hist))
+	for anchor, count := range hist {
+		if anchor != "" && count > 0 {
+			anchors = append(anchors, anchor)
+		}
+	}
+	slices.SortFunc(anchors, func(a, b string) int {
+		if hist[a] != hist[b] {
+			return hist[b] - hist[a]
+		}
+		return strings.Compare(a, b)
+	})
+	return anchors
+}
+
+// findAnchoredWaste tries all recoverers right after the anchors
+// (see WithAdaptiveRecovery) with less than maxWaste waste.
+// A fast recoverer succeeds at a position if it doesn't waste any input there.
+// It returns false if no recoverer succeeds at any anchor.
+func (pp *PreparedParser[Output]) findAnchoredWaste(
+	fastRecs, stepRecs []AnyParser, state State, err *ParserError, maxWaste int,
+) (int, AnyParser, bool) {
+	from := make([]int, len(fastRecs)) // position the next match of the fast recoverer has been searched from
+	next := make([]int, len(fastRecs)) // position of the next match of the fast recoverer
+	for i := range from {
+		from[i] = math.MaxInt
+	}
+	recoverAt := func(waste int) AnyParser {
+		current := state.MoveBy(waste)
+		for i, rec := range fastRecs {
+			if from[i] > waste || next[i] < waste { // the cached search doesn't cover this position
+				w, data := recoverWithBudget(rec, current, err.ParserData(rec.ID()))
+				if data != nil {
+					err.StoreParserData(rec.ID(), data)
+				}
+				from[i], next[i] = waste, math.MaxInt
+				if w >= 0 {
+					next[i] = waste + w
+				}
+			}
+			if next[i] == waste {
+				return rec
+			}
+		}
+		return stepRecoverAt(stepRecs, current, err)
+	}
+
+	if rec := recoverAt(0); rec != nil {
+		return 0, rec, true
+	}
+	for _, anchor := range state.constant.anchors {
+		waste := 0
+		for waste < maxWaste {
+			i := state.MoveBy(waste).Index(anchor)
+			if i < 0 {
+				break
+			}
+			waste += i + len(anchor)
+			if waste >= maxWaste {
+				break
+			}
+			if rec := recoverAt(waste); rec != nil {
+				Debugf("findAnchoredWaste - recoverer after anchor %q: ID=%d, waste=%d", anchor, rec.ID(), waste)
+				return waste, rec, true
+			}
+		}
+	}
+	return 0, nil, false
+}
+
+// stepRecoverAt returns the first step recoverer that succeeds at the state or nil.
+func stepRecoverAt(stepRecs []AnyParser, state State, err *ParserError) AnyParser {
+	for _, sr := range stepRecs {
+		if _, _, _, nErr := sr.parseAnyAfterError(err, state); nErr == nil {
+			return sr
+		}
+	}
+	return nil
+}
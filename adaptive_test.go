@@ -0,0 +1,103 @@
+package comb_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLearnTokenHistogram(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, comb.TokenHistogram{"\n": 2, ";": 3, ",": 0, "}": 0, ")": 1, "]": 0},
+		comb.LearnTokenHistogram("a;b;\nc(d);\n"))
+	assert.Equal(t, comb.TokenHistogram{"--": 2}, comb.LearnTokenHistogram("a---b--", "--"))
+}
+
+func TestAdaptiveRecovery(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		hist       comb.TokenHistogram
+		adaptive   bool
+		fast       bool
+		wantOutput []string
+	}{
+		{
+			name:       "byte by byte recovery should resync as early as possible",
+			wantOutput: []string{"x=3", "c=4"},
+		}, {
+			name:       "learned anchors should resync at the next line",
+			adaptive:   true,
+			wantOutput: []string{"c=4"},
+		}, {
+			name:       "provided anchors should be used",
+			hist:       comb.TokenHistogram{"\n": 3},
+			adaptive:   true,
+			wantOutput: []string{"c=4"},
+		}, {
+			name:       "missing anchors should fall back to byte by byte recovery",
+			hist:       comb.TokenHistogram{";": 5},
+			adaptive:   true,
+			wantOutput: []string{"x=3", "c=4"},
+		}, {
+			name:       "fast recovery should resync as early as possible",
+			fast:       true,
+			wantOutput: []string{"x=3", "c=4"},
+		}, {
+			name:       "learned anchors should be used for fast recoverers, too",
+			adaptive:   true,
+			fast:       true,
+			wantOutput: []string{"c=4"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := comb.NewFromString("a=1\nb=1x=3\nc=4\n", 10)
+			if tc.adaptive {
+				state = state.WithAdaptiveRecovery(tc.hist)
+			}
+			var recoverer comb.Recoverer
+			if tc.fast {
+				recoverer = cmb.RecoverToRegexp(regexp.MustCompile(`[a-z]+=[0-9]+\n`))
+			}
+			gotOutput, err := comb.RunOnState(state, comb.NewPreparedParser(
+				cmb.Suffixed(cmb.Many0(comb.SafeSpot(keyValueLine(recoverer))), cmb.EOF()),
+			))
+			assert.Error(t, err)
+			assert.Equal(t, tc.wantOutput, gotOutput)
+		})
+	}
+}
+
+// keyValueLine parses lines like `a=1`.
+// Without a recoverer it has to be recovered step by step.
+func keyValueLine(recoverer comb.Recoverer) comb.Parser[string] {
+	return comb.NewParser[string]("key=value line", func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		i := 0
+		for i < len(input) && 'a' <= input[i] && input[i] <= 'z' {
+			i++
+		}
+		if i == 0 || i >= len(input) || input[i] != '=' {
+			return state, "", state.NewSyntaxError("key=value line")
+		}
+		j := i + 1
+		for j < len(input) && '0' <= input[j] && input[j] <= '9' {
+			j++
+		}
+		if j == i+1 || j >= len(input) || input[j] != '\n' {
+			return state, "", state.NewSyntaxError("key=value line")
+		}
+		return state.MoveBy(j + 1), strings.TrimSpace(input[:j]), nil
+	}, recoverer)
+}
@@ -0,0 +1,112 @@
+package comb
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ============================================================================
+// Diagnostic Mode For Ambiguous Choices
+//
+
+// Ambiguity is a choice (e.g. cmb.FirstSuccessful) where more than one
+// alternative succeeds for the same input.
+// The order of the alternatives decides which one wins.
+type Ambiguity struct {
+	Choice       string   // description of the choice (Expected())
+	ChoiceID     int32    // ID of the choice parser
+	Alternatives []string // all alternatives that succeeded; the first one has been chosen
+	Examples     []AmbiguityExample
+}
+
+// AmbiguityExample is an input of the corpus showing an ambiguity.
+type AmbiguityExample struct {
+	Input        string // the whole input
+	Pos          int    // start of the ambiguous choice in the input
+	Line, Column int
+}
+
+func (a Ambiguity) String() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("ambiguous %s (ID %d): %s", a.Choice, a.ChoiceID, strings.Join(a.Alternatives, " | ")))
+	for _, ex := range a.Examples {
+		sb.WriteString(fmt.Sprintf("\n    at %d:%d in %q", ex.Line, ex.Column, ex.Input))
+	}
+	return sb.String()
+}
+
+// maxAmbiguityExamples limits the number of examples kept for an ambiguity.
+const maxAmbiguityExamples = 3
+
+type ambiguityFinding struct {
+	choice       AnyParser
+	alternatives []string
+	state        State
+}
+
+// CheckAlternatives is used by choices (like cmb.FirstSuccessful) to support
+// the diagnostic mode of FindAmbiguities.
+// After alternatives[chosen] succeeded at the state, it tries all following
+// alternatives and records the choice as ambiguous if any of them succeeds, too.
+// It does nothing outside of the diagnostic mode.
+func (st State) CheckAlternatives(choice AnyParser, chosen int, alternatives []AnyParser) {
+	findings := st.constant.ambiguities
+	if findings == nil || chosen >= len(alternatives)-1 {
+		return
+	}
+	succeeded := []string{expectedOf(alternatives[chosen])}
+	for _, alt := range st.succeedingAlternatives(alternatives[chosen+1:]) {
+		succeeded = append(succeeded, expectedOf(alt))
+	}
+	if len(succeeded) > 1 {
+		*findings = append(*findings, ambiguityFinding{choice: choice, alternatives: succeeded, state: st})
+	}
+}
+
+// FindAmbiguities runs the parser over all inputs of the corpus in a
+// diagnostic mode and reports all choices where more than one alternative
+// would have succeeded.
+// Ambiguities of the same choice with the same alternatives are reported
+// once with up to 3 examples.
+// This helps to understand why the order of alternatives matters.
+// The parser is run without error recovery.
+// It is prepared by FindAmbiguities, so it must not have been prepared before.
+func FindAmbiguities(p AnyParser, corpus []string) []Ambiguity {
+	pp := newPreparedParser[interface{}](p)
+	if len(pp.parsers) == 0 {
+		return nil
+	}
+
+	var ambiguities []Ambiguity
+	index := make(map[string]int)
+	for _, input := range corpus {
+		var findings []ambiguityFinding
+		state := NewFromString(input, FailFast)
+		state.constant.ambiguities = &findings
+		_, _, _ = pp.parsers[0].ParseAny(ParentUnknown, state)
+
+		for _, f := range findings {
+			key := fmt.Sprintf("%d|%s", f.choice.ID(), strings.Join(f.alternatives, "|"))
+			i, ok := index[key]
+			if !ok {
+				i = len(ambiguities)
+				index[key] = i
+				ambiguities = append(ambiguities, Ambiguity{
+					Choice:       expectedOf(f.choice),
+					ChoiceID:     f.choice.ID(),
+					Alternatives: f.alternatives,
+				})
+			}
+			if len(ambiguities[i].Examples) >= maxAmbiguityExamples {
+				continue
+			}
+			line, col := f.state.LineCol()
+			example := AmbiguityExample{Input: input, Pos: f.state.CurrentPos(), Line: line, Column: col}
+			if !slices.Contains(ambiguities[i].Examples, example) {
+				ambiguities[i].Examples = append(ambiguities[i].Examples, example)
+			}
+		}
+	}
+	return ambiguities
+}
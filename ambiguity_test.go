@@ -0,0 +1,33 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAmbiguities(t *testing.T) {
+	t.Parallel()
+
+	word := cmb.FirstSuccessful(cmb.String("if"), cmb.Alpha1(), cmb.String("iff"))
+	parser := cmb.Separated1(word, cmb.Char(' '), false)
+
+	got := comb.FindAmbiguities(parser, []string{"x", "x if", "if", "if if", "iff", "if iff"})
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, "FirstSuccessful", got[0].Choice)
+		assert.Equal(t, []string{`"if"`, cmb.Alpha1().Expected()}, got[0].Alternatives)
+		assert.Equal(t, []comb.AmbiguityExample{
+			{Input: "x if", Pos: 2, Line: 1, Column: 3},
+			{Input: "if", Pos: 0, Line: 1, Column: 1},
+			{Input: "if if", Pos: 0, Line: 1, Column: 1},
+		}, got[0].Examples)
+
+		assert.Equal(t, []string{`"if"`, cmb.Alpha1().Expected(), `"iff"`}, got[1].Alternatives)
+		assert.Contains(t, got[1].String(), `at 1:1 in "iff"`)
+		assert.Contains(t, got[1].String(), `at 1:4 in "if iff"`)
+	}
+
+	assert.Empty(t, comb.FindAmbiguities(cmb.FirstSuccessful(cmb.Char('a'), cmb.Char('b')), []string{"a", "b"}))
+}
@@ -0,0 +1,104 @@
+package comb
+
+import (
+	"reflect"
+)
+
+// ============================================================================
+// Arena Allocation For Outputs
+//
+
+// arenaChunkMax is the maximum number of values of a single chunk of an arena.
+const arenaChunkMax = 4096
+
+// Arena allocates values (e.g. nodes of an AST) in big chunks instead of
+// one by one (see Alloc).
+// This reduces the pressure on the garbage collector for short-lived parse
+// trees in servers.
+// All values are freed in one go with Reset after the result has been consumed.
+//
+// NOTE:
+//   - An arena must not be used concurrently in multiple runs.
+//   - Values allocated in alternatives that have been given up aren't freed
+//     before Reset.
+type Arena struct {
+	typed map[reflect.Type]arenaResetter
+}
+
+// NewArena creates a new, empty arena.
+func NewArena() *Arena {
+	return &Arena{typed: make(map[reflect.Type]arenaResetter)}
+}
+
+// Reset frees all values allocated in the arena.
+// The memory is reused by the next allocations.
+// So no value allocated before may be used after Reset.
+func (a *Arena) Reset() {
+	for _, ta := range a.typed {
+		ta.reset()
+	}
+}
+
+// WithArena returns the state with the arena used by Alloc.
+// It has to be called before parsing starts.
+func (st State) WithArena(a *Arena) State {
+	constant := *st.constant
+	constant.arena = a
+	st.constant = &constant
+	return st
+}
+
+// Alloc returns a pointer to a new zero value of type T.
+// The value is allocated in the arena of the state (see State.WithArena).
+// Without arena it is the same as new(T).
+// Functions like cmb.Map can use it for the nodes of their output.
+func Alloc[T any](state State) *T {
+	a := state.constant.arena
+	if a == nil {
+		return new(T)
+	}
+	key := reflect.TypeFor[T]()
+	ta, ok := a.typed[key].(*typedArena[T])
+	if !ok {
+		ta = &typedArena[T]{}
+		a.typed[key] = ta
+	}
+	return ta.alloc()
+}
+
+// arenaResetter is implemented by all typed arenas.
+type arenaResetter interface {
+	reset()
+}
+
+// typedArena allocates the values of a single type.
+// The chunks grow up to arenaChunkMax values.
+type typedArena[T any] struct {
+	chunks [][]T // all chunks; the current one is chunks[cur]
+	cur    int
+	next   int // index of the next free value in the current chunk
+}
+
+func (ta *typedArena[T]) alloc() *T {
+	if len(ta.chunks) == 0 {
+		ta.chunks = append(ta.chunks, make([]T, 16))
+	}
+	if ta.next >= len(ta.chunks[ta.cur]) {
+		ta.cur++
+		ta.next = 0
+		if ta.cur >= len(ta.chunks) {
+			size := min(2*len(ta.chunks[ta.cur-1]), arenaChunkMax)
+			ta.chunks = append(ta.chunks, make([]T, size))
+		}
+	}
+	v := &ta.chunks[ta.cur][ta.next]
+	ta.next++
+	return v
+}
+
+func (ta *typedArena[T]) reset() {
+	for i := 0; i <= ta.cur && i < len(ta.chunks); i++ {
+		clear(ta.chunks[i])
+	}
+	ta.cur, ta.next = 0, 0
+}
@@ -0,0 +1,55 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+type arenaNode struct {
+	Name string
+	Next *arenaNode
+}
+
+func TestArena(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[*arenaNode] {
+		node := cmb.MapWithSpan(cmb.Suffixed(cmb.Alpha1(), cmb.Char(';')),
+			func(name string, _ comb.Span, state comb.State) (*arenaNode, error) {
+				n := comb.Alloc[arenaNode](state)
+				n.Name = name
+				return n, nil
+			},
+		)
+		return cmb.Map(cmb.Many0(node), func(nodes []*arenaNode) (*arenaNode, error) {
+			for i := len(nodes) - 1; i > 0; i-- {
+				nodes[i-1].Next = nodes[i]
+			}
+			if len(nodes) == 0 {
+				return nil, nil
+			}
+			return nodes[0], nil
+		})
+	}
+	input := "a;b;c;d;e;f;g;h;i;j;k;l;m;n;o;p;q;r;s;t;"
+
+	arena := comb.NewArena()
+	first, err := comb.Run(input, newParser(), comb.WithArena(arena))
+	assert.NoError(t, err)
+	var names string
+	for n := first; n != nil; n = n.Next {
+		names += n.Name
+	}
+	assert.Equal(t, "abcdefghijklmnopqrst", names)
+
+	arena.Reset()
+	assert.Equal(t, arenaNode{}, *first, "the memory should be cleared")
+	second, err := comb.Run(input, newParser(), comb.WithArena(arena))
+	assert.NoError(t, err)
+	assert.Same(t, first, second, "the memory should be reused")
+
+	assert.NotSame(t, comb.Alloc[arenaNode](comb.NewFromString("", 0)), comb.Alloc[arenaNode](comb.NewFromString("", 0)))
+}
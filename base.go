@@ -7,6 +7,7 @@ package comb
 
 import (
 	"context"
+	"errors"
 	"log"
 	"log/slog"
 )
@@ -36,6 +37,19 @@ const RecoverWasteTooMuch = -1 // has to be -1 because of Go Index... functions
 const RecoverNever = -3
 
 const DefaultMaxErrors = 10 // the maximum number of errors to recover from (same as for the Go compiler)
+const FailFast = 0          // maximum number of errors for stopping at the first error (no error recovery)
+
+// DefaultErrorContext is the default number of bytes before and after
+// the error position that are kept as context (see ParserError.Context).
+const DefaultErrorContext = 16
+
+// DefaultErrorLineWidth is the default number of characters of the source
+// line shown in error messages (see State.WithErrorLineWidth).
+const DefaultErrorLineWidth = 30
+
+// ErrTooManyErrors is appended to the errors of a run if the maximum number of errors is reached.
+// Use errors.Is to find it.
+var ErrTooManyErrors = errors.New("too many errors, aborting")
 
 // Parser defines the type of a generic Parser.
 // A few rules should be followed to prevent unexpected behaviour:
@@ -44,6 +58,7 @@ const DefaultMaxErrors = 10 // the maximum number of errors to recover from (sam
 //   - A parser that consumes some input must advance with state.MoveBy()
 type Parser[Output any] interface {
 	ID() int32
+	StableID() uint64 // ID that stays the same across program runs and minor grammar edits
 	Expected() string
 	Parse(state State) (State, Output, *ParserError)                         // used by compiler (for type inference) and tests
 	ParseAny(parentID int32, state State) (State, interface{}, *ParserError) // used by PreparedParser (top -> down)
@@ -55,7 +70,9 @@ type Parser[Output any] interface {
 	IsStepRecoverer() bool
 	SwapRecoverer(Recoverer) // called during the construction phase
 	setID(int32)             // used by PreparedParser; only sets own ID
+	setStableID(uint64)      // used by PreparedParser
 	setParent(int32)         // sets initial parent ID
+	parentID() int32         // returns the initial parent ID
 }
 
 // ============================================================================
@@ -77,7 +94,8 @@ func RunOnBytes[Output any](input []byte, parse Parser[Output]) (Output, error)
 // RunOnString and RunOnBytes are just convenience wrappers around RunOnState.
 // RunOnState is the only one that is concurrent-safe because preparing the parser is NOT.
 func RunOnState[Output any](state State, parser *PreparedParser[Output]) (Output, error) {
-	return parser.parseAll(state)
+	_, out, err := parser.parseAll(state)
+	return out, err
 }
 
 // ============================================================================
@@ -89,12 +107,33 @@ func RunOnState[Output any](state State, parser *PreparedParser[Output]) (Output
 // The parsers store and advance the position within the data but never change the data itself.
 // This allows good error reporting, including the full line of text containing the error.
 type ConstState struct {
-	binary      bool                  // type of input (general)
-	bytes       []byte                // for binary input and parsers
-	text        string                // for string input and text parsers
-	n           int                   // length of the bytes or text
-	maxErrors   int                   // maximal number of errors to recover from
-	parserCache map[int32]interface{} // for private data of parsers
+	binary       bool                  // type of input (general)
+	bytes        []byte                // for binary input and parsers
+	text         string                // for string input and text parsers
+	n            int                   // length of the bytes or text
+	frameStart   int                   // start of the innermost frame (see Within and FrameOffset)
+	maxErrors    int                   // maximal number of errors to recover from
+	recBudget    int                   // maximal number of bytes scanned per recovery attempt (0 = unlimited)
+	parserCache  map[int32]interface{} // for private data of parsers
+	cst          *cstRecorder          // only set in CST mode
+	tracer       *tracer               // only set while recording a run
+	explain      *ErrorReport          // only set while explaining a run (see ExplainErrors)
+	debugger     *debugHook            // only set while debugging a run
+	source       string                // name of the input (only set for included input)
+	outer        *State                // state of the including input (only set for included input)
+	normalize    Normalizer            // normalization for matching (nil if turned off)
+	warn         func(warning error)   // handler for warnings (nil if turned off)
+	limits       Limits                // resource limits for untrusted input
+	ambiguities  *[]ambiguityFinding   // findings of the diagnostic mode (nil if turned off; see FindAmbiguities)
+	forest       *forestRun            // decisions of the current run (nil if turned off; see ParseForest)
+	anchors      []string              // anchor tokens for adaptive recovery ordered by frequency (see WithAdaptiveRecovery)
+	errContext   int                   // number of bytes around errors kept as context (see WithErrorContext)
+	errLineWidth int                   // number of characters of the source line shown in error messages (see WithErrorLineWidth)
+	latin1       bool                  // every byte of the text is a character (see WithLatin1)
+	actions      *actionLog            // semantic actions done in the run (see Action)
+	ctx          context.Context       // cancels the run (nil if turned off; see WithContext)
+	byteIndex    *byteIndex            // positions of bytes for recoverers (nil if turned off; see WithByteIndex)
+	arena        *Arena                // allocates outputs (nil if turned off; see WithArena)
 }
 
 func newConstState(binary bool, bytes []byte, text string, maxErrors int) *ConstState {
@@ -104,6 +143,7 @@ func newConstState(binary bool, bytes []byte, text string, maxErrors int) *Const
 	}
 	return &ConstState{
 		binary: binary, bytes: bytes, text: text, n: n, maxErrors: maxErrors, parserCache: make(map[int32]interface{}),
+		errContext: DefaultErrorContext, errLineWidth: DefaultErrorLineWidth, actions: &actionLog{},
 	}
 }
 
@@ -0,0 +1,94 @@
+// Package benchmarks contains realistic end-to-end benchmarks of comb parsers
+// for JSON, CSV, arithmetic expressions and HTTP headers.
+// They are compared with the standard library (encoding/json, encoding/csv
+// and net/textproto) and hand-written parsers.
+// So performance work has fixed reference points.
+//
+// Run them with:
+//
+//	go test -bench . ./benchmarks
+package benchmarks
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// ============================================================================
+// Input Generators
+//
+// All generators are deterministic, so the results of different runs
+// can be compared.
+
+// GenerateJSON returns a JSON array of n objects.
+func GenerateJSON(n int) string {
+	rnd := rand.New(rand.NewSource(int64(n)))
+	sb := strings.Builder{}
+	sb.WriteString("[\n")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		_, _ = fmt.Fprintf(&sb,
+			`  {"id": %d, "name": "user %d", "score": %d.%d, "active": %t, "tags": ["a", "b%d"], "parent": null}`,
+			i, rnd.Intn(1000), rnd.Intn(100), rnd.Intn(100), rnd.Intn(2) == 0, rnd.Intn(10),
+		)
+	}
+	sb.WriteString("\n]\n")
+	return sb.String()
+}
+
+// GenerateCSV returns n records with 5 fields each.
+// Some of the fields are quoted and contain commas and quotes.
+func GenerateCSV(n int) string {
+	rnd := rand.New(rand.NewSource(int64(n)))
+	sb := strings.Builder{}
+	for i := 0; i < n; i++ {
+		_, _ = fmt.Fprintf(&sb, "%d,name%d,%d,", i, rnd.Intn(1000), rnd.Intn(100000))
+		if rnd.Intn(3) == 0 {
+			_, _ = fmt.Fprintf(&sb, `"street %d, ""apt"" %d"`, rnd.Intn(100), rnd.Intn(10))
+		} else {
+			_, _ = fmt.Fprintf(&sb, "street %d", rnd.Intn(100))
+		}
+		_, _ = fmt.Fprintf(&sb, ",%t\n", rnd.Intn(2) == 0)
+	}
+	return sb.String()
+}
+
+// GenerateExpression returns an arithmetic expression with n operands
+// using the operators '+', '-' and '*' and parentheses.
+func GenerateExpression(n int) string {
+	rnd := rand.New(rand.NewSource(int64(n)))
+	ops := []string{" + ", " - ", " * "}
+	sb := strings.Builder{}
+	open := 0
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteString(ops[rnd.Intn(len(ops))])
+		}
+		if i < n-1 && rnd.Intn(4) == 0 {
+			sb.WriteByte('(')
+			open++
+		}
+		_, _ = fmt.Fprintf(&sb, "%d", rnd.Intn(10))
+		if open > 0 && rnd.Intn(3) == 0 {
+			sb.WriteByte(')')
+			open--
+		}
+	}
+	sb.WriteString(strings.Repeat(")", open))
+	return sb.String()
+}
+
+// GenerateHeaders returns n HTTP header lines followed by an empty line.
+func GenerateHeaders(n int) string {
+	rnd := rand.New(rand.NewSource(int64(n)))
+	names := []string{"Content-Type", "Accept", "User-Agent", "X-Request-Id", "Cache-Control", "Cookie"}
+	sb := strings.Builder{}
+	for i := 0; i < n; i++ {
+		_, _ = fmt.Fprintf(&sb, "%s: value-%d; q=0.%d\r\n", names[rnd.Intn(len(names))], rnd.Intn(1000), rnd.Intn(10))
+	}
+	sb.WriteString("\r\n")
+	return sb.String()
+}
@@ -0,0 +1,171 @@
+package benchmarks
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/stretchr/testify/assert"
+)
+
+var sizes = []struct {
+	name string
+	n    int
+}{
+	{"small", 10},
+	{"medium", 1_000},
+	{"large", 10_000},
+}
+
+func TestParsersAgreeWithBaselines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("JSON", func(t *testing.T) {
+		input := GenerateJSON(20)
+		var want any
+		assert.NoError(t, json.Unmarshal([]byte(input), &want))
+		got, err := comb.RunOnString(input, JSON())
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("CSV", func(t *testing.T) {
+		input := GenerateCSV(20)
+		want, err := csv.NewReader(strings.NewReader(input)).ReadAll()
+		assert.NoError(t, err)
+		got, err := comb.RunOnString(input, CSV())
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+		hand, ok := HandwrittenCSV(input)
+		assert.True(t, ok)
+		assert.Equal(t, want, hand)
+	})
+	t.Run("Expression", func(t *testing.T) {
+		input := GenerateExpression(50)
+		want, ok := HandwrittenExpression(input)
+		assert.True(t, ok)
+		got, err := comb.RunOnString(input, Expression())
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("Headers", func(t *testing.T) {
+		input := GenerateHeaders(20)
+		want, ok := HandwrittenHeaders(input)
+		assert.True(t, ok)
+		got, err := comb.RunOnString(input, Headers())
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+		mime, err := textproto.NewReader(bufio.NewReader(strings.NewReader(input))).ReadMIMEHeader()
+		assert.NoError(t, err)
+		assert.Len(t, mime, countNames(want))
+	})
+}
+
+func countNames(headers []Header) int {
+	names := make(map[string]bool)
+	for _, h := range headers {
+		names[textproto.CanonicalMIMEHeaderKey(h.Name)] = true
+	}
+	return len(names)
+}
+
+// benchmarkComb runs the prepared parser on the input b.N times.
+func benchmarkComb[Output any](b *testing.B, input string, pp *comb.PreparedParser[Output]) {
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := comb.RunOnState(comb.NewFromString(input, comb.DefaultMaxErrors), pp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkBaseline runs the baseline on the input b.N times.
+func benchmarkBaseline(b *testing.B, input string, parse func(string) bool) {
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !parse(input) {
+			b.Fatal("baseline failed")
+		}
+	}
+}
+
+func BenchmarkJSON(b *testing.B) {
+	pp := comb.NewPreparedParser(JSON())
+	for _, size := range sizes {
+		input := GenerateJSON(size.n)
+		b.Run("comb/"+size.name, func(b *testing.B) {
+			benchmarkComb(b, input, pp)
+		})
+		b.Run("encoding_json/"+size.name, func(b *testing.B) {
+			benchmarkBaseline(b, input, func(s string) bool {
+				var v any
+				return json.Unmarshal([]byte(s), &v) == nil
+			})
+		})
+	}
+}
+
+func BenchmarkCSV(b *testing.B) {
+	pp := comb.NewPreparedParser(CSV())
+	for _, size := range sizes {
+		input := GenerateCSV(size.n)
+		b.Run("comb/"+size.name, func(b *testing.B) {
+			benchmarkComb(b, input, pp)
+		})
+		b.Run("encoding_csv/"+size.name, func(b *testing.B) {
+			benchmarkBaseline(b, input, func(s string) bool {
+				_, err := csv.NewReader(strings.NewReader(s)).ReadAll()
+				return err == nil
+			})
+		})
+		b.Run("handwritten/"+size.name, func(b *testing.B) {
+			benchmarkBaseline(b, input, func(s string) bool {
+				_, ok := HandwrittenCSV(s)
+				return ok
+			})
+		})
+	}
+}
+
+func BenchmarkExpression(b *testing.B) {
+	pp := comb.NewPreparedParser(Expression())
+	for _, size := range sizes {
+		input := GenerateExpression(size.n)
+		b.Run("comb/"+size.name, func(b *testing.B) {
+			benchmarkComb(b, input, pp)
+		})
+		b.Run("handwritten/"+size.name, func(b *testing.B) {
+			benchmarkBaseline(b, input, func(s string) bool {
+				_, ok := HandwrittenExpression(s)
+				return ok
+			})
+		})
+	}
+}
+
+func BenchmarkHeaders(b *testing.B) {
+	pp := comb.NewPreparedParser(Headers())
+	for _, size := range sizes {
+		input := GenerateHeaders(size.n)
+		b.Run("comb/"+size.name, func(b *testing.B) {
+			benchmarkComb(b, input, pp)
+		})
+		b.Run("textproto/"+size.name, func(b *testing.B) {
+			benchmarkBaseline(b, input, func(s string) bool {
+				_, err := textproto.NewReader(bufio.NewReader(strings.NewReader(s))).ReadMIMEHeader()
+				return err == nil
+			})
+		})
+		b.Run("handwritten/"+size.name, func(b *testing.B) {
+			benchmarkBaseline(b, input, func(s string) bool {
+				_, ok := HandwrittenHeaders(s)
+				return ok
+			})
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package benchmarks
+
+import (
+	"math"
+	"strings"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// CSV returns a comb parser for CSV as described in RFC 4180 with
+// line feeds as record separators (like encoding/csv accepts them).
+// Every record has to end with a line feed.
+func CSV() comb.Parser[[][]string] {
+	quoted := cmb.Map(
+		cmb.Delimited(
+			cmb.Char('"'),
+			cmb.Many0(cmb.FirstSuccessful(
+				cmb.SatisfyMN("quoted characters", 1, math.MaxInt, func(r rune) bool { return r != '"' }),
+				cmb.Assign(`"`, cmb.String(`""`)),
+			)),
+			cmb.Char('"'),
+		),
+		func(parts []string) (string, error) {
+			return strings.Join(parts, ""), nil
+		},
+	)
+	plain := cmb.SatisfyMN("field", 0, math.MaxInt, func(r rune) bool {
+		return r != ',' && r != '"' && r != '\n' && r != '\r'
+	})
+	record := cmb.Separated1(cmb.FirstSuccessful(quoted, plain), cmb.Char(','), false)
+
+	return cmb.Suffixed(cmb.Many0(cmb.Suffixed(record, cmb.Char('\n'))), cmb.EOF())
+}
+
+// HandwrittenCSV parses CSV like the CSV parser does, but by hand.
+// It is the baseline for the best possible performance.
+func HandwrittenCSV(input string) ([][]string, bool) {
+	var records [][]string
+	for len(input) > 0 {
+		var record []string
+		for {
+			var field string
+			if strings.HasPrefix(input, `"`) {
+				sb := strings.Builder{}
+				i := 1
+				for {
+					j := strings.IndexByte(input[i:], '"')
+					if j < 0 {
+						return nil, false
+					}
+					sb.WriteString(input[i : i+j])
+					i += j + 1
+					if !strings.HasPrefix(input[i:], `"`) {
+						break
+					}
+					sb.WriteByte('"')
+					i++
+				}
+				field, input = sb.String(), input[i:]
+			} else {
+				end := strings.IndexAny(input, ",\"\n\r")
+				if end < 0 {
+					return nil, false
+				}
+				field, input = input[:end], input[end:]
+			}
+			record = append(record, field)
+			if !strings.HasPrefix(input, ",") {
+				break
+			}
+			input = input[1:]
+		}
+		if !strings.HasPrefix(input, "\n") {
+			return nil, false
+		}
+		records = append(records, record)
+		input = input[1:]
+	}
+	return records, true
+}
@@ -0,0 +1,97 @@
+package benchmarks
+
+import (
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// Expression returns a comb parser that evaluates arithmetic expressions of
+// integers with the operators '*', '+' and '-' and parentheses.
+func Expression() comb.Parser[int64] {
+	return cmb.Expression(
+		cmb.Int[int64](false, 10),
+		cmb.InfixLevel([]cmb.InfixOp[int64]{
+			{Op: "*", Fn: func(a, b int64) int64 { return a * b }},
+		}),
+		cmb.InfixLevel([]cmb.InfixOp[int64]{
+			{Op: "+", Fn: func(a, b int64) int64 { return a + b }},
+			{Op: "-", Fn: func(a, b int64) int64 { return a - b }},
+		}),
+	).AddParentheses("(", ")", false).Parser()
+}
+
+// HandwrittenExpression evaluates expressions like the Expression parser
+// does, but with a hand-written recursive descent parser.
+// It is the baseline for the best possible performance.
+func HandwrittenExpression(input string) (int64, bool) {
+	p := exprParser{input: input}
+	v, ok := p.sum()
+	p.space()
+	return v, ok && p.pos == len(p.input)
+}
+
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) sum() (int64, bool) {
+	v, ok := p.product()
+	for ok {
+		p.space()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return v, true
+		}
+		op := p.input[p.pos]
+		p.pos++
+		var w int64
+		if w, ok = p.product(); op == '+' {
+			v += w
+		} else {
+			v -= w
+		}
+	}
+	return 0, false
+}
+
+func (p *exprParser) product() (int64, bool) {
+	v, ok := p.operand()
+	for ok {
+		p.space()
+		if p.pos >= len(p.input) || p.input[p.pos] != '*' {
+			return v, true
+		}
+		p.pos++
+		var w int64
+		w, ok = p.operand()
+		v *= w
+	}
+	return 0, false
+}
+
+func (p *exprParser) operand() (int64, bool) {
+	p.space()
+	if p.pos < len(p.input) && p.input[p.pos] == '(' {
+		p.pos++
+		v, ok := p.sum()
+		p.space()
+		if !ok || p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return 0, false
+		}
+		p.pos++
+		return v, true
+	}
+	start := p.pos
+	var v int64
+	for p.pos < len(p.input) && '0' <= p.input[p.pos] && p.input[p.pos] <= '9' {
+		v = v*10 + int64(p.input[p.pos]-'0')
+		p.pos++
+	}
+	return v, p.pos > start
+}
+
+func (p *exprParser) space() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
@@ -0,0 +1,60 @@
+package benchmarks
+
+import (
+	"math"
+	"strings"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// Header is a single HTTP header field.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// Headers returns a comb parser for HTTP header fields (RFC 9112) up to
+// and including the empty line that ends them.
+// Obsolete line folding isn't supported.
+func Headers() comb.Parser[[]Header] {
+	name := cmb.SatisfyMN("field name", 1, math.MaxInt, isTokenChar)
+	value := cmb.SatisfyMN("field value", 0, math.MaxInt, func(r rune) bool {
+		return r != '\r' && r != '\n'
+	})
+	field := cmb.Map4(
+		name, cmb.Char(':'), value, cmb.CRLF(),
+		func(name string, _ rune, value string, _ string) (Header, error) {
+			return Header{Name: name, Value: strings.Trim(value, " \t")}, nil
+		},
+	)
+	return cmb.Suffixed(cmb.Many0(field), cmb.CRLF())
+}
+
+// HandwrittenHeaders parses HTTP header fields like the Headers parser does,
+// but by hand.
+// It is the baseline for the best possible performance.
+func HandwrittenHeaders(input string) ([]Header, bool) {
+	var headers []Header
+	for {
+		line, rest, ok := strings.Cut(input, "\r\n")
+		if !ok {
+			return nil, false
+		}
+		input = rest
+		if line == "" {
+			return headers, true
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || name == "" || strings.IndexFunc(name, func(r rune) bool { return !isTokenChar(r) }) >= 0 {
+			return nil, false
+		}
+		headers = append(headers, Header{Name: name, Value: strings.Trim(value, " \t")})
+	}
+}
+
+// isTokenChar returns true for the characters allowed in field names.
+func isTokenChar(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+		strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
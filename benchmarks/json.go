@@ -0,0 +1,69 @@
+package benchmarks
+
+import (
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// JSON returns a comb parser for JSON.
+// The output has the same types as encoding/json produces for `any`:
+// map[string]any, []any, float64, string, bool and nil.
+// Only the escape sequences of cmb.StringLiteral are supported.
+func JSON() comb.Parser[any] {
+	var value comb.Parser[any]
+
+	ws := cmb.Whitespace0()
+	token := func(r rune) comb.Parser[rune] {
+		return cmb.Suffixed(cmb.Char(r), ws)
+	}
+	str := cmb.Suffixed(cmb.Map(cmb.StringLiteral(cmb.StringConfig{}), func(lit cmb.StringLit) (string, error) {
+		return lit.Value, nil
+	}), ws)
+	lazyValue := comb.LazyBranchParser(func() comb.Parser[any] {
+		return value
+	})
+
+	type member struct {
+		key   string
+		value any
+	}
+	object := cmb.Map(
+		cmb.Delimited(
+			token('{'),
+			cmb.Separated0(cmb.Map3(str, token(':'), lazyValue, func(key string, _ rune, value any) (member, error) {
+				return member{key: key, value: value}, nil
+			}), token(','), false),
+			token('}'),
+		),
+		func(members []member) (any, error) {
+			obj := make(map[string]any, len(members))
+			for _, m := range members {
+				obj[m.key] = m.value
+			}
+			return obj, nil
+		},
+	)
+	array := cmb.Map(
+		cmb.Delimited(token('['), cmb.Separated0(lazyValue, token(','), false), token(']')),
+		func(values []any) (any, error) {
+			if values == nil {
+				values = []any{}
+			}
+			return values, nil
+		},
+	)
+	literal := func(s string, v any) comb.Parser[any] {
+		return cmb.Assign(v, cmb.Suffixed(cmb.String(s), ws))
+	}
+
+	value = cmb.FirstSuccessful(
+		object,
+		array,
+		cmb.Map(str, func(s string) (any, error) { return s, nil }),
+		cmb.Map(cmb.Suffixed(cmb.Float64(true, 10), ws), func(f float64) (any, error) { return f, nil }),
+		literal("true", true),
+		literal("false", false),
+		literal("null", nil),
+	)
+	return cmb.Delimited(ws, value, cmb.EOF())
+}
@@ -0,0 +1,74 @@
+package comb
+
+// ============================================================================
+// Bind Parser
+//
+
+// Bind applies the parser `p` and then the parser returned by `f`
+// for the output of `p`.
+// This allows data dependent grammars like "read N, then parse N items" or
+// schema dependent constructs.
+//
+// Only `p` is known to the PreparedParser, so error recovery works as usual
+// inside of `p`.
+// The parsers returned by `f` are created at runtime and behave like leaf parsers
+// to the outside world. Their errors look as if coming from Bind itself.
+// They get a parser cache of their own (see State.PutIntoCache).
+func Bind[A, B any](p Parser[A], f func(A) Parser[B]) Parser[B] {
+	var bp Parser[B]
+
+	if f == nil {
+		panic("Bind: f is nil")
+	}
+
+	bp = NewBranchParser[B](
+		"Bind",
+		func() []AnyParser {
+			return []AnyParser{p}
+		}, func(
+			childID int32,
+			childStartState, childState State,
+			childOut interface{},
+			childErr *ParserError,
+			data interface{},
+		) (State, B, *ParserError, interface{}) {
+			var zero B
+			Debugf("Bind.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				childStartState = childState
+				childState, childOut, childErr = p.ParseAny(bp.ID(), childStartState)
+			}
+			if childErr != nil {
+				return childState, zero, childErr, nil
+			}
+			a, _ := childOut.(A)
+			dp := f(a)
+			if dp == nil {
+				return childState, zero, childState.NewSemanticError("no parser to bind for %v", a), nil
+			}
+			dState := childState.withOwnCache()
+			nState, aOut, err := dp.ParseAny(ParentUnknown, dState)
+			if nState.constant == dState.constant {
+				nState.constant = childState.constant
+			}
+			out, _ := aOut.(B)
+			if err != nil {
+				return nState, out, claimDynamicError(err), nil
+			}
+			return nState, out, nil, nil
+		},
+	)
+	return bp
+}
+
+// claimDynamicError takes over an error from a parser that has been created
+// at runtime.
+// Its IDs are unknown to the PreparedParser, so any data stored for them
+// could clash with the data of registered parsers.
+func claimDynamicError(err *ParserError) *ParserError {
+	if err != nil {
+		err.parserID = -1
+		err.parserData = nil
+	}
+	return err
+}
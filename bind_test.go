@@ -0,0 +1,53 @@
+package comb
+
+import (
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	closing := map[rune]rune{'(': ')', '[': ']'}
+	openParen := NewParser[rune]("opening parenthesis", func(state State) (State, rune, *ParserError) {
+		for open := range closing {
+			if nState, r, err := Char(open).Parse(state); err == nil {
+				return nState, r, nil
+			}
+		}
+		return state, 0, state.NewSyntaxError("opening parenthesis")
+	}, nil)
+
+	tests := []struct {
+		name       string
+		input      string
+		wantOutput rune
+		wantErrors int
+	}{
+		{
+			name:       "round parentheses",
+			input:      "()",
+			wantOutput: ')',
+		}, {
+			name:       "square brackets",
+			input:      "[]",
+			wantOutput: ']',
+		}, {
+			name:       "mismatch",
+			input:      "(]",
+			wantErrors: 1,
+		},
+	}
+	for _, tc := range tests {
+		tt := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tt.name, func(t *testing.T) {
+			parser := Bind(openParen, func(open rune) Parser[rune] {
+				return Char(closing[open])
+			})
+			_, gotOutput, err := NewPreparedParser[rune](parser).parseAll(NewFromString(tt.input, 10))
+			if got, want := len(UnwrapErrors(err)), tt.wantErrors; got != want {
+				t.Errorf("err=%v, want=%d", err, want)
+			}
+			if tt.wantErrors == 0 && gotOutput != tt.wantOutput {
+				t.Errorf("got output=%q, want=%q", gotOutput, tt.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package comb
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// ============================================================================
+// Byte Index For Fast Recovery
+//
+
+// DefaultIndexedBytes are the bytes indexed by WithByteIndex if none are given.
+var DefaultIndexedBytes = []byte("\n;,)]}")
+
+// byteIndex contains the sorted positions of the indexed bytes in the input.
+type byteIndex struct {
+	indexed   [256]bool
+	positions [256][]int
+}
+
+// WithByteIndex returns the state with an index of all positions of the bytes
+// (or DefaultIndexedBytes) in the input.
+// The input is scanned once up front.
+// Recoverers (like cmb.IndexOf) consult the index with IndexByte, IndexAnyByte
+// and Index instead of scanning the input again and again.
+// This keeps error recovery on large inputs fast.
+// It has to be called before parsing starts.
+func (st State) WithByteIndex(bs ...byte) State {
+	if len(bs) == 0 {
+		bs = DefaultIndexedBytes
+	}
+	idx := &byteIndex{}
+	for _, b := range bs {
+		idx.indexed[b] = true
+	}
+	constant := *st.constant
+	constant.byteIndex = idx.of(&constant)
+	st.constant = &constant
+	return st
+}
+
+// of returns a new index of the same bytes for the input of the constant state.
+// It is used when the input is replaced (e.g. by PushInput).
+func (idx *byteIndex) of(constant *ConstState) *byteIndex {
+	nIdx := &byteIndex{indexed: idx.indexed}
+	if constant.binary {
+		for i, b := range constant.bytes {
+			if nIdx.indexed[b] {
+				nIdx.positions[b] = append(nIdx.positions[b], i)
+			}
+		}
+	} else {
+		for i := 0; i < len(constant.text); i++ {
+			if b := constant.text[i]; nIdx.indexed[b] {
+				nIdx.positions[b] = append(nIdx.positions[b], i)
+			}
+		}
+	}
+	return nIdx
+}
+
+// IndexByte returns the number of bytes from the current position to
+// the next occurrence of the byte or -1 if it doesn't occur anymore.
+// It uses the byte index if the byte is indexed (see WithByteIndex).
+func (st State) IndexByte(b byte) int {
+	return st.indexByteFrom(st.pos, b)
+}
+
+// IndexAnyByte returns the number of bytes from the current position to
+// the next occurrence of any of the bytes in the set or -1 if none of them
+// occurs anymore.
+// It uses the byte index if all bytes of the set are indexed (see WithByteIndex).
+func (st State) IndexAnyByte(set string) int {
+	if idx := st.constant.byteIndex; idx != nil && idx.covers(set) {
+		pos := -1
+		for i := 0; i < len(set); i++ {
+			if j := st.indexByteFrom(st.pos, set[i]); j >= 0 && (pos < 0 || j < pos) {
+				pos = j
+			}
+		}
+		return pos
+	}
+	if st.constant.binary {
+		i := 0
+		for _, b := range st.constant.bytes[st.pos:st.constant.n] {
+			if strings.IndexByte(set, b) >= 0 {
+				return i
+			}
+			i++
+		}
+		return -1
+	}
+	return strings.IndexAny(st.constant.text[st.pos:st.constant.n], set)
+}
+
+// Index returns the number of bytes from the current position to the next
+// occurrence of the stop token or -1 if it doesn't occur anymore.
+// It uses the byte index if the first byte of the token is indexed (see WithByteIndex).
+func (st State) Index(stop string) int {
+	if stop == "" {
+		return 0
+	}
+	idx := st.constant.byteIndex
+	if (idx == nil || !idx.indexed[stop[0]]) && !st.constant.binary {
+		return strings.Index(st.constant.text[st.pos:st.constant.n], stop)
+	}
+	for pos := st.pos; ; {
+		i := st.indexByteFrom(pos, stop[0])
+		if i < 0 {
+			return -1
+		}
+		pos += i
+		if st.hasPrefixAt(pos, stop) {
+			return pos - st.pos
+		}
+		pos++
+	}
+}
+
+// indexByteFrom returns the number of bytes from pos to the next occurrence
+// of the byte or -1 if it doesn't occur anymore.
+func (st State) indexByteFrom(pos int, b byte) int {
+	if idx := st.constant.byteIndex; idx != nil && idx.indexed[b] {
+		positions := idx.positions[b]
+		i, _ := slices.BinarySearch(positions, pos)
+		if i >= len(positions) || positions[i] >= st.constant.n {
+			return -1
+		}
+		return positions[i] - pos
+	}
+	if st.constant.binary {
+		return bytes.IndexByte(st.constant.bytes[pos:st.constant.n], b)
+	}
+	return strings.IndexByte(st.constant.text[pos:st.constant.n], b)
+}
+
+// hasPrefixAt returns true if the input at pos starts with the prefix.
+func (st State) hasPrefixAt(pos int, prefix string) bool {
+	end := pos + len(prefix)
+	if end > st.constant.n {
+		return false
+	}
+	if st.constant.binary {
+		return string(st.constant.bytes[pos:end]) == prefix
+	}
+	return st.constant.text[pos:end] == prefix
+}
+
+// covers returns true if all bytes of the set are indexed.
+func (idx *byteIndex) covers(set string) bool {
+	for i := 0; i < len(set); i++ {
+		if !idx.indexed[set[i]] {
+			return false
+		}
+	}
+	return true
+}
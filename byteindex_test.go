@@ -0,0 +1,93 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteIndex(t *testing.T) {
+	t.Parallel()
+
+	const input = "a;b\nc;;d\nx;y\n"
+	testCases := []struct {
+		name  string
+		pos   int
+		index func(comb.State) int
+		want  int
+	}{
+		{
+			name:  "IndexByte should find the next byte",
+			pos:   2,
+			index: func(st comb.State) int { return st.IndexByte(';') },
+			want:  3,
+		}, {
+			name:  "IndexByte should report missing bytes",
+			pos:   12,
+			index: func(st comb.State) int { return st.IndexByte(';') },
+			want:  -1,
+		}, {
+			name:  "IndexAnyByte should find the nearest byte",
+			pos:   2,
+			index: func(st comb.State) int { return st.IndexAnyByte(";\n") },
+			want:  1,
+		}, {
+			name:  "Index should find a string starting with an indexed byte",
+			pos:   0,
+			index: func(st comb.State) int { return st.Index(";d") },
+			want:  6,
+		}, {
+			name:  "Index should find a string starting with another byte",
+			pos:   0,
+			index: func(st comb.State) int { return st.Index("x;") },
+			want:  9,
+		}, {
+			name:  "Index should report missing strings",
+			pos:   0,
+			index: func(st comb.State) int { return st.Index(";z") },
+			want:  -1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			text := comb.NewFromString(input, 0)
+			binary := comb.NewFromBytes([]byte(input), 0)
+			assert.Equal(t, tc.want, tc.index(text.MoveBy(tc.pos)), "text")
+			assert.Equal(t, tc.want, tc.index(binary.MoveBy(tc.pos)), "binary")
+			assert.Equal(t, tc.want, tc.index(text.WithByteIndex().MoveBy(tc.pos)), "indexed text")
+			assert.Equal(t, tc.want, tc.index(binary.WithByteIndex().MoveBy(tc.pos)), "indexed binary")
+		})
+	}
+}
+
+func TestByteIndexRecovery(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[[]string] {
+		return cmb.Suffixed(cmb.Many1(cmb.Suffixed(cmb.Alpha1(), comb.SafeSpot(cmb.Char(';')))), cmb.EOF())
+	}
+	input := "ab;12;cd;34;ef;"
+
+	wantOut, wantErr := comb.RunOnState(comb.NewFromString(input, 10), comb.NewPreparedParser(newParser()))
+	gotOut, gotErr := comb.RunOnState(comb.NewFromString(input, 10).WithByteIndex(), comb.NewPreparedParser(newParser()))
+	assert.Equal(t, wantOut, gotOut)
+	assert.Equal(t, wantErr, gotErr)
+	assert.Len(t, comb.UnwrapErrors(gotErr), 2)
+}
+
+func TestByteIndexReplacedInput(t *testing.T) {
+	t.Parallel()
+
+	outer := comb.NewFromString("abc;def;", 0).WithByteIndex(';').MoveBy(4)
+	included := comb.PushInput(outer, "x;", "included")
+	assert.Equal(t, 1, included.IndexByte(';'), "included input")
+
+	strict := comb.NewFromString("\xff;", 0).WithByteIndex(';').WithStrictUTF8()
+	assert.Equal(t, 3, strict.IndexByte(';'), "input with replaced invalid UTF-8")
+}
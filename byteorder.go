@@ -0,0 +1,48 @@
+package comb
+
+import "encoding/binary"
+
+// ============================================================================
+// Byte Order Of Binary Numbers
+//
+
+// ByteOrder returns the byte order of binary numbers (see WithByteOrder).
+// It is big endian (network byte order) if no byte order has been set.
+func (st State) ByteOrder() binary.ByteOrder {
+	if st.byteOrder == nil {
+		return binary.BigEndian
+	}
+	return st.byteOrder
+}
+
+// WithByteOrder returns the state with the byte order used by the parsers
+// of binary numbers (e.g. cmb.Binary).
+// It can be called before parsing starts or by parsers for the rest of the input.
+// So formats that switch the byte order mid-stream (e.g. TIFF) can use the
+// same parsers for both byte orders (see SetByteOrder).
+func (st State) WithByteOrder(order binary.ByteOrder) State {
+	if order == nil {
+		panic("WithByteOrder is unable to handle a nil byte order")
+	}
+	st.byteOrder = order
+	return st
+}
+
+// SetByteOrder sets the byte order for the rest of the input
+// (see State.WithByteOrder).
+// It doesn't consume any input and always succeeds.
+// The output is the byte order.
+// Use it after the byte order mark of a format, e.g.:
+//
+//	cmb.FirstSuccessful(
+//		cmb.Prefixed(cmb.Bytes([]byte("II")), comb.SetByteOrder(binary.LittleEndian)),
+//		cmb.Prefixed(cmb.Bytes([]byte("MM")), comb.SetByteOrder(binary.BigEndian)),
+//	)
+func SetByteOrder(order binary.ByteOrder) Parser[binary.ByteOrder] {
+	if order == nil {
+		panic("SetByteOrder is unable to handle a nil byte order")
+	}
+	return NewParser[binary.ByteOrder]("SetByteOrder", func(state State) (State, binary.ByteOrder, *ParserError) {
+		return state.WithByteOrder(order), order, nil
+	}, neverRecover)
+}
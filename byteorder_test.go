@@ -0,0 +1,28 @@
+package comb
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestByteOrder(t *testing.T) {
+	t.Parallel()
+
+	state := NewFromBytes([]byte{1, 2}, 10)
+	if got := state.ByteOrder(); got != binary.BigEndian {
+		t.Errorf("got default byte order %v, want big endian", got)
+	}
+	nState, out, err := SetByteOrder(binary.LittleEndian).Parse(state)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	if out != binary.LittleEndian || nState.ByteOrder() != binary.LittleEndian {
+		t.Errorf("got byte order %v (output %v), want little endian", nState.ByteOrder(), out)
+	}
+	if nState.CurrentPos() != 0 {
+		t.Errorf("got position %d, want 0", nState.CurrentPos())
+	}
+	if got := nState.MoveBy(1).ByteOrder(); got != binary.LittleEndian {
+		t.Errorf("got byte order %v after moving, want little endian", got)
+	}
+}
@@ -0,0 +1,329 @@
+package cmb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unsafe"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Parse Binary Data
+//
+
+// IntegerType is a constraint that permits any integer type.
+type IntegerType interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Uvarint parses an unsigned variable-length integer
+// as used by Protocol Buffers and `encoding/binary`.
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+func Uvarint() comb.Parser[uint64] {
+	var p comb.Parser[uint64]
+
+	expected := "unsigned varint"
+
+	parse := func(state comb.State) (comb.State, uint64, *comb.ParserError) {
+		ui, n := binary.Uvarint(state.CurrentBytes())
+		if n == 0 {
+			return state, 0, comb.MarkIncomplete(state.NewSyntaxError("%s (at EOF)", expected))
+		}
+		if n < 0 {
+			return state, 0, state.NewSemanticError("%s overflows 64 bits", expected)
+		}
+		return state.MoveBy(n), ui, nil
+	}
+
+	p = comb.NewParser[uint64](expected, parse, varintRecoverer)
+	return p
+}
+
+// Varint parses a signed (zig-zag encoded) variable-length integer
+// as used by Protocol Buffers and `encoding/binary`.
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+func Varint() comb.Parser[int64] {
+	var p comb.Parser[int64]
+
+	expected := "signed varint"
+
+	parse := func(state comb.State) (comb.State, int64, *comb.ParserError) {
+		i, n := binary.Varint(state.CurrentBytes())
+		if n == 0 {
+			return state, 0, comb.MarkIncomplete(state.NewSyntaxError("%s (at EOF)", expected))
+		}
+		if n < 0 {
+			return state, 0, state.NewSemanticError("%s overflows 64 bits", expected)
+		}
+		return state.MoveBy(n), i, nil
+	}
+
+	p = comb.NewParser[int64](expected, parse, varintRecoverer)
+	return p
+}
+
+// varintRecoverer recovers to the start of the next valid varint.
+// A varint ends with the first byte that hasn't got the high bit set.
+// Varints that overflow 64 bits are skipped completely.
+func varintRecoverer(state comb.State, _ interface{}) (int, interface{}) {
+	input := state.CurrentBytes()
+	for i := 0; i < len(input); {
+		_, n := binary.Uvarint(input[i:])
+		if n > 0 {
+			return i, nil
+		}
+		if n == 0 { // no end of a varint anymore
+			break
+		}
+		for i < len(input) && input[i] >= 0x80 { // skip the overflowing varint
+			i++
+		}
+		i++
+	}
+	return comb.RecoverWasteTooMuch, nil
+}
+
+// FixedNumber is a constraint that permits the number types with a fixed size.
+type FixedNumber interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~float32 | ~float64
+}
+
+// Binary parses a number of type T in the byte order of the state
+// (see comb.State.WithByteOrder and comb.SetByteOrder).
+// The default byte order is big endian (network byte order).
+// So formats that switch the byte order mid-stream need only one parser
+// per number type.
+// This parser has an optimized recoverer.
+func Binary[T FixedNumber]() comb.Parser[T] {
+	var p comb.Parser[T]
+
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	expected := fmt.Sprintf("binary %T", zero)
+
+	parse := func(state comb.State) (comb.State, T, *comb.ParserError) {
+		var n T
+		if state.BytesRemaining() < size {
+			return state, n, comb.MarkIncomplete(
+				state.NewSyntaxError("%s (only %d bytes of input left)", expected, state.BytesRemaining()),
+			)
+		}
+		if _, err := binary.Decode(state.CurrentBytes()[:size], state.ByteOrder(), &n); err != nil {
+			return state, n, state.NewSemanticError("%s: %w", expected, err)
+		}
+		return state.MoveBy(size), n, nil
+	}
+
+	recoverer := func(state comb.State, _ interface{}) (int, interface{}) {
+		if state.BytesRemaining() < size {
+			return comb.RecoverWasteTooMuch, nil
+		}
+		return 0, nil
+	}
+
+	p = comb.NewParser[T](expected, parse, recoverer)
+	return p
+}
+
+// TLV parses a tag-length-value triple.
+// First the tag is parsed by `tagParser` and the length by `lenParser`.
+// Then the value is parsed by the parser returned from `bodyFor(tag)`.
+// The body parser only sees the number of bytes given by the length
+// (see comb.Within) and has to consume all of them.
+//
+// NOTE:
+//   - Even though TLV accepts parsers as arguments, it behaves like a leaf parser
+//     to the outside world. Errors of the sub-parsers look as if coming from TLV itself.
+//   - The recoverer of the tag parser is used.
+func TLV[Tag any, Len IntegerType, Output any](
+	tagParser comb.Parser[Tag], lenParser comb.Parser[Len], bodyFor func(Tag) comb.Parser[Output],
+) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	if bodyFor == nil {
+		panic("TLV: bodyFor is nil")
+	}
+	expected := fmt.Sprintf("TLV (tag: %s, length: %s)", tagParser.Expected(), lenParser.Expected())
+
+	parse := func(state comb.State) (comb.State, Output, *comb.ParserError) {
+		var zero Output
+
+		nState, aTag, err := tagParser.ParseAny(comb.ParentUnknown, state)
+		if err != nil {
+			return state, zero, comb.ClaimError(err)
+		}
+		tag, _ := aTag.(Tag)
+		nState, aLen, err := lenParser.ParseAny(comb.ParentUnknown, nState)
+		if err != nil {
+			return state, zero, comb.ClaimError(err)
+		}
+		length, _ := aLen.(Len)
+		if length < 0 {
+			return state, zero, nState.NewSemanticError("negative TLV length %d", length)
+		}
+		if int(length) > nState.BytesRemaining() {
+			return state, zero, comb.MarkIncomplete(nState.NewSyntaxError(
+				"TLV value of %d bytes (only %d bytes of input left)", length, nState.BytesRemaining(),
+			))
+		}
+
+		body := bodyFor(tag)
+		if body == nil {
+			return state, zero, state.NewSemanticError("unknown TLV tag %v", tag)
+		}
+		bState, aOut, err := comb.Within(int(length), body).ParseAny(comb.ParentUnknown, nState)
+		out, _ := aOut.(Output)
+		if err != nil {
+			return state, out, comb.ClaimError(err)
+		}
+		return bState, out, nil
+	}
+
+	p = comb.NewParser[Output](expected, parse, tagParser.Recover)
+	return p
+}
+
+// Padding parses exactly `n` bytes of padding with arbitrary values.
+func Padding(n int) comb.Parser[[]byte] {
+	var p comb.Parser[[]byte]
+
+	if n < 0 {
+		panic("Padding is unable to handle negative `n`")
+	}
+	expected := fmt.Sprintf("padding of %d bytes", n)
+
+	parse := func(state comb.State) (comb.State, []byte, *comb.ParserError) {
+		if state.BytesRemaining() < n {
+			return state, []byte{}, comb.MarkIncomplete(
+				state.NewSyntaxError("%s (only %d bytes of input left)", expected, state.BytesRemaining()),
+			)
+		}
+		nState := state.MoveBy(n)
+		return nState, state.BytesTo(nState), nil
+	}
+
+	p = comb.NewParser[[]byte](expected, parse, Forbidden())
+	return p
+}
+
+// AlignTo parses padding bytes with arbitrary values up to the next multiple
+// of `n` bytes relative to the start of the innermost frame (see comb.Within).
+// Outside of any frame the alignment is relative to the start of the input.
+// Nothing is consumed if the input is aligned already.
+// Use AlignToAbsolute for alignment relative to the start of the input
+// inside of frames.
+func AlignTo(n int) comb.Parser[[]byte] {
+	return alignTo(n, fmt.Sprintf("alignment to %d bytes", n), comb.State.FrameOffset)
+}
+
+// AlignToAbsolute is AlignTo but always relative to the start of the input.
+func AlignToAbsolute(n int) comb.Parser[[]byte] {
+	return alignTo(n, fmt.Sprintf("absolute alignment to %d bytes", n), comb.State.CurrentPos)
+}
+
+func alignTo(n int, expected string, offset func(comb.State) int) comb.Parser[[]byte] {
+	var p comb.Parser[[]byte]
+
+	if n <= 0 {
+		panic("AlignTo is unable to handle `n` <= 0")
+	}
+
+	parse := func(state comb.State) (comb.State, []byte, *comb.ParserError) {
+		nState, err := skipToBoundary(state, n, offset(state), expected)
+		if err != nil {
+			return state, []byte{}, err
+		}
+		return nState, state.BytesTo(nState), nil
+	}
+
+	p = comb.NewParser[[]byte](expected, parse, Forbidden())
+	return p
+}
+
+// PaddedTo parses `p` followed by padding bytes with arbitrary values up to
+// the next multiple of `n` bytes relative to the start of the innermost frame
+// (like AlignTo).
+// This is common for records and chunks in binary file formats.
+//
+// NOTE:
+//   - Even though PaddedTo accepts a parser as argument, it behaves like a leaf parser
+//     to the outside world. Errors of `p` look as if coming from PaddedTo itself.
+//   - The recoverer of `p` is used.
+func PaddedTo[Output any](n int, p comb.Parser[Output]) comb.Parser[Output] {
+	var pp comb.Parser[Output]
+
+	if n <= 0 {
+		panic("PaddedTo is unable to handle `n` <= 0")
+	}
+	expected := fmt.Sprintf("%s padded to %d bytes", p.Expected(), n)
+
+	parse := func(state comb.State) (comb.State, Output, *comb.ParserError) {
+		pState, aOut, err := p.ParseAny(comb.ParentUnknown, state)
+		out, _ := aOut.(Output)
+		if err != nil {
+			return state, out, comb.ClaimError(err)
+		}
+		nState, err := skipToBoundary(pState, n, pState.FrameOffset(), expected)
+		if err != nil {
+			return state, out, err
+		}
+		return nState, out, nil
+	}
+
+	pp = comb.NewParser[Output](expected, parse, p.Recover)
+	return pp
+}
+
+// skipToBoundary moves the state to the next multiple of `n` bytes
+// given the current `offset`.
+func skipToBoundary(state comb.State, n, offset int, expected string) (comb.State, *comb.ParserError) {
+	pad := (n - offset%n) % n
+	if state.BytesRemaining() < pad {
+		return state, comb.MarkIncomplete(state.NewSyntaxError(
+			"%s (%d bytes of padding needed but only %d bytes of input left)", expected, pad, state.BytesRemaining(),
+		))
+	}
+	return state.MoveBy(pad), nil
+}
+
+// Checksummed parses a body followed by a checksum and verifies the checksum.
+// `verify` gets the raw bytes consumed by the body parser and the parsed checksum.
+// If it returns an error, Checksummed fails with a semantic error
+// at the position of the checksum that wraps it (see errors.Is).
+//
+// NOTE:
+//   - Even though Checksummed accepts parsers as arguments, it behaves like a leaf parser
+//     to the outside world. Errors of the sub-parsers look as if coming from Checksummed itself.
+//     So a frame with a bad checksum is always rejected as a whole.
+//   - There is no optimized recoverer.
+func Checksummed[Output, C any](
+	body comb.Parser[Output], checksumParser comb.Parser[C], verify func(consumedBytes []byte, c C) error,
+) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	if verify == nil {
+		panic("Checksummed: verify is nil")
+	}
+	expected := fmt.Sprintf("%s followed by checksum %s", body.Expected(), checksumParser.Expected())
+
+	parse := func(state comb.State) (comb.State, Output, *comb.ParserError) {
+		bState, aOut, err := body.ParseAny(comb.ParentUnknown, state)
+		out, _ := aOut.(Output)
+		if err != nil {
+			return state, out, comb.ClaimError(err)
+		}
+		cState, aSum, err := checksumParser.ParseAny(comb.ParentUnknown, bState)
+		if err != nil {
+			return state, out, comb.ClaimError(err)
+		}
+		sum, _ := aSum.(C)
+		if vErr := verify(state.BytesTo(bState), sum); vErr != nil {
+			return state, out, bState.NewSemanticError("checksum mismatch: %w", vErr)
+		}
+		return cState, out, nil
+	}
+
+	p = comb.NewParser[Output](expected, parse, nil)
+	return p
+}
@@ -0,0 +1,381 @@
+package cmb_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVarint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         []byte
+		wantErr       bool
+		wantUOutput   uint64
+		wantOutput    int64
+		wantRemaining int
+	}{
+		{
+			name:          "single byte",
+			input:         []byte{0x02, 0xff},
+			wantUOutput:   2,
+			wantOutput:    1,
+			wantRemaining: 1,
+		}, {
+			name:          "multiple bytes",
+			input:         []byte{0xac, 0x02},
+			wantUOutput:   300,
+			wantOutput:    150,
+			wantRemaining: 0,
+		}, {
+			name:    "incomplete input",
+			input:   []byte{0xac},
+			wantErr: true,
+		}, {
+			name:    "empty input",
+			input:   []byte{},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := comb.NewFromBytes(tc.input, 0)
+			nState, gotUOutput, err := cmb.Uvarint().Parse(state)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if err != nil && !err.Incomplete() {
+				t.Errorf("got complete error %v, want incomplete", err)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.wantUOutput, gotUOutput)
+				assert.Equal(t, tc.wantRemaining, nState.BytesRemaining())
+			}
+
+			nState, gotOutput, err := cmb.Varint().Parse(state)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+				assert.Equal(t, tc.wantRemaining, nState.BytesRemaining())
+			}
+		})
+	}
+}
+
+func TestAlignTo(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        comb.Parser[[]byte]
+		input         []byte
+		wantErr       bool
+		wantOutput    []byte
+		wantRemaining int
+	}{
+		{
+			name:          "aligned input should consume nothing",
+			parser:        cmb.Prefixed(cmb.Padding(4), cmb.AlignTo(4)),
+			input:         []byte{1, 2, 3, 4, 5, 6},
+			wantOutput:    []byte{},
+			wantRemaining: 2,
+		}, {
+			name:          "padding should be consumed up to the boundary",
+			parser:        cmb.Prefixed(cmb.Padding(1), cmb.AlignTo(4)),
+			input:         []byte{1, 0, 0, 0, 5},
+			wantOutput:    []byte{0, 0, 0},
+			wantRemaining: 1,
+		}, {
+			name: "alignment should be relative to the frame",
+			parser: cmb.Prefixed(cmb.Padding(1), comb.Within(3,
+				cmb.Prefixed(cmb.Padding(1), cmb.Suffixed(cmb.AlignTo(2), cmb.Padding(1))),
+			)),
+			input:         []byte{9, 1, 0, 2, 7},
+			wantOutput:    []byte{0},
+			wantRemaining: 1,
+		}, {
+			name:          "absolute alignment should ignore the frame",
+			parser:        cmb.Prefixed(cmb.Padding(1), comb.Within(3, cmb.Prefixed(cmb.Padding(1), cmb.AlignToAbsolute(4)))),
+			input:         []byte{9, 1, 0, 2, 7},
+			wantOutput:    []byte{0, 2},
+			wantRemaining: 1,
+		}, {
+			name:    "padding beyond the frame should fail",
+			parser:  cmb.Prefixed(cmb.Padding(1), comb.Within(3, cmb.Prefixed(cmb.Padding(1), cmb.AlignTo(4)))),
+			input:   []byte{9, 1, 0, 2, 7},
+			wantErr: true,
+		}, {
+			name:    "missing padding should fail",
+			parser:  cmb.Prefixed(cmb.Padding(1), cmb.AlignTo(4)),
+			input:   []byte{1, 0},
+			wantErr: true,
+		}, {
+			name:          "padded parser should skip to the boundary",
+			parser:        cmb.PaddedTo(4, cmb.Bytes([]byte{1, 2, 3, 4, 5})),
+			input:         []byte{1, 2, 3, 4, 5, 0, 0, 0, 9},
+			wantOutput:    []byte{1, 2, 3, 4, 5},
+			wantRemaining: 1,
+		}, {
+			name:    "padded parser without padding should fail",
+			parser:  cmb.PaddedTo(4, cmb.Bytes([]byte{1, 2, 3, 4, 5})),
+			input:   []byte{1, 2, 3, 4, 5, 0},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			nState, gotOutput, err := tc.parser.Parse(comb.NewFromBytes(tc.input, 0))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+				assert.Equal(t, tc.wantRemaining, nState.BytesRemaining())
+			}
+		})
+	}
+}
+
+func TestBinary(t *testing.T) {
+	t.Parallel()
+
+	type tag uint16
+	tiff := func() comb.Parser[tag] { // byte order mark followed by the magic number 42
+		return cmb.Prefixed(cmb.FirstSuccessful(
+			cmb.Prefixed(cmb.Bytes([]byte("II")), comb.SetByteOrder(binary.LittleEndian)),
+			cmb.Prefixed(cmb.Bytes([]byte("MM")), comb.SetByteOrder(binary.BigEndian)),
+		), cmb.Binary[tag]())
+	}
+
+	testCases := []struct {
+		name       string
+		parser     comb.Parser[tag]
+		state      comb.State
+		wantErr    bool
+		wantOutput tag
+	}{
+		{
+			name:       "big endian should be the default",
+			parser:     cmb.Binary[tag](),
+			state:      comb.NewFromBytes([]byte{0x01, 0x02}, 0),
+			wantOutput: 0x0102,
+		}, {
+			name:       "byte order of the state should be used",
+			parser:     cmb.Binary[tag](),
+			state:      comb.NewFromBytes([]byte{0x01, 0x02}, 0).WithByteOrder(binary.LittleEndian),
+			wantOutput: 0x0201,
+		}, {
+			name:       "little endian set mid-stream should be used",
+			parser:     tiff(),
+			state:      comb.NewFromBytes([]byte{'I', 'I', 42, 0}, 0),
+			wantOutput: 42,
+		}, {
+			name:       "big endian set mid-stream should be used",
+			parser:     tiff(),
+			state:      comb.NewFromBytes([]byte{'M', 'M', 0, 42}, 0),
+			wantOutput: 42,
+		}, {
+			name:    "missing bytes should fail",
+			parser:  cmb.Binary[tag](),
+			state:   comb.NewFromBytes([]byte{0x01}, 0),
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			nState, gotOutput, err := tc.parser.Parse(tc.state)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+				assert.True(t, nState.AtEnd())
+			}
+		})
+	}
+
+	_, f, err := cmb.Binary[float32]().Parse(comb.NewFromBytes([]byte{0, 0, 0xc0, 0x3f}, 0).WithByteOrder(binary.LittleEndian))
+	assert.Nil(t, err)
+	assert.Equal(t, float32(1.5), f)
+}
+
+func TestVarintRecovery(t *testing.T) {
+	t.Parallel()
+
+	var input []byte
+	for i := 0; i < 10; i++ {
+		input = append(input, 0xff)
+	}
+	input = append(input, 0x7f, 0x05) // the varint before 0x05 overflows
+	state := comb.NewFromBytes(input, 0)
+
+	for _, p := range []comb.AnyParser{cmb.Uvarint(), cmb.Varint()} {
+		_, _, err := p.ParseAny(comb.ParentUnknown, state)
+		assert.ErrorContains(t, err, "overflows 64 bits")
+		waste, _ := p.Recover(state, nil)
+		assert.Equal(t, 11, waste, "the overflowing varint should be skipped")
+	}
+	waste, _ := cmb.Uvarint().Recover(comb.NewFromBytes([]byte{0xff, 0xff}, 0), nil)
+	assert.Equal(t, comb.RecoverWasteTooMuch, waste, "unterminated varint")
+}
+
+func TestTLV(t *testing.T) {
+	t.Parallel()
+
+	bodyFor := func(tag byte) comb.Parser[[]byte] {
+		switch tag {
+		case 1:
+			return cmb.Bytes([]byte("abc"))
+		case 2:
+			return cmb.Padding(2)
+		case 3:
+			return cmb.TakeBytesWhile("any bytes", 0, func(byte) bool { return true })
+		default:
+			return nil
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		input         []byte
+		wantErr       bool
+		wantOutput    []byte
+		wantRemaining int
+	}{
+		{
+			name:          "simple value",
+			input:         []byte{1, 3, 'a', 'b', 'c', 0xff},
+			wantOutput:    []byte("abc"),
+			wantRemaining: 1,
+		}, {
+			name:          "padding value",
+			input:         []byte{2, 2, 0, 0},
+			wantOutput:    []byte{0, 0},
+			wantRemaining: 0,
+		}, {
+			name:          "wrong length",
+			input:         []byte{1, 4, 'a', 'b', 'c', 'd'},
+			wantErr:       true,
+			wantRemaining: 6,
+		}, {
+			name:          "greedy value limited by the length",
+			input:         []byte{3, 2, 'a', 'b', 'c'},
+			wantOutput:    []byte("ab"),
+			wantRemaining: 1,
+		}, {
+			name:          "unknown tag",
+			input:         []byte{4, 1, 0},
+			wantErr:       true,
+			wantRemaining: 3,
+		}, {
+			name:          "too short",
+			input:         []byte{1, 3, 'a'},
+			wantErr:       true,
+			wantRemaining: 3,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := cmb.TLV(anyByte(), cmb.Uvarint(), bodyFor)
+			nState, gotOutput, err := p.Parse(comb.NewFromBytes(tc.input, 0))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+				assert.Equal(t, tc.wantRemaining, nState.BytesRemaining())
+			}
+		})
+	}
+}
+
+func anyByte() comb.Parser[byte] {
+	return comb.NewParser[byte]("any byte", func(state comb.State) (comb.State, byte, *comb.ParserError) {
+		buf := state.CurrentBytes()
+		if len(buf) == 0 {
+			return state, 0, state.NewSyntaxError("any byte (at EOF)")
+		}
+		return state.MoveBy(1), buf[0], nil
+	}, nil)
+}
+
+func TestChecksummed(t *testing.T) {
+	t.Parallel()
+
+	errWrongSum := errors.New("wrong sum")
+	sum := func(consumed []byte, c byte) error {
+		var s byte
+		for _, b := range consumed {
+			s += b
+		}
+		if s != c {
+			return fmt.Errorf("%w: got 0x%x, want 0x%x", errWrongSum, s, c)
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name          string
+		input         []byte
+		wantErr       bool
+		wantErrIs     error
+		wantRemaining int
+	}{
+		{
+			name:          "valid checksum",
+			input:         []byte{1, 2, 3, 6, 7},
+			wantRemaining: 1,
+		}, {
+			name:          "invalid checksum",
+			input:         []byte{1, 2, 3, 7},
+			wantErr:       true,
+			wantErrIs:     errWrongSum,
+			wantRemaining: 4,
+		}, {
+			name:          "missing checksum",
+			input:         []byte{1, 2, 3},
+			wantErr:       true,
+			wantRemaining: 3,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := cmb.Checksummed(cmb.Bytes([]byte{1, 2, 3}), anyByte(), sum)
+			nState, gotOutput, err := p.Parse(comb.NewFromBytes(tc.input, 0))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if tc.wantErrIs != nil {
+				assert.ErrorIs(t, err, tc.wantErrIs, "the error of verify should be wrapped")
+			}
+			if !tc.wantErr {
+				assert.Equal(t, []byte{1, 2, 3}, gotOutput)
+			}
+			assert.Equal(t, tc.wantRemaining, nState.BytesRemaining())
+		})
+	}
+}
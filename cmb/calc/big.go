@@ -0,0 +1,99 @@
+package calc
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// ============================================================================
+// Arbitrary Precision Arithmetic
+//
+
+// BigInt parses a decimal integer into a big.Int.
+// It never overflows.
+func BigInt() comb.Parser[*big.Int] {
+	return cmb.Map(cmb.Integer(false, 10, false), func(s string) (*big.Int, error) {
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+		return i, nil
+	})
+}
+
+// BigIntLevels returns the usual precedence levels of integer arithmetic
+// for big.Int values (see Int64Levels).
+// Division and remainder are truncated like in Go.
+// The only possible errors wrap ErrDivisionByZero.
+func BigIntLevels() []cmb.PrecedenceLevel[Value[*big.Int]] {
+	quo := func(a, b *big.Int) (*big.Int, error) {
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("%s / %s: %w", a, b, ErrDivisionByZero)
+		}
+		return new(big.Int).Quo(a, b), nil
+	}
+	rem := func(a, b *big.Int) (*big.Int, error) {
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("%s %% %s: %w", a, b, ErrDivisionByZero)
+		}
+		return new(big.Int).Rem(a, b), nil
+	}
+	return []cmb.PrecedenceLevel[Value[*big.Int]]{
+		cmb.PrefixLevel([]cmb.PrefixOp[Value[*big.Int]]{
+			{Op: "-", Fn: Lift1(func(a *big.Int) (*big.Int, error) { return new(big.Int).Neg(a), nil })},
+		}),
+		cmb.InfixLevel([]cmb.InfixOp[Value[*big.Int]]{
+			{Op: "*", Fn: Lift2(func(a, b *big.Int) (*big.Int, error) { return new(big.Int).Mul(a, b), nil })},
+			{Op: "/", Fn: Lift2(quo)},
+			{Op: "%", Fn: Lift2(rem)},
+		}),
+		cmb.InfixLevel([]cmb.InfixOp[Value[*big.Int]]{
+			{Op: "+", Fn: Lift2(func(a, b *big.Int) (*big.Int, error) { return new(big.Int).Add(a, b), nil })},
+			{Op: "-", Fn: Lift2(func(a, b *big.Int) (*big.Int, error) { return new(big.Int).Sub(a, b), nil })},
+		}),
+	}
+}
+
+// BigFloat parses a decimal floating point number into a big.Float
+// with the precision `prec` (in bits).
+// A precision of 0 means the default precision of big.Float.SetString.
+func BigFloat(prec uint) comb.Parser[*big.Float] {
+	return cmb.Map(cmb.Float(false, 10, false), func(s string) (*big.Float, error) {
+		f, ok := new(big.Float).SetPrec(prec).SetString(s)
+		if !ok {
+			return nil, fmt.Errorf("invalid float %q", s)
+		}
+		return f, nil
+	})
+}
+
+// BigFloatLevels returns the usual precedence levels of floating point
+// arithmetic for big.Float values:
+// the prefix operator '-', the infix operators '*' and '/'
+// and finally the infix operators '+' and '-'.
+// Division by zero is an error wrapping ErrDivisionByZero (instead of
+// resulting in an infinity).
+func BigFloatLevels() []cmb.PrecedenceLevel[Value[*big.Float]] {
+	quo := func(a, b *big.Float) (*big.Float, error) {
+		if b.Sign() == 0 {
+			return nil, fmt.Errorf("%s / %s: %w", a, b, ErrDivisionByZero)
+		}
+		return new(big.Float).Quo(a, b), nil
+	}
+	return []cmb.PrecedenceLevel[Value[*big.Float]]{
+		cmb.PrefixLevel([]cmb.PrefixOp[Value[*big.Float]]{
+			{Op: "-", Fn: Lift1(func(a *big.Float) (*big.Float, error) { return new(big.Float).Neg(a), nil })},
+		}),
+		cmb.InfixLevel([]cmb.InfixOp[Value[*big.Float]]{
+			{Op: "*", Fn: Lift2(func(a, b *big.Float) (*big.Float, error) { return new(big.Float).Mul(a, b), nil })},
+			{Op: "/", Fn: Lift2(quo)},
+		}),
+		cmb.InfixLevel([]cmb.InfixOp[Value[*big.Float]]{
+			{Op: "+", Fn: Lift2(func(a, b *big.Float) (*big.Float, error) { return new(big.Float).Add(a, b), nil })},
+			{Op: "-", Fn: Lift2(func(a, b *big.Float) (*big.Float, error) { return new(big.Float).Sub(a, b), nil })},
+		}),
+	}
+}
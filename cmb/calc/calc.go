@@ -0,0 +1,172 @@
+// Package calc contains arithmetic helpers for evaluating expressions
+// parsed with cmb.Expression.
+// The functions of expression operators can't return errors.
+// So the operators work on Value s that carry the first error of the
+// evaluation and Result turns it into a semantic error of the parser.
+// This way overflows and divisions by zero are reported instead of
+// silently wrapping around or panicking.
+//
+// Example:
+//
+//	parser := calc.Result(cmb.Expression(
+//		calc.Operand(cmb.Int[int64](false, 10)),
+//		calc.Int64Levels()...,
+//	).AddParentheses("(", ")", false).Parser())
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+var (
+	// ErrOverflow is wrapped by all errors of operations whose result
+	// doesn't fit into the type.
+	ErrOverflow = errors.New("integer overflow")
+
+	// ErrDivisionByZero is wrapped by all errors of divisions
+	// (and remainders) by zero.
+	ErrDivisionByZero = errors.New("division by zero")
+)
+
+// ============================================================================
+// Values Carrying Errors Through an Evaluation
+//
+
+// Value is an intermediate result of evaluating an expression.
+// Err is the first error of the evaluation so far.
+type Value[T any] struct {
+	V   T
+	Err error
+}
+
+// Operand turns the output of the value parser of an expression into a Value.
+func Operand[T any](p comb.Parser[T]) comb.Parser[Value[T]] {
+	return cmb.Map(p, func(v T) (Value[T], error) {
+		return Value[T]{V: v}, nil
+	})
+}
+
+// Lift1 turns a checked unary operation into a function usable for
+// prefix and postfix operators.
+// An error of the operand is passed on without calling fn.
+func Lift1[T any](fn func(T) (T, error)) func(Value[T]) Value[T] {
+	return func(a Value[T]) Value[T] {
+		if a.Err != nil {
+			return a
+		}
+		v, err := fn(a.V)
+		return Value[T]{V: v, Err: err}
+	}
+}
+
+// Lift2 turns a checked binary operation into a function usable for
+// infix operators.
+// The first error of the operands is passed on without calling fn.
+func Lift2[T any](fn func(T, T) (T, error)) func(Value[T], Value[T]) Value[T] {
+	return func(a, b Value[T]) Value[T] {
+		if a.Err != nil {
+			return a
+		}
+		if b.Err != nil {
+			return b
+		}
+		v, err := fn(a.V, b.V)
+		return Value[T]{V: v, Err: err}
+	}
+}
+
+// Result returns the value of the evaluated expression.
+// The error of the evaluation (if any) becomes a semantic error
+// at the start of the expression.
+func Result[T any](p comb.Parser[Value[T]]) comb.Parser[T] {
+	return cmb.Map(p, func(v Value[T]) (T, error) {
+		return v.V, v.Err
+	})
+}
+
+// ============================================================================
+// Checked int64 Arithmetic
+//
+
+// Add returns a + b or an error wrapping ErrOverflow.
+func Add(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("%d + %d: %w", a, b, ErrOverflow)
+	}
+	return a + b, nil
+}
+
+// Sub returns a - b or an error wrapping ErrOverflow.
+func Sub(a, b int64) (int64, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, fmt.Errorf("%d - %d: %w", a, b, ErrOverflow)
+	}
+	return a - b, nil
+}
+
+// Mul returns a * b or an error wrapping ErrOverflow.
+func Mul(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, fmt.Errorf("%d * %d: %w", a, b, ErrOverflow)
+	}
+	return c, nil
+}
+
+// Div returns a / b (truncated like in Go) or an error wrapping
+// ErrDivisionByZero or ErrOverflow.
+func Div(a, b int64) (int64, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("%d / %d: %w", a, b, ErrDivisionByZero)
+	}
+	if a == math.MinInt64 && b == -1 {
+		return 0, fmt.Errorf("%d / %d: %w", a, b, ErrOverflow)
+	}
+	return a / b, nil
+}
+
+// Mod returns a % b (with the sign of a like in Go) or an error wrapping
+// ErrDivisionByZero.
+func Mod(a, b int64) (int64, error) {
+	if b == 0 {
+		return 0, fmt.Errorf("%d %% %d: %w", a, b, ErrDivisionByZero)
+	}
+	return a % b, nil
+}
+
+// Neg returns -a or an error wrapping ErrOverflow.
+func Neg(a int64) (int64, error) {
+	if a == math.MinInt64 {
+		return 0, fmt.Errorf("-(%d): %w", a, ErrOverflow)
+	}
+	return -a, nil
+}
+
+// Int64Levels returns the usual precedence levels of int64 arithmetic
+// with checked operations:
+// the prefix operator '-', the infix operators '*', '/' and '%'
+// and finally the infix operators '+' and '-'.
+func Int64Levels() []cmb.PrecedenceLevel[Value[int64]] {
+	return []cmb.PrecedenceLevel[Value[int64]]{
+		cmb.PrefixLevel([]cmb.PrefixOp[Value[int64]]{
+			{Op: "-", Fn: Lift1(Neg)},
+		}),
+		cmb.InfixLevel([]cmb.InfixOp[Value[int64]]{
+			{Op: "*", Fn: Lift2(Mul)},
+			{Op: "/", Fn: Lift2(Div)},
+			{Op: "%", Fn: Lift2(Mod)},
+		}),
+		cmb.InfixLevel([]cmb.InfixOp[Value[int64]]{
+			{Op: "+", Fn: Lift2(Add)},
+			{Op: "-", Fn: Lift2(Sub)},
+		}),
+	}
+}
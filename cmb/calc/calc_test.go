@@ -0,0 +1,124 @@
+package calc_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/flowdev/comb/cmb/calc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckedOps(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		op      func(a, b int64) (int64, error)
+		a, b    int64
+		want    int64
+		wantErr error
+	}{
+		{name: "add", op: calc.Add, a: 3, b: -5, want: -2},
+		{name: "add overflow", op: calc.Add, a: math.MaxInt64, b: 1, wantErr: calc.ErrOverflow},
+		{name: "add underflow", op: calc.Add, a: math.MinInt64, b: -1, wantErr: calc.ErrOverflow},
+		{name: "sub", op: calc.Sub, a: 3, b: 5, want: -2},
+		{name: "sub overflow", op: calc.Sub, a: math.MinInt64, b: 1, wantErr: calc.ErrOverflow},
+		{name: "mul", op: calc.Mul, a: -3, b: 5, want: -15},
+		{name: "mul overflow", op: calc.Mul, a: math.MaxInt64/2 + 1, b: 2, wantErr: calc.ErrOverflow},
+		{name: "mul min by -1", op: calc.Mul, a: math.MinInt64, b: -1, wantErr: calc.ErrOverflow},
+		{name: "div", op: calc.Div, a: -7, b: 2, want: -3},
+		{name: "div by zero", op: calc.Div, a: 7, b: 0, wantErr: calc.ErrDivisionByZero},
+		{name: "div min by -1", op: calc.Div, a: math.MinInt64, b: -1, wantErr: calc.ErrOverflow},
+		{name: "mod", op: calc.Mod, a: -7, b: 2, want: -1},
+		{name: "mod by zero", op: calc.Mod, a: 7, b: 0, wantErr: calc.ErrDivisionByZero},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tc.op(tc.a, tc.b)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err := calc.Neg(math.MinInt64)
+	assert.ErrorIs(t, err, calc.ErrOverflow)
+}
+
+func TestInt64Levels(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[int64] {
+		return calc.Result(cmb.Expression(
+			calc.Operand(cmb.Int[int64](false, 10)),
+			calc.Int64Levels()...,
+		).AddParentheses("(", ")", false).Parser())
+	}
+
+	testCases := []struct {
+		name    string
+		input   string
+		want    int64
+		wantErr error
+	}{
+		{name: "precedence", input: "-2+3*(4-1)%5", want: 2},
+		{name: "overflow", input: "9223372036854775807+1", wantErr: calc.ErrOverflow},
+		{name: "division by zero", input: "1+7/(2-2)", wantErr: calc.ErrDivisionByZero},
+		{name: "first error wins", input: "1/0+9223372036854775807*2", wantErr: calc.ErrDivisionByZero},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := comb.RunOnString(tc.input, newParser())
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestBigIntLevels(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[*big.Int] {
+		return calc.Result(cmb.Expression(calc.Operand(calc.BigInt()), calc.BigIntLevels()...).Parser())
+	}
+
+	got, err := comb.RunOnString("9223372036854775807*2+2", newParser())
+	assert.NoError(t, err)
+	assert.Equal(t, "18446744073709551616", got.String())
+
+	_, err = comb.RunOnString("1%0", newParser())
+	assert.ErrorIs(t, err, calc.ErrDivisionByZero)
+}
+
+func TestBigFloatLevels(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[*big.Float] {
+		return calc.Result(cmb.Expression(calc.Operand(calc.BigFloat(100)), calc.BigFloatLevels()...).Parser())
+	}
+
+	got, err := comb.RunOnString("-1.5*4/3", newParser())
+	assert.NoError(t, err)
+	assert.Equal(t, "-2", got.Text('g', 10))
+
+	_, err = comb.RunOnString("1/0.0", newParser())
+	assert.ErrorIs(t, err, calc.ErrDivisionByZero)
+}
@@ -17,28 +17,34 @@ import (
 // a provided candidate.
 // If the rune could not be found at the current position,
 // the parser returns an error result.
+// If the state has a normalizer (see comb.State.WithNormalizer), only the
+// normalized input is compared (for parsing and recovering).
 // This parser is a good candidate for SafeSpot and has an optimized recoverer.
 func Char(char rune) comb.Parser[rune] {
 	var p comb.Parser[rune]
 
 	expected := strconv.QuoteRune(char)
+	token := string(char)
 
 	parse := func(state comb.State) (comb.State, rune, *comb.ParserError) {
-		r, size := utf8.DecodeRuneInString(state.CurrentString())
-		if r == utf8.RuneError {
+		r, size := state.CurrentRune()
+		if r == utf8.RuneError && size <= 1 { // U+FFFD itself is valid (see comb.State.WithStrictUTF8)
 			if size == 0 {
 				return state, utf8.RuneError, state.NewSyntaxError("%s (at EOF)", expected)
 			}
 			return state, utf8.RuneError, state.NewSyntaxError("%s (got UTF-8 error)", expected)
 		}
-		if r != char {
-			return state, utf8.RuneError, state.NewSyntaxError("%s (got %q)", expected, r)
+		if state.Normalizer() != nil {
+			if n, _ := state.MatchNormalized(token); n >= 0 {
+				return state.MoveBy(n), char, nil
+			}
+		} else if r == char {
+			return state.MoveBy(size), r, nil
 		}
-
-		return state.MoveBy(size), r, nil
+		return state, utf8.RuneError, state.NewSyntaxError("%s (got %q)", expected, r)
 	}
 
-	p = comb.NewParser[rune](expected, parse, IndexOf(char))
+	p = comb.NewParser[rune](expected, parse, indexNormalized(IndexOf(char), token))
 	return p
 }
 
@@ -78,8 +84,8 @@ func Satisfy(expected string, predicate func(rune) bool) comb.Parser[rune] {
 	var p comb.Parser[rune]
 
 	parse := func(state comb.State) (comb.State, rune, *comb.ParserError) {
-		r, size := utf8.DecodeRuneInString(state.CurrentString())
-		if r == utf8.RuneError {
+		r, size := state.CurrentRune()
+		if r == utf8.RuneError && size <= 1 {
 			if size == 0 {
 				return state, utf8.RuneError, state.NewSyntaxError("%s (at EOF)", expected)
 			}
@@ -93,17 +99,32 @@ func Satisfy(expected string, predicate func(rune) bool) comb.Parser[rune] {
 	}
 
 	recoverer := func(state comb.State, _ interface{}) (int, interface{}) {
-		return strings.IndexFunc(state.CurrentString(), predicate), nil
+		return indexFunc(state, state.CurrentString(), predicate), nil
 	}
 
 	p = comb.NewParser[rune](expected, parse, recoverer)
 	return p
 }
 
-// String parses a token from the input and returns the part of the input that
-// matched the token.
+// indexFunc is strings.IndexFunc for the text input of the state.
+// It decodes single bytes in Latin-1 mode (see comb.State.DecodeRune).
+func indexFunc(state comb.State, input string, f func(rune) bool) int {
+	if !state.IsLatin1() {
+		return strings.IndexFunc(input, f)
+	}
+	for i := 0; i < len(input); i++ {
+		if f(rune(input[i])) {
+			return i
+		}
+	}
+	return -1
+}
+
+// String parses a token from the input and returns the token.
 // If the token could not be found at the current position,
 // the parser returns an error result.
+// If the state has a normalizer (see comb.State.WithNormalizer), only the
+// normalized input is compared (for parsing and recovering).
 // This parser is a good candidate for SafeSpot and has an optimized recoverer.
 func String(token string) comb.Parser[string] {
 	var p comb.Parser[string]
@@ -111,15 +132,18 @@ func String(token string) comb.Parser[string] {
 	expected := strconv.Quote(token)
 
 	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
-		if !strings.HasPrefix(state.CurrentString(), token) {
+		n, incomplete := state.MatchNormalized(token)
+		if n < 0 {
+			if incomplete {
+				return state, "", comb.MarkIncomplete(state.NewSyntaxError(expected))
+			}
 			return state, "", state.NewSyntaxError(expected)
 		}
 
-		newState := state.MoveBy(len(token))
-		return newState, token, nil
+		return state.MoveBy(n), token, nil
 	}
 
-	p = comb.NewParser[string](expected, parse, IndexOf(token))
+	p = comb.NewParser[string](expected, parse, indexNormalized(IndexOf(token), token))
 	return p
 }
 
@@ -133,7 +157,11 @@ func Bytes(token []byte) comb.Parser[[]byte] {
 	expected := fmt.Sprintf("0x%x", token)
 
 	parse := func(state comb.State) (comb.State, []byte, *comb.ParserError) {
-		if !bytes.HasPrefix(state.CurrentBytes(), token) {
+		input := state.CurrentBytes()
+		if !bytes.HasPrefix(input, token) {
+			if bytes.HasPrefix(token, input) {
+				return state, []byte{}, comb.MarkIncomplete(state.NewSyntaxError(expected))
+			}
 			return state, []byte{}, state.NewSyntaxError(expected)
 		}
 
@@ -145,10 +173,64 @@ func Bytes(token []byte) comb.Parser[[]byte] {
 	return p
 }
 
+// TakeBytesWhile parses at least `atLeast` bytes matching the predicate and
+// returns them as part of the input.
+// It is the twin of SatisfyMN for byte input (see comb.NewFromBytes)
+// and doesn't convert any input to a string.
+// The output shares its memory with the input.
+// The length of the output is limited by comb.Limits.MaxStringLen.
+//
+// This parser is a good candidate for SafeSpot and has an optimized Recoverer.
+func TakeBytesWhile(expected string, atLeast int, predicate func(byte) bool) comb.Parser[[]byte] {
+	var p comb.Parser[[]byte]
+
+	if atLeast < 0 {
+		panic("TakeBytesWhile is unable to handle negative `atLeast` argument")
+	}
+
+	parse := func(state comb.State) (comb.State, []byte, *comb.ParserError) {
+		input := state.CurrentBytes()
+		maxLen := state.Limits().MaxStringLen
+		n := 0
+		for n < len(input) && predicate(input[n]) {
+			n++
+			if maxLen > 0 && n > maxLen {
+				return state, []byte{}, state.NewLimitError("length of "+expected, maxLen)
+			}
+		}
+		if n < atLeast {
+			if n >= len(input) {
+				return state, []byte{}, comb.MarkIncomplete(
+					state.NewSyntaxError("%s (need %d, found %d at EOF)", expected, atLeast, n),
+				)
+			}
+			return state, []byte{}, state.NewSyntaxError("%s (need %d, found %d, got 0x%02x)", expected, atLeast, n, input[n])
+		}
+		return state.MoveBy(n), input[:n:n], nil // the capacity protects the input from appends
+	}
+
+	p = comb.NewParser[[]byte](expected, parse, func(state comb.State, _ interface{}) (int, interface{}) {
+		count := 0
+		for i, b := range state.CurrentBytes() {
+			if !predicate(b) {
+				count = 0
+				continue
+			}
+			count++
+			if count >= atLeast {
+				return i + 1 - count, nil
+			}
+		}
+		return comb.RecoverWasteTooMuch, nil
+	})
+	return p
+}
+
 // UntilString parses until it finds a token in the input and returns
 // the part of the input that preceded the token.
 // If found the parser moves beyond the stop string.
 // If the token could not be found, the parser returns an error result.
+// The length of the output is limited by comb.Limits.MaxStringLen.
 //
 // NOTE:
 //   - This function panics if `stop` is empty.
@@ -168,8 +250,12 @@ func UntilString(stop string) comb.Parser[string] {
 	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
 		input := state.CurrentString()
 		i := strings.Index(input, stop)
-		if i == -1 {
-			return state, "", state.NewSyntaxError(expected)
+		if maxLen := state.Limits().MaxStringLen; maxLen > 0 &&
+			(i > maxLen || i == -1 && len(input) >= maxLen+len(stop)) {
+			return state, "", state.NewLimitError("length of "+expected, maxLen)
+		}
+		if i == -1 { // the stop might still come with more input
+			return state, "", comb.MarkIncomplete(state.NewSyntaxError(expected))
 		}
 
 		newState := state.MoveBy(i + len(stop))
@@ -195,6 +281,8 @@ func UntilString(stop string) comb.Parser[string] {
 // If the provided parser is not successful or the predicate doesn't match
 // `atLeast` times, the parser fails and goes back to the start.
 //
+// The length of the output is limited by comb.Limits.MaxStringLen.
+//
 // This parser is a good candidate for SafeSpot and has an optimized Recoverer.
 // An even more specialized Recoverer can be used later with `parser.SwapRecoverer(newRecoverer) Parser`.
 func SatisfyMN(expected string, atLeast, atMost int, predicate func(rune) bool) comb.Parser[string] {
@@ -210,15 +298,18 @@ func SatisfyMN(expected string, atLeast, atMost int, predicate func(rune) bool)
 	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
 		current := state
 		count := 0
+		maxLen := state.Limits().MaxStringLen
 		for atMost > count {
-			r, size := utf8.DecodeRuneInString(current.CurrentString())
-			if r == utf8.RuneError {
+			r, size := current.CurrentRune()
+			if r == utf8.RuneError && size <= 1 {
 				if count >= atLeast {
 					output := state.StringTo(current)
 					return current, output, nil
 				}
 				if size == 0 {
-					return state, "", state.NewSyntaxError("%s (need %d, found %d at EOF)", expected, atLeast, count)
+					return state, "", comb.MarkIncomplete(
+						state.NewSyntaxError("%s (need %d, found %d at EOF)", expected, atLeast, count),
+					)
 				}
 				return state, "", state.NewSyntaxError("%s (need %d, found %d, got UTF-8 error)", expected, atLeast, count)
 			}
@@ -233,6 +324,9 @@ func SatisfyMN(expected string, atLeast, atMost int, predicate func(rune) bool)
 
 			current = current.MoveBy(size)
 			count++
+			if maxLen > 0 && state.ByteCount(current) > maxLen {
+				return state, "", state.NewLimitError("length of "+expected, maxLen)
+			}
 		}
 
 		output := state.StringTo(current)
@@ -247,7 +341,9 @@ func satisfyMNRecoverer(atLeast int, predicate func(rune) bool) comb.Recoverer {
 	return func(state comb.State, _ interface{}) (int, interface{}) {
 		count := 0
 		start := 0
-		for i, r := range state.CurrentString() {
+		input := state.CurrentString()
+		for i := 0; i < len(input); {
+			r, size := state.DecodeRune(input[i:])
 			if predicate(r) {
 				if count == 0 {
 					start = i
@@ -259,6 +355,7 @@ func satisfyMNRecoverer(atLeast int, predicate func(rune) bool) comb.Recoverer {
 			} else {
 				count = 0
 			}
+			i += size
 		}
 		return comb.RecoverWasteTooMuch, nil
 	}
@@ -311,6 +408,18 @@ func Digit1() comb.Parser[string] {
 	return SatisfyMN("digit", 1, math.MaxInt, IsDigit)
 }
 
+// AlphaBytes1 parses one or more ASCII letters: a-z, A-Z.
+// It is the twin of Alpha1 for byte input (see TakeBytesWhile).
+func AlphaBytes1() comb.Parser[[]byte] {
+	return TakeBytesWhile("letter", 1, isASCIIAlpha)
+}
+
+// DigitBytes1 parses one or more ASCII digits: 0-9.
+// It is the twin of Digit1 for byte input (see TakeBytesWhile).
+func DigitBytes1() comb.Parser[[]byte] {
+	return TakeBytesWhile("digit", 1, isASCIIDigit)
+}
+
 // HexDigit0 parses zero or more ASCII hexadecimal characters: a-f, A-F, 0-9.
 // In the cases where the input is empty, or no terminating character is found, the parser
 // returns the input as is.
@@ -348,16 +457,22 @@ func OneOfRunes(collection ...rune) comb.Parser[rune] {
 	}
 	expected := fmt.Sprintf("one of %q", collection)
 
-	parser := Satisfy(expected, func(r rune) bool {
+	contains := func(r rune) bool {
 		return slices.Contains(collection, r)
-	})
+	}
+	parser := Satisfy(expected, contains)
 	parser.SwapRecoverer(func(state comb.State, _ interface{}) (int, interface{}) {
+		if state.IsLatin1() {
+			return indexFunc(state, state.CurrentString(), contains), nil
+		}
 		return strings.IndexAny(state.CurrentString(), string(collection)), nil
 	})
 	return parser
 }
 
-// OneOf parses a single string from the given set of strings.
+// OneOf parses a single string from the given set of strings and returns it.
+// If the state has a normalizer (see comb.State.WithNormalizer), only the
+// normalized input is compared (for parsing and recovering).
 // This parser is a good candidate for SafeSpot and has an optimized recoverer.
 func OneOf(collection ...string) comb.Parser[string] {
 	var p comb.Parser[string]
@@ -369,17 +484,22 @@ func OneOf(collection ...string) comb.Parser[string] {
 	expected := fmt.Sprintf("one of %q", collection)
 
 	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
-		input := state.CurrentString()
+		incomplete := false
 		for _, token := range collection {
-			if strings.HasPrefix(input, token) {
-				return state.MoveBy(len(token)), token, nil
+			n, inc := state.MatchNormalized(token)
+			if n >= 0 {
+				return state.MoveBy(n), token, nil
 			}
+			incomplete = incomplete || inc
 		}
 
+		if incomplete {
+			return state, "", comb.MarkIncomplete(state.NewSyntaxError(expected))
+		}
 		return state, "", state.NewSyntaxError(expected)
 	}
 
-	p = comb.NewParser[string](expected, parse, IndexOfAny(collection...))
+	p = comb.NewParser[string](expected, parse, indexNormalized(IndexOfAny(collection...), collection...))
 	return p
 }
 
@@ -1,6 +1,7 @@
 package cmb_test
 
 import (
+	"strings"
 	"testing"
 	"unicode"
 	"unicode/utf8"
@@ -1777,6 +1778,138 @@ func BenchmarkToken(b *testing.B) {
 	}
 }
 
+func TestNormalizedMatching(t *testing.T) {
+	t.Parallel()
+
+	// a tiny NFC like normalizer for the test: composes e + combining acute accent
+	nfc := strings.NewReplacer("e\u0301", "\u00e9").Replace
+
+	testCases := []struct {
+		name          string
+		parser        comb.Parser[string]
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "decomposed input should match composed token",
+			parser:        cmb.String("caf\u00e9"),
+			input:         "cafe\u0301!",
+			wantOutput:    "caf\u00e9",
+			wantRemaining: "!",
+		}, {
+			name:          "composed input should match decomposed token",
+			parser:        cmb.String("cafe\u0301"),
+			input:         "caf\u00e9!",
+			wantOutput:    "cafe\u0301",
+			wantRemaining: "!",
+		}, {
+			name:          "combining next rune should not match",
+			parser:        cmb.String("cafe"),
+			input:         "cafe\u0301",
+			wantErr:       true,
+			wantRemaining: "cafe\u0301",
+		}, {
+			name:          "one of should match normalized",
+			parser:        cmb.OneOf("x", "\u00e9"),
+			input:         "e\u0301x",
+			wantOutput:    "\u00e9",
+			wantRemaining: "x",
+		}, {
+			name: "char should match normalized",
+			parser: cmb.Map(cmb.Char('\u00e9'), func(r rune) (string, error) {
+				return string(r), nil
+			}),
+			input:         "e\u0301x",
+			wantOutput:    "\u00e9",
+			wantRemaining: "x",
+		}, {
+			name: "char should not match the raw input",
+			parser: cmb.Map(cmb.Char('e'), func(r rune) (string, error) {
+				return string(r), nil
+			}),
+			input:         "e\u0301x",
+			wantErr:       true,
+			wantRemaining: "e\u0301x",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := comb.NewFromString(tc.input, 10).WithNormalizer(nfc)
+			newState, gotResult, gotErr := tc.parser.Parse(state)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestNormalizedRecovery(t *testing.T) {
+	t.Parallel()
+
+	// a tiny NFC like normalizer for the test: composes e + combining acute accent
+	nfc := strings.NewReplacer("e\u0301", "\u00e9").Replace
+
+	testCases := []struct {
+		name      string
+		parser    comb.AnyParser
+		input     string
+		wantWaste int
+	}{
+		{
+			name:      "char should find the normalized input",
+			parser:    cmb.Char('\u00e9'),
+			input:     "xy e\u0301",
+			wantWaste: 3,
+		}, {
+			name:      "char should skip the raw input",
+			parser:    cmb.Char('e'),
+			input:     "e\u0301 e",
+			wantWaste: 4,
+		}, {
+			name:      "string should find the normalized input",
+			parser:    cmb.String("caf\u00e9"),
+			input:     "a cafe\u0301",
+			wantWaste: 2,
+		}, {
+			name:      "one of should find the normalized input",
+			parser:    cmb.OneOf("x", "\u00e9"),
+			input:     "ab e\u0301",
+			wantWaste: 3,
+		}, {
+			name:      "missing token should waste too much",
+			parser:    cmb.String("caf\u00e9"),
+			input:     "a cafe",
+			wantWaste: comb.RecoverWasteTooMuch,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := comb.NewFromString(tc.input, 10).WithNormalizer(nfc)
+			if gotWaste, _ := tc.parser.Recover(state, nil); gotWaste != tc.wantWaste {
+				t.Errorf("got waste %d, want waste %d", gotWaste, tc.wantWaste)
+			}
+		})
+	}
+}
+
 func TestSatisfyMN(t *testing.T) {
 	t.Parallel()
 
@@ -1883,3 +2016,78 @@ func BenchmarkSatisfyMN(b *testing.B) {
 		_, _, _ = p.Parse(input)
 	}
 }
+
+func TestTakeBytesWhile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        comb.Parser[[]byte]
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing letters should succeed",
+			parser:        cmb.AlphaBytes1(),
+			input:         "abc123",
+			wantOutput:    "abc",
+			wantRemaining: "123",
+		}, {
+			name:          "parsing digits should succeed",
+			parser:        cmb.DigitBytes1(),
+			input:         "123abc",
+			wantOutput:    "123",
+			wantRemaining: "abc",
+		}, {
+			name:          "parsing non-ASCII letters should fail",
+			parser:        cmb.AlphaBytes1(),
+			input:         "ä",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "ä",
+		}, {
+			name:          "parsing an empty input should fail",
+			parser:        cmb.DigitBytes1(),
+			input:         "",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "",
+		}, {
+			name:          "parsing too few bytes should fail",
+			parser:        cmb.TakeBytesWhile("x", 3, func(b byte) bool { return b == 'x' }),
+			input:         "xxy",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "xxy",
+		}, {
+			name:          "parsing zero bytes should succeed if allowed",
+			parser:        cmb.TakeBytesWhile("x", 0, func(b byte) bool { return b == 'x' }),
+			input:         "yyy",
+			wantOutput:    "",
+			wantRemaining: "yyy",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := tc.parser.Parse(comb.NewFromBytes([]byte(tc.input), 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+
+			if string(gotResult) != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remaining := string(newState.CurrentBytes())
+			if remaining != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remaining, tc.wantRemaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package cmb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Command-Line Arguments
+//
+
+// ArgSeparator separates the command-line arguments in the input of the
+// CLI parsers (see JoinArgs).
+// Every argument is a line of its own, so error messages report
+// the number of the argument (starting at 1) and the column within it.
+const ArgSeparator = '\n'
+
+// JoinArgs joins the command-line arguments (without the program name)
+// to the input of the CLI parsers (Flag, FlagValue, Positional, ...).
+// Arguments containing newlines shift the argument numbers in error messages.
+func JoinArgs(args []string) string {
+	return strings.Join(args, string(ArgSeparator))
+}
+
+// ParseArgs parses the command-line arguments (without the program name)
+// with parser `p` that has to consume all of them.
+// A typical command-line grammar is a Permutation of flags followed by
+// positional arguments:
+//
+//	cmb.ParseArgs(os.Args[1:], cmb.Map2(
+//		cmb.Permutation(
+//			cmb.Map(cmb.Optional(cmb.Flag("verbose")), setVerbose),
+//			cmb.Map(cmb.FlagValue("level", cmb.Int[int](false, 10)), setLevel),
+//		),
+//		cmb.PositionalN(2, cmb.Alphanumeric1()),
+//		build,
+//	))
+func ParseArgs[Output any](args []string, p comb.Parser[Output]) (Output, error) {
+	return comb.RunOnString(JoinArgs(args), Suffixed(p, EOF()))
+}
+
+// Flag parses the flag `name` and returns true.
+// Names with a single character are used with a single dash ("-v") and
+// longer names with two dashes ("--verbose").
+// The flag has to be a complete argument.
+// Use Optional to make the flag optional.
+func Flag(name string) comb.Parser[bool] {
+	var p comb.Parser[bool]
+
+	flag := flagOf(name)
+	expected := "flag " + flag
+
+	parse := func(state comb.State) (comb.State, bool, *comb.ParserError) {
+		if !state.AtLineStart() || currentArg(state) != flag {
+			return state, false, state.NewSyntaxError(expected)
+		}
+		return nextArg(state, len(flag)), true, nil
+	}
+
+	p = comb.NewParser[bool](expected, parse, argRecoverer(func(arg string) bool { return arg == flag }))
+	return p
+}
+
+// FlagValue parses the flag `name` with a value that is parsed by `value`.
+// The value can be given as part of the same argument ("--level=3" or "-l3")
+// or as the next argument ("--level 3" or "-l 3").
+// `value` has to consume the whole value.
+// Errors in the value are reported at their position within the argument.
+// Use Optional to make the flag optional.
+//
+// NOTE:
+//   - Even though FlagValue accepts a parser as argument, it behaves like a leaf parser
+//     to the outside world. Errors of `value` look as if coming from FlagValue itself.
+func FlagValue[Output any](name string, value comb.Parser[Output]) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	flag := flagOf(name)
+	attached := flag + "=" // prefix of the attached value
+	if len(name) == 1 {
+		attached = flag
+	}
+	expected := fmt.Sprintf("flag %s with %s", flag, value.Expected())
+	isFlag := func(arg string) bool {
+		return arg == flag || strings.HasPrefix(arg, attached)
+	}
+
+	parse := func(state comb.State) (comb.State, Output, *comb.ParserError) {
+		var zero Output
+
+		arg := currentArg(state)
+		if !state.AtLineStart() || !isFlag(arg) {
+			return state, zero, state.NewSyntaxError(expected)
+		}
+		vState := state.MoveBy(len(attached))
+		if arg == flag {
+			vState = nextArg(state, len(flag))
+			if vState.AtEnd() {
+				return state, zero, comb.MarkIncomplete(vState.NewSyntaxError("value for flag %s (at EOF)", flag))
+			}
+		}
+		n := len(currentArg(vState))
+		wState, out, err := comb.Within(n, value).Parse(vState)
+		if err != nil {
+			return state, zero, comb.ClaimError(err)
+		}
+		return nextArg(wState, 0), out, nil
+	}
+
+	p = comb.NewParser[Output](expected, parse, argRecoverer(isFlag))
+	return p
+}
+
+// Positional parses a positional argument with `p`.
+// `p` has to consume the whole argument.
+// Arguments starting with a dash are flags and never positional arguments
+// (except for "-" that often stands for standard input).
+//
+// NOTE:
+//   - Even though Positional accepts a parser as argument, it behaves like a leaf parser
+//     to the outside world. Errors of `p` look as if coming from Positional itself.
+func Positional[Output any](p comb.Parser[Output]) comb.Parser[Output] {
+	var pp comb.Parser[Output]
+
+	expected := "positional argument " + p.Expected()
+	isPositional := func(arg string) bool {
+		return arg == "-" || !strings.HasPrefix(arg, "-")
+	}
+
+	parse := func(state comb.State) (comb.State, Output, *comb.ParserError) {
+		var zero Output
+
+		arg := currentArg(state)
+		if !state.AtLineStart() {
+			return state, zero, state.NewSyntaxError(expected)
+		}
+		if state.AtEnd() {
+			return state, zero, comb.MarkIncomplete(state.NewSyntaxError("%s (at EOF)", expected))
+		}
+		if !isPositional(arg) {
+			return state, zero, state.NewSyntaxError("%s (got unknown flag %q)", expected, arg)
+		}
+		wState, out, err := comb.Within(len(arg), p).Parse(state)
+		if err != nil {
+			return state, zero, comb.ClaimError(err)
+		}
+		return nextArg(wState, 0), out, nil
+	}
+
+	pp = comb.NewParser[Output](expected, parse, argRecoverer(isPositional))
+	return pp
+}
+
+// PositionalN parses exactly `n` positional arguments with `p`
+// (see Positional).
+func PositionalN[Output any](n int, p comb.Parser[Output]) comb.Parser[[]Output] {
+	if n < 0 {
+		panic("PositionalN is unable to handle negative `n`")
+	}
+	return Count(n, Positional(p))
+}
+
+// flagOf returns the flag for the name as used on the command-line.
+func flagOf(name string) string {
+	switch {
+	case name == "":
+		panic("the name of a flag must not be empty")
+	case strings.HasPrefix(name, "-"):
+		panic(fmt.Sprintf("the name of a flag must not start with a dash: %q", name))
+	case len(name) == 1:
+		return "-" + name
+	default:
+		return "--" + name
+	}
+}
+
+// currentArg returns the (rest of the) argument at the current position.
+func currentArg(state comb.State) string {
+	arg := state.CurrentString()
+	if i := strings.IndexByte(arg, ArgSeparator); i >= 0 {
+		return arg[:i]
+	}
+	return arg
+}
+
+// nextArg moves the state `n` bytes forward and behind the following
+// argument separator.
+func nextArg(state comb.State, n int) comb.State {
+	state = state.MoveBy(n)
+	if r, size := state.CurrentRune(); r == ArgSeparator {
+		return state.MoveBy(size)
+	}
+	return state
+}
+
+// argRecoverer returns a recoverer that finds the next argument accepted
+// by `accept`.
+func argRecoverer(accept func(arg string) bool) comb.Recoverer {
+	return func(state comb.State, _ interface{}) (int, interface{}) {
+		input := state.CurrentString()
+		start := 0
+		if !state.AtLineStart() {
+			i := strings.IndexByte(input, ArgSeparator)
+			if i < 0 {
+				return comb.RecoverWasteTooMuch, nil
+			}
+			start = i + 1
+		}
+		for start < len(input) {
+			arg := input[start:]
+			end := strings.IndexByte(arg, ArgSeparator)
+			if end >= 0 {
+				arg = arg[:end]
+			}
+			if accept(arg) {
+				return start, nil
+			}
+			if end < 0 {
+				break
+			}
+			start += end + 1
+		}
+		return comb.RecoverWasteTooMuch, nil
+	}
+}
@@ -0,0 +1,100 @@
+package cmb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArgs(t *testing.T) {
+	t.Parallel()
+
+	type config struct {
+		verbose bool
+		level   int
+		name    string
+		files   []string
+	}
+	type option func(*config)
+	newParser := func() comb.Parser[config] {
+		return cmb.Map2(
+			cmb.Permutation(
+				cmb.Map(cmb.Optional(cmb.Flag("v")), func(v bool) (option, error) {
+					return func(c *config) { c.verbose = v }, nil
+				}),
+				cmb.Map(cmb.FlagValue("level", cmb.Int[int](false, 10)), func(level int) (option, error) {
+					return func(c *config) { c.level = level }, nil
+				}),
+				cmb.Map(cmb.Optional(cmb.FlagValue("n", cmb.Alphanumeric1())), func(name string) (option, error) {
+					return func(c *config) { c.name = name }, nil
+				}),
+			),
+			cmb.PositionalN(2, cmb.Alphanumeric1()),
+			func(opts []option, files []string) (config, error) {
+				c := config{files: files}
+				for _, opt := range opts {
+					opt(&c)
+				}
+				return c, nil
+			},
+		)
+	}
+
+	testCases := []struct {
+		name       string
+		args       []string
+		wantErr    string
+		wantOutput config
+	}{
+		{
+			name:       "all flags should be parsed",
+			args:       []string{"-v", "--level=3", "-nfoo", "a", "b"},
+			wantOutput: config{verbose: true, level: 3, name: "foo", files: []string{"a", "b"}},
+		}, {
+			name:       "flags in any order with separate values should be parsed",
+			args:       []string{"-n", "foo", "--level", "3", "-v", "a", "b"},
+			wantOutput: config{verbose: true, level: 3, name: "foo", files: []string{"a", "b"}},
+		}, {
+			name:       "optional flags can be left out",
+			args:       []string{"--level=3", "a", "b"},
+			wantOutput: config{level: 3, files: []string{"a", "b"}},
+		}, {
+			name:    "missing required flag should fail",
+			args:    []string{"-v", "a", "b"},
+			wantErr: "expected flag --level with decimal integer",
+		}, {
+			name:    "bad value should be reported within the argument",
+			args:    []string{"--level=3x", "a", "b"},
+			wantErr: "[1:10] --level=3▶x",
+		}, {
+			name:    "unknown flag should fail",
+			args:    []string{"--level=3", "--debug", "a", "b"},
+			wantErr: `unexpected "--debug\n" before positional argument letter or numeral (deleted) [2:1]`,
+		}, {
+			name:    "missing flag value should fail",
+			args:    []string{"--level"},
+			wantErr: "expected value for flag --level (at EOF)",
+		}, {
+			name:    "too many positional arguments should fail",
+			args:    []string{"--level=3", "a", "b", "c"},
+			wantErr: "[4:1] ▶c",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := cmb.ParseArgs(tc.args, newParser())
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantOutput, gotOutput)
+		})
+	}
+}
@@ -4,6 +4,8 @@
 package cmb
 
 import (
+	"strings"
+
 	"github.com/flowdev/comb"
 )
 
@@ -31,3 +33,159 @@ func EOF() comb.Parser[interface{}] {
 	)
 	return p
 }
+
+// Complete applies the parser and requires it to consume the whole input
+// like `Suffixed(parser, EOF())`.
+// But if input is left, the error lists what the parser could still have
+// accepted at that position (see comb.ExpectedOf) instead of only
+// the end of the input.
+func Complete[Output any](parser comb.Parser[Output]) comb.Parser[Output] {
+	var p comb.Parser[Output]
+	eof := EOF()
+
+	p = comb.NewBranchParser[Output](
+		"Complete",
+		func() []comb.AnyParser {
+			return []comb.AnyParser{parser, eof}
+		}, func(
+			childID int32,
+			childStartState, childState comb.State,
+			childOut interface{},
+			childErr *comb.ParserError,
+			data interface{},
+		) (comb.State, Output, *comb.ParserError, interface{}) {
+			comb.Debugf("Complete.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			partRes, _ := data.(partialCompleteResult[Output])
+			switch {
+			case childID < 0: // top-down
+				partRes.start = childState
+				childState, childOut, childErr = parser.ParseAny(p.ID(), childState)
+			case childID == eof.ID(): // bottom-up: the rest of the input has been skipped
+				return childState, partRes.out, childErr, partRes
+			}
+			partRes.out, _ = childOut.(Output)
+			if childErr != nil {
+				return childState, partRes.out, childErr, partRes
+			}
+
+			eofState, _, eofErr := eof.ParseAny(p.ID(), childState)
+			if eofErr != nil {
+				if expected := comb.ExpectedOf(parser, partRes.start, childState); len(expected) > 0 {
+					eofErr.PatchMessage(strings.Join(expected, ", ") + " or ")
+				}
+				return eofState, partRes.out, eofErr, partRes
+			}
+			return eofState, partRes.out, nil, nil
+		},
+	)
+	return p
+}
+
+// partialCompleteResult is internal to the parsing method of Complete.
+type partialCompleteResult[Output any] struct {
+	start comb.State
+	out   Output
+}
+
+// NotPreceded succeeds without consuming any input if the rune before
+// the current position doesn't satisfy the predicate.
+// It always succeeds at the start of the input.
+// This allows to look behind (e.g., a keyword mustn't be part of an identifier).
+func NotPreceded(pred func(rune) bool) comb.Parser[interface{}] {
+	var p comb.Parser[interface{}]
+
+	if pred == nil {
+		panic("NotPreceded: pred is nil")
+	}
+	expected := "not to be preceded by a matching rune"
+
+	parse := func(state comb.State) (comb.State, interface{}, *comb.ParserError) {
+		r, size := state.PreviousRune()
+		if size > 0 && pred(r) {
+			return state, nil, state.NewSyntaxError("%s (found %q)", expected, r)
+		}
+		return state, nil, nil
+	}
+
+	p = comb.NewParser[interface{}](expected, parse, Forbidden())
+	return p
+}
+
+// WordBoundary succeeds without consuming any input if the current position
+// is at the start or end of a word.
+// Words consist of alphanumeric runes (see IsAlphanumeric).
+// The start and end of the input count as non-word runes.
+func WordBoundary() comb.Parser[interface{}] {
+	var p comb.Parser[interface{}]
+
+	expected := "word boundary"
+
+	parse := func(state comb.State) (comb.State, interface{}, *comb.ParserError) {
+		prev, prevSize := state.PreviousRune()
+		next, nextSize := state.CurrentRune()
+		if (prevSize > 0 && IsAlphanumeric(prev)) == (nextSize > 0 && IsAlphanumeric(next)) {
+			return state, nil, state.NewSyntaxError(expected)
+		}
+		return state, nil, nil
+	}
+
+	p = comb.NewParser[interface{}](expected, parse, Forbidden())
+	return p
+}
+
+// SOL succeeds without consuming any input if the current position
+// is at the start of a line.
+// The start of the input is always the start of a line.
+func SOL() comb.Parser[interface{}] {
+	var p comb.Parser[interface{}]
+
+	expected := "start of a line"
+
+	parse := func(state comb.State) (comb.State, interface{}, *comb.ParserError) {
+		if !state.AtLineStart() {
+			return state, nil, state.NewSyntaxError(expected)
+		}
+		return state, nil, nil
+	}
+
+	p = comb.NewParser[interface{}](expected, parse, Forbidden())
+	return p
+}
+
+// EOL succeeds without consuming any input if the current position
+// is at the end of a line (before "\n" or "\r\n").
+// The end of the input is always the end of a line.
+func EOL() comb.Parser[interface{}] {
+	var p comb.Parser[interface{}]
+
+	expected := "end of a line"
+
+	parse := func(state comb.State) (comb.State, interface{}, *comb.ParserError) {
+		input := state.CurrentString()
+		if input != "" && !strings.HasPrefix(input, "\n") && !strings.HasPrefix(input, "\r\n") {
+			return state, nil, state.NewSyntaxError(expected)
+		}
+		return state, nil, nil
+	}
+
+	p = comb.NewParser[interface{}](expected, parse, Forbidden())
+	return p
+}
+
+// SOI succeeds without consuming any input if the current position
+// is at the start of the input.
+func SOI() comb.Parser[interface{}] {
+	var p comb.Parser[interface{}]
+
+	expected := "start of the input"
+
+	parse := func(state comb.State) (comb.State, interface{}, *comb.ParserError) {
+		if state.CurrentPos() != 0 {
+			return state, nil, state.NewSyntaxError(expected)
+		}
+		return state, nil, nil
+	}
+
+	p = comb.NewParser[interface{}](expected, parse, Forbidden())
+	return p
+}
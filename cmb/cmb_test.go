@@ -1,7 +1,9 @@
 package cmb_test
 
 import (
+	"slices"
 	"testing"
+	"unicode"
 
 	"github.com/flowdev/comb"
 	"github.com/flowdev/comb/cmb"
@@ -44,3 +46,181 @@ func TestEOF(t *testing.T) {
 		})
 	}
 }
+
+func TestComplete(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantOutput []string
+		wantErr    string
+	}{
+		{
+			name:       "consumed input should succeed",
+			input:      "1,2",
+			wantOutput: []string{"1", "2"},
+		}, {
+			name:       "left input should report the follow set",
+			input:      "1,2 3",
+			wantOutput: []string{"1", "2"},
+			wantErr:    "expected ',' or end of the input (still 2 bytes of input left) [1:4] 1,2▶ 3",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := cmb.Complete(cmb.Separated1(cmb.Digit1(), cmb.Char(','), false))
+			gotOutput, err := comb.RunOnState(comb.NewFromString(tc.input, comb.FailFast), comb.NewPreparedParser(p))
+			if tc.wantErr == "" && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if tc.wantErr != "" && (err == nil || err.Error() != tc.wantErr) {
+				t.Errorf("got error %v, want %q", err, tc.wantErr)
+			}
+			if !slices.Equal(gotOutput, tc.wantOutput) {
+				t.Errorf("got output %q, want %q", gotOutput, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestNotPreceded(t *testing.T) {
+	state := comb.NewFromString("ab1", 0)
+
+	tests := []struct {
+		name    string
+		state   comb.State
+		wantErr bool
+	}{
+		{
+			name:    "start of input",
+			state:   state,
+			wantErr: false,
+		}, {
+			name:    "preceded by letter",
+			state:   state.MoveBy(1),
+			wantErr: true,
+		}, {
+			name:    "preceded by digit",
+			state:   state.MoveBy(3),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endState, _, err := cmb.NotPreceded(unicode.IsLetter).Parse(tt.state)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tt.wantErr)
+			}
+			if got, want := endState.CurrentPos(), tt.state.CurrentPos(); got != want {
+				t.Errorf("got position %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestWordBoundary(t *testing.T) {
+	state := comb.NewFromString("ab €ü", 0)
+
+	tests := []struct {
+		name    string
+		state   comb.State
+		wantErr bool
+	}{
+		{
+			name:    "start of input",
+			state:   state,
+			wantErr: false,
+		}, {
+			name:    "inside word",
+			state:   state.MoveBy(1),
+			wantErr: true,
+		}, {
+			name:    "end of word",
+			state:   state.MoveBy(2),
+			wantErr: false,
+		}, {
+			name:    "between non-word runes",
+			state:   state.MoveBy(3),
+			wantErr: true,
+		}, {
+			name:    "start of word",
+			state:   state.MoveBy(6),
+			wantErr: false,
+		}, {
+			name:    "end of input",
+			state:   state.MoveBy(8),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := cmb.WordBoundary().Parse(tt.state)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnchors(t *testing.T) {
+	state := comb.NewFromString("ab\r\ncd", 0)
+
+	tests := []struct {
+		name       string
+		state      comb.State
+		wantSOLErr bool
+		wantEOLErr bool
+		wantSOIErr bool
+	}{
+		{
+			name:       "start of input",
+			state:      state,
+			wantEOLErr: true,
+		}, {
+			name:       "middle of line",
+			state:      state.MoveBy(1),
+			wantSOLErr: true,
+			wantEOLErr: true,
+			wantSOIErr: true,
+		}, {
+			name:       "end of line",
+			state:      state.MoveBy(2),
+			wantSOLErr: true,
+			wantSOIErr: true,
+		}, {
+			name:       "start of line",
+			state:      state.MoveBy(4),
+			wantEOLErr: true,
+			wantSOIErr: true,
+		}, {
+			name:       "end of input",
+			state:      state.MoveBy(6),
+			wantSOLErr: true,
+			wantSOIErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, anchor := range []struct {
+				name    string
+				parser  comb.Parser[interface{}]
+				wantErr bool
+			}{
+				{name: "SOL", parser: cmb.SOL(), wantErr: tt.wantSOLErr},
+				{name: "EOL", parser: cmb.EOL(), wantErr: tt.wantEOLErr},
+				{name: "SOI", parser: cmb.SOI(), wantErr: tt.wantSOIErr},
+			} {
+				endState, _, err := anchor.parser.Parse(tt.state)
+				if gotErr := err != nil; gotErr != anchor.wantErr {
+					t.Errorf("%s: got error %v, want error: %t", anchor.name, err, anchor.wantErr)
+				}
+				if got, want := endState.CurrentPos(), tt.state.CurrentPos(); got != want {
+					t.Errorf("%s: got position %d, want %d", anchor.name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package cmb
 
 import (
+	"fmt"
+
 	"github.com/flowdev/comb"
 )
 
 // Optional applies an optional child parser. Will return a zero value
 // if not successful.
-// Optional will ignore any parsing error except if a SafeSpot is active.
+// Optional will ignore any parsing error except if a SafeSpot is active
+// or the error is fatal.
 func Optional[Output any](parser comb.Parser[Output]) comb.Parser[Output] {
 	var p comb.Parser[Output]
 
@@ -30,7 +33,7 @@ func Optional[Output any](parser comb.Parser[Output]) comb.Parser[Output] {
 				childState, childOut, childErr = parser.ParseAny(p.ID(), childStartState)
 				out, _ = childOut.(Output)
 			}
-			if childErr != nil && childStartState.SafeSpotMoved(childState) { // we can't ignore the error
+			if childErr != nil && (childStartState.SafeSpotMoved(childState) || childErr.Fatal()) { // we can't ignore the error
 				return childState, out, childErr, out
 			}
 			if childErr != nil { // successful result without input consumption
@@ -42,6 +45,30 @@ func Optional[Output any](parser comb.Parser[Output]) comb.Parser[Output] {
 	return p
 }
 
+// Maybe applies an optional child parser like Optional.
+// But the output tells whether the child parser matched (possibly empty input)
+// or not at all.
+func Maybe[Output any](parser comb.Parser[Output]) comb.Parser[comb.Option[Output]] {
+	return Optional(Map(parser, func(out Output) (comb.Option[Output], error) {
+		return comb.Some(out), nil
+	}))
+}
+
+// Or2 applies the first parser and, if it fails, the second one
+// like FirstSuccessful.
+// But the parsers can have different output types, and the output tells
+// which of them matched.
+func Or2[A, B any](pa comb.Parser[A], pb comb.Parser[B]) comb.Parser[comb.Either[A, B]] {
+	return FirstSuccessful(
+		Map(pa, func(a A) (comb.Either[A, B], error) {
+			return comb.Left[A, B](a), nil
+		}),
+		Map(pb, func(b B) (comb.Either[A, B], error) {
+			return comb.Right[A](b), nil
+		}),
+	)
+}
+
 // Peek tries to apply the provided parser without consuming any input.
 // It effectively allows looking ahead in the input.
 //
@@ -64,6 +91,7 @@ func Peek[Output any](parse comb.Parser[Output]) comb.Parser[Output] {
 // Not succeeds if the parser fails and succeeds if the parser fails.
 // It effectively allows looking ahead in the input.
 // An error returned should be handled (or ignored) by the parent parser.
+// Fatal errors of the parser are returned unchanged.
 //
 // NOTE:
 //   - SafeSpot isn't honored here because we aren't officially parsing anything.
@@ -76,6 +104,9 @@ func Not[Output any](parser comb.Parser[Output]) comb.Parser[bool] {
 	expected := "not " + parser.Expected()
 	notParse := func(state comb.State) (comb.State, bool, *comb.ParserError) {
 		_, _, err := parser.ParseAny(comb.ParentUnknown, state)
+		if err != nil && err.Fatal() {
+			return state, false, comb.ClaimError(err)
+		}
 		if err != nil {
 			return state, true, nil
 		}
@@ -183,3 +214,282 @@ func Map5[PO1, PO2, PO3, PO4, PO5 any, MO any](
 ) comb.Parser[MO] {
 	return MapN("Map5", parse1, parse2, parse3, parse4, parse5, 5, nil, nil, nil, nil, fn)
 }
+
+// MapWithSpan applies a function to the successful result of 1 parser.
+// The function gets the span of the input consumed by the parser and
+// the state after the parser, too.
+// So it can compute positions, slice the original input or consult the state.
+func MapWithSpan[PO any, MO any](parse comb.Parser[PO], fn func(PO, comb.Span, comb.State) (MO, error)) comb.Parser[MO] {
+	var p comb.Parser[MO]
+
+	if fn == nil {
+		panic("MapWithSpan: fn is nil")
+	}
+
+	p = comb.NewBranchParser[MO](
+		"MapWithSpan",
+		func() []comb.AnyParser {
+			return []comb.AnyParser{parse}
+		}, func(
+			childID int32,
+			childStartState, childState comb.State,
+			childOut interface{},
+			childErr *comb.ParserError,
+			data interface{},
+		) (comb.State, MO, *comb.ParserError, interface{}) {
+			var zero MO
+			comb.Debugf("MapWithSpan.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			start := childStartState.CurrentPos()
+			if childID >= 0 { // bottom-up
+				if pos, ok := data.(int); ok {
+					start = pos
+				}
+			} else { // top-down
+				childStartState = childState
+				start = childStartState.CurrentPos()
+				childState, childOut, childErr = parse.ParseAny(p.ID(), childStartState)
+			}
+			if childErr != nil {
+				return childState, zero, childErr, start
+			}
+			out, _ := childOut.(PO)
+			span := comb.Span{Start: start, End: max(start, childState.CurrentPos())}
+			mOut, err := fn(out, span, childState)
+			if err != nil {
+				childState = childState.SaveError(childState.NewSemanticError("%w", err))
+				return childState, mOut, nil, start
+			}
+			return childState, mOut, nil, nil
+		},
+	)
+	return p
+}
+
+// RawValue is the result of the Raw parser.
+type RawValue[T any] struct {
+	Raw   string // the exact input consumed by the parser
+	Value T      // the result of the parser
+}
+
+// Raw returns the exact input consumed by the parser together with its result.
+// This is cheaper than recognizing the input and parsing it again.
+func Raw[T any](parse comb.Parser[T]) comb.Parser[RawValue[T]] {
+	return MapWithSpan(parse, func(value T, span comb.Span, state comb.State) (RawValue[T], error) {
+		return RawValue[T]{Raw: state.StringOf(span), Value: value}, nil
+	})
+}
+
+// Between parses the opening delimiter, the main parser and the closing delimiter
+// and returns the result of the main parser.
+// In contrast to Delimited, a missing closing delimiter is reported together
+// with the position of the matching opening delimiter
+// (e.g.: `expected "(" opened at 3:7 to be closed by ")"`).
+// Recursive grammars (using comb.LazyBranchParser) are guarded against
+// nesting deeper than `maxDepth`. A `maxDepth` of 0 means no limit.
+func Between[OO, O, OC any](open comb.Parser[OO], parser comb.Parser[O], close comb.Parser[OC], maxDepth int,
+) comb.Parser[O] {
+	if maxDepth < 0 {
+		panic("Between is unable to handle negative `maxDepth`")
+	}
+	bd := &betweenData[OO, O, OC]{open: open, parser: parser, close: close, maxDepth: maxDepth}
+	p := comb.NewBranchParser[O]("Between", bd.children, bd.parseAfterChild)
+	bd.id = p.ID
+	return p
+}
+
+type betweenData[OO, O, OC any] struct {
+	id       func() int32
+	open     comb.Parser[OO]
+	parser   comb.Parser[O]
+	close    comb.Parser[OC]
+	maxDepth int
+}
+
+// partialBetweenResult is internal to the parsing method and methods and functions called by it.
+type partialBetweenResult[O any] struct {
+	openState comb.State
+	out       O
+}
+
+func (bd *betweenData[OO, O, OC]) children() []comb.AnyParser {
+	return []comb.AnyParser{bd.open, bd.parser, bd.close}
+}
+
+func (bd *betweenData[OO, O, OC]) parseAfterChild(
+	childID int32,
+	childStartState, childState comb.State,
+	childOut interface{},
+	childErr *comb.ParserError,
+	data interface{},
+) (comb.State, O, *comb.ParserError, interface{}) {
+	var partRes partialBetweenResult[O]
+
+	comb.Debugf("Between.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+
+	if childID >= 0 { // on the way up: Fetch
+		partRes, _ = data.(partialBetweenResult[O])
+	}
+
+	switch {
+	case childID < 0:
+		partRes.openState = childState
+		childState, _, childErr = bd.open.ParseAny(bd.id(), childState)
+		if childErr != nil {
+			return childState, partRes.out, childErr, partRes
+		}
+		if bd.maxDepth > 0 && bd.depth(childState) >= bd.maxDepth {
+			return childState, partRes.out, partRes.openState.NewSemanticError(
+				"nesting depth of %d exceeded by %s", bd.maxDepth, bd.open.Expected(),
+			), nil
+		}
+		childState, childErr = bd.parseMain(childState, &partRes)
+		if childErr != nil {
+			return childState, partRes.out, childErr, partRes
+		}
+	case childID == bd.open.ID():
+		partRes.openState = childStartState
+		if childErr != nil {
+			return childState, partRes.out, childErr, partRes
+		}
+		childState, childErr = bd.parseMain(childState, &partRes)
+		if childErr != nil {
+			return childState, partRes.out, childErr, partRes
+		}
+	case childID == bd.parser.ID():
+		partRes.out, _ = childOut.(O)
+		if childErr != nil {
+			return childState, partRes.out, childErr, partRes
+		}
+	case childID == bd.close.ID():
+		return childState, partRes.out, childErr, partRes
+	default:
+		childErr = childState.NewSemanticError("unable to parse after child with unknown ID %d", childID)
+		return childState, partRes.out, childErr, partRes
+	}
+
+	nState, _, childErr := bd.close.ParseAny(bd.id(), childState)
+	if childErr != nil {
+		line, col := partRes.openState.LineCol()
+		// the error of the close parser is kept, so its recoverer can be used
+		childErr.PatchMessage(fmt.Sprintf("%s opened at %d:%d to be closed by ", bd.open.Expected(), line, col))
+		return childState, partRes.out, childErr, partRes
+	}
+	return nState, partRes.out, nil, nil
+}
+
+// parseMain parses the main parser while keeping track of the nesting depth.
+func (bd *betweenData[OO, O, OC]) parseMain(state comb.State, partRes *partialBetweenResult[O]) (comb.State, *comb.ParserError) {
+	var aOut interface{}
+	var err *comb.ParserError
+
+	depth := bd.depth(state)
+	state.PutIntoCache(bd.id(), depth+1)
+	state, aOut, err = bd.parser.ParseAny(bd.id(), state)
+	state.PutIntoCache(bd.id(), depth)
+	partRes.out, _ = aOut.(O)
+	return state, err
+}
+
+// depth returns the current nesting depth that is stored in the parser cache.
+func (bd *betweenData[OO, O, OC]) depth(state comb.State) int {
+	depth, _ := state.GetFromCache(bd.id()).(int)
+	return depth
+}
+
+// Expect applies the parser and, if it fails, reports the error with `msg`
+// (`expected ` + msg) but tolerates it.
+// So Expect returns the zero value without consuming any input and
+// parsing continues as if nothing happened.
+// This is useful for errors that are easy to explain but don't need recovery
+// (e.g., a missing semicolon).
+// Fatal errors and errors after a safe spot of the parser are passed on unchanged.
+func Expect[Output any](parser comb.Parser[Output], msg string) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	p = comb.NewBranchParser[Output](
+		msg,
+		func() []comb.AnyParser {
+			return []comb.AnyParser{parser}
+		}, func(
+			childID int32,
+			childStartState, childState comb.State,
+			childOut interface{},
+			childErr *comb.ParserError,
+			data interface{},
+		) (comb.State, Output, *comb.ParserError, interface{}) {
+			comb.Debugf("Expect.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				childStartState = childState
+				childState, childOut, childErr = parser.ParseAny(p.ID(), childStartState)
+			}
+			if childErr != nil && (childStartState.SafeSpotMoved(childState) || childErr.Fatal()) { // we can't tolerate the error
+				out, _ := childOut.(Output)
+				return childState, out, childErr, nil
+			}
+			if childErr != nil {
+				return childStartState.SaveError(childStartState.NewSyntaxError(msg)), comb.ZeroOf[Output](), nil, nil
+			}
+			out, _ := childOut.(Output)
+			return childState, out, nil, nil
+		},
+	)
+	return p
+}
+
+// Label applies the parser unchanged but with `name` as its Expected() value.
+// So the name shows up as the kind of the nodes of a CST (see comb.RunWithCST)
+// and in traces without changing what the parser accepts.
+// Unlike Expect, errors of the parser aren't tolerated.
+func Label[Output any](parser comb.Parser[Output], name string) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	p = comb.NewBranchParser[Output](
+		name,
+		func() []comb.AnyParser {
+			return []comb.AnyParser{parser}
+		}, func(
+			childID int32,
+			childStartState, childState comb.State,
+			childOut interface{},
+			childErr *comb.ParserError,
+			data interface{},
+		) (comb.State, Output, *comb.ParserError, interface{}) {
+			comb.Debugf("Label.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				childState, childOut, childErr = parser.ParseAny(p.ID(), childState)
+			}
+			out, _ := childOut.(Output)
+			return childState, out, childErr, nil
+		},
+	)
+	return p
+}
+
+// Require applies the parser and makes any error of it fatal.
+// So no error recovery is tried past it and parsing stops with the error.
+// This gives grammar authors explicit control over which errors are fatal
+// (e.g., a missing file header).
+func Require[Output any](parser comb.Parser[Output]) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	p = comb.NewBranchParser[Output](
+		"Require",
+		func() []comb.AnyParser {
+			return []comb.AnyParser{parser}
+		}, func(
+			childID int32,
+			childStartState, childState comb.State,
+			childOut interface{},
+			childErr *comb.ParserError,
+			data interface{},
+		) (comb.State, Output, *comb.ParserError, interface{}) {
+			comb.Debugf("Require.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				childState, childOut, childErr = parser.ParseAny(p.ID(), childState)
+			}
+			out, _ := childOut.(Output)
+			return childState, out, comb.MarkFatal(childErr), nil
+		},
+	)
+	return p
+}
@@ -2,6 +2,7 @@ package cmb
 
 import (
 	"errors"
+	"slices"
 	"strconv"
 	"testing"
 
@@ -67,6 +68,84 @@ func BenchmarkOptional(b *testing.B) {
 	}
 }
 
+func TestMaybe(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantOutput comb.Option[string]
+	}{
+		{
+			name:       "matching parser should be some",
+			input:      "123",
+			wantOutput: comb.Some("123"),
+		}, {
+			name:       "matching empty input should be some",
+			input:      "abc",
+			wantOutput: comb.Some(""),
+		}, {
+			name:       "no match should be none",
+			input:      ":",
+			wantOutput: comb.None[string](),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := Maybe(FirstSuccessful(Digit1(), Suffixed(Digit0(), Alpha1())))
+			gotResult, gotErr := comb.RunOnString(tc.input, Suffixed(parser, Optional(Alpha1())))
+			if gotErr != nil {
+				t.Errorf("got unexpected error %v", gotErr)
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestOr2(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput comb.Either[uint64, string]
+	}{
+		{
+			name:       "first alternative should be left",
+			input:      "123",
+			wantOutput: comb.Left[uint64, string](123),
+		}, {
+			name:       "second alternative should be right",
+			input:      "abc",
+			wantOutput: comb.Right[uint64]("abc"),
+		}, {
+			name:    "no match should fail",
+			input:   ":",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotResult, gotErr := comb.RunOnString(tc.input, Or2(UInt64(false, 10), Alpha1()))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if !tc.wantErr && gotResult != tc.wantOutput {
+				t.Errorf("got output %+v, want output %+v", gotResult, tc.wantOutput)
+			}
+		})
+	}
+}
+
 func TestPeek(t *testing.T) {
 	t.Parallel()
 
@@ -538,3 +617,387 @@ func BenchmarkMap2(b *testing.B) {
 		_, _, _ = parser.Parse(input)
 	}
 }
+
+func TestMapWithSpan(t *testing.T) {
+	t.Parallel()
+
+	type spanned struct {
+		text string
+		span comb.Span
+	}
+	fn := func(out string, span comb.Span, state comb.State) (spanned, error) {
+		return spanned{text: state.StringOf(span), span: span}, nil
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput spanned
+	}{
+		{
+			name:       "matching parser should succeed",
+			input:      "abc123",
+			wantOutput: spanned{text: "123", span: comb.Span{Start: 3, End: 6}},
+		},
+		{
+			name:       "non matching parser should fail",
+			input:      "abc;",
+			wantErr:    true,
+			wantOutput: spanned{},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := Prefixed(Alpha1(), MapWithSpan(Digit1(), fn))
+			gotResult, gotErr := comb.RunOnString(tc.input, parser)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestRaw(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput RawValue[int64]
+	}{
+		{
+			name:       "matching parser should succeed",
+			input:      "abc0x1_f;",
+			wantOutput: RawValue[int64]{Raw: "0x1_f", Value: 0x1f},
+		},
+		{
+			name:       "non matching parser should fail",
+			input:      "abc;",
+			wantErr:    true,
+			wantOutput: RawValue[int64]{},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := Delimited(Alpha1(), Raw(Int64(false, 0)), Char(';'))
+			gotResult, gotErr := comb.RunOnString(tc.input, parser)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %v, want output %v", gotResult, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestMapDefer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput []int
+	}{
+		{
+			name:       "values only should succeed",
+			input:      "a=1;b=2;",
+			wantOutput: []int{1, 2},
+		}, {
+			name:       "backward and forward references should succeed",
+			input:      "a=b;b=2;c=a;",
+			wantOutput: []int{2, 2, 2},
+		}, {
+			name:       "unknown reference should fail",
+			input:      "a=1;b=c;",
+			wantErr:    true,
+			wantOutput: []int{1, 0},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			symbols := make(map[string]*int)
+			lookup := func(name string) (int, error) {
+				v, ok := symbols[name]
+				if !ok {
+					return 0, errors.New("unknown symbol " + strconv.Quote(name))
+				}
+				return *v, nil
+			}
+			assignment := Map2(Alpha1(), Prefixed(Char('='), FirstSuccessful(Digit1(), Alpha1())),
+				func(name, value string) (*int, error) {
+					v := new(int)
+					symbols[name] = v
+					if i, err := strconv.Atoi(value); err == nil {
+						*v = i
+						return v, nil
+					}
+					return v, Defer(func() (err error) {
+						*v, err = lookup(value)
+						return err
+					})
+				},
+			)
+			parser := Many1(Suffixed(assignment, Char(';')))
+
+			gotResult, gotErr := comb.RunOnString(tc.input, parser)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			gotOutput := make([]int, len(gotResult))
+			for i, v := range gotResult {
+				gotOutput[i] = *v
+			}
+			if !slices.Equal(gotOutput, tc.wantOutput) {
+				t.Errorf("got output %v, want output %v", gotOutput, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    string
+		wantOutput int
+	}{
+		{
+			name:       "no nesting should succeed",
+			input:      "()",
+			wantOutput: 1,
+		}, {
+			name:       "maximum nesting should succeed",
+			input:      "((()))",
+			wantOutput: 3,
+		}, {
+			name:    "too deep nesting should fail",
+			input:   "(((())))",
+			wantErr: "nesting depth of 3 exceeded by '(' [1:4] (((▶())))",
+		}, {
+			name:    "unclosed delimiter should fail",
+			input:   "((()",
+			wantErr: "expected '(' opened at 1:2 to be closed by ')' (at EOF) [1:5] ((()▶",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var nested comb.Parser[int]
+			nested = Between(comb.SafeSpot(Char('(')), Map(Optional(comb.LazyBranchParser(func() comb.Parser[int] {
+				return nested
+			})), func(depth int) (int, error) {
+				return depth + 1, nil
+			}), Char(')'), 3)
+			// without error recovery because of the direct recursion
+			gotOutput, gotErr := comb.RunOnState(comb.NewFromString(tc.input, 0), comb.NewPreparedParser(nested))
+			if tc.wantErr != "" {
+				if errs := comb.UnwrapErrors(gotErr); len(errs) == 0 || errs[0].Error() != tc.wantErr {
+					t.Errorf("got error %v, want error %q", gotErr, tc.wantErr)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Errorf("got unexpected error %v", gotErr)
+			}
+			if gotOutput != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotOutput, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestBetweenRecovery(t *testing.T) {
+	t.Parallel()
+
+	parser := Suffixed(Many0(Between(comb.SafeSpot(Char('(')), Digit1(), Char(')'), 0)), EOF())
+	gotOutput, gotErr := comb.RunOnString("(1x)(2)", parser)
+	wantErr := `unexpected "x" before '(' opened at 1:1 to be closed by ')' (deleted) [1:3] (1▶x)(2)` // the close parser recovers
+	if gotErr == nil || gotErr.Error() != wantErr {
+		t.Errorf("got error %v, want error %q", gotErr, wantErr)
+	}
+	if wantOutput := []string{"1", "2"}; !slices.Equal(gotOutput, wantOutput) {
+		t.Errorf("got output %q, want output %q", gotOutput, wantOutput)
+	}
+}
+
+func TestBetweenInBind(t *testing.T) {
+	t.Parallel()
+
+	// the Between created by Bind is numbered independently of the registered
+	// parsers, so it mustn't see the nesting depth of the outer Between
+	inner := comb.Bind(Char('['), func(rune) comb.Parser[string] {
+		return Between(Char('<'), Digit1(), Char('>'), 1)
+	})
+	parser := Between(Char('('), Suffixed(inner, Char(']')), Char(')'), 1)
+	gotOutput, gotErr := comb.RunOnString("([<1>])", parser)
+	if gotErr != nil {
+		t.Errorf("got unexpected error %v", gotErr)
+	}
+	if wantOutput := "1"; gotOutput != wantOutput {
+		t.Errorf("got output %q, want output %q", gotOutput, wantOutput)
+	}
+}
+
+func TestExpect(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		require    bool
+		wantErr    string
+		wantOutput string
+	}{
+		{
+			name:       "parsing with suffix should succeed",
+			input:      "abc;",
+			wantOutput: "abc;",
+		}, {
+			name:       "parsing without suffix should report error",
+			input:      "abc",
+			wantErr:    "expected semicolon [1:4] abc▶",
+			wantOutput: "abc\x00",
+		}, {
+			name:       "fatal error should be passed on unchanged",
+			input:      "abc",
+			require:    true,
+			wantErr:    "expected ';' (at EOF) [1:4] abc▶",
+			wantOutput: "abc\ufffd",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			semicolon := Char(';')
+			if tc.require {
+				semicolon = Require(semicolon)
+			}
+			p := Map2(Alpha1(), Expect(semicolon, "semicolon"), func(s string, r rune) (string, error) {
+				return s + string(r), nil
+			})
+			gotOutput, gotErr := comb.RunOnString(tc.input, p)
+			if tc.wantErr != "" {
+				if gotErr == nil || gotErr.Error() != tc.wantErr {
+					t.Errorf("got error %v, want error %q", gotErr, tc.wantErr)
+				}
+			} else if gotErr != nil {
+				t.Errorf("got unexpected error %v", gotErr)
+			}
+			if gotOutput != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotOutput, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestLabel(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput string
+	}{
+		{
+			name:       "parsing with suffix should succeed",
+			input:      "abc;",
+			wantOutput: "abc;",
+		}, {
+			name:    "parsing without suffix should fail like the parser",
+			input:   "abc",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			semicolon := Label(Char(';'), "semicolon")
+			if got := semicolon.Expected(); got != "semicolon" {
+				t.Errorf("got expected %q, want %q", got, "semicolon")
+			}
+			p := Map2(Alpha1(), semicolon, func(s string, r rune) (string, error) {
+				return s + string(r), nil
+			})
+			gotOutput, gotErr := comb.RunOnString(tc.input, p)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if !tc.wantErr && gotOutput != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotOutput, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		require    bool
+		wantOutput []string
+	}{
+		{
+			name:       "recovery should be used without Require",
+			require:    false,
+			wantOutput: []string{"abc", "", "def"},
+		}, {
+			name:       "recovery should be forbidden with Require",
+			require:    true,
+			wantOutput: []string{"abc", ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			count := Map(Suffixed(UInt64(false, 10), Char(':')), func(n uint64) (int, error) {
+				return int(n), nil
+			})
+			word := Alpha1()
+			if tc.require {
+				word = Require(word)
+			}
+			item := Suffixed(word, comb.SafeSpot(Char(';')))
+			gotOutput, gotErr := comb.RunOnString("3:abc;123;def;", CountOf(count, item))
+			if got := len(comb.UnwrapErrors(gotErr)); got != 1 {
+				t.Errorf("got errors %v, want 1 error", gotErr)
+			}
+			if !slices.Equal(gotOutput, tc.wantOutput) {
+				t.Errorf("got output %q, want output %q", gotOutput, tc.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package cmb
+
+import (
+	"github.com/flowdev/comb"
+)
+
+// When applies the parser only if the condition holds for the current state.
+// Otherwise, it returns a zero value without consuming any input.
+func When[Output any](cond func(comb.State) bool, parser comb.Parser[Output]) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	if cond == nil {
+		panic("When: cond is nil")
+	}
+
+	p = comb.NewBranchParser[Output](
+		"When",
+		func() []comb.AnyParser {
+			return []comb.AnyParser{parser}
+		}, func(
+			childID int32,
+			childStartState, childState comb.State,
+			childOut interface{},
+			childErr *comb.ParserError,
+			data interface{},
+		) (comb.State, Output, *comb.ParserError, interface{}) {
+			comb.Debugf("When.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				if !cond(childState) {
+					return childState, comb.ZeroOf[Output](), nil, nil
+				}
+				childState, childOut, childErr = parser.ParseAny(p.ID(), childState)
+			}
+			out, _ := childOut.(Output)
+			return childState, out, childErr, nil
+		},
+	)
+	return p
+}
+
+// IfElse applies the predicate parser first.
+// If it succeeds, its input is consumed and the parser returned by `thenP`
+// is applied next.
+// Otherwise, nothing is consumed and the parser returned by `elseP` is applied.
+// Both functions get the output of the predicate parser.
+//
+// NOTE:
+//   - Even though IfElse accepts parsers as arguments, it behaves like a leaf parser
+//     to the outside world. Errors of the sub-parsers look as if coming from IfElse itself.
+//   - There is no optimized recoverer.
+func IfElse[C, Output any](
+	pred comb.Parser[C], thenP, elseP func(C) comb.Parser[Output],
+) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	if thenP == nil {
+		panic("IfElse: thenP is nil")
+	}
+	if elseP == nil {
+		panic("IfElse: elseP is nil")
+	}
+
+	parse := func(state comb.State) (comb.State, Output, *comb.ParserError) {
+		var sub comb.Parser[Output]
+
+		nState, aCond, err := pred.ParseAny(comb.ParentUnknown, state)
+		c, _ := aCond.(C)
+		if err != nil && err.Fatal() {
+			return state, comb.ZeroOf[Output](), comb.ClaimError(err)
+		}
+		if err == nil {
+			sub = thenP(c)
+		} else {
+			nState = state
+			sub = elseP(c)
+		}
+		nState, aOut, err := sub.ParseAny(comb.ParentUnknown, nState)
+		out, _ := aOut.(Output)
+		if err != nil {
+			return state, out, comb.ClaimError(err)
+		}
+		return nState, out, nil
+	}
+
+	p = comb.NewParser[Output]("IfElse", parse, nil)
+	return p
+}
+
+// Verify applies the parser and checks its output with the predicate.
+// If the predicate fails, Verify fails with a syntax error
+// (`expected ` + msg) at the start of the parser.
+// So semantic constraints like "number must be < 256" are easy to express.
+func Verify[Output any](parser comb.Parser[Output], pred func(Output) bool, msg string) comb.Parser[Output] {
+	var p comb.Parser[Output]
+
+	if pred == nil {
+		panic("Verify: pred is nil")
+	}
+
+	p = comb.NewBranchParser[Output](
+		msg,
+		func() []comb.AnyParser {
+			return []comb.AnyParser{parser}
+		}, func(
+			childID int32,
+			childStartState, childState comb.State,
+			childOut interface{},
+			childErr *comb.ParserError,
+			data interface{},
+		) (comb.State, Output, *comb.ParserError, interface{}) {
+			comb.Debugf("Verify.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				childStartState = childState
+				childState, childOut, childErr = parser.ParseAny(p.ID(), childStartState)
+			}
+			out, _ := childOut.(Output)
+			if childErr != nil {
+				return childState, out, childErr, nil
+			}
+			if !pred(out) {
+				return childStartState, out, childStartState.NewSyntaxError(msg), nil
+			}
+			return childState, out, nil, nil
+		},
+	)
+	return p
+}
@@ -0,0 +1,139 @@
+package cmb
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+)
+
+func TestWhen(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		cond       func(comb.State) bool
+		input      string
+		wantErr    bool
+		wantOutput string
+	}{
+		{
+			name:       "true condition should parse",
+			cond:       func(comb.State) bool { return true },
+			input:      "abc",
+			wantOutput: "abc",
+		}, {
+			name:       "false condition should succeed without parsing",
+			cond:       func(comb.State) bool { return false },
+			input:      "abc",
+			wantOutput: "",
+		}, {
+			name:    "true condition with bad input should fail",
+			cond:    func(comb.State) bool { return true },
+			input:   "123",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, gotResult, gotErr := When(tc.cond, Alpha1()).Parse(comb.NewFromString(tc.input, 0))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if !tc.wantErr && gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestIfElse(t *testing.T) {
+	t.Parallel()
+
+	thenP := func(_ rune) comb.Parser[string] { return Digit1() }
+	elseP := func(_ rune) comb.Parser[string] { return Alpha1() }
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "then branch",
+			input:         "#123abc",
+			wantOutput:    "123",
+			wantRemaining: "abc",
+		}, {
+			name:          "else branch",
+			input:         "abc123",
+			wantOutput:    "abc",
+			wantRemaining: "123",
+		}, {
+			name:          "failing then branch",
+			input:         "#abc",
+			wantErr:       true,
+			wantRemaining: "#abc",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			nState, gotResult, gotErr := IfElse(Char('#'), thenP, elseP).Parse(comb.NewFromString(tc.input, 0))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if !tc.wantErr && gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+			if got := nState.CurrentString(); got != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", got, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput uint64
+	}{
+		{
+			name:       "valid number",
+			input:      "255",
+			wantOutput: 255,
+		}, {
+			name:       "too big number",
+			input:      "256",
+			wantErr:    true,
+			wantOutput: 256,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := Verify(UInt64(false, 10), func(i uint64) bool { return i < 256 }, "number < 256")
+			_, gotResult, gotErr := parser.Parse(comb.NewFromString(tc.input, 0))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if gotErr != nil && gotErr.Error() != "expected number < 256 [1:1] ▶256" {
+				t.Errorf("got error message %q", gotErr.Error())
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotResult, tc.wantOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package cmb
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Derive Parsers From Struct Tags
+//
+
+// DeriveOption configures the parser created by Derive.
+type DeriveOption func(*deriveConfig)
+
+type deriveConfig struct {
+	skip func(rune) bool
+}
+
+// DeriveSkip sets the predicate for the runes that are skipped before every
+// literal and value.
+// The default is unicode.IsSpace.
+// A nil predicate turns skipping off.
+func DeriveSkip(skip func(rune) bool) DeriveOption {
+	return func(cfg *deriveConfig) {
+		cfg.skip = skip
+	}
+}
+
+// Derive creates a parser for the struct type T from the `comb` tags of its fields.
+// A tag is a sequence of literals in single quotes and the word `value`
+// for the value of the field, e.g.: `comb:"'port' '=' value ';'"`.
+// Literals can contain the escape sequences `\'`, `\\`, `\n`, `\r` and `\t`.
+// The fields are parsed in the order of their declaration and
+// fields without a `comb` tag are ignored.
+//
+// Supported field types are strings, booleans, integers and floats.
+// String values are either Go strings in double quotes or all runes up to
+// the next rune to skip or the first rune of the next literal.
+//
+// NOTE:
+//   - Even though Derive uses other parsers, it behaves like a leaf parser
+//     to the outside world. Errors of the sub-parsers look as if coming from Derive itself.
+//   - Derive panics if T isn't a struct or a tag is invalid.
+//   - There is no optimized recoverer.
+func Derive[T any](opts ...DeriveOption) comb.Parser[T] {
+	var p comb.Parser[T]
+
+	cfg := deriveConfig{skip: unicode.IsSpace}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("Derive: type %s isn't a struct", typ))
+	}
+	items := deriveItems(typ)
+	for i, item := range items { // the value parsers are built only once
+		if item.field >= 0 {
+			items[i].value = deriveValue(typ.Field(item.field).Type, item.stop, cfg.skip)
+		}
+	}
+	expected := typ.Name()
+	if expected == "" {
+		expected = "record"
+	}
+
+	parse := func(state comb.State) (comb.State, T, *comb.ParserError) {
+		var out T
+
+		v := reflect.ValueOf(&out).Elem()
+		nState := state
+		for _, item := range items {
+			nState = skipRunes(nState, cfg.skip)
+			if item.field < 0 {
+				if !strings.HasPrefix(nState.CurrentString(), item.literal) {
+					return state, out, nState.NewSyntaxError("%q", item.literal)
+				}
+				nState = nState.MoveBy(len(item.literal))
+				continue
+			}
+			var err *comb.ParserError
+			nState, err = item.value(nState, v.Field(item.field))
+			if err != nil {
+				return state, out, comb.ClaimError(err)
+			}
+		}
+		return nState, out, nil
+	}
+
+	p = comb.NewParser[T](expected, parse, nil)
+	return p
+}
+
+// deriveItem is either a literal or the value of a field (field >= 0).
+type deriveItem struct {
+	literal string
+	field   int
+	stop    rune                                                            // first rune of the next literal (for string values)
+	value   func(comb.State, reflect.Value) (comb.State, *comb.ParserError) // parser of the value
+}
+
+func deriveItems(typ reflect.Type) []deriveItem {
+	var items []deriveItem
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag, ok := field.Tag.Lookup("comb")
+		if !ok {
+			continue
+		}
+		if !field.IsExported() {
+			panic(fmt.Sprintf("Derive: field %s has to be exported", field.Name))
+		}
+		switch field.Type.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			panic(fmt.Sprintf("Derive: field %s has unsupported type %s", field.Name, field.Type))
+		}
+		items = append(items, parseDeriveTag(tag, field.Name, i)...)
+	}
+
+	for i := len(items) - 2; i >= 0; i-- {
+		if items[i].field >= 0 && items[i+1].field < 0 {
+			items[i].stop, _ = utf8.DecodeRuneInString(items[i+1].literal)
+		}
+	}
+	return items
+}
+
+func parseDeriveTag(tag, fieldName string, fieldIndex int) []deriveItem {
+	var items []deriveItem
+	for tag = strings.TrimSpace(tag); tag != ""; tag = strings.TrimSpace(tag) {
+		end := strings.IndexFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || r == '\'' })
+		if end < 0 {
+			end = len(tag)
+		}
+		switch {
+		case tag[:end] == "value":
+			items = append(items, deriveItem{field: fieldIndex})
+			tag = tag[end:]
+		case tag[0] == '\'':
+			literal, n := unquoteDeriveLiteral(tag[1:])
+			if n < 0 || literal == "" {
+				panic(fmt.Sprintf("Derive: invalid literal in tag of field %s: %s", fieldName, tag))
+			}
+			items = append(items, deriveItem{literal: literal, field: -1})
+			tag = tag[1+n:]
+		default:
+			panic(fmt.Sprintf("Derive: unexpected text in tag of field %s: %s", fieldName, tag))
+		}
+	}
+	return items
+}
+
+// unquoteDeriveLiteral returns the literal up to the closing quote and
+// the number of bytes consumed (including the quote) or -1.
+func unquoteDeriveLiteral(s string) (string, int) {
+	literal := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			return literal.String(), i + 1
+		case '\\':
+			i++
+			if i >= len(s) {
+				return "", -1
+			}
+			switch s[i] {
+			case 'n':
+				literal.WriteByte('\n')
+			case 'r':
+				literal.WriteByte('\r')
+			case 't':
+				literal.WriteByte('\t')
+			case '\\', '\'':
+				literal.WriteByte(s[i])
+			default:
+				return "", -1
+			}
+		default:
+			literal.WriteByte(s[i])
+		}
+	}
+	return "", -1
+}
+
+// deriveValue returns the parser for values of the type.
+func deriveValue(typ reflect.Type, stop rune, skip func(rune) bool) func(comb.State, reflect.Value) (comb.State, *comb.ParserError) {
+	switch typ.Kind() {
+	case reflect.String:
+		return func(state comb.State, v reflect.Value) (comb.State, *comb.ParserError) {
+			input := state.CurrentString()
+			if strings.HasPrefix(input, `"`) {
+				quoted, err := strconv.QuotedPrefix(input)
+				if err != nil {
+					return state, state.NewSyntaxError("Go string")
+				}
+				s, _ := strconv.Unquote(quoted)
+				v.SetString(s)
+				return state.MoveBy(len(quoted)), nil
+			}
+			n := indexFunc(state, input, func(r rune) bool {
+				return r == stop || (skip != nil && skip(r))
+			})
+			if n < 0 {
+				n = len(input)
+			}
+			if n == 0 {
+				return state, state.NewSyntaxError("%s value", v.Type())
+			}
+			v.SetString(input[:n])
+			return state.MoveBy(n), nil
+		}
+	case reflect.Bool:
+		p := OneOf("true", "false")
+		return func(state comb.State, v reflect.Value) (comb.State, *comb.ParserError) {
+			nState, out, err := p.Parse(state)
+			if err != nil {
+				return state, err
+			}
+			v.SetBool(out == "true")
+			return nState, nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p := Int[int64](true, 10)
+		return func(state comb.State, v reflect.Value) (comb.State, *comb.ParserError) {
+			nState, out, err := p.Parse(state)
+			if err != nil {
+				return state, err
+			}
+			if v.OverflowInt(out) {
+				return state, state.NewSemanticError("value %d overflows %s", out, v.Type())
+			}
+			v.SetInt(out)
+			return nState, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		p := Int[uint64](false, 10)
+		return func(state comb.State, v reflect.Value) (comb.State, *comb.ParserError) {
+			nState, out, err := p.Parse(state)
+			if err != nil {
+				return state, err
+			}
+			if v.OverflowUint(out) {
+				return state, state.NewSemanticError("value %d overflows %s", out, v.Type())
+			}
+			v.SetUint(out)
+			return nState, nil
+		}
+	default: // floats
+		p := Float64(true, 10)
+		return func(state comb.State, v reflect.Value) (comb.State, *comb.ParserError) {
+			nState, out, err := p.Parse(state)
+			if err != nil {
+				return state, err
+			}
+			if v.OverflowFloat(out) {
+				return state, state.NewSemanticError("value %g overflows %s", out, v.Type())
+			}
+			v.SetFloat(out)
+			return nState, nil
+		}
+	}
+}
+
+func skipRunes(state comb.State, skip func(rune) bool) comb.State {
+	if skip == nil {
+		return state
+	}
+	input := state.CurrentString()
+	n := indexFunc(state, input, func(r rune) bool { return !skip(r) })
+	if n < 0 {
+		n = len(input)
+	}
+	return state.MoveBy(n)
+}
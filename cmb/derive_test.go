@@ -0,0 +1,105 @@
+package cmb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+type serverConfig struct {
+	Name    string  `comb:"'name' '=' value ';'"`
+	Port    uint16  `comb:"'port' '=' value ';'"`
+	Debug   bool    `comb:"'debug' '=' value ';'"`
+	Offset  int8    `comb:"'offset' '=' value ';'"`
+	Ratio   float64 `comb:"'ratio' '=' value ';'"`
+	ignored string
+}
+
+func TestDerive(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    serverConfig
+		wantRemaining string
+	}{
+		{
+			name:  "parsing a full record should succeed",
+			input: "name = web;\nport=8080 ;\ndebug = true;\noffset = -3;\nratio = 0.5; rest",
+			wantOutput: serverConfig{
+				Name: "web", Port: 8080, Debug: true, Offset: -3, Ratio: 0.5,
+			},
+			wantRemaining: " rest",
+		}, {
+			name:  "parsing a quoted string should succeed",
+			input: `name="a; b"; port=1; debug=false; offset=0; ratio=1;`,
+			wantOutput: serverConfig{
+				Name: "a; b", Port: 1, Ratio: 1,
+			},
+			wantRemaining: "",
+		}, {
+			name:          "parsing a missing literal should fail",
+			input:         "name = web; port 8080;",
+			wantErr:       true,
+			wantRemaining: "name = web; port 8080;",
+		}, {
+			name:          "parsing a too big number should fail",
+			input:         "name = web; port = 80800;",
+			wantErr:       true,
+			wantRemaining: "name = web; port = 80800;",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := cmb.Derive[serverConfig]().Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.wantOutput, gotResult)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestDeriveSkip(t *testing.T) {
+	t.Parallel()
+
+	type pair struct {
+		Key   string `comb:"value '\\t'"`
+		Value int    `comb:"value '\\n'"`
+	}
+
+	gotResult, gotErr := comb.RunOnString("a b\t42\n", cmb.Derive[pair](cmb.DeriveSkip(nil)))
+	assert.NoError(t, gotErr)
+	assert.Equal(t, pair{Key: "a b", Value: 42}, gotResult)
+
+	assert.Panics(t, func() {
+		type bad struct {
+			Key string `comb:"'unclosed"`
+		}
+		cmb.Derive[bad]()
+	})
+	assert.Panics(t, func() {
+		type bad struct {
+			Key string `comb:"valueX"`
+		}
+		cmb.Derive[bad]()
+	})
+	assert.Panics(t, func() {
+		cmb.Derive[int]()
+	})
+}
@@ -3,6 +3,7 @@ package cmb
 import (
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -30,11 +31,17 @@ type PostfixOp[Output any] struct {
 	SafeSpot bool
 	Fn       func(Output) Output
 }
+type ChainOp struct {
+	Op       string
+	SafeSpot bool
+}
 
 type PrecedenceLevel[Output any] struct {
 	prefixLevel  []PrefixOp[Output]
 	infixLevel   []InfixOp[Output]
 	postfixLevel []PostfixOp[Output]
+	chainLevel   []ChainOp
+	chainFn      func(operands []Output, ops []string) Output
 	opParser     comb.Parser[string]
 	opFn1s       map[string]func(Output) Output
 	opFn2s       map[string]func(Output, Output) Output
@@ -138,6 +145,43 @@ func PostfixLevel[Output any](ops []PostfixOp[Output]) PrecedenceLevel[Output] {
 	}
 }
 
+// ChainLevel returns a precedence level for evaluating chains of
+// operations (like Python's chained comparisons: `a < b <= c`).
+// All operands and operators of a chain are collected, and the function
+// computes the output from them (e.g. `a < b && b <= c`).
+// It is only called for chains with at least one operator.
+// Contrary to InfixLevel, this allows operators whose results have a different
+// meaning than their operands (e.g. bool instead of number).
+// It will panic in the following cases:
+//   - empty string for the operator
+//   - nil function for the output mapping
+//   - double operators
+func ChainLevel[Output any](ops []ChainOp, fn func(operands []Output, ops []string) Output) PrecedenceLevel[Output] {
+	if fn == nil {
+		panic("chain level has no mapping function")
+	}
+	check := make(map[string]struct{}, len(ops))
+	sops := make([]string, len(ops))
+	safeSpots := make(map[string]bool, len(ops))
+	for i, op := range ops {
+		if op.Op == "" {
+			panic(fmt.Sprintf("chain operation with index %d has no operator", i))
+		}
+		if _, ok := check[op.Op]; ok {
+			panic(fmt.Sprintf("chain operation %q (index %d) is a duplicate", op.Op, i))
+		}
+		check[op.Op] = struct{}{}
+		sops[i] = op.Op
+		safeSpots[op.Op] = op.SafeSpot
+	}
+	return PrecedenceLevel[Output]{
+		chainLevel:  ops,
+		chainFn:     fn,
+		opSafeSpots: safeSpots,
+		opsText:     fmt.Sprintf("%q", sops),
+	}
+}
+
 type expr[Output any] struct {
 	id                func() int32
 	expected          string
@@ -149,6 +193,7 @@ type expr[Output any] struct {
 	closeParenParser  comb.Parser[string]
 	closeParenParsers map[string]comb.Parser[string]
 	safeSpots         []safeSpot
+	spaceAroundInfix  bool // space before and after infix operators has to match
 }
 type parens struct {
 	open, close string
@@ -175,6 +220,8 @@ type levelData[Output any] struct {
 	out    Output
 	op     string
 	preOps []string
+	outs   []Output // operands of a chain level
+	ops    []string // operators of a chain level
 	exit   int
 }
 
@@ -185,7 +232,7 @@ type levelData[Output any] struct {
 // The valueParser MUST be a simple parser that doesn't need any data for error recovery.
 //
 // PrecedenceLevel s can be set in this function call or added one by one later.
-// Each PrecedenceLevel can only contain either all prefix or all infix or all postfix operators.
+// Each PrecedenceLevel can only contain either all prefix or all infix or all postfix or all chain operators.
 // Within each level evaluation is always from left to right.
 // The order of the levels matters and is similar to FirstSuccessful.
 // The first level added, binds the strongest (e.g., unary sign operator) and
@@ -219,6 +266,10 @@ func (e expr[Output]) AddPostfixLevel(level ...PostfixOp[Output]) expr[Output] {
 	e.levels = append(e.levels, PostfixLevel(level))
 	return e
 }
+func (e expr[Output]) AddChainLevel(fn func(operands []Output, ops []string) Output, level ...ChainOp) expr[Output] {
+	e.levels = append(e.levels, ChainLevel(level, fn))
+	return e
+}
 func (e expr[Output]) AddParentheses(open, close string, safeSpot bool) expr[Output] {
 	e.parens = append(e.parens, parens{open: open, close: close, safeSpot: safeSpot})
 	return e
@@ -232,6 +283,18 @@ func (e expr[Output]) WithSpace(spaceParser comb.Parser[string]) expr[Output] {
 	return e
 }
 
+// RequireSpaceAroundInfix makes the space around infix operators significant
+// (like in Swift) and returns the updated expression object.
+// An infix operator then needs space on both sides or on neither side.
+// So `a - b` and `a-b` are subtractions, but `a -b` ends the expression
+// after `a` and leaves `-b` to be parsed as a prefix operation
+// (e.g. as the next element of a list).
+// Space is everything consumed by the space parser (see WithSpace).
+func (e expr[Output]) RequireSpaceAroundInfix(require bool) expr[Output] {
+	e.spaceAroundInfix = require
+	return e
+}
+
 // WithExpected sets what kind of expression is expected and
 // returns the updated expression object.
 // This is used by other parsers embedding this one, like the `Not` parser.
@@ -288,7 +351,7 @@ func (e expr[Output]) checkOperators() expr[Output] {
 		safeSpots = append(safeSpots, safeSpot{op: ")", l: 0, rec: OneOf(safeCloseParens...)})
 	}
 	for l, level := range e.levels {
-		sops := make([]string, len(level.prefixLevel)+len(level.infixLevel)+len(level.postfixLevel))
+		sops := make([]string, len(level.prefixLevel)+len(level.infixLevel)+len(level.postfixLevel)+len(level.chainLevel))
 		switch {
 		case level.prefixLevel != nil:
 			for i, op := range level.prefixLevel {
@@ -312,6 +375,17 @@ func (e expr[Output]) checkOperators() expr[Output] {
 				}
 				sops[i] = op.Op
 			}
+		case level.chainLevel != nil:
+			for i, op := range level.chainLevel {
+				if _, ok := infixCheck[op.Op]; ok { // chain operators are at the same position as infix operators
+					panic(fmt.Sprintf("chain operation %q is a duplicate", op.Op))
+				}
+				infixCheck[op.Op] = struct{}{}
+				if op.SafeSpot {
+					safeSpots = append(safeSpots, safeSpot{op: op.Op, l: l + 1, rec: e.oneOfOperator(op.Op)})
+				}
+				sops[i] = op.Op
+			}
 		default:
 			for i, op := range level.postfixLevel {
 				if _, ok := postfixCheck[op.Op]; ok {
@@ -382,15 +456,14 @@ func (e expr[Output]) indexOfAnyOperator(stops ...string) comb.Recoverer {
 	}
 
 	return func(state comb.State, _ interface{}) (int, interface{}) {
-		orgInput := state.CurrentString()
 		pos := comb.RecoverWasteTooMuch
 		for i := 0; i < n; i++ {
-			input := orgInput
+			cur := state
 			start := 0
 			stopLen := len(stops[i])
 			found := false // we might have to try multiple times because sometimes it just looks like the op but isn't
 			for !found {   // e.g.: "++" instead of "+"
-				switch j := strings.Index(input, stops[i]); j {
+				switch j := cur.Index(stops[i]); j {
 				case -1: // ignore
 					found = true
 				case 0: // it won't get better than this
@@ -406,7 +479,7 @@ func (e expr[Output]) indexOfAnyOperator(stops ...string) comb.Recoverer {
 						}
 					} else {
 						start += stopLen + opLen
-						input = input[stopLen+opLen:]
+						cur = cur.MoveBy(stopLen + opLen)
 					}
 				default:
 					if pos < 0 || start+j < pos {
@@ -417,7 +490,7 @@ func (e expr[Output]) indexOfAnyOperator(stops ...string) comb.Recoverer {
 							found = true
 						} else {
 							start += j + stopLen + opLen
-							input = input[j+stopLen+opLen:]
+							cur = cur.MoveBy(j + stopLen + opLen)
 						}
 					}
 				}
@@ -441,7 +514,7 @@ func isEndOfOp(state comb.State, openParenParser, closeParenParser comb.Parser[s
 	if state.AtEnd() {
 		return true, 0
 	}
-	r, rsize := utf8.DecodeRuneInString(state.CurrentString())
+	r, rsize := state.CurrentRune()
 	if r != utf8.RuneError {
 		if IsAlphanumeric(r) || unicode.IsSpace(r) {
 			return true, 0
@@ -524,6 +597,8 @@ func (e expr[Output]) parseLevelWithData(
 		return e.parsePrefixLevelWithData(l, e.levels[l], state, data)
 	case e.levels[l].infixLevel != nil:
 		return e.parseInfixLevelWithData(l, e.levels[l], state, data)
+	case e.levels[l].chainLevel != nil:
+		return e.parseChainLevelWithData(l, e.levels[l], state, data)
 	default:
 		return e.parsePostfixLevelWithData(l, e.levels[l], state, data)
 	}
@@ -733,16 +808,19 @@ func (e expr[Output]) parseInfixLevelWithData(
 			}
 			state = nState
 		}
-		parseSpace = true
 		if parseOp {
 			nState, op, err = level.opParser.Parse(state)
 			if err != nil {
 				return startState, out, nil, nil // good case
 			}
+			if parseSpace && e.spaceAroundInfix && !e.balancedSpace(startState, state, nState) {
+				return startState, out, nil, nil // good case: it's a prefix or postfix operator
+			}
 			state = nState
 		} else {
 			op = rData.lData[l].op
 		}
+		parseSpace = true
 		parseOp = true
 		val1 := out
 		if parseVal2 {
@@ -765,6 +843,86 @@ func (e expr[Output]) parseInfixLevelWithData(
 		}
 	}
 }
+func (e expr[Output]) parseChainLevelWithData(
+	l int,
+	level PrecedenceLevel[Output],
+	startState comb.State,
+	data *recoverData[Output],
+) (comb.State, Output, *comb.ParserError, *recoverData[Output]) {
+	var out Output
+	var err *comb.ParserError
+	var rData *recoverData[Output]
+
+	parseVal1, parseSpace, parseOp, _ := infixParseCase(l, data)
+
+	if data == nil {
+		rData = &recoverData[Output]{lData: make([]levelData[Output], len(e.levels))}
+	} else {
+		rData = data
+	}
+	state := startState
+	nState := state
+	data2 := data
+	op := ""
+	outs := slices.Clone(rData.lData[l].outs)
+	ops := slices.Clone(rData.lData[l].ops)
+
+	if parseVal1 {
+		nState, out, err, data2 = e.parseLevelWithData(l-1, state, data)
+		if err != nil {
+			err.PatchMessage("chain operator " + level.opsText + " or ")
+			rData = data2
+			rData.lData[l] = levelData[Output]{exit: 1, out: out, outs: outs, ops: ops}
+			return nState, out, err, rData // exit 1
+		}
+		state = nState
+		outs = append(outs, out)
+	} else { // the failed operator and its operand are lost
+		if len(outs) == 0 {
+			outs = append(outs, rData.lData[l].out)
+		}
+		ops = ops[:len(outs)-1]
+	}
+	for {
+		startState = state
+		if parseSpace {
+			nState, err = e.parseSpace(state)
+			if err != nil {
+				break // good case
+			}
+			state = nState
+		}
+		if parseOp {
+			nState, op, err = level.opParser.Parse(state)
+			if err != nil || parseSpace && e.spaceAroundInfix && !e.balancedSpace(startState, state, nState) {
+				state = startState
+				break // good case
+			}
+			state = nState
+		}
+		parseSpace = true
+		parseOp = true
+		ops = append(ops, op)
+
+		nState, out, err, data2 = e.parseLevelWithData(l-1, state, nil)
+		if err != nil {
+			err.PatchMessage("chain operator " + level.opsText + " or ")
+			rData = data2
+			rData.lData[l] = levelData[Output]{exit: 2, outs: outs, ops: ops}
+			return nState, level.chainFn(append(outs, out), ops), err, rData // exit 2
+		}
+		outs = append(outs, out)
+		state = nState
+		if level.opSafeSpots[op] {
+			state = nState.MoveSafeSpot()
+		}
+	}
+
+	if len(outs) == 1 {
+		return state, outs[0], nil, nil
+	}
+	return state, level.chainFn(outs, ops), nil, nil
+}
 func (e expr[Output]) parsePostfixLevelWithData(
 	l int,
 	level PrecedenceLevel[Output],
@@ -860,6 +1018,14 @@ func postfixParseCase[Output any](l int, data *recoverData[Output]) (parseVal1,
 	return true, true, true
 }
 
+// balancedSpace returns true if there is space before and after an operator or on neither side.
+// The operator starts at opState and the space before it starts at spaceState.
+func (e expr[Output]) balancedSpace(spaceState, opState, afterOpState comb.State) bool {
+	spaceBefore := opState.Moved(spaceState)
+	nState, _ := e.parseSpace(afterOpState)
+	return spaceBefore == nState.Moved(afterOpState)
+}
+
 func (e expr[Output]) parseSpace(state comb.State) (comb.State, *comb.ParserError) {
 	nState, _, err := e.space.Parse(state)
 	if err != nil {
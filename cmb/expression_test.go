@@ -317,6 +317,168 @@ func TestExpression_HappyPath(t *testing.T) {
 	}
 }
 
+func TestExpression_SpaceAroundInfix(t *testing.T) {
+	t.Parallel()
+
+	newParser := func(require bool) comb.Parser[int64] {
+		return cmb.Expression(cmb.Int64(false, 10)).
+			AddPrefixLevel(cmb.PrefixOp[int64]{Op: "-", Fn: func(i int64) int64 { return -i }}).
+			AddInfixLevel(cmb.InfixOp[int64]{Op: "-", Fn: func(a, b int64) int64 { return a - b }}).
+			RequireSpaceAroundInfix(require).Parser()
+	}
+
+	testCases := []struct {
+		name          string
+		require       bool
+		input         string
+		wantOutput    int64
+		wantRemaining string
+	}{
+		{
+			name:          "space on both sides should be infix",
+			require:       true,
+			input:         "5 - 3",
+			wantOutput:    2,
+			wantRemaining: "",
+		}, {
+			name:          "space on neither side should be infix",
+			require:       true,
+			input:         "5-3",
+			wantOutput:    2,
+			wantRemaining: "",
+		}, {
+			name:          "space only before should leave a prefix op",
+			require:       true,
+			input:         "5 -3",
+			wantOutput:    5,
+			wantRemaining: " -3",
+		}, {
+			name:          "space only after should end the expression",
+			require:       true,
+			input:         "5- 3",
+			wantOutput:    5,
+			wantRemaining: "- 3",
+		}, {
+			name:          "prefix op after balanced infix op should work",
+			require:       true,
+			input:         "5 - -3",
+			wantOutput:    8,
+			wantRemaining: "",
+		}, {
+			name:          "space should be ignored by default",
+			require:       false,
+			input:         "5 -3",
+			wantOutput:    2,
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := newParser(tc.require).Parse(comb.NewFromString(tc.input, 10))
+			if gotErr != nil {
+				t.Errorf("got unexpected error %v", gotErr)
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotResult, tc.wantOutput)
+			}
+			if remaining := newState.CurrentString(); remaining != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remaining, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestExpression_ChainLevel(t *testing.T) {
+	t.Parallel()
+
+	compare := func(operands []int64, ops []string) int64 {
+		for i, op := range ops {
+			a, b := operands[i], operands[i+1]
+			if op == "<" && a >= b || op == "<=" && a > b {
+				return 0
+			}
+		}
+		return 1
+	}
+	newParser := func() comb.Parser[int64] {
+		return cmb.Expression(cmb.Int64(false, 10)).
+			AddInfixLevel(cmb.InfixOp[int64]{Op: "+", Fn: func(a, b int64) int64 { return a + b }}).
+			AddChainLevel(compare, cmb.ChainOp{Op: "<="}, cmb.ChainOp{Op: "<"}).
+			Parser()
+	}
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    int64
+		wantRemaining string
+	}{
+		{
+			name:          "single operand should be returned unchanged",
+			input:         "5 ",
+			wantOutput:    5,
+			wantRemaining: " ",
+		}, {
+			name:          "true chain should be true",
+			input:         "1 < 2 <= 2",
+			wantOutput:    1,
+			wantRemaining: "",
+		}, {
+			name:          "false chain should be false even if folding left would be true",
+			input:         "3 < 2 < 5",
+			wantOutput:    0,
+			wantRemaining: "",
+		}, {
+			name:          "stronger levels should be evaluated first",
+			input:         "1+1 < 3 <= 1+2!",
+			wantOutput:    1,
+			wantRemaining: "!",
+		}, {
+			name:          "missing operand should fail",
+			input:         "1 < ",
+			wantErr:       true,
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := newParser().Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if !tc.wantErr && gotResult != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotResult, tc.wantOutput)
+			}
+			if remaining := newState.CurrentString(); remaining != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remaining, tc.wantRemaining)
+			}
+		})
+	}
+
+	t.Run("recovery should keep the rest of the chain", func(t *testing.T) {
+		t.Parallel()
+
+		p := cmb.Expression(comb.SafeSpot(cmb.Int64(false, 10))).
+			AddChainLevel(compare, cmb.ChainOp{Op: "<", SafeSpot: true}).Parser()
+		gotResult, gotErr := comb.RunOnString("1 < x < 3", p)
+		if gotErr == nil {
+			t.Errorf("expected an error")
+		}
+		if gotResult != 1 { // 1 < 3
+			t.Errorf("got output %d, want output %d", gotResult, 1)
+		}
+	})
+}
+
 func TestExpression_ErrorCases(t *testing.T) {
 	t.Parallel()
 
@@ -9,21 +9,28 @@ import (
 // All parsers have to be of the same type.
 //
 // If no parser succeeds, this combinator produces an error Result.
+// A fatal error of a parser stops trying the other parsers.
+// The diagnostic mode of comb.FindAmbiguities and the breadth-first
+// parsing of comb.ParseForest are supported.
 func FirstSuccessful[Output any](parsers ...comb.Parser[Output]) comb.Parser[Output] {
 	if len(parsers) == 0 {
 		panic("FirstSuccessful(missing parsers)")
 	}
 
 	fsd := &firstSuccessfulData[Output]{parsers: parsers}
+	fsd.alternatives = fsd.children()
 
 	p := comb.NewBranchParser[Output]("FirstSuccessful", fsd.children, fsd.parseAfterChild)
 	fsd.id = p.ID
+	fsd.self = p
 	return p
 }
 
 type firstSuccessfulData[Output any] struct {
-	id      func() int32
-	parsers []comb.Parser[Output]
+	id           func() int32
+	self         comb.AnyParser
+	parsers      []comb.Parser[Output]
+	alternatives []comb.AnyParser // the parsers for comb.State.CheckAlternatives and ForkChoice
 }
 
 // partialFSResult is internal to the parsing method and methods and functions called by it.
@@ -63,7 +70,7 @@ func (fsd *firstSuccessfulData[Output]) parseAfterChild(
 
 		if childErr == nil {
 			return bestState, bestOut, nil, nil
-		} else if childStartState.SafeSpotMoved(childState) {
+		} else if childStartState.SafeSpotMoved(childState) || childErr.Fatal() {
 			return bestState, bestOut, bestErr, bestRes // we can't avoid this error by going another path
 		}
 	}
@@ -85,9 +92,18 @@ func (fsd *firstSuccessfulData[Output]) parseAfterChild(
 		p := fsd.parsers[i]
 		childState, childOut, childErr = p.ParseAny(fsd.id(), childStartState)
 		if childErr == nil {
+			if childStartState.ForkChoice(i, fsd.alternatives) {
+				if i == 0 {
+					bestState = childStartState
+					bestErr = childStartState.NewSemanticError("successful alternative skipped by comb.ParseForest")
+					bestRes.pos = childStartState.CurrentPos()
+				}
+				continue
+			}
+			childStartState.CheckAlternatives(fsd.self, i, fsd.alternatives)
 			bestRes.out, _ = childOut.(Output)
 			return childState, bestRes.out, nil, nil
-		} else if childStartState.SafeSpotMoved(childState) {
+		} else if childStartState.SafeSpotMoved(childState) || childErr.Fatal() {
 			bestRes.out, _ = childOut.(Output)
 			bestRes.pos = childState.CurrentPos()
 			return childState, bestRes.out, childErr, bestRes // we can't avoid this error by going another path
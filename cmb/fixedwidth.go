@@ -0,0 +1,114 @@
+package cmb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Fixed-Width Records
+//
+
+// FieldSpec describes a field of a fixed-width record.
+type FieldSpec struct {
+	Name   string         // name of the field for error messages
+	Width  int            // width of the field in characters
+	Trim   bool           // leading and trailing spaces are removed before parsing
+	Parser comb.AnyParser // parses the whole field; nil returns the text of the field
+}
+
+// FixedWidth parses a record of fields with fixed widths (like mainframe records).
+// Every field is parsed on its own, so field parsers can't consume the input
+// of the next field.
+// The output contains the output of the field parsers (or the text of fields without parser).
+//
+// Errors name the field and its columns (starting at 1) in the record
+// (e.g.: `expected decimal integer found 'x' (field "amount", columns 11-18)`).
+// The end of a line inside of a record is an error, too.
+// The error recovery continues with the next line.
+func FixedWidth(fields []FieldSpec) comb.Parser[[]interface{}] {
+	var p comb.Parser[[]interface{}]
+
+	if len(fields) == 0 {
+		panic("FixedWidth: no fields given")
+	}
+	for _, field := range fields {
+		if field.Width <= 0 {
+			panic("FixedWidth: the width of field " + field.Name + " has to be positive")
+		}
+	}
+
+	parse := func(state comb.State) (comb.State, []interface{}, *comb.ParserError) {
+		outs := make([]interface{}, 0, len(fields))
+		current := state
+		col := 1
+		for _, field := range fields {
+			where := fieldWhere(field, col)
+			col += field.Width
+
+			input := current.CurrentString()
+			n := 0
+			for i := 0; i < field.Width; i++ {
+				r, size := current.DecodeRune(input[n:])
+				if size == 0 {
+					return state, outs, comb.MarkIncomplete(current.MoveBy(n).NewSyntaxError("rest of field%s (at EOF)", where))
+				}
+				if r == '\n' || r == '\r' {
+					return state, outs, current.MoveBy(n).NewSyntaxError("rest of field%s (got end of line)", where)
+				}
+				n += size
+			}
+
+			text, start := input[:n], 0
+			if field.Trim {
+				start = len(text) - len(strings.TrimLeft(text, " "))
+				text = strings.Trim(text, " ")
+			}
+			if field.Parser == nil {
+				outs = append(outs, text)
+			} else {
+				out, err := parseField(current.MoveBy(start), text, field.Parser, where)
+				if err != nil {
+					return state, outs, err
+				}
+				outs = append(outs, out)
+			}
+			current = current.MoveBy(n)
+		}
+		return current, outs, nil
+	}
+
+	recoverer := func(state comb.State, _ interface{}) (int, interface{}) {
+		i := strings.IndexByte(state.CurrentString(), '\n')
+		if i < 0 {
+			return comb.RecoverWasteTooMuch, nil
+		}
+		return i + 1, nil
+	}
+
+	p = comb.NewParser[[]interface{}]("fixed-width record", parse, recoverer)
+	return p
+}
+
+// parseField parses the text of a field that starts at the state.
+// Errors are reported at their position in the record.
+func parseField(state comb.State, text string, parser comb.AnyParser, where string) (interface{}, *comb.ParserError) {
+	fState, out, err := parser.ParseAny(comb.ParentUnknown, comb.PushInput(state, text, ""))
+	if err == nil && !fState.AtEnd() {
+		err = fState.NewSyntaxError("end of field (got %q)", fState.CurrentString())
+	}
+	if err == nil {
+		return out, nil
+	}
+	pos := 0
+	if ep, ok := comb.PositionOf(err); ok {
+		pos = ep.Pos
+	}
+	return nil, state.MoveBy(pos).NewSemanticError("%s%s", err.Message(), where)
+}
+
+func fieldWhere(field FieldSpec, col int) string {
+	return fmt.Sprintf(" (field %q, columns %d-%d)", field.Name, col, col+field.Width-1)
+}
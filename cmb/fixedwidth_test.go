@@ -0,0 +1,72 @@
+package cmb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedWidth(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[[]interface{}] {
+		return cmb.FixedWidth([]cmb.FieldSpec{
+			{Name: "id", Width: 4, Parser: cmb.Int64(false, 10)},
+			{Name: "name", Width: 6, Trim: true},
+			{Name: "amount", Width: 5, Trim: true, Parser: cmb.Int64(true, 10)},
+		})
+	}
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       string
+		wantOutput    []interface{}
+		wantRemaining string
+	}{
+		{
+			name:          "valid record should succeed",
+			input:         "0042Anna    -17\nnext",
+			wantOutput:    []interface{}{int64(42), "Anna", int64(-17)},
+			wantRemaining: "\nnext",
+		}, {
+			name:          "adjacent numbers should be separated by the widths",
+			input:         "1234Bob   12345",
+			wantOutput:    []interface{}{int64(1234), "Bob", int64(12345)},
+			wantRemaining: "",
+		}, {
+			name:          "invalid field should name the field and columns",
+			input:         "0042Anna    1x7",
+			wantErr:       `end of field (got "x7") (field "amount", columns 11-15) [1:14]`,
+			wantRemaining: "0042Anna    1x7",
+		}, {
+			name:          "field error should be at the position in the record",
+			input:         "00a2Anna      7",
+			wantErr:       `expected end of field (got "a2") (field "id", columns 1-4) [1:3]`,
+			wantRemaining: "00a2Anna      7",
+		}, {
+			name:          "short line should fail",
+			input:         "0042An\n",
+			wantErr:       `expected rest of field (field "name", columns 5-10) (got end of line) [1:7]`,
+			wantRemaining: "0042An\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotOutput, err := newParser().Parse(comb.NewFromString(tc.input, 0))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tc.wantOutput, gotOutput)
+			}
+			assert.Equal(t, tc.wantRemaining, newState.CurrentString())
+		})
+	}
+}
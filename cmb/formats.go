@@ -0,0 +1,753 @@
+package cmb
+
+import (
+	"encoding/base64"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Parse Semantic Versions
+//
+
+// SemVersion is a semantic version as specified by https://semver.org.
+type SemVersion struct {
+	Major, Minor, Patch uint64
+	Pre                 []string // pre-release identifiers
+	Build               []string // build metadata identifiers
+}
+
+// String returns the semantic version in its canonical form.
+func (v SemVersion) String() string {
+	s := strconv.FormatUint(v.Major, 10) + "." + strconv.FormatUint(v.Minor, 10) + "." + strconv.FormatUint(v.Patch, 10)
+	if len(v.Pre) > 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// SemVer parses a semantic version like `1.2.3-rc.1+build.5` fully
+// compliant to https://semver.org.
+// A prefix like `v` isn't part of a semantic version and has to be parsed separately.
+func SemVer() comb.Parser[SemVersion] {
+	var p comb.Parser[SemVersion]
+
+	expected := "semantic version"
+
+	parse := func(state comb.State) (comb.State, SemVersion, *comb.ParserError) {
+		var v SemVersion
+
+		input := state.CurrentString()
+		n := 0 // number of bytes read from input
+		for i, part := range []struct {
+			name string
+			num  *uint64
+		}{{"major", &v.Major}, {"minor", &v.Minor}, {"patch", &v.Patch}} {
+			if i > 0 {
+				if n >= len(input) || input[n] != '.' {
+					return state, SemVersion{}, state.MoveBy(n).NewSyntaxError("%s ('.' before %s version)", expected, part.name)
+				}
+				n++
+			}
+			m := countASCIIDigits(input[n:])
+			if m == 0 {
+				return state, SemVersion{}, state.MoveBy(n).NewSyntaxError("%s (%s version)", expected, part.name)
+			}
+			if m > 1 && input[n] == '0' {
+				return state, SemVersion{}, state.MoveBy(n).NewSyntaxError(
+					"%s (%s version without leading zeros)", expected, part.name,
+				)
+			}
+			num, err := strconv.ParseUint(input[n:n+m], 10, 64)
+			if err != nil {
+				return state, SemVersion{}, state.MoveBy(n).NewSemanticError("%s version overflows 64 bits", part.name)
+			}
+			*part.num = num
+			n += m
+		}
+
+		if n < len(input) && input[n] == '-' {
+			n++
+			ids, m, errMsg := semVerIdentifiers(input[n:], true)
+			if errMsg != "" {
+				return state, SemVersion{}, state.MoveBy(n+m).NewSyntaxError("%s (pre-release %s)", expected, errMsg)
+			}
+			v.Pre = ids
+			n += m
+		}
+		if n < len(input) && input[n] == '+' {
+			n++
+			ids, m, errMsg := semVerIdentifiers(input[n:], false)
+			if errMsg != "" {
+				return state, SemVersion{}, state.MoveBy(n+m).NewSyntaxError("%s (build %s)", expected, errMsg)
+			}
+			v.Build = ids
+			n += m
+		}
+		return state.MoveBy(n), v, nil
+	}
+
+	p = comb.NewParser[SemVersion](expected, parse, nil)
+	return p
+}
+
+// semVerIdentifiers parses dot separated identifiers.
+// It returns the identifiers and the number of bytes consumed.
+// In case of an error, the error message is returned and
+// the number of bytes is the offset of the error.
+func semVerIdentifiers(input string, pre bool) ([]string, int, string) {
+	var ids []string
+
+	n := 0
+	for {
+		m := 0
+		for n+m < len(input) && isSemVerIdentifierByte(input[n+m]) {
+			m++
+		}
+		if m == 0 {
+			return nil, n, "identifier"
+		}
+		id := input[n : n+m]
+		if pre && m > 1 && id[0] == '0' && countASCIIDigits(id) == m {
+			return nil, n, "numeric identifier without leading zeros"
+		}
+		ids = append(ids, id)
+		n += m
+		if n >= len(input) || input[n] != '.' {
+			return ids, n, ""
+		}
+		n++
+	}
+}
+
+func isSemVerIdentifierByte(b byte) bool {
+	return isASCIIAlphanumeric(b) || b == '-'
+}
+
+// ============================================================================
+// Parse Go Module Paths
+//
+
+// ModulePath parses a Go module or import path like `github.com/flowdev/comb`.
+// A path consists of non-empty elements separated by slashes.
+// The elements consist of ASCII letters, ASCII digits and the
+// punctuation characters '-', '.', '_' and '~'.
+// An element mustn't start or end with a dot.
+// A trailing slash isn't part of the path.
+func ModulePath() comb.Parser[string] {
+	var p comb.Parser[string]
+
+	expected := "Go module path"
+
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		n := 0 // number of bytes read from input
+		for {
+			m := 0
+			for n+m < len(input) && isModulePathByte(input[n+m]) {
+				m++
+			}
+			if m == 0 {
+				return state, "", state.MoveBy(n).NewSyntaxError("%s (path element)", expected)
+			}
+			if elem := input[n : n+m]; elem[0] == '.' || elem[m-1] == '.' {
+				return state, "", state.MoveBy(n).NewSyntaxError(
+					"%s (path element that doesn't start or end with '.')", expected,
+				)
+			}
+			n += m
+			if n+1 >= len(input) || input[n] != '/' || !isModulePathByte(input[n+1]) {
+				return state.MoveBy(n), input[:n], nil
+			}
+			n++
+		}
+	}
+
+	p = comb.NewParser[string](expected, parse, nil)
+	return p
+}
+
+func isModulePathByte(b byte) bool {
+	return isASCIIAlphanumeric(b) || strings.IndexByte("-._~", b) >= 0
+}
+
+// ============================================================================
+// Parse URIs
+//
+
+// ParsedURI contains the components of a URI as specified by RFC 3986.
+// All components except the query are percent-decoded.
+// The query is kept raw because decoding would make it ambiguous.
+type ParsedURI struct {
+	Scheme       string
+	HasAuthority bool   // true if the URI contains "//" (even with an empty host)
+	UserInfo     string // user information (e.g. "user:password")
+	Host         string // host name or IP address (IPv6 addresses without brackets)
+	Port         string
+	Path         string
+	RawQuery     string // query without '?'
+	Fragment     string // fragment without '#'
+}
+
+// URI parses an absolute URI (with scheme) as specified by RFC 3986.
+// The authority can contain user information, a host name, an IPv4 address or
+// an IP literal in brackets (IPv6 or IPvFuture) and a port.
+// The URI ends with the first character that isn't allowed in a URI
+// (e.g., a space, '"' or '>').
+// So it can be embedded in larger grammars without extracting it first.
+func URI() comb.Parser[ParsedURI] {
+	var p comb.Parser[ParsedURI]
+
+	expected := "URI"
+
+	parse := func(state comb.State) (comb.State, ParsedURI, *comb.ParserError) {
+		var uri ParsedURI
+
+		input := state.CurrentString()
+		newError := func(pos int, what string) *comb.ParserError {
+			return state.MoveBy(pos).NewSyntaxError("%s (%s)", expected, what)
+		}
+
+		// scheme
+		n := 0
+		for n < len(input) && (isASCIIAlphanumeric(input[n]) || strings.IndexByte("+-.", input[n]) >= 0) {
+			if n == 0 && !isASCIIAlpha(input[n]) {
+				break
+			}
+			n++
+		}
+		if n == 0 {
+			return state, ParsedURI{}, newError(0, "scheme")
+		}
+		if n >= len(input) || input[n] != ':' {
+			return state, ParsedURI{}, newError(n, "':' after scheme")
+		}
+		uri.Scheme = strings.ToLower(input[:n])
+		n++
+
+		// authority
+		if strings.HasPrefix(input[n:], "//") {
+			n += 2
+			uri.HasAuthority = true
+			m, what := parseURIAuthority(input[n:], &uri)
+			if what != "" {
+				return state, ParsedURI{}, newError(n+m, what)
+			}
+			n += m
+		}
+
+		// path
+		m, ok := scanURIPart(input[n:], isURIPathByte)
+		if !ok {
+			return state, ParsedURI{}, newError(n+m, "percent encoding in path")
+		}
+		uri.Path = decodeURIPart(input[n : n+m])
+		n += m
+
+		// query
+		if n < len(input) && input[n] == '?' {
+			n++
+			m, ok = scanURIPart(input[n:], isURIQueryByte)
+			if !ok {
+				return state, ParsedURI{}, newError(n+m, "percent encoding in query")
+			}
+			uri.RawQuery = input[n : n+m]
+			n += m
+		}
+
+		// fragment
+		if n < len(input) && input[n] == '#' {
+			n++
+			m, ok = scanURIPart(input[n:], isURIQueryByte)
+			if !ok {
+				return state, ParsedURI{}, newError(n+m, "percent encoding in fragment")
+			}
+			uri.Fragment = decodeURIPart(input[n : n+m])
+			n += m
+		}
+
+		return state.MoveBy(n), uri, nil
+	}
+
+	p = comb.NewParser[ParsedURI](expected, parse, nil)
+	return p
+}
+
+// parseURIAuthority parses the authority part of a URI into `uri`.
+// It returns the number of bytes consumed.
+// In case of an error, a description of the expected input is returned and
+// the number of bytes is the offset of the error.
+func parseURIAuthority(input string, uri *ParsedURI) (int, string) {
+	n := 0
+
+	// user information (only if followed by '@')
+	m := 0
+	for m < len(input) && (isURIUserInfoByte(input[m]) || input[m] == '%') {
+		m++
+	}
+	if m < len(input) && input[m] == '@' {
+		if k, ok := scanURIPart(input[:m], isURIUserInfoByte); !ok {
+			return k, "percent encoding in user information"
+		}
+		uri.UserInfo = decodeURIPart(input[:m])
+		n = m + 1
+	}
+
+	// host
+	if n < len(input) && input[n] == '[' {
+		end := strings.IndexByte(input[n:], ']')
+		if end < 0 {
+			return n, "IP literal with ']'"
+		}
+		literal := input[n+1 : n+end]
+		if strings.HasPrefix(literal, "v") || strings.HasPrefix(literal, "V") {
+			if !isIPvFuture(literal) {
+				return n + 1, "IPvFuture address"
+			}
+		} else if addr, err := netip.ParseAddr(literal); err != nil || !addr.Is6() {
+			return n + 1, "IPv6 address"
+		}
+		uri.Host = literal
+		n += end + 1
+	} else {
+		m, ok := scanURIPart(input[n:], isURIRegNameByte)
+		if !ok {
+			return n + m, "percent encoding in host"
+		}
+		uri.Host = strings.ToLower(decodeURIPart(input[n : n+m]))
+		n += m
+	}
+
+	// port
+	if n < len(input) && input[n] == ':' {
+		n++
+		m = countASCIIDigits(input[n:])
+		uri.Port = input[n : n+m]
+		n += m
+	}
+	if n < len(input) && strings.IndexByte("/?#", input[n]) < 0 && isURIQueryByte(input[n]) {
+		return n, "end of authority"
+	}
+	return n, ""
+}
+
+// isIPvFuture checks: "v" 1*HEXDIG "." 1*( unreserved / sub-delims / ":" )
+func isIPvFuture(literal string) bool {
+	dot := strings.IndexByte(literal, '.')
+	if dot < 2 || dot == len(literal)-1 {
+		return false
+	}
+	for i := 1; i < dot; i++ {
+		if !isASCIIHexDigit(literal[i]) {
+			return false
+		}
+	}
+	for i := dot + 1; i < len(literal); i++ {
+		if !isURIUnreserved(literal[i]) && !isURISubDelim(literal[i]) && literal[i] != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// scanURIPart returns the number of bytes that are allowed or correctly percent encoded.
+// If a percent encoding is wrong, false and the offset of the '%' are returned.
+func scanURIPart(input string, allowed func(byte) bool) (int, bool) {
+	n := 0
+	for n < len(input) {
+		switch {
+		case input[n] == '%':
+			if n+2 >= len(input) || !isASCIIHexDigit(input[n+1]) || !isASCIIHexDigit(input[n+2]) {
+				return n, false
+			}
+			n += 3
+		case allowed(input[n]):
+			n++
+		default:
+			return n, true
+		}
+	}
+	return n, true
+}
+
+// decodeURIPart decodes a part that has been checked by scanURIPart already.
+func decodeURIPart(part string) string {
+	decoded, err := url.PathUnescape(part)
+	if err != nil { // can't happen after scanURIPart
+		return part
+	}
+	return decoded
+}
+
+func isURIUnreserved(b byte) bool {
+	return isASCIIAlphanumeric(b) || strings.IndexByte("-._~", b) >= 0
+}
+
+func isURISubDelim(b byte) bool {
+	return strings.IndexByte("!$&'()*+,;=", b) >= 0
+}
+
+func isURIRegNameByte(b byte) bool {
+	return isURIUnreserved(b) || isURISubDelim(b)
+}
+
+func isURIUserInfoByte(b byte) bool {
+	return isURIRegNameByte(b) || b == ':'
+}
+
+func isURIPathByte(b byte) bool {
+	return isURIUserInfoByte(b) || b == '@' || b == '/'
+}
+
+func isURIQueryByte(b byte) bool {
+	return isURIPathByte(b) || b == '?'
+}
+
+// ============================================================================
+// Parse Query Strings
+//
+
+// QueryPair is a key and value of a query string.
+type QueryPair struct {
+	Key, Value string
+}
+
+// Query is an ordered multimap of the pairs of a query string.
+type Query []QueryPair
+
+// Get returns the first value for the key or an empty string.
+func (q Query) Get(key string) string {
+	for _, pair := range q {
+		if pair.Key == key {
+			return pair.Value
+		}
+	}
+	return ""
+}
+
+// Values returns all values for the key in order.
+func (q Query) Values(key string) []string {
+	var values []string
+	for _, pair := range q {
+		if pair.Key == key {
+			values = append(values, pair.Value)
+		}
+	}
+	return values
+}
+
+// Keys returns the distinct keys in the order of their first occurrence.
+func (q Query) Keys() []string {
+	var keys []string
+	for _, pair := range q {
+		if !slices.Contains(keys, pair.Key) {
+			keys = append(keys, pair.Key)
+		}
+	}
+	return keys
+}
+
+// QueryConfig configures the QueryString parser.
+// The zero value parses query strings like url.ParseQuery.
+type QueryConfig struct {
+	Separators string // bytes separating the pairs (default: "&")
+	KeepPlus   bool   // '+' isn't decoded to a space (it is in form encoding)
+}
+
+// QueryString parses a query string like `a=1&b=two%20words&a=3` into
+// its pairs in order (repeated keys are kept).
+// Keys and values are percent-decoded.
+// A pair without '=' has an empty value and empty pairs are ignored.
+// The query string ends with the first character that isn't allowed in the
+// query of a URI (e.g., a space or '#').
+// So it can be used standalone (e.g., for log fields) or for the
+// RawQuery of a ParsedURI.
+func QueryString(cfg QueryConfig) comb.Parser[Query] {
+	var p comb.Parser[Query]
+
+	separators := cfg.Separators
+	if separators == "" {
+		separators = "&"
+	}
+	expected := "query string"
+
+	decode := func(part string) string {
+		if !cfg.KeepPlus {
+			part = strings.ReplaceAll(part, "+", " ")
+		}
+		return decodeURIPart(part)
+	}
+
+	parse := func(state comb.State) (comb.State, Query, *comb.ParserError) {
+		input := state.CurrentString()
+		query := Query{}
+		n := 0
+		for n < len(input) {
+			if strings.IndexByte(separators, input[n]) >= 0 {
+				n++
+				continue
+			}
+			inPair := func(b byte) bool {
+				return b != '=' && strings.IndexByte(separators, b) < 0 && isURIQueryByte(b)
+			}
+			m, ok := scanURIPart(input[n:], inPair)
+			if !ok {
+				return state, query, state.MoveBy(n+m).NewSyntaxError("%s (percent encoding)", expected)
+			}
+			if m == 0 && input[n] != '=' { // end of the query string
+				break
+			}
+			pair := QueryPair{Key: decode(input[n : n+m])}
+			n += m
+			if n < len(input) && input[n] == '=' {
+				n++
+				m, ok = scanURIPart(input[n:], func(b byte) bool {
+					return strings.IndexByte(separators, b) < 0 && isURIQueryByte(b)
+				})
+				if !ok {
+					return state, query, state.MoveBy(n+m).NewSyntaxError("%s (percent encoding)", expected)
+				}
+				pair.Value = decode(input[n : n+m])
+				n += m
+			}
+			query = append(query, pair)
+		}
+		return state.MoveBy(n), query, nil
+	}
+
+	p = comb.NewParser[Query](expected, parse, nil)
+	return p
+}
+
+// Query parses the RawQuery of the URI with QueryString.
+func (uri ParsedURI) Query(cfg QueryConfig) (Query, error) {
+	return comb.RunOnString(uri.RawQuery, Suffixed(QueryString(cfg), EOF()))
+}
+
+// ============================================================================
+// Parse Encoded Data
+//
+
+// Base64 parses base64 encoded data with the given alphabet and returns the decoded bytes.
+// The alphabet has to consist of 64 unique bytes and '=' is used for padding.
+// In strict mode, padding is required, no line breaks or spaces are allowed and
+// unused bits have to be zero.
+// In lenient mode, padding is optional and spaces, tabs and line breaks are ignored
+// between groups of 4 bytes if another complete group follows them (e.g., for MIME bodies).
+// So the data doesn't swallow the start of the next token.
+// Empty input is valid base64 and results in empty output.
+func Base64(alphabet string, strict bool) comb.Parser[[]byte] {
+	var p comb.Parser[[]byte]
+
+	if len(alphabet) != 64 {
+		panic("Base64: alphabet has to be 64 bytes long")
+	}
+	enc := base64.NewEncoding(alphabet)
+	if strict {
+		enc = enc.Strict()
+	} else {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	expected := "base64 data"
+
+	parse := func(state comb.State) (comb.State, []byte, *comb.ParserError) {
+		input := state.CurrentString()
+		data := make([]byte, 0, len(input))
+		n := 0 // number of bytes read from input
+		padding := 0
+	ForLoop:
+		for ; n < len(input); n++ {
+			b := input[n]
+			switch {
+			case !strict && isBase64Space(b) && padding == 0 && len(data)%4 == 0:
+				m := n + 1
+				for m < len(input) && isBase64Space(input[m]) {
+					m++
+				}
+				if !isBase64Group(input[m:], alphabet) { // the spaces don't belong to us
+					break ForLoop
+				}
+				n = m - 1
+				continue
+			case b == '=' && padding < 2:
+				padding++
+			case padding == 0 && strings.IndexByte(alphabet, b) >= 0:
+			default:
+				break ForLoop // don't break switch but for
+			}
+			data = append(data, b)
+		}
+
+		if !strict {
+			data = data[:len(data)-padding]
+		}
+		out := make([]byte, enc.DecodedLen(len(data)))
+		m, err := enc.Decode(out, data)
+		if err != nil {
+			return state, []byte{}, state.NewSyntaxError("%s (%w)", expected, err)
+		}
+		return state.MoveBy(n), out[:m], nil
+	}
+
+	p = comb.NewParser[[]byte](expected, parse, Forbidden())
+	return p
+}
+
+// Base64URL parses base64 data encoded with the URL and filename safe
+// alphabet of RFC 4648 (see Base64).
+func Base64URL(strict bool) comb.Parser[[]byte] {
+	return Base64(base64URLAlphabet, strict)
+}
+
+const base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// isBase64Group checks if the input starts with a complete group of 4 bytes
+// (possibly padded).
+func isBase64Group(input, alphabet string) bool {
+	if len(input) < 4 {
+		return false
+	}
+	for i := 0; i < 4; i++ {
+		switch {
+		case strings.IndexByte(alphabet, input[i]) >= 0:
+			if i == 3 && input[2] == '=' {
+				return false
+			}
+		case input[i] != '=' || i < 2:
+			return false
+		}
+	}
+	return true
+}
+
+func isBase64Space(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// QuotedPrintable parses quoted-printable encoded text as specified
+// by RFC 2045 and returns the decoded bytes.
+// It consumes printable ASCII characters, spaces, tabs, line breaks and
+// escape sequences (`=XX`) and it removes soft line breaks (`=` at the end of a line).
+// Trailing spaces and tabs at the end of a line are removed, too.
+// In strict mode, only CRLF line breaks and upper case hex digits are valid and
+// an invalid escape sequence is an error.
+// In lenient mode, LF line breaks and lower case hex digits are valid, too, and
+// an invalid escape sequence is used literally.
+func QuotedPrintable(strict bool) comb.Parser[[]byte] {
+	var p comb.Parser[[]byte]
+
+	expected := "quoted-printable text"
+
+	parse := func(state comb.State) (comb.State, []byte, *comb.ParserError) {
+		input := state.CurrentString()
+		out := make([]byte, 0, len(input))
+		spaceStart := -1 // start of trailing literal spaces and tabs in out
+		n := 0           // number of bytes read from input
+		for n < len(input) {
+			b := input[n]
+			switch {
+			case b == '=':
+				if m := qpLineBreak(input[n+1:], strict); m > 0 || (!strict && n+1 == len(input)) { // soft line break
+					n += 1 + m
+					continue
+				}
+				spaceStart = -1
+				if n+2 < len(input) && isQPHexDigit(input[n+1], strict) && isQPHexDigit(input[n+2], strict) {
+					v, _ := strconv.ParseUint(input[n+1:n+3], 16, 8)
+					out = append(out, byte(v))
+					n += 3
+					continue
+				}
+				if strict {
+					return state, []byte{}, state.MoveBy(n).NewSyntaxError("%s (escape sequence)", expected)
+				}
+				out = append(out, b)
+				n++
+			case b == ' ' || b == '\t':
+				if spaceStart < 0 {
+					spaceStart = len(out)
+				}
+				out = append(out, b)
+				n++
+			case b > ' ' && b <= '~':
+				spaceStart = -1
+				out = append(out, b)
+				n++
+			default:
+				if spaceStart >= 0 { // trailing spaces and tabs at the end of a line are removed
+					out = out[:spaceStart]
+					spaceStart = -1
+				}
+				m := qpLineBreak(input[n:], strict)
+				if m == 0 {
+					return state.MoveBy(n), out, nil
+				}
+				out = append(out, input[n:n+m]...)
+				n += m
+			}
+		}
+		if spaceStart >= 0 {
+			out = out[:spaceStart]
+		}
+		return state.MoveBy(n), out, nil
+	}
+
+	p = comb.NewParser[[]byte](expected, parse, Forbidden())
+	return p
+}
+
+// qpLineBreak returns the length of the line break at the start of the input or 0.
+func qpLineBreak(input string, strict bool) int {
+	switch {
+	case strings.HasPrefix(input, "\r\n"):
+		return 2
+	case !strict && strings.HasPrefix(input, "\n"):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isQPHexDigit(b byte, strict bool) bool {
+	if strict {
+		return (b >= '0' && b <= '9') || (b >= 'A' && b <= 'F')
+	}
+	return isASCIIHexDigit(b)
+}
+
+// ============================================================================
+// Helpers
+//
+
+func isASCIIAlpha(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func isASCIIDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isASCIIAlphanumeric(b byte) bool {
+	return isASCIIAlpha(b) || isASCIIDigit(b)
+}
+
+func isASCIIHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
+
+func countASCIIDigits(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return i
+		}
+	}
+	return len(s)
+}
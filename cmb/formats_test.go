@@ -0,0 +1,474 @@
+package cmb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSemVer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    cmb.SemVersion
+		wantRemaining string
+	}{
+		{
+			name:          "parsing simple version should succeed",
+			input:         "1.22.3 rest",
+			wantOutput:    cmb.SemVersion{Major: 1, Minor: 22, Patch: 3},
+			wantRemaining: " rest",
+		}, {
+			name:  "parsing full version should succeed",
+			input: "1.0.0-alpha.1.x-y+build.007",
+			wantOutput: cmb.SemVersion{
+				Major: 1, Pre: []string{"alpha", "1", "x-y"}, Build: []string{"build", "007"},
+			},
+			wantRemaining: "",
+		}, {
+			name:          "parsing version with build only should succeed",
+			input:         "0.0.1+20130313144700",
+			wantOutput:    cmb.SemVersion{Patch: 1, Build: []string{"20130313144700"}},
+			wantRemaining: "",
+		}, {
+			name:          "parsing version with missing patch should fail",
+			input:         "1.2",
+			wantErr:       true,
+			wantRemaining: "1.2",
+		}, {
+			name:          "parsing version with leading zero should fail",
+			input:         "1.02.3",
+			wantErr:       true,
+			wantRemaining: "1.02.3",
+		}, {
+			name:          "parsing pre-release with leading zero should fail",
+			input:         "1.2.3-01",
+			wantErr:       true,
+			wantRemaining: "1.2.3-01",
+		}, {
+			name:          "parsing empty pre-release identifier should fail",
+			input:         "1.2.3-rc..1",
+			wantErr:       true,
+			wantRemaining: "1.2.3-rc..1",
+		}, {
+			name:          "parsing too big version should fail",
+			input:         "18446744073709551616.0.0",
+			wantErr:       true,
+			wantRemaining: "18446744073709551616.0.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := cmb.SemVer().Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantOutput, gotResult)
+			if !tc.wantErr {
+				assert.Equal(t, tc.input[:len(tc.input)-len(tc.wantRemaining)], gotResult.String())
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing standard library path should succeed",
+			input:         "fmt",
+			wantOutput:    "fmt",
+			wantRemaining: "",
+		}, {
+			name:          "parsing module path with version should succeed",
+			input:         "github.com/flowdev/comb/v2@v2.0.1",
+			wantOutput:    "github.com/flowdev/comb/v2",
+			wantRemaining: "@v2.0.1",
+		}, {
+			name:          "parsing path with trailing slash should succeed",
+			input:         "golang.org/x/mod_~-/",
+			wantOutput:    "golang.org/x/mod_~-",
+			wantRemaining: "/",
+		}, {
+			name:          "parsing path with leading slash should fail",
+			input:         "/abc",
+			wantErr:       true,
+			wantRemaining: "/abc",
+		}, {
+			name:          "parsing path with dot element should fail",
+			input:         "example.com/../abc",
+			wantErr:       true,
+			wantRemaining: "example.com/../abc",
+		}, {
+			name:          "parsing path with element ending in a dot should fail",
+			input:         "example.",
+			wantErr:       true,
+			wantRemaining: "example.",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := cmb.ModulePath().Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestURI(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantErrMsg    string
+		wantOutput    cmb.ParsedURI
+		wantRemaining string
+	}{
+		{
+			name:  "parsing full URI should succeed",
+			input: "HTTPS://us%20er:pw@Example.COM:8080/a%2Fb/c?x=1&y=%20#frag%21 rest",
+			wantOutput: cmb.ParsedURI{
+				Scheme: "https", HasAuthority: true, UserInfo: "us er:pw", Host: "example.com", Port: "8080",
+				Path: "/a/b/c", RawQuery: "x=1&y=%20", Fragment: "frag!",
+			},
+			wantRemaining: " rest",
+		}, {
+			name:  "parsing IPv6 literal should succeed",
+			input: "http://[2001:db8::7]/c=GB?objectClass?one>",
+			wantOutput: cmb.ParsedURI{
+				Scheme: "http", HasAuthority: true, Host: "2001:db8::7", Path: "/c=GB", RawQuery: "objectClass?one",
+			},
+			wantRemaining: ">",
+		}, {
+			name:  "parsing IPvFuture literal should succeed",
+			input: "http://[v1.fe:x]:80",
+			wantOutput: cmb.ParsedURI{
+				Scheme: "http", HasAuthority: true, Host: "v1.fe:x", Port: "80",
+			},
+		}, {
+			name:          "parsing URI without authority should succeed",
+			input:         "mailto:John.Doe@example.com\"",
+			wantOutput:    cmb.ParsedURI{Scheme: "mailto", Path: "John.Doe@example.com"},
+			wantRemaining: "\"",
+		}, {
+			name:          "parsing URI with empty authority should succeed",
+			input:         "file:///etc/hosts",
+			wantOutput:    cmb.ParsedURI{Scheme: "file", HasAuthority: true, Path: "/etc/hosts"},
+			wantRemaining: "",
+		}, {
+			name:          "parsing URI without scheme should fail",
+			input:         "//example.com",
+			wantErr:       true,
+			wantRemaining: "//example.com",
+		}, {
+			name:          "parsing invalid IPv6 literal should fail",
+			input:         "http://[1::2::3]/",
+			wantErr:       true,
+			wantRemaining: "http://[1::2::3]/",
+		}, {
+			name:          "parsing invalid port should fail",
+			input:         "http://host:8a/",
+			wantErr:       true,
+			wantRemaining: "http://host:8a/",
+		}, {
+			name:          "parsing invalid percent encoding should fail",
+			input:         "http://host/a%2",
+			wantErr:       true,
+			wantRemaining: "http://host/a%2",
+		}, {
+			name:          "parsing invalid percent encoding in host should fail",
+			input:         "http://ho%zzst/",
+			wantErr:       true,
+			wantErrMsg:    "expected URI (percent encoding in host) [1:10]",
+			wantRemaining: "http://ho%zzst/",
+		}, {
+			name:          "parsing invalid percent encoding in user information should fail",
+			input:         "http://us%zzer@host/",
+			wantErr:       true,
+			wantErrMsg:    "expected URI (percent encoding in user information) [1:10]",
+			wantRemaining: "http://us%zzer@host/",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := cmb.URI().Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if tc.wantErrMsg != "" {
+				assert.ErrorContains(t, gotErr, tc.wantErrMsg)
+			}
+			assert.Equal(t, tc.wantOutput, gotResult)
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestQueryString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		cfg           cmb.QueryConfig
+		input         string
+		wantErr       bool
+		wantOutput    cmb.Query
+		wantRemaining string
+	}{
+		{
+			name:  "parsing query with repeated keys should succeed",
+			input: "a=1&b=two%20words&a=3+x#frag",
+			wantOutput: cmb.Query{
+				{Key: "a", Value: "1"}, {Key: "b", Value: "two words"}, {Key: "a", Value: "3 x"},
+			},
+			wantRemaining: "#frag",
+		}, {
+			name:          "parsing keys without values and empty pairs should succeed",
+			input:         "flag&&x=&=y end",
+			wantOutput:    cmb.Query{{Key: "flag"}, {Key: "x"}, {Value: "y"}},
+			wantRemaining: " end",
+		}, {
+			name:          "parsing with other separators and plus should succeed",
+			cfg:           cmb.QueryConfig{Separators: ";&", KeepPlus: true},
+			input:         "a=1+1;b=%2B&c=3",
+			wantOutput:    cmb.Query{{Key: "a", Value: "1+1"}, {Key: "b", Value: "+"}, {Key: "c", Value: "3"}},
+			wantRemaining: "",
+		}, {
+			name:          "parsing empty query should succeed",
+			input:         " ",
+			wantOutput:    cmb.Query{},
+			wantRemaining: " ",
+		}, {
+			name:          "parsing invalid percent encoding should fail",
+			input:         "a=1&b=%zz",
+			wantErr:       true,
+			wantOutput:    cmb.Query{{Key: "a", Value: "1"}},
+			wantRemaining: "a=1&b=%zz",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := cmb.QueryString(tc.cfg).Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantOutput, gotResult)
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestParsedURIQuery(t *testing.T) {
+	t.Parallel()
+
+	_, uri, err := cmb.URI().Parse(comb.NewFromString("https://example.com/?tag=a&tag=b&q=x%26y", 10))
+	assert.Nil(t, err)
+	query, qErr := uri.Query(cmb.QueryConfig{})
+	assert.NoError(t, qErr)
+	assert.Equal(t, []string{"a", "b"}, query.Values("tag"))
+	assert.Equal(t, "x&y", query.Get("q"))
+	assert.Equal(t, []string{"tag", "q"}, query.Keys())
+}
+
+func TestBase64(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        comb.Parser[[]byte]
+		input         string
+		wantErr       bool
+		wantOutput    []byte
+		wantRemaining string
+	}{
+		{
+			name:          "parsing strict base64 should succeed",
+			parser:        cmb.Base64(stdBase64Alphabet, true),
+			input:         "aGVsbG8=\"",
+			wantOutput:    []byte("hello"),
+			wantRemaining: "\"",
+		}, {
+			name:          "parsing empty base64 should succeed",
+			parser:        cmb.Base64(stdBase64Alphabet, true),
+			input:         "",
+			wantOutput:    []byte{},
+			wantRemaining: "",
+		}, {
+			name:          "parsing strict base64 without padding should fail",
+			parser:        cmb.Base64(stdBase64Alphabet, true),
+			input:         "aGVsbG8",
+			wantErr:       true,
+			wantOutput:    []byte{},
+			wantRemaining: "aGVsbG8",
+		}, {
+			name:          "parsing strict base64 with line break should stop",
+			parser:        cmb.Base64(stdBase64Alphabet, true),
+			input:         "aGVs\r\nbG8=",
+			wantOutput:    []byte("hel"),
+			wantRemaining: "\r\nbG8=",
+		}, {
+			name:          "parsing lenient base64 with line breaks should succeed",
+			parser:        cmb.Base64(stdBase64Alphabet, false),
+			input:         "aGVs\r\nbG8= ;",
+			wantOutput:    []byte("hello"),
+			wantRemaining: " ;",
+		}, {
+			name:          "parsing lenient base64 should stop before the next token",
+			parser:        cmb.Base64(stdBase64Alphabet, false),
+			input:         "QUJD\nfoo",
+			wantOutput:    []byte("ABC"),
+			wantRemaining: "\nfoo",
+		}, {
+			name:          "parsing lenient base64 should only skip spaces before complete groups",
+			parser:        cmb.Base64(stdBase64Alphabet, false),
+			input:         "aGVs bG8",
+			wantOutput:    []byte("hel"),
+			wantRemaining: " bG8",
+		}, {
+			name:          "parsing URL base64 should succeed",
+			parser:        cmb.Base64URL(false),
+			input:         "-_-_+",
+			wantOutput:    []byte{0xfb, 0xff, 0xbf},
+			wantRemaining: "+",
+		}, {
+			name:          "parsing base64 of wrong length should fail",
+			parser:        cmb.Base64URL(false),
+			input:         "abcde",
+			wantErr:       true,
+			wantOutput:    []byte{},
+			wantRemaining: "abcde",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := tc.parser.Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantOutput, gotResult)
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
+
+const stdBase64Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+func TestQuotedPrintable(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		strict        bool
+		input         string
+		wantErr       bool
+		wantOutput    string
+		wantRemaining string
+	}{
+		{
+			name:          "parsing strict text should succeed",
+			strict:        true,
+			input:         "caf=C3=A9 =3D soft=\r\nbreak  \r\nnext=20\r\n\x00",
+			wantOutput:    "café = softbreak\r\nnext \r\n",
+			wantRemaining: "\x00",
+		}, {
+			name:          "parsing strict text with bad escape should fail",
+			strict:        true,
+			input:         "a=c3",
+			wantErr:       true,
+			wantOutput:    "",
+			wantRemaining: "a=c3",
+		}, {
+			name:          "parsing strict text should stop at LF",
+			strict:        true,
+			input:         "a\nb",
+			wantOutput:    "a",
+			wantRemaining: "\nb",
+		}, {
+			name:          "parsing lenient text should succeed",
+			strict:        false,
+			input:         "caf=c3=a9 a=zz soft=\nbreak\t\nend=",
+			wantOutput:    "café a=zz softbreak\nend",
+			wantRemaining: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := cmb.QuotedPrintable(tc.strict).Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if string(gotResult) != tc.wantOutput {
+				t.Errorf("got output %q, want output %q", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
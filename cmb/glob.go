@@ -0,0 +1,318 @@
+package cmb
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Glob Patterns
+//
+
+// GlobPartKind is the kind of a part of a glob pattern.
+type GlobPartKind int
+
+const (
+	GlobLiteral GlobPartKind = iota // literal text
+	GlobStar                        // '*' matches any sequence of characters (including none)
+	GlobAny                         // '?' matches any single character
+	GlobClass                       // '[...]' matches a single character of a class
+)
+
+// RuneRange is an inclusive range of runes.
+type RuneRange struct {
+	Lo, Hi rune
+}
+
+// GlobPart is a part of a compiled glob pattern.
+type GlobPart struct {
+	Kind    GlobPartKind
+	Literal string      // text of a GlobLiteral
+	Ranges  []RuneRange // ranges of a GlobClass
+	Negated bool        // the GlobClass starts with '!' or '^'
+}
+
+// GlobPattern is a compiled glob pattern.
+type GlobPattern struct {
+	Raw   string // the pattern as found in the input
+	Parts []GlobPart
+}
+
+// Glob parses a glob pattern up to the next white space (or the end of the input)
+// and compiles it.
+// The pattern can contain `*`, `?` and character classes like `[a-z_]` or `[!0-9]`.
+// A `]` directly after the opening `[` (or `[!`) is part of the class.
+// A backslash escapes the next character.
+// Errors in the pattern are reported at their exact position.
+func Glob() comb.Parser[GlobPattern] {
+	var p comb.Parser[GlobPattern]
+
+	expected := "glob pattern"
+
+	parse := func(state comb.State) (comb.State, GlobPattern, *comb.ParserError) {
+		input := state.CurrentString()
+		end := indexFunc(state, input, unicode.IsSpace)
+		if end < 0 {
+			end = len(input)
+		}
+		if end == 0 {
+			if input == "" {
+				return state, GlobPattern{}, state.NewSyntaxError("%s (at EOF)", expected)
+			}
+			r, _ := state.DecodeRune(input)
+			return state, GlobPattern{}, state.NewSyntaxError("%s (got %q)", expected, r)
+		}
+
+		pattern := GlobPattern{Raw: input[:end]}
+		literal := strings.Builder{}
+		addPart := func(part GlobPart) {
+			if literal.Len() > 0 {
+				pattern.Parts = append(pattern.Parts, GlobPart{Kind: GlobLiteral, Literal: literal.String()})
+				literal.Reset()
+			}
+			pattern.Parts = append(pattern.Parts, part)
+		}
+		for i := 0; i < end; {
+			r, size := state.DecodeRune(input[i:end])
+			switch r {
+			case '*':
+				if n := len(pattern.Parts); literal.Len() > 0 || n == 0 || pattern.Parts[n-1].Kind != GlobStar {
+					addPart(GlobPart{Kind: GlobStar})
+				}
+			case '?':
+				addPart(GlobPart{Kind: GlobAny})
+			case '[':
+				part, n, err := parseGlobClass(state.MoveBy(i), input[i:end])
+				if err != nil {
+					return state, GlobPattern{}, err
+				}
+				addPart(part)
+				size = n
+			case '\\':
+				if i+size >= end {
+					return state, GlobPattern{}, state.MoveBy(i).NewSyntaxError("escaped character after '\\'")
+				}
+				r, n := state.DecodeRune(input[i+size : end])
+				literal.WriteRune(r)
+				size += n
+			default:
+				literal.WriteRune(r)
+			}
+			i += size
+		}
+		if literal.Len() > 0 {
+			pattern.Parts = append(pattern.Parts, GlobPart{Kind: GlobLiteral, Literal: literal.String()})
+		}
+		return state.MoveBy(end), pattern, nil
+	}
+
+	p = comb.NewParser[GlobPattern](expected, parse, satisfyMNRecoverer(1, func(r rune) bool {
+		return !unicode.IsSpace(r)
+	}))
+	return p
+}
+
+// parseGlobClass parses a character class starting with '[' at the start of the input.
+// It returns the class and the number of bytes consumed.
+func parseGlobClass(state comb.State, input string) (GlobPart, int, *comb.ParserError) {
+	part := GlobPart{Kind: GlobClass}
+	i := 1
+	if i < len(input) && (input[i] == '!' || input[i] == '^') {
+		part.Negated = true
+		i++
+	}
+	first := true
+	for {
+		if i >= len(input) {
+			return part, 0, state.NewSyntaxError("closing ']' of character class")
+		}
+		if input[i] == ']' && !first {
+			break
+		}
+		first = false
+		start := i
+		lo, n, err := globClassRune(state.MoveBy(i), input[i:])
+		if err != nil {
+			return part, 0, err
+		}
+		i += n
+		hi := lo
+		if i+1 < len(input) && input[i] == '-' && input[i+1] != ']' {
+			i++
+			hi, n, err = globClassRune(state.MoveBy(i), input[i:])
+			if err != nil {
+				return part, 0, err
+			}
+			i += n
+			if hi < lo {
+				return part, 0, state.MoveBy(start).NewSyntaxError("valid character range (got %q)", input[start:i])
+			}
+		}
+		part.Ranges = append(part.Ranges, RuneRange{Lo: lo, Hi: hi})
+	}
+	return part, i + 1, nil
+}
+
+func globClassRune(state comb.State, input string) (rune, int, *comb.ParserError) {
+	r, n := state.DecodeRune(input)
+	if r != '\\' {
+		return r, n, nil
+	}
+	if n >= len(input) {
+		return r, 0, state.NewSyntaxError("escaped character after '\\'")
+	}
+	r, m := state.DecodeRune(input[n:])
+	return r, n + m, nil
+}
+
+// Match reports whether the whole name matches the pattern.
+func (g GlobPattern) Match(name string) bool {
+	return matchGlobParts(g.Parts, name)
+}
+
+// matchGlobParts matches with two pointers (one into the parts and one into the name).
+// Only the last star is backtracked, so matching takes at most
+// O(len(parts) * len(name)) steps even for patterns like `*a*a*a*b`.
+func matchGlobParts(parts []GlobPart, name string) bool {
+	p, n := 0, 0
+	star, starN := -1, 0 // index of the last star and the position in the name it continues at
+	for p < len(parts) || n < len(name) {
+		if p < len(parts) {
+			part := parts[p]
+			switch part.Kind {
+			case GlobStar:
+				star, starN = p, n
+				p++
+				continue
+			case GlobLiteral:
+				if strings.HasPrefix(name[n:], part.Literal) {
+					p++
+					n += len(part.Literal)
+					continue
+				}
+			default: // GlobAny and GlobClass
+				r, size := utf8.DecodeRuneInString(name[n:])
+				if size > 0 && (part.Kind == GlobAny || part.matchClass(r)) {
+					p++
+					n += size
+					continue
+				}
+			}
+		}
+		if star < 0 || starN >= len(name) {
+			return false
+		}
+		_, size := utf8.DecodeRuneInString(name[starN:])
+		starN += size // the last star consumes one more rune
+		p, n = star+1, starN
+	}
+	return true
+}
+
+func (part GlobPart) matchClass(r rune) bool {
+	for _, rr := range part.Ranges {
+		if rr.Lo <= r && r <= rr.Hi {
+			return !part.Negated
+		}
+	}
+	return part.Negated
+}
+
+// ============================================================================
+// Domain Patterns
+//
+
+// HostPattern is a host name that may start with a wildcard label
+// (e.g. `*.example.com`).
+type HostPattern struct {
+	Raw      string   // the pattern as found in the input
+	Wildcard bool     // the pattern starts with the label `*`
+	Labels   []string // the labels without the wildcard (e.g. ["example", "com"])
+}
+
+// DomainPattern parses a host name with an optional wildcard as first label.
+// Labels consist of ASCII letters, digits and hyphens that can't start or end a label.
+// A label has to be 1 to 63 characters long and the whole pattern can't be
+// longer than 253 characters.
+// Errors are reported at the position of the offending label.
+func DomainPattern() comb.Parser[HostPattern] {
+	var p comb.Parser[HostPattern]
+
+	expected := "domain pattern"
+
+	parse := func(state comb.State) (comb.State, HostPattern, *comb.ParserError) {
+		input := state.CurrentString()
+		end := 0
+		for end < len(input) && (isLabelChar(input[end]) || input[end] == '.' || input[end] == '*') {
+			end++
+		}
+		if end == 0 {
+			if input == "" {
+				return state, HostPattern{}, state.NewSyntaxError("%s (at EOF)", expected)
+			}
+			r, _ := state.DecodeRune(input)
+			return state, HostPattern{}, state.NewSyntaxError("%s (got %q)", expected, r)
+		}
+		if end > 253 {
+			return state, HostPattern{}, state.NewSyntaxError("%s of at most 253 characters (got %d)", expected, end)
+		}
+
+		dp := HostPattern{Raw: input[:end]}
+		pos := 0
+		for i, label := range strings.Split(input[:end], ".") {
+			lState := state.MoveBy(pos)
+			pos += len(label) + 1
+			switch {
+			case label == "*" && i == 0:
+				dp.Wildcard = true
+				continue
+			case strings.Contains(label, "*"):
+				return state, HostPattern{}, lState.NewSyntaxError("wildcard only as the whole first label (got %q)", label)
+			case label == "":
+				return state, HostPattern{}, lState.NewSyntaxError("domain label (got empty label)")
+			case len(label) > 63:
+				return state, HostPattern{}, lState.NewSyntaxError("domain label of at most 63 characters (got %d)", len(label))
+			case label[0] == '-' || label[len(label)-1] == '-':
+				return state, HostPattern{}, lState.NewSyntaxError("domain label without leading or trailing '-' (got %q)", label)
+			}
+			dp.Labels = append(dp.Labels, label)
+		}
+		if len(dp.Labels) == 0 {
+			return state, HostPattern{}, state.NewSyntaxError("%s with at least one label after the wildcard", expected)
+		}
+		return state.MoveBy(end), dp, nil
+	}
+
+	p = comb.NewParser[HostPattern](expected, parse, satisfyMNRecoverer(1, func(r rune) bool {
+		return r < utf8.RuneSelf && (isLabelChar(byte(r)) || r == '*')
+	}))
+	return p
+}
+
+func isLabelChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-'
+}
+
+// Match reports whether the host matches the pattern.
+// The comparison is case-insensitive and a wildcard matches exactly one label.
+func (dp HostPattern) Match(host string) bool {
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if dp.Wildcard {
+		if len(labels) != len(dp.Labels)+1 || labels[0] == "" {
+			return false
+		}
+		labels = labels[1:]
+	}
+	if len(labels) != len(dp.Labels) {
+		return false
+	}
+	for i, label := range labels {
+		if !strings.EqualFold(label, dp.Labels[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package cmb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlob(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       string
+		wantRemaining string
+		wantMatch     []string
+		wantNoMatch   []string
+	}{
+		{
+			name:          "stars and question marks should succeed",
+			input:         "*.g?  rest",
+			wantRemaining: "  rest",
+			wantMatch:     []string{"main.go", ".go", "a.b.gz"},
+			wantNoMatch:   []string{"main.golang", "main.g", "main_go"},
+		}, {
+			name:        "character classes should succeed",
+			input:       "file[0-9][!a-c]",
+			wantMatch:   []string{"file1d", "file9-"},
+			wantNoMatch: []string{"filex1", "file1a", "file1"},
+		}, {
+			name:        "closing bracket first in a class should be literal",
+			input:       "[]a]",
+			wantMatch:   []string{"]", "a"},
+			wantNoMatch: []string{"b"},
+		}, {
+			name:        "escaped characters should be literal",
+			input:       `a\*b`,
+			wantMatch:   []string{"a*b"},
+			wantNoMatch: []string{"axb"},
+		}, {
+			name:        "many stars should match without exponential backtracking",
+			input:       "*a*a*a*a*a*a*a*a*b",
+			wantMatch:   []string{strings.Repeat("a", 50) + "b", "xaxaxaaaaaaab"},
+			wantNoMatch: []string{strings.Repeat("a", 100), "aaaaaaab"},
+		}, {
+			name:        "stars should match empty and multi-byte names",
+			input:       "ä*ö",
+			wantMatch:   []string{"äö", "äxyzö", "äöö"},
+			wantNoMatch: []string{"ä", "öä"},
+		}, {
+			name:          "unclosed class should fail at the bracket",
+			input:         "ab[cd",
+			wantErr:       "closing ']' of character class [1:3]",
+			wantRemaining: "ab[cd",
+		}, {
+			name:          "invalid range should fail at the range",
+			input:         "[az-a]",
+			wantErr:       `valid character range (got "z-a") [1:3]`,
+			wantRemaining: "[az-a]",
+		}, {
+			name:          "empty pattern should fail",
+			input:         " x",
+			wantErr:       "glob pattern",
+			wantRemaining: " x",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotOutput, err := cmb.Glob().Parse(comb.NewFromString(tc.input, 0))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tc.wantRemaining, newState.CurrentString())
+			for _, name := range tc.wantMatch {
+				assert.True(t, gotOutput.Match(name), "should match %q", name)
+			}
+			for _, name := range tc.wantNoMatch {
+				assert.False(t, gotOutput.Match(name), "should not match %q", name)
+			}
+		})
+	}
+}
+
+func TestDomainPattern(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		input       string
+		wantErr     string
+		wantOutput  cmb.HostPattern
+		wantMatch   []string
+		wantNoMatch []string
+	}{
+		{
+			name:        "plain host name should succeed",
+			input:       "Example.com;",
+			wantOutput:  cmb.HostPattern{Raw: "Example.com", Labels: []string{"Example", "com"}},
+			wantMatch:   []string{"example.COM", "example.com."},
+			wantNoMatch: []string{"www.example.com", "example.org"},
+		}, {
+			name:        "wildcard should match exactly one label",
+			input:       "*.example.com",
+			wantOutput:  cmb.HostPattern{Raw: "*.example.com", Wildcard: true, Labels: []string{"example", "com"}},
+			wantMatch:   []string{"www.example.com"},
+			wantNoMatch: []string{"example.com", "a.b.example.com", ".example.com"},
+		}, {
+			name:    "wildcard in the middle should fail at the label",
+			input:   "www.*.com",
+			wantErr: `wildcard only as the whole first label (got "*") [1:5]`,
+		}, {
+			name:    "label with trailing hyphen should fail at the label",
+			input:   "a.b-.c",
+			wantErr: `domain label without leading or trailing '-' (got "b-") [1:3]`,
+		}, {
+			name:    "empty label should fail",
+			input:   "a..c",
+			wantErr: "domain label (got empty label) [1:3]",
+		}, {
+			name:    "wildcard alone should fail",
+			input:   "*",
+			wantErr: "domain pattern with at least one label after the wildcard",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, gotOutput, err := cmb.DomainPattern().Parse(comb.NewFromString(tc.input, 0))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.wantOutput, gotOutput)
+			for _, host := range tc.wantMatch {
+				assert.True(t, gotOutput.Match(host), "should match %q", host)
+			}
+			for _, host := range tc.wantNoMatch {
+				assert.False(t, gotOutput.Match(host), "should not match %q", host)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package cmb
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flowdev/comb"
+)
+
+// IdentifierProfile configures the Identifier parser.
+type IdentifierProfile struct {
+	ExtraStart    string // additional characters allowed at the start (e.g. "_$")
+	ExtraContinue string // additional characters allowed after the start (e.g. "$")
+
+	// WarnMixedScripts emits a warning (see comb.State.Warn) for identifiers
+	// that mix scripts. Only the combinations of the UTS #39 restriction level
+	// 'highly restrictive' are allowed (e.g. Latin with Han and Hiragana).
+	WarnMixedScripts bool
+	// WarnConfusables emits a warning (see comb.State.Warn) for identifiers
+	// that use non-Latin characters but look like a Latin identifier
+	// (e.g. a Cyrillic 'а' instead of the Latin 'a').
+	WarnConfusables bool
+}
+
+// Identifier parses an identifier as defined by UAX #31 (Unicode identifiers):
+// a character with the property XID_Start followed by any number of characters
+// with the property XID_Continue.
+// The profile can allow additional characters and turn on warnings for
+// suspicious identifiers following UTS #39 (Unicode security mechanisms).
+// Warnings don't fail the parser.
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+func Identifier(profile IdentifierProfile) comb.Parser[string] {
+	var p comb.Parser[string]
+
+	expected := "identifier"
+
+	isStart := func(r rune) bool {
+		return isXIDStart(r) || strings.ContainsRune(profile.ExtraStart, r)
+	}
+	isContinue := func(r rune) bool {
+		return isXIDContinue(r) || strings.ContainsRune(profile.ExtraStart, r) ||
+			strings.ContainsRune(profile.ExtraContinue, r)
+	}
+
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		r, size := state.DecodeRune(input)
+		if r == utf8.RuneError {
+			if size == 0 {
+				return state, "", state.NewSyntaxError("%s (at EOF)", expected)
+			}
+			return state, "", state.NewSyntaxError("%s (got UTF-8 error)", expected)
+		}
+		if !isStart(r) {
+			return state, "", state.NewSyntaxError("%s (got %q)", expected, r)
+		}
+		end := size
+		for end < len(input) {
+			r, size = state.DecodeRune(input[end:])
+			if r == utf8.RuneError || !isContinue(r) {
+				break
+			}
+			end += size
+		}
+
+		ident := input[:end]
+		if profile.WarnMixedScripts {
+			if scripts := scriptsOf(ident); !allowedScriptMix(scripts) {
+				state = state.Warn("identifier %q mixes the scripts %s", ident, strings.Join(scripts, ", "))
+			}
+		}
+		if profile.WarnConfusables {
+			if latin, ok := latinSkeleton(ident); ok && latin != ident {
+				state = state.Warn("identifier %q is confusable with %q", ident, latin)
+			}
+		}
+		return state.MoveBy(end), ident, nil
+	}
+
+	p = comb.NewParser[string](expected, parse, satisfyMNRecoverer(1, isStart))
+	return p
+}
+
+// isXIDStart approximates the Unicode property XID_Start with the tables of
+// the unicode package.
+func isXIDStart(r rune) bool {
+	if r < utf8.RuneSelf {
+		return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+	}
+	return (unicode.IsLetter(r) || unicode.Is(unicode.Nl, r) || unicode.Is(unicode.Other_ID_Start, r)) &&
+		!unicode.Is(unicode.Pattern_Syntax, r) && !unicode.Is(unicode.Pattern_White_Space, r)
+}
+
+// isXIDContinue approximates the Unicode property XID_Continue with the tables of
+// the unicode package.
+func isXIDContinue(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isXIDStart(r) || '0' <= r && r <= '9' || r == '_'
+	}
+	return isXIDStart(r) ||
+		(unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc, unicode.Other_ID_Continue) &&
+			!unicode.Is(unicode.Pattern_Syntax, r) && !unicode.Is(unicode.Pattern_White_Space, r))
+}
+
+// scriptsOf returns the sorted names of the scripts used in the identifier.
+// The scripts Common and Inherited are ignored because they are used
+// together with all other scripts.
+func scriptsOf(ident string) []string {
+	var scripts []string
+	var last *unicode.RangeTable // script of the previous rune (runes of a script are usually adjacent)
+	for _, r := range ident {
+		if r < utf8.RuneSelf {
+			if unicode.IsLetter(r) && !slices.Contains(scripts, "Latin") {
+				scripts = append(scripts, "Latin")
+			}
+			continue
+		}
+		if last != nil && unicode.Is(last, r) { // the script is known already
+			continue
+		}
+		var name string
+		name, last = scriptOf(r)
+		if name != "" && name != "Common" && name != "Inherited" && !slices.Contains(scripts, name) {
+			scripts = append(scripts, name)
+		}
+	}
+	slices.Sort(scripts)
+	return scripts
+}
+
+// scriptOf returns the name and the table of the script of the rune.
+// The scripts of the allowed mixes are checked first,
+// so only runes of other scripts have to search all scripts.
+func scriptOf(r rune) (string, *unicode.RangeTable) {
+	for _, name := range mixScripts {
+		if table := unicode.Scripts[name]; unicode.Is(table, r) {
+			return name, table
+		}
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return name, table
+		}
+	}
+	return "", nil
+}
+
+// scriptMixes are the combinations of scripts allowed by the
+// UTS #39 restriction level 'highly restrictive'.
+var scriptMixes = [][]string{
+	{"Han", "Hiragana", "Katakana", "Latin"},
+	{"Bopomofo", "Han", "Latin"},
+	{"Han", "Hangul", "Latin"},
+}
+
+// mixScripts are all scripts of scriptMixes.
+var mixScripts = []string{"Han", "Hiragana", "Katakana", "Latin", "Bopomofo", "Hangul"}
+
+func allowedScriptMix(scripts []string) bool {
+	if len(scripts) <= 1 {
+		return true
+	}
+	for _, mix := range scriptMixes {
+		allowed := true
+		for _, script := range scripts {
+			if !slices.Contains(mix, script) {
+				allowed = false
+				break
+			}
+		}
+		if allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// latinConfusables maps Cyrillic and Greek letters to the Latin letters
+// they are confusable with (a small subset of the UTS #39 confusables data).
+var latinConfusables = map[rune]rune{
+	// Cyrillic
+	'а': 'a', 'с': 'c', 'ԁ': 'd', 'е': 'e', 'һ': 'h', 'і': 'i', 'ј': 'j', 'о': 'o',
+	'р': 'p', 'ԛ': 'q', 'ѕ': 's', 'ԝ': 'w', 'х': 'x', 'у': 'y',
+	'А': 'A', 'В': 'B', 'С': 'C', 'Е': 'E', 'Н': 'H', 'І': 'I', 'Ј': 'J', 'К': 'K',
+	'М': 'M', 'О': 'O', 'Р': 'P', 'Ѕ': 'S', 'Т': 'T', 'Х': 'X', 'Ү': 'Y',
+	// Greek
+	'α': 'a', 'ι': 'i', 'ο': 'o', 'ρ': 'p', 'ν': 'v', 'υ': 'u',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N',
+	'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X', 'Ζ': 'Z',
+}
+
+// latinSkeleton returns the identifier with all confusable letters replaced
+// by their Latin counterparts.
+// It reports false if the identifier contains other non-ASCII characters,
+// so it can't be mistaken for a Latin identifier.
+func latinSkeleton(ident string) (string, bool) {
+	sb := strings.Builder{}
+	sb.Grow(len(ident))
+	for _, r := range ident {
+		if r < utf8.RuneSelf {
+			sb.WriteRune(r)
+			continue
+		}
+		latin, ok := latinConfusables[r]
+		if !ok {
+			return "", false
+		}
+		sb.WriteRune(latin)
+	}
+	return sb.String(), true
+}
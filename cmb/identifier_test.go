@@ -0,0 +1,107 @@
+package cmb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentifier(t *testing.T) {
+	t.Parallel()
+
+	strict := cmb.IdentifierProfile{ExtraStart: "_", WarnMixedScripts: true, WarnConfusables: true}
+	testCases := []struct {
+		name         string
+		profile      cmb.IdentifierProfile
+		input        string
+		wantErr      bool
+		wantOutput   string
+		wantWarnings []string
+	}{
+		{
+			name:       "ASCII identifier should succeed",
+			profile:    strict,
+			input:      "_foo42+",
+			wantOutput: "_foo42",
+		}, {
+			name:       "non-Latin identifier should succeed without warnings",
+			profile:    strict,
+			input:      "переменная := 1",
+			wantOutput: "переменная",
+		}, {
+			name:       "allowed mix of scripts should succeed without warnings",
+			profile:    strict,
+			input:      "Tokyo東京とうきょう",
+			wantOutput: "Tokyo東京とうきょう",
+		}, {
+			name:       "combining marks should continue an identifier",
+			profile:    strict,
+			input:      "café",
+			wantOutput: "café",
+		}, {
+			name:       "digit at start should fail",
+			profile:    strict,
+			input:      "1abc",
+			wantErr:    true,
+			wantOutput: "",
+		}, {
+			name:       "underscore at start without profile should fail",
+			profile:    cmb.IdentifierProfile{},
+			input:      "_abc",
+			wantErr:    true,
+			wantOutput: "",
+		}, {
+			name:       "extra continue characters should be allowed after the start",
+			profile:    cmb.IdentifierProfile{ExtraContinue: "$"},
+			input:      "a$b",
+			wantOutput: "a$b",
+		}, {
+			name:         "mixing Latin and Cyrillic should warn twice",
+			profile:      strict,
+			input:        "pаypal",
+			wantOutput:   "pаypal",
+			wantWarnings: []string{"mixes the scripts Cyrillic, Latin", `is confusable with "paypal"`},
+		}, {
+			name:         "mixing two scripts outside of the allowed mixes should warn",
+			profile:      strict,
+			input:        "δδжж",
+			wantOutput:   "δδжж",
+			wantWarnings: []string{"mixes the scripts Cyrillic, Greek"},
+		}, {
+			name:         "whole script confusable should warn",
+			profile:      strict,
+			input:        "раура",
+			wantOutput:   "раура",
+			wantWarnings: []string{`is confusable with "paypa"`},
+		}, {
+			name:       "warnings should be turned off by default",
+			profile:    cmb.IdentifierProfile{},
+			input:      "pаypal",
+			wantOutput: "pаypal",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var warnings []error
+			state := comb.NewFromString(tc.input, 0).WithWarningHandler(func(warning error) {
+				warnings = append(warnings, warning)
+			})
+			gotOutput, gotErr := comb.RunOnState(state, comb.NewPreparedParser(cmb.Identifier(tc.profile)))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %v", gotErr, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantOutput, gotOutput)
+			if assert.Len(t, warnings, len(tc.wantWarnings)) {
+				for i, want := range tc.wantWarnings {
+					assert.ErrorContains(t, warnings[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package cmb
+
+import (
+	"fmt"
+	"slices"
+	"unicode"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Keyword Tables
+//
+
+// KeywordSet parses one of the keywords and returns it as given in `words`.
+// It replaces chains like FirstSuccessful(String("if"), String("else"), ...)
+// with a single trie lookup.
+// The longest keyword wins and it has to end at a word boundary.
+// So "in" doesn't match the start of "int" or "index".
+// Word characters are the ones allowed in identifiers (see Identifier).
+// If `caseInsensitive` is true, Unicode simple case folding is used
+// (e.g. "IF", "If" and "if" are all matched by "if").
+// Use KeywordSetFold for locale specific case mappings.
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+// This function panics during the construction phase if no keyword is given,
+// a keyword is empty or two keywords are the same (after case folding).
+func KeywordSet(words []string, caseInsensitive bool) comb.Parser[string] {
+	fold := func(r rune) rune { return r }
+	if caseInsensitive {
+		fold = foldRune
+	}
+	return KeywordSetFold(words, fold)
+}
+
+// KeywordSetFold is KeywordSet with a custom case mapping of runes.
+// E.g. `unicode.TurkishCase.ToLower` matches Turkish keywords with dotted
+// and dotless i correctly.
+// The case mapping is applied to the keywords and the input alike.
+func KeywordSetFold(words []string, fold func(rune) rune) comb.Parser[string] {
+	if len(words) == 0 {
+		panic("KeywordSet is unable to handle missing keywords")
+	}
+	if fold == nil {
+		panic("KeywordSetFold is unable to handle a nil `fold` function")
+	}
+	trie := &keywordTrie{root: &keywordNode{word: -1}, fold: fold}
+	for i, w := range words {
+		if w == "" {
+			panic(fmt.Sprintf("KeywordSet is unable to handle the empty keyword with index %d", i))
+		}
+		if j := trie.add(w, i); j >= 0 {
+			panic(fmt.Sprintf("KeywordSet keyword %q (index %d) is a duplicate of %q", w, i, words[j]))
+		}
+	}
+	expected := "keyword"
+	if len(words) <= 5 {
+		expected = fmt.Sprintf("one of the keywords %q", words)
+	}
+
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		word, n, incomplete := trie.match(state, state.CurrentString())
+		if word < 0 {
+			if incomplete {
+				return state, "", comb.MarkIncomplete(state.NewSyntaxError(expected))
+			}
+			return state, "", state.NewSyntaxError(expected)
+		}
+		return state.MoveBy(n), words[word], nil
+	}
+
+	recoverer := func(state comb.State, _ interface{}) (int, interface{}) {
+		input := state.CurrentString()
+		prev, _ := state.PreviousRune()
+		for i := 0; i < len(input); {
+			r, size := state.DecodeRune(input[i:])
+			if !isXIDContinue(prev) {
+				if word, _, _ := trie.match(state, input[i:]); word >= 0 {
+					return i, nil
+				}
+			}
+			prev = r
+			i += size
+		}
+		return comb.RecoverWasteTooMuch, nil
+	}
+
+	return comb.NewParser[string](expected, parse, recoverer)
+}
+
+// keywordTrie is a trie of case folded keywords.
+type keywordTrie struct {
+	root *keywordNode
+	fold func(rune) rune
+}
+
+type keywordNode struct {
+	edges []keywordEdge // sorted by rune
+	word  int           // index of the keyword ending here or -1
+}
+
+type keywordEdge struct {
+	r    rune
+	next *keywordNode
+}
+
+// add adds the keyword with the index to the trie.
+// It returns the index of an equal keyword or -1.
+func (t *keywordTrie) add(word string, index int) int {
+	node := t.root
+	for _, r := range word {
+		r = t.fold(r)
+		i, ok := slices.BinarySearchFunc(node.edges, r, compareEdge)
+		if !ok {
+			node.edges = slices.Insert(node.edges, i, keywordEdge{r: r, next: &keywordNode{word: -1}})
+		}
+		node = node.edges[i].next
+	}
+	if node.word >= 0 {
+		return node.word
+	}
+	node.word = index
+	return -1
+}
+
+// match returns the index of the longest keyword at the start of the input
+// that ends at a word boundary and its length in bytes.
+// The index is -1 if there is no such keyword.
+// incomplete is true if a keyword might match with more input.
+func (t *keywordTrie) match(state comb.State, input string) (word, n int, incomplete bool) {
+	word, n = -1, 0
+	node := t.root
+	for i := 0; ; {
+		if i == len(input) {
+			if node.word >= 0 {
+				return node.word, i, false
+			}
+			return word, n, word < 0 && len(node.edges) > 0
+		}
+		r, size := state.DecodeRune(input[i:])
+		if node.word >= 0 && !isXIDContinue(r) {
+			word, n = node.word, i
+		}
+		j, ok := slices.BinarySearchFunc(node.edges, t.fold(r), compareEdge)
+		if !ok {
+			return word, n, false
+		}
+		node = node.edges[j].next
+		i += size
+	}
+}
+
+func compareEdge(e keywordEdge, r rune) int {
+	return int(e.r - r)
+}
+
+// foldRune returns the smallest rune of the case folding orbit of r.
+// So all case variants of a letter map to the same rune.
+func foldRune(r rune) rune {
+	smallest := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		smallest = min(smallest, f)
+	}
+	return smallest
+}
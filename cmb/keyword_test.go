@@ -0,0 +1,117 @@
+package cmb_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeywordSet(t *testing.T) {
+	t.Parallel()
+
+	words := []string{"in", "int", "if", "import", "straße"}
+	testCases := []struct {
+		name           string
+		parser         comb.Parser[string]
+		input          string
+		wantErr        bool
+		wantIncomplete bool
+		wantOutput     string
+		wantRemaining  string
+	}{
+		{
+			name:          "keyword at word boundary should succeed",
+			parser:        cmb.KeywordSet(words, false),
+			input:         "if x",
+			wantOutput:    "if",
+			wantRemaining: " x",
+		}, {
+			name:          "longest keyword should win",
+			parser:        cmb.KeywordSet(words, false),
+			input:         "int(x)",
+			wantOutput:    "int",
+			wantRemaining: "(x)",
+		}, {
+			name:          "keyword at the end of the input should succeed",
+			parser:        cmb.KeywordSet(words, false),
+			input:         "in",
+			wantOutput:    "in",
+			wantRemaining: "",
+		}, {
+			name:          "keyword as prefix of an identifier should fail",
+			parser:        cmb.KeywordSet(words, false),
+			input:         "index",
+			wantErr:       true,
+			wantRemaining: "index",
+		}, {
+			name:          "keyword with wrong case should fail",
+			parser:        cmb.KeywordSet(words, false),
+			input:         "IF x",
+			wantErr:       true,
+			wantRemaining: "IF x",
+		}, {
+			name:          "case insensitive keyword should return the table spelling",
+			parser:        cmb.KeywordSet(words, true),
+			input:         "ImPoRt x",
+			wantOutput:    "import",
+			wantRemaining: " x",
+		}, {
+			name:          "case insensitive keyword should fold non-ASCII letters",
+			parser:        cmb.KeywordSet(words, true),
+			input:         "STRAßE.",
+			wantOutput:    "straße",
+			wantRemaining: ".",
+		}, {
+			name:          "locale specific case mapping should be used",
+			parser:        cmb.KeywordSetFold([]string{"için"}, unicode.TurkishCase.ToLower),
+			input:         "İÇİN ",
+			wantOutput:    "için",
+			wantRemaining: " ",
+		}, {
+			name:           "partial keyword at the end of the input should be incomplete",
+			parser:         cmb.KeywordSet(words, false),
+			input:          "imp",
+			wantErr:        true,
+			wantIncomplete: true,
+			wantRemaining:  "imp",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotOutput, err := tc.parser.Parse(comb.NewFromString(tc.input, 10))
+			assert.Equal(t, tc.wantErr, err != nil, "error: %v", err)
+			if err != nil {
+				assert.Equal(t, tc.wantIncomplete, err.Incomplete())
+			}
+			assert.Equal(t, tc.wantOutput, gotOutput)
+			assert.Equal(t, tc.wantRemaining, newState.CurrentString())
+		})
+	}
+}
+
+func TestKeywordSetRecoverer(t *testing.T) {
+	t.Parallel()
+
+	p := cmb.KeywordSet([]string{"if", "else"}, false)
+	waste, _ := p.Recover(comb.NewFromString("elsewhere x=1 else", 10), nil)
+	assert.Equal(t, 14, waste)
+
+	waste, _ = p.Recover(comb.NewFromString("xif", 10), nil)
+	assert.Equal(t, comb.RecoverWasteTooMuch, waste)
+}
+
+func TestKeywordSetPanics(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { cmb.KeywordSet(nil, false) })
+	assert.Panics(t, func() { cmb.KeywordSet([]string{"if", ""}, false) })
+	assert.Panics(t, func() { cmb.KeywordSet([]string{"if", "IF"}, true) })
+	assert.NotPanics(t, func() { cmb.KeywordSet([]string{"if", "IF"}, false) })
+}
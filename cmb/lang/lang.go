@@ -0,0 +1,153 @@
+// Package lang contains configurable parsers for common elements of
+// programming languages: identifiers, comments, number and string literals
+// and operators.
+// All of them return the parsed value together with its span in the input.
+// So new language grammars can start from a solid base.
+package lang
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// Token is a value together with the span of the input it has been parsed from.
+type Token[T any] struct {
+	Value T
+	Span  comb.Span
+}
+
+// Spanned applies the parser and returns its output together with the span
+// of the consumed input.
+func Spanned[T any](parser comb.Parser[T]) comb.Parser[Token[T]] {
+	return cmb.MapWithSpan(parser, func(value T, span comb.Span, _ comb.State) (Token[T], error) {
+		return Token[T]{Value: value, Span: span}, nil
+	})
+}
+
+// Identifier parses an identifier as configured (see cmb.Identifier).
+func Identifier(profile cmb.IdentifierProfile) comb.Parser[Token[string]] {
+	return Spanned(cmb.Identifier(profile))
+}
+
+// Number parses a number literal as configured (see cmb.NumberLiteral).
+func Number(cfg cmb.NumberConfig) comb.Parser[Token[cmb.NumberLit]] {
+	return Spanned(cmb.NumberLiteral(cfg))
+}
+
+// Operator parses one of the operator tokens. The longest matching operator
+// wins (e.g. "<=" instead of "<").
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+// This function panics during the construction phase if no operator is given
+// or one of them is empty.
+func Operator(ops ...string) comb.Parser[Token[string]] {
+	if len(ops) == 0 {
+		panic("Operator is unable to handle missing operators")
+	}
+	if slices.Contains(ops, "") {
+		panic("Operator is unable to handle an empty operator")
+	}
+	sorted := slices.Clone(ops)
+	slices.SortStableFunc(sorted, func(a, b string) int {
+		return len(b) - len(a)
+	})
+	expected := "operator"
+
+	parse := func(state comb.State) (comb.State, Token[string], *comb.ParserError) {
+		input := state.CurrentString()
+		for _, op := range sorted {
+			if strings.HasPrefix(input, op) {
+				nState := state.MoveBy(len(op))
+				return nState, Token[string]{Value: op, Span: state.SpanTo(nState)}, nil
+			}
+		}
+		for _, op := range sorted {
+			if len(input) < len(op) && strings.HasPrefix(op, input) {
+				return state, Token[string]{}, comb.MarkIncomplete(state.NewSyntaxError(expected))
+			}
+		}
+		return state, Token[string]{}, state.NewSyntaxError(expected)
+	}
+
+	return comb.NewParser[Token[string]](expected, parse, cmb.IndexOfAny(ops...))
+}
+
+// LineComment parses a comment from the start token (e.g. "//" or "#")
+// to the end of the line.
+// The value is the text after the start token without the line end
+// (that isn't consumed).
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+// This function panics during the construction phase if `start` is empty.
+func LineComment(start string) comb.Parser[Token[string]] {
+	if start == "" {
+		panic("LineComment is unable to handle an empty `start`")
+	}
+	expected := "line comment"
+
+	parse := func(state comb.State) (comb.State, Token[string], *comb.ParserError) {
+		input := state.CurrentString()
+		if !strings.HasPrefix(input, start) {
+			if strings.HasPrefix(start, input) {
+				return state, Token[string]{}, comb.MarkIncomplete(state.NewSyntaxError(expected))
+			}
+			return state, Token[string]{}, state.NewSyntaxError(expected)
+		}
+		end := strings.IndexByte(input, '\n')
+		if end < 0 {
+			end = len(input)
+		} else if end > 0 && input[end-1] == '\r' {
+			end--
+		}
+		nState := state.MoveBy(end)
+		return nState, Token[string]{Value: input[len(start):end], Span: state.SpanTo(nState)}, nil
+	}
+
+	return comb.NewParser[Token[string]](expected, parse, cmb.IndexOf(start))
+}
+
+// BlockComment parses a comment between the start and end tokens
+// (e.g. "/*" and "*/").
+// Nested comments are allowed if `nested` is true.
+// The value is the text between the start and end tokens.
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+// This function panics during the construction phase if `start` or `end` is empty.
+func BlockComment(start, end string, nested bool) comb.Parser[Token[string]] {
+	if start == "" || end == "" {
+		panic("BlockComment is unable to handle an empty `start` or `end`")
+	}
+	expected := "block comment"
+
+	parse := func(state comb.State) (comb.State, Token[string], *comb.ParserError) {
+		input := state.CurrentString()
+		if !strings.HasPrefix(input, start) {
+			if strings.HasPrefix(start, input) {
+				return state, Token[string]{}, comb.MarkIncomplete(state.NewSyntaxError(expected))
+			}
+			return state, Token[string]{}, state.NewSyntaxError(expected)
+		}
+		depth := 1
+		for i := len(start); i < len(input); {
+			switch {
+			case strings.HasPrefix(input[i:], end):
+				depth--
+				if depth == 0 {
+					nState := state.MoveBy(i + len(end))
+					return nState, Token[string]{Value: input[len(start):i], Span: state.SpanTo(nState)}, nil
+				}
+				i += len(end)
+			case nested && strings.HasPrefix(input[i:], start):
+				depth++
+				i += len(start)
+			default:
+				i++
+			}
+		}
+		return state, Token[string]{}, comb.MarkIncomplete(
+			state.MoveBy(len(input)).NewSyntaxError("%q to close the %s", end, expected),
+		)
+	}
+
+	return comb.NewParser[Token[string]](expected, parse, cmb.IndexOf(start))
+}
@@ -0,0 +1,188 @@
+package lang_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/flowdev/comb/cmb/lang"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentifierAndNumber(t *testing.T) {
+	t.Parallel()
+
+	p := cmb.Map2(
+		lang.Identifier(cmb.IdentifierProfile{}),
+		cmb.Prefixed(cmb.Char('='), lang.Number(cmb.NumberConfig{Bases: []int{16}})),
+		func(id lang.Token[string], num lang.Token[cmb.NumberLit]) ([]comb.Span, error) {
+			assert.Equal(t, "abc", id.Value)
+			assert.Equal(t, "0x1F", num.Value.Raw)
+			return []comb.Span{id.Span, num.Span}, nil
+		},
+	)
+	got, err := comb.RunOnString("abc=0x1F", p)
+	assert.NoError(t, err)
+	assert.Equal(t, []comb.Span{{Start: 0, End: 3}, {Start: 4, End: 8}}, got)
+}
+
+func TestOperator(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   string
+		want    lang.Token[string]
+		wantErr bool
+	}{
+		{
+			name:  "longest operator should win",
+			input: "<=1",
+			want:  lang.Token[string]{Value: "<=", Span: comb.Span{Start: 0, End: 2}},
+		}, {
+			name:  "short operator should match",
+			input: "<1",
+			want:  lang.Token[string]{Value: "<", Span: comb.Span{Start: 0, End: 1}},
+		}, {
+			name:    "no operator should fail",
+			input:   "1",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, got, err := lang.Operator("<", "<=", "<<").Parse(comb.NewFromString(tc.input, 10))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        comb.Parser[lang.Token[string]]
+		input         string
+		want          string
+		wantErr       bool
+		wantRemaining string
+	}{
+		{
+			name:          "line comment should stop at the line end",
+			parser:        lang.LineComment("//"),
+			input:         "// note\r\nx",
+			want:          " note",
+			wantRemaining: "\r\nx",
+		}, {
+			name:   "line comment should stop at the end of the input",
+			parser: lang.LineComment("#"),
+			input:  "#note",
+			want:   "note",
+		}, {
+			name:          "block comment should stop at the first end",
+			parser:        lang.BlockComment("/*", "*/", false),
+			input:         "/* a /* b */ c */",
+			want:          " a /* b ",
+			wantRemaining: " c */",
+		}, {
+			name:   "nested block comment should stop at the matching end",
+			parser: lang.BlockComment("/*", "*/", true),
+			input:  "/* a /* b */ c */",
+			want:   " a /* b */ c ",
+		}, {
+			name:    "unterminated block comment should fail",
+			parser:  lang.BlockComment("/*", "*/", true),
+			input:   "/* a /* b */",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			nState, got, err := tc.parser.Parse(comb.NewFromString(tc.input, 10))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.want, got.Value)
+				assert.Equal(t, tc.wantRemaining, nState.CurrentString())
+				assert.Equal(t, len(tc.input)-len(tc.wantRemaining), got.Span.Len())
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		cfg     lang.StringConfig
+		input   string
+		want    lang.StringLit
+		wantErr string
+	}{
+		{
+			name:  "escapes should be replaced",
+			input: `"a\t\"\x41ä\u{1F600}"x`,
+			want:  lang.StringLit{Raw: `"a\t\"\x41ä\u{1F600}"`, Value: "a\t\"Aä😀", Quote: '"'},
+		}, {
+			name:  "configured quotes should be allowed",
+			cfg:   lang.StringConfig{Quotes: `'"`},
+			input: `'a\'b'`,
+			want:  lang.StringLit{Raw: `'a\'b'`, Value: "a'b", Quote: '\''},
+		}, {
+			name:  "raw strings should keep backslashes",
+			cfg:   lang.StringConfig{NoEscapes: true},
+			input: `"a\n"`,
+			want:  lang.StringLit{Raw: `"a\n"`, Value: `a\n`, Quote: '"'},
+		}, {
+			name:  "multiline strings should allow line ends",
+			cfg:   lang.StringConfig{Multiline: true},
+			input: "\"a\nb\"",
+			want:  lang.StringLit{Raw: "\"a\nb\"", Value: "a\nb", Quote: '"'},
+		}, {
+			name:    "line ends should fail",
+			input:   "\"a\nb\"",
+			wantErr: `expected '"' to close the string literal [1:3] "a▶`,
+		}, {
+			name:    "invalid escapes should fail",
+			input:   `"a\qb"`,
+			wantErr: `expected valid escape sequence after '\' [1:3] "a▶\qb"`,
+		}, {
+			name:    "unterminated strings should fail",
+			input:   `"ab`,
+			wantErr: `expected '"' to close the string literal [1:4] "ab▶`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, got, err := lang.String(tc.cfg).Parse(comb.NewFromString(tc.input, 10))
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tc.want.Raw, got.Value.Raw)
+			assert.Equal(t, tc.want.Value, got.Value.Value)
+			assert.Equal(t, tc.want.Quote, got.Value.Quote)
+			assert.Equal(t, comb.Span{Start: 0, End: len(tc.want.Raw)}, got.Span)
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package lang
+
+import (
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// StringConfig configures the String parser (see cmb.StringConfig).
+type StringConfig = cmb.StringConfig
+
+// StringLit is a string literal (see cmb.StringLit).
+type StringLit = cmb.StringLit
+
+// String parses a string literal as configured (see cmb.StringLiteral).
+func String(cfg StringConfig) comb.Parser[Token[StringLit]] {
+	return Spanned(cmb.StringLiteral(cfg))
+}
@@ -1,6 +1,8 @@
 package cmb
 
 import (
+	"errors"
+
 	"github.com/flowdev/comb"
 )
 
@@ -76,6 +78,26 @@ func MapN[PO1, PO2, PO3, PO4, PO5 any, MO any](
 	return p
 }
 
+// Defer returns an error that signals MapN (and so all MapX parsers) to
+// postpone the resolve function until the whole input has been parsed.
+// The mapping function should return a placeholder (e.g., a pointer) as output
+// that is completed by resolve with access to the full parse.
+// This enables two-phase grammars (parse structure first, resolve references
+// later) without a separate AST walker.
+// An error returned by resolve is reported as a semantic error at the position
+// after the mapped parsers.
+func Defer(resolve func() error) error {
+	return deferredMapping{resolve: resolve}
+}
+
+type deferredMapping struct {
+	resolve func() error
+}
+
+func (dm deferredMapping) Error() string {
+	return "deferred mapping"
+}
+
 type mapData[PO1, PO2, PO3, PO4, PO5 any, MO any] struct {
 	id       func() int32
 	expected string
@@ -234,43 +256,39 @@ func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) parseAfterChild(
 					}
 
 					out, err := md.fn5(partRes.out1, partRes.out2, partRes.out3, partRes.out4, out5)
-					if err != nil {
-						childState = childState.SaveError(childState.NewSemanticError(err.Error()))
-						return childState, out, nil, partRes
-					}
-					return childState, out, nil, nil
+					return md.result(childState, out, err, partRes)
 				}
 
 				out, err := md.fn4(partRes.out1, partRes.out2, partRes.out3, partRes.out4)
-				if err != nil {
-					childState = childState.SaveError(childState.NewSemanticError(err.Error()))
-					return childState, out, nil, partRes
-				}
-				return childState, out, nil, nil
+				return md.result(childState, out, err, partRes)
 			}
 
 			out, err := md.fn3(partRes.out1, partRes.out2, partRes.out3)
-			if err != nil {
-				childState = childState.SaveError(childState.NewSemanticError(err.Error()))
-				return childState, out, nil, partRes
-			}
-			return childState, out, nil, nil
+			return md.result(childState, out, err, partRes)
 		}
 
 		out, err := md.fn2(partRes.out1, partRes.out2)
-		if err != nil {
-			childState = childState.SaveError(childState.NewSemanticError(err.Error()))
-			return childState, out, nil, partRes
-		}
-		return childState, out, nil, nil
+		return md.result(childState, out, err, partRes)
 	}
 
 	out, err := md.fn1(partRes.out1)
-	if err != nil {
-		childState = childState.SaveError(childState.NewSemanticError(err.Error()))
-		return childState, out, nil, partRes
+	return md.result(childState, out, err, partRes)
+}
+
+// result handles the error of the mapping function.
+// Deferred mappings (see Defer) are registered with the state.
+func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) result(
+	state comb.State, out MO, err error, partRes partialMapResult[PO1, PO2, PO3, PO4],
+) (comb.State, MO, *comb.ParserError, interface{}) {
+	if err == nil {
+		return state, out, nil, nil
+	}
+	var dm deferredMapping
+	if errors.As(err, &dm) {
+		return state.Defer(dm.resolve), out, nil, nil
 	}
-	return childState, out, nil, nil
+	state = state.SaveError(state.NewSemanticError("%w", err))
+	return state, out, nil, partRes
 }
 
 func (md *mapData[PO1, PO2, PO3, PO4, PO5, MO]) fn(partRes partialMapResult[PO1, PO2, PO3, PO4]) (MO, error) {
@@ -79,3 +79,107 @@ func Separated1[Output any, S comb.Separator](
 ) comb.Parser[[]Output] {
 	return SeparatedMN(parse, separator, 1, math.MaxInt, parseSeparatorAtEnd)
 }
+
+// CountOf parses a count with `countParser` first and then exactly
+// that many items with `item`.
+// An item that has been recovered after an error still counts as one item,
+// so a bad element doesn't desynchronize the count.
+//
+// If the count is negative, the parser fails with a semantic error.
+// A count above comb.Limits.MaxElements is a fatal error.
+// An item has to consume input, or the parser fails.
+func CountOf[Output any](countParser comb.Parser[int], item comb.Parser[Output]) comb.Parser[[]Output] {
+	cd := &countOfData[Output]{countParser: countParser, item: item}
+	p := comb.NewBranchParser[[]Output]("CountOf", cd.children, cd.parseAfterChild)
+	cd.id = p.ID
+	return p
+}
+
+type countOfData[Output any] struct {
+	id          func() int32
+	countParser comb.Parser[int]
+	item        comb.Parser[Output]
+}
+
+// partialCountOfResult is internal to the parsing method and methods and functions called by it.
+type partialCountOfResult[Output any] struct {
+	count int
+	outs  []Output
+}
+
+func (cd *countOfData[Output]) children() []comb.AnyParser {
+	return []comb.AnyParser{cd.countParser, cd.item}
+}
+
+func (cd *countOfData[Output]) parseAfterChild(
+	childID int32,
+	childStartState, childState comb.State,
+	childOut interface{},
+	childErr *comb.ParserError,
+	data interface{},
+) (comb.State, []Output, *comb.ParserError, interface{}) {
+	var partRes partialCountOfResult[Output]
+
+	comb.Debugf("CountOf.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+
+	if childID >= 0 { // on the way up: Fetch
+		partRes, _ = data.(partialCountOfResult[Output])
+	}
+
+	switch {
+	case childID < 0:
+		childStartState = childState
+		childState, childOut, childErr = cd.countParser.ParseAny(cd.id(), childStartState)
+		if childErr != nil {
+			return childState, nil, childErr, partRes
+		}
+		partRes.count, _ = childOut.(int)
+	case childID == cd.countParser.ID():
+		if childErr != nil {
+			return childState, nil, childErr, partRes
+		}
+		partRes.count, _ = childOut.(int)
+	case childID == cd.item.ID():
+		out, _ := childOut.(Output)
+		if childErr != nil {
+			return childState, append(partRes.outs, out), childErr, partRes
+		}
+		if !childStartState.Moved(childState) {
+			return childState, append(partRes.outs, out), cd.noProgressError(childState), partRes
+		}
+		partRes.outs = append(partRes.outs, out)
+	default:
+		childErr = childState.NewSemanticError("unable to parse after child with unknown ID %d", childID)
+		return childState, partRes.outs, childErr, partRes
+	}
+
+	if partRes.count < 0 {
+		return childState, nil, childStartState.NewSemanticError("negative count %d", partRes.count), nil
+	}
+	if maxElements := childState.Limits().MaxElements; maxElements > 0 && partRes.count > maxElements {
+		return childState, nil, childStartState.NewLimitError("count", maxElements), nil
+	}
+	if partRes.outs == nil {
+		partRes.outs = make([]Output, 0, min(32, partRes.count))
+	}
+
+	for len(partRes.outs) < partRes.count {
+		childStartState = childState
+		childState, childOut, childErr = cd.item.ParseAny(cd.id(), childStartState)
+		out, _ := childOut.(Output)
+		if childErr != nil {
+			return childState, append(partRes.outs, out), childErr, partRes
+		}
+		if !childStartState.Moved(childState) {
+			return childState, append(partRes.outs, out), cd.noProgressError(childState), partRes
+		}
+		partRes.outs = append(partRes.outs, out)
+	}
+	return childState, partRes.outs, nil, nil
+}
+
+// noProgressError reports an item that didn't consume any input.
+// Without it, a big count would make us go around in circles.
+func (cd *countOfData[Output]) noProgressError(state comb.State) *comb.ParserError {
+	return state.NewSyntaxError("%s (item of count without input consumption)", cd.item.Expected())
+}
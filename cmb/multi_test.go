@@ -401,3 +401,79 @@ func BenchmarkSeparated1(t *testing.B) {
 		_, _, _ = parser.Parse(state)
 	}
 }
+
+func TestCountOf(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		limits     comb.Limits
+		item       comb.Parser[string]
+		wantErrors int
+		wantOutput []string
+	}{
+		{
+			name:       "parsing exact count should succeed",
+			input:      "2:abc;def;",
+			wantOutput: []string{"abc", "def"},
+		},
+		{
+			name:       "parsing zero count should succeed",
+			input:      "0:",
+			wantOutput: []string{},
+		},
+		{
+			name:       "parsing less than count should fail",
+			input:      "3:abc;def;",
+			wantErrors: 1,
+			wantOutput: []string{"abc", "def", ""},
+		},
+		{
+			name:       "a bad item should still count",
+			input:      "3:abc;123;def;",
+			wantErrors: 1,
+			wantOutput: []string{"abc", "", "def"},
+		},
+		{
+			name:       "count above the element limit should fail",
+			input:      "3:abc;def;ghi;",
+			limits:     comb.Limits{MaxElements: 2},
+			wantErrors: 1,
+		},
+		{
+			name:       "count within the element limit should succeed",
+			input:      "2:abc;def;",
+			limits:     comb.Limits{MaxElements: 2},
+			wantOutput: []string{"abc", "def"},
+		},
+		{
+			name:       "item without input consumption should fail",
+			input:      "2147483647:abc",
+			item:       Alpha0(),
+			wantErrors: 1,
+			wantOutput: []string{"abc", ""},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			count := Map(Suffixed(UInt64(false, 10), Char(':')), func(n uint64) (int, error) {
+				return int(n), nil
+			})
+			item := tc.item
+			if item == nil {
+				item = Suffixed(Alpha1(), comb.SafeSpot(Char(';')))
+			}
+			state := comb.NewFromString(tc.input, 10).WithLimits(tc.limits)
+			gotResult, gotErr := comb.RunOnState(state, comb.NewPreparedParser(CountOf(count, item)))
+			if got, want := len(comb.UnwrapErrors(gotErr)), tc.wantErrors; got != want {
+				t.Errorf("got errors %v, want %d errors", gotErr, want)
+			}
+			assert.Equal(t, tc.wantOutput, gotResult)
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package cmb
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
+	"unsafe"
 
 	"github.com/flowdev/comb"
 )
@@ -20,6 +24,8 @@ import (
 // `underscoreAllowed` can be true to allow '_' characters.
 // No check on position or number of (consecutive) underscores is done.
 // The Go parse functions will do more checks on this.
+// Byte input (see comb.NewFromBytes) isn't converted to a string
+// except for the integer itself.
 func Integer(signAllowed bool, base int, underscoreAllowed bool) comb.Parser[string] {
 	if base != 0 && (base < 2 || base > 36) {
 		panic(fmt.Sprintf(
@@ -42,64 +48,73 @@ func Integer(signAllowed bool, base int, underscoreAllowed bool) comb.Parser[str
 		expected = fmt.Sprintf("integer of base %d", base)
 	}
 
-	const allDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
-
 	parser := func(state comb.State) (comb.State, string, *comb.ParserError) {
-		fullInput := state.CurrentString()
-		input := fullInput
-		if input == "" {
-			return state, "", state.NewSyntaxError(expected + " at EOF")
+		if state.IsBinary() {
+			return integerOf(state, state.CurrentBytes(), base, signAllowed, underscoreAllowed, expected)
 		}
+		return integerOf(state, state.CurrentString(), base, signAllowed, underscoreAllowed, expected)
+	}
 
-		n := 0 // number of bytes read from input
+	recovererBase := base
+	if base == 0 {
+		recovererBase = 10
+	}
+	allRunes := digitsToRunes(allIntegerDigits)
+	return comb.NewParser[string](expected, parser, IndexOfAny(allRunes[:recovererBase]...))
+}
 
-		// Pick off the leading sign.
-		if signAllowed {
-			if input[0] == '+' || input[0] == '-' {
-				input = input[1:]
-				n = 1
-				if input == "" {
-					return state, "", state.NewSyntaxError(expected + " at EOF")
-				}
-			}
-		}
+const allIntegerDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
 
-		input, base, n = rebaseInt(input, base, n)
-		digits := allDigits[:base]
-		good := false
-		digit := ' '
+// integerOf parses an integer from text or byte input without converting
+// more than the integer itself.
+func integerOf[T text](
+	state comb.State, fullInput T, base int, signAllowed, underscoreAllowed bool, expected string,
+) (comb.State, string, *comb.ParserError) {
+	input := fullInput
+	if len(input) == 0 {
+		return state, "", state.NewSyntaxError(expected + " at EOF")
+	}
 
-	ForLoop:
-		for _, digit = range input {
-			switch {
-			case digit == '_':
-				if !underscoreAllowed {
-					break ForLoop // don't break switch but for
-				}
-				n++
-			case strings.IndexRune(digits, unicode.ToLower(digit)) >= 0:
-				n++
-				good = true
-			default:
-				break ForLoop // don't break switch but for
+	n := 0 // number of bytes read from input
+
+	// Pick off the leading sign.
+	if signAllowed {
+		if input[0] == '+' || input[0] == '-' {
+			input = input[1:]
+			n = 1
+			if len(input) == 0 {
+				return state, "", comb.MarkIncomplete(state.NewSyntaxError(expected + " at EOF"))
 			}
 		}
+	}
 
-		if !good {
-			return state, "", state.NewSyntaxError("%s found '%c'", expected, digit)
+	input, base, n = rebaseInt(input, base, n)
+	digits := allIntegerDigits[:base]
+	good := false
+	digit := ' '
+
+ForLoop:
+	for i := 0; i < len(input); i++ {
+		switch c := input[i]; {
+		case c == '_' && underscoreAllowed:
+			digit = '_'
+			n++
+		case strings.IndexByte(digits, lowerASCII(c)) >= 0:
+			n++
+			good = true
+		default:
+			digit, _ = decodeRune(state, input[i:])
+			break ForLoop // don't break switch but for
 		}
-		return state.MoveBy(n), fullInput[:n], nil
 	}
 
-	recovererBase := base
-	if base == 0 {
-		recovererBase = 10
+	if !good {
+		return state, "", state.NewSyntaxError("%s found '%c'", expected, digit)
 	}
-	allRunes := digitsToRunes(allDigits)
-	return comb.NewParser[string](expected, parser, IndexOfAny(allRunes[:recovererBase]...))
+	return state.MoveBy(n), string(fullInput[:n]), nil
 }
 
-func rebaseInt(input string, base, n int) (string, int, int) {
+func rebaseInt[T text](input T, base, n int) (T, int, int) {
 	if base != 0 {
 		return input, base, n
 	}
@@ -139,56 +154,71 @@ func digitsToRunes(digits string) []rune {
 	return runes
 }
 
-// Int64 parses an integer from the input using `strconv.ParseInt`.
-func Int64(signAllowed bool, base int) comb.Parser[int64] {
-	var p comb.Parser[int64]
+// integer is the set of all integer types that Int can produce.
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
 
-	underscoreAllowed := false
-	if base == 0 {
-		underscoreAllowed = true
-	}
-	intParser := Integer(signAllowed, base, underscoreAllowed)
+// Int parses an integer of type T from the input.
+// It works with text and byte input alike (see Integer).
+// Underscores are allowed for base 0 only.
+// A value that doesn't fit into T results in a semantic error that wraps
+// strconv.ErrRange and the output is the nearest value T can hold.
+// So all integer types share the same error messages:
+//
+//	cmb.Int[uint16](false, 10) // port numbers
+//	cmb.Int[int8](true, 0)     // "-0x80" is fine, "128" isn't
+func Int[T integer](signAllowed bool, base int) comb.Parser[T] {
+	var p comb.Parser[T]
+
+	var zero T
+	bitSize := int(unsafe.Sizeof(zero)) * 8
+	signed := ^zero < 0
+	intParser := Integer(signAllowed, base, base == 0)
 
-	parser := func(state comb.State) (comb.State, int64, *comb.ParserError) {
+	parser := func(state comb.State) (comb.State, T, *comb.ParserError) {
 		nState, out, pErr := intParser.ParseAny(p.ID(), state)
 		str, _ := out.(string)
 		if pErr != nil {
 			return state, 0, comb.ClaimError(pErr)
 		}
-		i, err := strconv.ParseInt(str, base, 64)
+		var i T
+		var err error
+		if signed {
+			var i64 int64
+			i64, err = strconv.ParseInt(str, base, bitSize)
+			i = T(i64)
+		} else {
+			var u64 uint64
+			u64, err = strconv.ParseUint(strings.TrimPrefix(str, "+"), base, bitSize)
+			i = T(u64)
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			return nState, i, state.NewSemanticError("%s is out of range for %T: %w", str, zero, strconv.ErrRange)
+		}
 		if err != nil {
-			return nState, i, state.NewSemanticError(err.Error())
+			return nState, i, state.NewSemanticError("%w", err)
 		}
 		return nState, i, nil
 	}
-	p = comb.NewParser[int64](intParser.Expected(), parser, intParser.Recover)
+	p = comb.NewParser[T](intParser.Expected(), parser, intParser.Recover)
 	return p
 }
 
+// Int64 parses an integer from the input using `strconv.ParseInt`.
+// It works with text and byte input alike (see Integer).
+//
+// Deprecated: Use Int[int64] instead.
+func Int64(signAllowed bool, base int) comb.Parser[int64] {
+	return Int[int64](signAllowed, base)
+}
+
 // UInt64 parses an integer from the input using `strconv.ParseUint`.
+//
+// Deprecated: Use Int[uint64] instead.
 func UInt64(signAllowed bool, base int) comb.Parser[uint64] {
-	var p comb.Parser[uint64]
-
-	underscoreAllowed := false
-	if base == 0 {
-		underscoreAllowed = true
-	}
-	intParser := Integer(signAllowed, base, underscoreAllowed)
-
-	parser := func(state comb.State) (comb.State, uint64, *comb.ParserError) {
-		nState, out, pErr := intParser.ParseAny(p.ID(), state)
-		str, _ := out.(string)
-		if pErr != nil {
-			return state, 0, comb.ClaimError(pErr)
-		}
-		ui, err := strconv.ParseUint(str, base, 64)
-		if err != nil {
-			return nState, ui, state.NewSemanticError(err.Error())
-		}
-		return nState, ui, nil
-	}
-	p = comb.NewParser[uint64](intParser.Expected(), parser, intParser.Recover)
-	return p
+	return Int[uint64](signAllowed, base)
 }
 
 // ============================================================================
@@ -231,7 +261,7 @@ func Float(signAllowed bool, base int, underscoreAllowed bool) comb.Parser[strin
 			if input[0] == '+' || input[0] == '-' {
 				n = 1
 				if len(input) <= 1 {
-					return state, "", state.NewSyntaxError(expected + " at EOF")
+					return state, "", comb.MarkIncomplete(state.NewSyntaxError(expected + " at EOF"))
 				}
 			}
 		}
@@ -243,7 +273,7 @@ func Float(signAllowed bool, base int, underscoreAllowed bool) comb.Parser[strin
 		good := false
 		digit := ' '
 
-		digit, m, good = readDigits(input[n:], underscoreAllowed, digits)
+		digit, m, good = readDigits(state, input[n:], underscoreAllowed, digits)
 		if !good && digit != '.' {
 			return state, "", state.NewSyntaxError("%s found '%c'", expected, digit)
 		}
@@ -252,7 +282,7 @@ func Float(signAllowed bool, base int, underscoreAllowed bool) comb.Parser[strin
 
 		if digit == '.' {
 			n++
-			digit, m, good = readDigits(input[n:], underscoreAllowed, digits)
+			digit, m, good = readDigits(state, input[n:], underscoreAllowed, digits)
 			if !good && !hasDigits {
 				return state, "", state.NewSyntaxError("%s found '%c'", expected, digit)
 			}
@@ -263,7 +293,7 @@ func Float(signAllowed bool, base int, underscoreAllowed bool) comb.Parser[strin
 			(base == 16 && (digit == 'p' || digit == 'P')) {
 
 			n++
-			digit, m, good = readDigits(input[n:], underscoreAllowed, allDigits[:10])
+			digit, m, good = readDigits(state, input[n:], underscoreAllowed, allDigits[:10])
 			if !good {
 				return state, "", state.NewSyntaxError("%s found '%c'", expected, digit)
 			}
@@ -292,13 +322,14 @@ func rebaseFloat(input string, base int) (int, int) {
 	}
 	return 10, 0
 }
-func readDigits(input string, underscoreAllowed bool, digits string) (int32, int, bool) {
+func readDigits(state comb.State, input string, underscoreAllowed bool, digits string) (int32, int, bool) {
 	digit := ' '
 	good := false
 	n := 0
 
 ForLoop:
-	for _, digit = range input {
+	for n < len(input) {
+		digit, _ = state.DecodeRune(input[n:])
 		switch {
 		case digit == '_':
 			if !underscoreAllowed {
@@ -354,9 +385,236 @@ func Float64(signAllowed bool, base int) comb.Parser[float64] {
 		}
 		f, err := strconv.ParseFloat(str, 64)
 		if err != nil {
-			return nState, f, state.NewSemanticError(err.Error())
+			return nState, f, state.NewSemanticError("%w", err)
 		}
 		return nState, f, nil
 	}
 	return comb.NewParser[float64](floatParser.Expected(), parser, floatParser.Recover)
 }
+
+// ============================================================================
+// Parse Configurable Number Literals
+//
+
+// FloatForm is a set of the allowed forms of floating point literals.
+type FloatForm uint8
+
+const (
+	FloatFraction    FloatForm = 1 << iota // digits on both sides of the dot (e.g. "1.5")
+	FloatLeadingDot                        // no digits before the dot (e.g. ".5")
+	FloatTrailingDot                       // no digits after the dot (e.g. "1.")
+	FloatExponent                          // decimal exponent (e.g. "1e-5")
+)
+
+// NumberConfig configures the NumberLiteral parser.
+// The zero value allows unsigned decimal integers only.
+type NumberConfig struct {
+	Signed    bool      // a leading '+' or '-' is allowed
+	Bases     []int     // additional bases 2, 8 and 16 with the prefixes "0b", "0o" and "0x" (case-insensitive)
+	Separator rune      // separator allowed between two digits (e.g. '_'); 0 turns it off
+	Floats    FloatForm // allowed forms of decimal floating point literals
+	Suffixes  []string  // allowed suffixes (e.g. "u8", "f32", "%" or "px"); the longest match wins
+}
+
+// NumberLit is a number literal as found in the input.
+type NumberLit struct {
+	Raw     string // the whole literal including sign, prefix and suffix
+	Base    int    // 2, 8, 10 or 16
+	IsFloat bool   // the literal contains a fraction or an exponent
+	Suffix  string // the matched suffix (empty if none)
+}
+
+// NumberLiteral parses a number literal as configured.
+// The number isn't converted because the suffix might change its meaning.
+// Separators are only consumed between two digits, so "1_" is parsed as "1".
+// A trailing dot isn't consumed if it is followed by another dot or a letter
+// (e.g. "1..2" or "1.String()").
+// The same is true for an exponent without digits (e.g. "1em" with the suffix "em").
+// Byte input (see comb.NewFromBytes) isn't converted to a string
+// except for the literal itself.
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+func NumberLiteral(cfg NumberConfig) comb.Parser[NumberLit] {
+	for _, base := range cfg.Bases {
+		if base != 2 && base != 8 && base != 16 {
+			panic(fmt.Sprintf("The additional bases have to be 2, 8 or 16, but one is: %d", base))
+		}
+	}
+	suffixes := slices.Clone(cfg.Suffixes)
+	slices.SortStableFunc(suffixes, func(a, b string) int {
+		return len(b) - len(a)
+	})
+	expected := "number"
+
+	parse := func(state comb.State) (comb.State, NumberLit, *comb.ParserError) {
+		if state.IsBinary() {
+			return numberLiteralOf(state, state.CurrentBytes(), cfg, suffixes, expected)
+		}
+		return numberLiteralOf(state, state.CurrentString(), cfg, suffixes, expected)
+	}
+
+	stops := digitsToRunes(allIntegerDigits[:10])
+	if cfg.Floats&FloatLeadingDot != 0 {
+		stops = append(stops, '.')
+	}
+	return comb.NewParser[NumberLit](expected, parse, IndexOfAny(stops...))
+}
+
+// numberLiteralOf parses a number literal from text or byte input without
+// converting more than the literal itself.
+func numberLiteralOf[T text](
+	state comb.State, input T, cfg NumberConfig, suffixes []string, expected string,
+) (comb.State, NumberLit, *comb.ParserError) {
+	n := 0
+	if cfg.Signed && len(input) > 0 && (input[0] == '+' || input[0] == '-') {
+		n = 1
+	}
+	if n >= len(input) {
+		return state, NumberLit{}, comb.MarkIncomplete(state.NewSyntaxError("%s (at EOF)", expected))
+	}
+
+	lit := NumberLit{Base: 10}
+	if base := numberPrefixBase(input[n:], cfg.Bases); base != 10 {
+		lit.Base = base
+		n += 2
+		m := readSeparatedDigits(state, input[n:], allIntegerDigits[:base], cfg.Separator)
+		if m == 0 {
+			if n >= len(input) {
+				return state, NumberLit{}, comb.MarkIncomplete(state.NewSyntaxError("%s (at EOF)", expected))
+			}
+			return state, NumberLit{}, state.NewSyntaxError("%s (got %q after prefix)", expected, input[n-2:n])
+		}
+		n += m
+	} else {
+		m := readSeparatedDigits(state, input[n:], allIntegerDigits[:10], cfg.Separator)
+		n += m
+		hasDigits := m > 0
+		if n < len(input) && input[n] == '.' {
+			frac := readSeparatedDigits(state, input[n+1:], allIntegerDigits[:10], cfg.Separator)
+			switch {
+			case frac > 0 && (hasDigits && cfg.Floats&FloatFraction != 0 || !hasDigits && cfg.Floats&FloatLeadingDot != 0):
+				n += 1 + frac
+				lit.IsFloat = true
+				hasDigits = true
+			case frac == 0 && hasDigits && cfg.Floats&FloatTrailingDot != 0 && !continuesDot(state, input[n+1:]):
+				n++
+				lit.IsFloat = true
+			}
+		}
+		if !hasDigits {
+			if n >= len(input) {
+				return state, NumberLit{}, state.NewSyntaxError("%s (at EOF)", expected)
+			}
+			r, _ := decodeRune(state, input[n:])
+			return state, NumberLit{}, state.NewSyntaxError("%s (got %q)", expected, r)
+		}
+		if cfg.Floats&FloatExponent != 0 {
+			if m := readExponent(state, input[n:], cfg.Separator); m > 0 {
+				n += m
+				lit.IsFloat = true
+			}
+		}
+	}
+
+	for _, suffix := range suffixes {
+		if hasPrefix(input[n:], suffix) {
+			lit.Suffix = suffix
+			n += len(suffix)
+			break
+		}
+	}
+	lit.Raw = string(input[:n])
+	return state.MoveBy(n), lit, nil
+}
+
+// numberPrefixBase returns the base of the prefix at the start of the input
+// if it is one of the allowed bases or 10.
+func numberPrefixBase[T text](input T, bases []int) int {
+	if len(input) < 2 || input[0] != '0' {
+		return 10
+	}
+	base := 10
+	switch input[1] {
+	case 'b', 'B':
+		base = 2
+	case 'o', 'O':
+		base = 8
+	case 'x', 'X':
+		base = 16
+	}
+	if !slices.Contains(bases, base) {
+		return 10
+	}
+	return base
+}
+
+// readSeparatedDigits returns the number of bytes of the digits at the start
+// of the input. Separators are only counted between two digits.
+func readSeparatedDigits[T text](state comb.State, input T, digits string, separator rune) int {
+	n := 0
+	for n < len(input) {
+		if strings.IndexByte(digits, lowerASCII(input[n])) >= 0 {
+			n++
+			continue
+		}
+		r, size := decodeRune(state, input[n:])
+		if separator == 0 || r != separator || n == 0 ||
+			n+size >= len(input) || strings.IndexByte(digits, lowerASCII(input[n+size])) < 0 {
+			break
+		}
+		n += size
+	}
+	return n
+}
+
+// readExponent returns the number of bytes of the decimal exponent at the
+// start of the input or 0 if there is none.
+func readExponent[T text](state comb.State, input T, separator rune) int {
+	if len(input) == 0 || (input[0] != 'e' && input[0] != 'E') {
+		return 0
+	}
+	n := 1
+	if n < len(input) && (input[n] == '+' || input[n] == '-') {
+		n++
+	}
+	m := readSeparatedDigits(state, input[n:], "0123456789", separator)
+	if m == 0 {
+		return 0
+	}
+	return n + m
+}
+
+// continuesDot reports whether the input after a dot makes it part of
+// something else (a range or a selector).
+func continuesDot[T text](state comb.State, input T) bool {
+	r, _ := decodeRune(state, input)
+	return r == '.' || r == '_' || unicode.IsLetter(r)
+}
+
+// text is the input of parsers that work with text and byte input alike.
+type text interface {
+	string | []byte
+}
+
+// decodeRune decodes the first rune of text or byte input.
+// Text is decoded like the state does (see comb.State.DecodeRune).
+func decodeRune[T text](state comb.State, input T) (rune, int) {
+	switch v := any(input).(type) {
+	case string:
+		return state.DecodeRune(v)
+	case []byte:
+		return utf8.DecodeRune(v)
+	}
+	return utf8.RuneError, 0
+}
+
+// hasPrefix is strings.HasPrefix for text or byte input.
+func hasPrefix[T text](input T, prefix string) bool {
+	return len(input) >= len(prefix) && string(input[:len(prefix)]) == prefix
+}
+
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -1,7 +1,10 @@
 package cmb_test
 
 import (
+	"errors"
 	"math"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/flowdev/comb"
@@ -117,6 +120,54 @@ func BenchmarkInt64(b *testing.B) {
 	}
 }
 
+func TestNumberErrorsAreWrapped(t *testing.T) {
+	t.Parallel()
+
+	_, err := comb.RunOnString("9223372036854775808", cmb.Int64(true, 10))
+	if !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("got error %v, want it to wrap strconv.ErrRange", err)
+	}
+
+	errOdd := errors.New("odd number")
+	even := cmb.Map(cmb.Int64(false, 10), func(i int64) (int64, error) {
+		if i%2 != 0 {
+			return i, errOdd
+		}
+		return i, nil
+	})
+	_, err = comb.RunOnString("3", even)
+	if !errors.Is(err, errOdd) {
+		t.Errorf("got error %v, want it to wrap the user error", err)
+	}
+}
+
+func TestNumbersFromBytes(t *testing.T) {
+	t.Parallel()
+
+	state := comb.NewFromBytes([]byte("-0x1f_ff;"), 10)
+	newState, gotInt, err := cmb.Int64(true, 0).Parse(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotInt != -0x1fff || string(newState.CurrentBytes()) != ";" {
+		t.Errorf("got %d with remaining %q, want %d with remaining %q", gotInt, newState.CurrentBytes(), -0x1fff, ";")
+	}
+
+	cfg := cmb.NumberConfig{Signed: true, Floats: cmb.FloatFraction | cmb.FloatExponent, Suffixes: []string{"px"}}
+	newState, gotLit, err := cmb.NumberLiteral(cfg).Parse(comb.NewFromBytes([]byte("+1.5e3px "), 10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantLit := cmb.NumberLit{Raw: "+1.5e3px", Base: 10, IsFloat: true, Suffix: "px"}
+	if gotLit != wantLit || string(newState.CurrentBytes()) != " " {
+		t.Errorf("got %+v with remaining %q, want %+v with remaining %q", gotLit, newState.CurrentBytes(), wantLit, " ")
+	}
+
+	if _, _, err = cmb.Int64(false, 10).Parse(comb.NewFromBytes([]byte("x1"), 10)); err == nil {
+		t.Errorf("expected an error for a non-digit")
+	}
+}
+
 func TestUInt64(t *testing.T) {
 	t.Parallel()
 
@@ -194,6 +245,95 @@ func BenchmarkUInt64(b *testing.B) {
 	}
 }
 
+func TestInt(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		parse      func(input string) (int64, error)
+		input      string
+		wantErr    bool
+		wantOutput int64
+	}{
+		{
+			name:       "int8 minimum should succeed",
+			parse:      parseInt[int8](true, 0),
+			input:      "-0x80",
+			wantOutput: math.MinInt8,
+		}, {
+			name:       "int8 overflow should fail",
+			parse:      parseInt[int8](true, 10),
+			input:      "128",
+			wantErr:    true,
+			wantOutput: math.MaxInt8,
+		}, {
+			name:       "uint8 maximum should succeed",
+			parse:      parseInt[uint8](false, 16),
+			input:      "ff",
+			wantOutput: math.MaxUint8,
+		}, {
+			name:       "uint16 overflow should fail",
+			parse:      parseInt[uint16](false, 10),
+			input:      "65536",
+			wantErr:    true,
+			wantOutput: math.MaxUint16,
+		}, {
+			name:       "uint32 with plus sign should succeed",
+			parse:      parseInt[uint32](true, 10),
+			input:      "+42",
+			wantOutput: 42,
+		}, {
+			name:    "uint32 with minus sign should fail",
+			parse:   parseInt[uint32](true, 10),
+			input:   "-42",
+			wantErr: true,
+		}, {
+			name:       "int32 binary should succeed",
+			parse:      parseInt[int32](false, 2),
+			input:      "101",
+			wantOutput: 5,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, gotErr := tc.parse(tc.input)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+			if gotOutput != tc.wantOutput {
+				t.Errorf("got output %d, want output %d", gotOutput, tc.wantOutput)
+			}
+		})
+	}
+}
+
+func TestIntOverflowMessage(t *testing.T) {
+	t.Parallel()
+
+	_, err := comb.RunOnString("300", cmb.Int[uint8](false, 10))
+	if !errors.Is(err, strconv.ErrRange) {
+		t.Errorf("got error %v, want it to wrap strconv.ErrRange", err)
+	}
+	if want := "300 is out of range for uint8: value out of range"; !strings.Contains(err.Error(), want) {
+		t.Errorf("got error %q, want it to contain %q", err, want)
+	}
+}
+
+// parseInt runs Int[T] and converts the output for easy comparison.
+func parseInt[T int8 | uint8 | uint16 | int32 | uint32](signAllowed bool, base int) func(string) (int64, error) {
+	return func(input string) (int64, error) {
+		_, out, err := cmb.Int[T](signAllowed, base).Parse(comb.NewFromString(input, 10))
+		if err != nil {
+			return int64(out), err
+		}
+		return int64(out), nil
+	}
+}
+
 func TestFloat64(t *testing.T) {
 	t.Parallel()
 
@@ -295,3 +435,130 @@ func BenchmarkFloat64(b *testing.B) {
 		_, _, _ = parser.Parse(input)
 	}
 }
+
+func TestNumberLiteral(t *testing.T) {
+	t.Parallel()
+
+	rich := cmb.NumberConfig{
+		Signed:    true,
+		Bases:     []int{2, 8, 16},
+		Separator: '_',
+		Floats:    cmb.FloatFraction | cmb.FloatLeadingDot | cmb.FloatExponent,
+		Suffixes:  []string{"u8", "u16", "f32", "%", "px", "em"},
+	}
+	testCases := []struct {
+		name          string
+		cfg           cmb.NumberConfig
+		input         string
+		wantErr       bool
+		wantOutput    cmb.NumberLit
+		wantRemaining string
+	}{
+		{
+			name:          "plain decimal integer should succeed",
+			cfg:           cmb.NumberConfig{},
+			input:         "123abc",
+			wantOutput:    cmb.NumberLit{Raw: "123", Base: 10},
+			wantRemaining: "abc",
+		}, {
+			name:          "sign should only be allowed if configured",
+			cfg:           cmb.NumberConfig{},
+			input:         "-1",
+			wantErr:       true,
+			wantRemaining: "-1",
+		}, {
+			name:          "prefix of a base that isn't allowed should stop after the zero",
+			cfg:           cmb.NumberConfig{},
+			input:         "0x1f",
+			wantOutput:    cmb.NumberLit{Raw: "0", Base: 10},
+			wantRemaining: "x1f",
+		}, {
+			name:          "hexadecimal integer with separators and suffix should succeed",
+			cfg:           rich,
+			input:         "-0xFF_ffu16;",
+			wantOutput:    cmb.NumberLit{Raw: "-0xFF_ffu16", Base: 16, Suffix: "u16"},
+			wantRemaining: ";",
+		}, {
+			name:          "binary integer should succeed",
+			cfg:           rich,
+			input:         "0b1012",
+			wantOutput:    cmb.NumberLit{Raw: "0b101", Base: 2},
+			wantRemaining: "2",
+		}, {
+			name:          "prefix without digits should fail",
+			cfg:           rich,
+			input:         "0o9",
+			wantErr:       true,
+			wantRemaining: "0o9",
+		}, {
+			name:          "separator at the end should not be consumed",
+			cfg:           rich,
+			input:         "1_000_",
+			wantOutput:    cmb.NumberLit{Raw: "1_000", Base: 10},
+			wantRemaining: "_",
+		}, {
+			name:          "float with exponent and suffix should succeed",
+			cfg:           rich,
+			input:         "+1.5e-3f32",
+			wantOutput:    cmb.NumberLit{Raw: "+1.5e-3f32", Base: 10, IsFloat: true, Suffix: "f32"},
+			wantRemaining: "",
+		}, {
+			name:          "float with leading dot should succeed",
+			cfg:           rich,
+			input:         ".5%",
+			wantOutput:    cmb.NumberLit{Raw: ".5%", Base: 10, IsFloat: true, Suffix: "%"},
+			wantRemaining: "",
+		}, {
+			name:          "exponent without digits should be left for the suffix",
+			cfg:           rich,
+			input:         "2em",
+			wantOutput:    cmb.NumberLit{Raw: "2em", Base: 10, Suffix: "em"},
+			wantRemaining: "",
+		}, {
+			name:          "trailing dot should only be consumed if configured",
+			cfg:           rich,
+			input:         "1.",
+			wantOutput:    cmb.NumberLit{Raw: "1", Base: 10},
+			wantRemaining: ".",
+		}, {
+			name:          "trailing dot should succeed",
+			cfg:           cmb.NumberConfig{Floats: cmb.FloatTrailingDot},
+			input:         "1. ",
+			wantOutput:    cmb.NumberLit{Raw: "1.", Base: 10, IsFloat: true},
+			wantRemaining: " ",
+		}, {
+			name:          "trailing dot of a range should not be consumed",
+			cfg:           cmb.NumberConfig{Floats: cmb.FloatTrailingDot},
+			input:         "1..2",
+			wantOutput:    cmb.NumberLit{Raw: "1", Base: 10},
+			wantRemaining: "..2",
+		}, {
+			name:          "dot alone should fail",
+			cfg:           rich,
+			input:         ".x",
+			wantErr:       true,
+			wantRemaining: ".x",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotResult, gotErr := cmb.NumberLiteral(tc.cfg).Parse(comb.NewFromString(tc.input, 10))
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", gotErr, tc.wantErr)
+			}
+
+			if gotResult != tc.wantOutput {
+				t.Errorf("got output %#v, want output %#v", gotResult, tc.wantOutput)
+			}
+
+			remainingString := newState.CurrentString()
+			if remainingString != tc.wantRemaining {
+				t.Errorf("got remaining %q, want remaining %q", remainingString, tc.wantRemaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package cmb
+
+import (
+	"strings"
+
+	"github.com/flowdev/comb"
+)
+
+// Permutation applies all parsers exactly once but in any order.
+// The output contains the outputs of the parsers in the order of the
+// arguments (not of the input).
+// Parsers that accept empty input (e.g. wrapped in Optional or Maybe)
+// are only applied without consuming input after all others.
+// So they can be left out of the input.
+//
+// NOTE:
+//   - Even though Permutation accepts parsers as arguments, it behaves like a leaf parser
+//     to the outside world. Errors of the sub-parsers look as if coming from Permutation itself.
+//   - There is no optimized recoverer.
+func Permutation[Output any](parsers ...comb.Parser[Output]) comb.Parser[[]Output] {
+	var p comb.Parser[[]Output]
+
+	if len(parsers) == 0 {
+		panic("Permutation is unable to handle missing parsers")
+	}
+	names := make([]string, len(parsers))
+	for i, parser := range parsers {
+		names[i] = parser.Expected()
+	}
+	expected := "permutation of " + strings.Join(names, ", ")
+
+	parse := func(state comb.State) (comb.State, []Output, *comb.ParserError) {
+		outs := make([]Output, len(parsers))
+		done := make([]bool, len(parsers))
+		current := state
+		for found := true; found; {
+			found = false
+			for i, parser := range parsers {
+				if done[i] {
+					continue
+				}
+				nState, aOut, err := parser.ParseAny(comb.ParentUnknown, current)
+				if err != nil && err.Fatal() {
+					return state, outs, comb.ClaimError(err)
+				}
+				if err == nil && nState.Moved(current) {
+					outs[i], _ = aOut.(Output)
+					done[i] = true
+					current = nState
+					found = true
+					break
+				}
+			}
+		}
+		for i, parser := range parsers { // the rest has to accept empty input
+			if done[i] {
+				continue
+			}
+			nState, aOut, err := parser.ParseAny(comb.ParentUnknown, current)
+			if err != nil {
+				return state, outs, comb.ClaimError(err)
+			}
+			outs[i], _ = aOut.(Output)
+			current = nState
+		}
+		return current, outs, nil
+	}
+
+	p = comb.NewParser[[]Output](expected, parse, nil)
+	return p
+}
@@ -0,0 +1,66 @@
+package cmb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermutation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		parser        comb.Parser[[]string]
+		input         string
+		wantErr       bool
+		wantOutput    []string
+		wantRemaining string
+	}{
+		{
+			name:          "parsers in order should succeed",
+			parser:        cmb.Permutation(cmb.String("a"), cmb.String("b"), cmb.String("c")),
+			input:         "abcd",
+			wantOutput:    []string{"a", "b", "c"},
+			wantRemaining: "d",
+		}, {
+			name:          "parsers in any order should succeed",
+			parser:        cmb.Permutation(cmb.String("a"), cmb.String("b"), cmb.String("c")),
+			input:         "cabd",
+			wantOutput:    []string{"a", "b", "c"},
+			wantRemaining: "d",
+		}, {
+			name:          "optional parsers can be left out",
+			parser:        cmb.Permutation(cmb.String("a"), cmb.Optional(cmb.String("b")), cmb.String("c")),
+			input:         "cad",
+			wantOutput:    []string{"a", "", "c"},
+			wantRemaining: "d",
+		}, {
+			name:    "missing parser should fail",
+			parser:  cmb.Permutation(cmb.String("a"), cmb.String("b"), cmb.String("c")),
+			input:   "cad",
+			wantErr: true,
+		}, {
+			name:    "parsers shouldn't match twice",
+			parser:  cmb.Permutation(cmb.String("a"), cmb.String("b")),
+			input:   "aab",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotOutput, err := tc.parser.Parse(comb.NewFromString(tc.input, 10))
+			assert.Equal(t, tc.wantErr, err != nil, "error: %v", err)
+			if err == nil {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+				assert.Equal(t, tc.wantRemaining, newState.CurrentString())
+			}
+		})
+	}
+}
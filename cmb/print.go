@@ -0,0 +1,143 @@
+package cmb
+
+import (
+	"reflect"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Printers (Parsers With Inverse)
+//
+
+// Verbatim turns a parser of strings into a printer that prints the output as is.
+// This is correct for parsers that return the consumed input (like String or Digit1).
+func Verbatim(parser comb.Parser[string]) comb.Printer[string] {
+	return comb.WithPrinter(parser, func(out string, buf *comb.PrintBuffer) error {
+		buf.WriteString(out)
+		return nil
+	})
+}
+
+// MapPrinter is like Map, but it can print the mapped output, too.
+// `inverse` has to undo the work of `fn`.
+func MapPrinter[PO any, MO any](
+	parse comb.Printer[PO], fn func(PO) (MO, error), inverse func(MO) (PO, error),
+) comb.Printer[MO] {
+	if inverse == nil {
+		panic("MapPrinter: inverse is nil")
+	}
+	return comb.WithPrinter(Map(parse, fn), func(out MO, buf *comb.PrintBuffer) error {
+		pOut, err := inverse(out)
+		if err != nil {
+			return err
+		}
+		return parse.Print(pOut, buf)
+	})
+}
+
+// Map2Printer is like Map2, but it can print the mapped output, too.
+// `inverse` has to undo the work of `fn`.
+func Map2Printer[PO1, PO2 any, MO any](
+	parse1 comb.Printer[PO1], parse2 comb.Printer[PO2],
+	fn func(PO1, PO2) (MO, error), inverse func(MO) (PO1, PO2, error),
+) comb.Printer[MO] {
+	if inverse == nil {
+		panic("Map2Printer: inverse is nil")
+	}
+	return comb.WithPrinter(Map2(parse1, parse2, fn), func(out MO, buf *comb.PrintBuffer) error {
+		out1, out2, err := inverse(out)
+		if err != nil {
+			return err
+		}
+		if err = parse1.Print(out1, buf); err != nil {
+			return err
+		}
+		return parse2.Print(out2, buf)
+	})
+}
+
+// Map3Printer is like Map3, but it can print the mapped output, too.
+// `inverse` has to undo the work of `fn`.
+func Map3Printer[PO1, PO2, PO3 any, MO any](
+	parse1 comb.Printer[PO1], parse2 comb.Printer[PO2], parse3 comb.Printer[PO3],
+	fn func(PO1, PO2, PO3) (MO, error), inverse func(MO) (PO1, PO2, PO3, error),
+) comb.Printer[MO] {
+	if inverse == nil {
+		panic("Map3Printer: inverse is nil")
+	}
+	return comb.WithPrinter(Map3(parse1, parse2, parse3, fn), func(out MO, buf *comb.PrintBuffer) error {
+		out1, out2, out3, err := inverse(out)
+		if err != nil {
+			return err
+		}
+		if err = parse1.Print(out1, buf); err != nil {
+			return err
+		}
+		if err = parse2.Print(out2, buf); err != nil {
+			return err
+		}
+		return parse3.Print(out3, buf)
+	})
+}
+
+// Many0Printer is like Many0, but it can print the outputs, too.
+func Many0Printer[Output any](parse comb.Printer[Output]) comb.Printer[[]Output] {
+	return comb.WithPrinter(Many0[Output](parse), func(outs []Output, buf *comb.PrintBuffer) error {
+		for _, out := range outs {
+			if err := parse.Print(out, buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Separated0Printer is like Separated0 (without a separator at the end),
+// but it can print the outputs, too.
+// `sep` is printed between the outputs.
+func Separated0Printer[Output any, S comb.Separator](
+	parse comb.Printer[Output], separator comb.Printer[S], sep S,
+) comb.Printer[[]Output] {
+	return comb.WithPrinter(Separated0[Output, S](parse, separator, false), func(outs []Output, buf *comb.PrintBuffer) error {
+		for i, out := range outs {
+			if i > 0 {
+				if err := separator.Print(sep, buf); err != nil {
+					return err
+				}
+			}
+			if err := parse.Print(out, buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Spanned is an output together with the span of the input it was parsed from.
+type Spanned[Output any] struct {
+	Value  Output
+	Span   comb.Span
+	parsed Output // value right after parsing
+	valid  bool   // Span and parsed are valid
+}
+
+// Original makes a printer that is biased to the original formatting.
+// If the value didn't change since parsing (according to reflect.DeepEqual)
+// and the original input is known (see comb.Reprint), the original input
+// of the span is printed.
+// Otherwise, the value is printed with the given printer.
+func Original[Output any](parse comb.Printer[Output]) comb.Printer[Spanned[Output]] {
+	p := MapWithSpan(parse, func(out Output, span comb.Span, _ comb.State) (Spanned[Output], error) {
+		return Spanned[Output]{Value: out, Span: span, parsed: out, valid: true}, nil
+	})
+	return comb.WithPrinter(p, func(out Spanned[Output], buf *comb.PrintBuffer) error {
+		if out.valid && reflect.DeepEqual(out.Value, out.parsed) {
+			if text, ok := buf.OriginalText(out.Span); ok {
+				buf.WriteString(text)
+				return nil
+			}
+		}
+		return parse.Print(out.Value, buf)
+	})
+}
@@ -0,0 +1,88 @@
+package cmb_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+type keyValue struct {
+	Key   string
+	Value int
+}
+
+func keyValueList() comb.Printer[[]cmb.Spanned[keyValue]] {
+	concat := func(a, b, c string) (string, error) {
+		return a + b + c, nil
+	}
+	item := cmb.Map3Printer(
+		cmb.Verbatim(cmb.Alpha1()),
+		cmb.Verbatim(cmb.Map3(cmb.Whitespace0(), cmb.String("="), cmb.Whitespace0(), concat)),
+		cmb.MapPrinter(cmb.Verbatim(cmb.Digit1()), strconv.Atoi, func(i int) (string, error) {
+			return strconv.Itoa(i), nil
+		}),
+		func(key, _ string, value int) (keyValue, error) {
+			return keyValue{Key: key, Value: value}, nil
+		},
+		func(kv keyValue) (string, string, int, error) {
+			return kv.Key, "=", kv.Value, nil
+		},
+	)
+	separator := cmb.Verbatim(cmb.Map3(cmb.Whitespace0(), cmb.String(","), cmb.Whitespace0(), concat))
+	return cmb.Separated0Printer(cmb.Original(item), separator, ", ")
+}
+
+func TestPrinter(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		input       string
+		wantPrint   string
+		wantSame    string // reprint without changes
+		wantReprint string // after changing the value of the second item to 5
+	}{
+		{
+			name:        "printing a formatted list should keep unchanged items",
+			input:       "a = 1,b= 22 ,c=3",
+			wantPrint:   "a=1, b=22, c=3",
+			wantSame:    "a = 1, b= 22, c=3",
+			wantReprint: "a = 1, b=5, c=3",
+		}, {
+			name:        "printing a compact list should normalize separators",
+			input:       "x=1,y=2",
+			wantPrint:   "x=1, y=2",
+			wantSame:    "x=1, y=2",
+			wantReprint: "x=1, y=5",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := keyValueList()
+			out, err := comb.RunOnString(tc.input, p)
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+
+			gotPrint, err := comb.Print(p, out)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantPrint, gotPrint)
+
+			gotReprint, err := comb.Reprint(tc.input, p, out)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantSame, gotReprint)
+
+			out[1].Value.Value = 5
+			gotReprint, err = comb.Reprint(tc.input, p, out)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantReprint, gotReprint)
+		})
+	}
+}
@@ -3,7 +3,10 @@ package cmb
 import (
 	"bytes"
 	"reflect"
+	"regexp"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/flowdev/comb"
 )
@@ -29,7 +32,7 @@ func IndexOf[S comb.Separator](stop S) comb.Recoverer {
 	case reflect.Uint8:
 		xstop := interface{}(stop).(byte)
 		return func(state comb.State, _ interface{}) (int, interface{}) {
-			waste := bytes.IndexByte(state.CurrentBytes(), xstop)
+			waste := state.IndexByte(xstop)
 			if waste < 0 {
 				return comb.RecoverWasteTooMuch, nil
 			}
@@ -37,8 +40,16 @@ func IndexOf[S comb.Separator](stop S) comb.Recoverer {
 		}
 	case reflect.Int32:
 		rstop := interface{}(stop).(rune)
+		if rstop < utf8.RuneSelf {
+			return IndexOf(byte(rstop))
+		}
 		return func(state comb.State, _ interface{}) (int, interface{}) {
-			waste := strings.IndexRune(state.CurrentString(), rstop)
+			waste := -1
+			if !state.IsLatin1() {
+				waste = strings.IndexRune(state.CurrentString(), rstop)
+			} else if rstop <= 0xFF {
+				waste = state.IndexByte(byte(rstop))
+			}
 			if waste < 0 {
 				return comb.RecoverWasteTooMuch, nil
 			}
@@ -50,7 +61,11 @@ func IndexOf[S comb.Separator](stop S) comb.Recoverer {
 			panic("stop is empty")
 		}
 		return func(state comb.State, _ interface{}) (int, interface{}) {
-			waste := strings.Index(state.CurrentString(), sstop)
+			stop, ok := state.EncodeString(sstop)
+			if !ok {
+				return comb.RecoverWasteTooMuch, nil
+			}
+			waste := state.Index(stop)
 			if waste < 0 {
 				return comb.RecoverWasteTooMuch, nil
 			}
@@ -61,8 +76,9 @@ func IndexOf[S comb.Separator](stop S) comb.Recoverer {
 		if len(bstop) == 0 {
 			panic("stop is empty")
 		}
+		sstop := string(bstop)
 		return func(state comb.State, _ interface{}) (int, interface{}) {
-			waste := bytes.Index(state.CurrentBytes(), bstop)
+			waste := state.Index(sstop)
 			if waste < 0 {
 				return comb.RecoverWasteTooMuch, nil
 			}
@@ -73,6 +89,29 @@ func IndexOf[S comb.Separator](stop S) comb.Recoverer {
 	}
 }
 
+// indexNormalized returns a recoverer that searches the tokens in the
+// normalized input if the state has a normalizer (see comb.State.WithNormalizer).
+// Otherwise, `recoverer` is used.
+func indexNormalized(recoverer comb.Recoverer, tokens ...string) comb.Recoverer {
+	return func(state comb.State, data interface{}) (int, interface{}) {
+		if state.Normalizer() == nil || state.IsLatin1() { // MatchNormalized doesn't normalize in Latin-1 mode
+			return recoverer(state, data)
+		}
+		for current := state; ; {
+			for _, token := range tokens {
+				if n, _ := current.MatchNormalized(token); n >= 0 {
+					return state.ByteCount(current), nil
+				}
+			}
+			if current.AtEnd() {
+				return comb.RecoverWasteTooMuch, nil
+			}
+			_, size := current.CurrentRune()
+			current = current.MoveBy(max(size, 1))
+		}
+	}
+}
+
 // IndexOfAny searches until it finds a stop token in the input.
 // If found, the recoverer returns the number of bytes up to the stop.
 // If no stop token could be found, the recoverer returns comb.RecoverWasteTooMuch.
@@ -89,6 +128,7 @@ func IndexOfAny[S comb.Separator](stops ...S) comb.Recoverer {
 		modeString
 	)
 	var mode int
+	var byteSet string // all stops as bytes (only if they are all single bytes)
 	n := len(stops)
 
 	if n == 0 {
@@ -101,8 +141,17 @@ func IndexOfAny[S comb.Separator](stops ...S) comb.Recoverer {
 	switch v := reflect.ValueOf(stops[0]); v.Kind() {
 	case reflect.Uint8:
 		mode = modeByte
+		byteSet = string(interface{}(stops).([]byte))
 	case reflect.Int32:
 		mode = modeRune
+		rstops := interface{}(stops).([]rune)
+		if !slices.ContainsFunc(rstops, func(r rune) bool { return r >= utf8.RuneSelf }) {
+			bset := make([]byte, len(rstops))
+			for i, r := range rstops {
+				bset[i] = byte(r)
+			}
+			byteSet = string(bset)
+		}
 	case reflect.String:
 		mode = modeString
 	case reflect.Slice:
@@ -112,24 +161,19 @@ func IndexOfAny[S comb.Separator](stops ...S) comb.Recoverer {
 	}
 
 	indexOfOneOfByte := func(state comb.State, _ interface{}) (int, interface{}) {
-		input := state.CurrentBytes()
-		xstops := interface{}(stops).([]byte)
-		pos := comb.RecoverWasteTooMuch
-		for i := 0; i < n; i++ {
-			switch j := bytes.IndexByte(input, xstops[i]); j {
-			case -1: // ignore
-			case 0: // it won't get better than this
-				return 0, nil
-			default:
-				if pos < 0 || j < pos {
-					pos = j
-				}
-			}
-		}
-		return pos, nil
+		return state.IndexAnyByte(byteSet), nil
 	}
 	indexOfOneOfRune := func(state comb.State, _ interface{}) (int, interface{}) {
-		return strings.IndexAny(state.CurrentString(), string(interface{}(stops).([]rune))), nil
+		if byteSet != "" {
+			return state.IndexAnyByte(byteSet), nil
+		}
+		rstops := interface{}(stops).([]rune)
+		if state.IsLatin1() {
+			return indexFunc(state, state.CurrentString(), func(r rune) bool {
+				return slices.Contains(rstops, r)
+			}), nil
+		}
+		return strings.IndexAny(state.CurrentString(), string(rstops)), nil
 	}
 	indexOfOneOfBytes := func(state comb.State, _ interface{}) (int, interface{}) {
 		input := state.CurrentBytes()
@@ -149,11 +193,14 @@ func IndexOfAny[S comb.Separator](stops ...S) comb.Recoverer {
 		return pos, nil
 	}
 	indexOfOneOfString := func(state comb.State, _ interface{}) (int, interface{}) {
-		input := state.CurrentString()
 		sstops := interface{}(stops).([]string)
 		pos := comb.RecoverWasteTooMuch
 		for i := 0; i < n; i++ {
-			switch j := strings.Index(input, sstops[i]); j {
+			stop, ok := state.EncodeString(sstops[i])
+			if !ok {
+				continue
+			}
+			switch j := state.Index(stop); j {
 			case -1: // ignore
 			case 0: // it won't get better than this
 				return 0, nil
@@ -176,3 +223,79 @@ func IndexOfAny[S comb.Separator](stops ...S) comb.Recoverer {
 		return indexOfOneOfString
 	}
 }
+
+// RecoverAny combines recoverers.
+// The resulting recoverer returns the minimal waste of all given recoverers.
+// If all of them return comb.RecoverNever, it returns comb.RecoverNever, too.
+// The data is handed to the recoverers as `[]interface{}` with one element per recoverer.
+// This function panics during the construction phase if no recoverers are provided.
+func RecoverAny(recoverers ...comb.Recoverer) comb.Recoverer {
+	n := len(recoverers)
+	if n == 0 {
+		panic("no recoverers provided")
+	}
+
+	return func(state comb.State, data interface{}) (int, interface{}) {
+		datas, _ := data.([]interface{})
+		if len(datas) != n {
+			datas = make([]interface{}, n)
+		}
+		minWaste := comb.RecoverNever
+		for i, rec := range recoverers {
+			var waste int
+			waste, datas[i] = rec(state, datas[i])
+			switch {
+			case waste == 0: // it won't get better than this
+				return 0, datas
+			case waste > 0:
+				if minWaste < 0 || waste < minWaste {
+					minWaste = waste
+				}
+			case waste != comb.RecoverNever && minWaste == comb.RecoverNever:
+				minWaste = comb.RecoverWasteTooMuch
+			}
+		}
+		return minWaste, datas
+	}
+}
+
+// RecoverToString searches until it finds the stop string in the input.
+// It is the same as IndexOf for strings.
+// This function panics during the construction phase if `stop` is empty.
+func RecoverToString(stop string) comb.Recoverer {
+	return IndexOf(stop)
+}
+
+// RecoverToRegexp searches until it finds a match of the regular expression in the input.
+// If found, the Recoverer returns the number of bytes up to the match.
+// If no match could be found, the recoverer returns comb.RecoverWasteTooMuch.
+// This function panics during the construction phase if `re` is nil.
+func RecoverToRegexp(re *regexp.Regexp) comb.Recoverer {
+	if re == nil {
+		panic("regular expression is nil")
+	}
+	return func(state comb.State, _ interface{}) (int, interface{}) {
+		loc := re.FindStringIndex(state.CurrentString())
+		if loc == nil {
+			return comb.RecoverWasteTooMuch, nil
+		}
+		return loc[0], nil
+	}
+}
+
+// RecoverToLineEnd searches until it finds the end of the current line
+// ("\n", "\r\n" or the end of the input).
+// The Recoverer returns the number of bytes up to the line end.
+func RecoverToLineEnd() comb.Recoverer {
+	return func(state comb.State, _ interface{}) (int, interface{}) {
+		input := state.CurrentString()
+		waste := strings.IndexByte(input, '\n')
+		if waste < 0 {
+			return len(input), nil
+		}
+		if waste > 0 && input[waste-1] == '\r' {
+			waste--
+		}
+		return waste, nil
+	}
+}
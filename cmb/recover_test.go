@@ -0,0 +1,84 @@
+package cmb_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+func TestRecoverers(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		recoverer comb.Recoverer
+		input     string
+		wantWaste int
+	}{
+		{
+			name:      "RecoverToString should find the stop",
+			recoverer: cmb.RecoverToString("end"),
+			input:     "abc end",
+			wantWaste: 4,
+		}, {
+			name:      "RecoverToString should fail without stop",
+			recoverer: cmb.RecoverToString("end"),
+			input:     "abc",
+			wantWaste: comb.RecoverWasteTooMuch,
+		}, {
+			name:      "RecoverToRegexp should find the match",
+			recoverer: cmb.RecoverToRegexp(regexp.MustCompile(`[0-9]+`)),
+			input:     "abc 123",
+			wantWaste: 4,
+		}, {
+			name:      "RecoverToRegexp should fail without match",
+			recoverer: cmb.RecoverToRegexp(regexp.MustCompile(`[0-9]+`)),
+			input:     "abc",
+			wantWaste: comb.RecoverWasteTooMuch,
+		}, {
+			name:      "RecoverToLineEnd should find a newline",
+			recoverer: cmb.RecoverToLineEnd(),
+			input:     "abc\ndef",
+			wantWaste: 3,
+		}, {
+			name:      "RecoverToLineEnd should find a CRLF",
+			recoverer: cmb.RecoverToLineEnd(),
+			input:     "abc\r\ndef",
+			wantWaste: 3,
+		}, {
+			name:      "RecoverToLineEnd should find the end of the input",
+			recoverer: cmb.RecoverToLineEnd(),
+			input:     "abc",
+			wantWaste: 3,
+		}, {
+			name:      "RecoverAny should find the minimal waste",
+			recoverer: cmb.RecoverAny(cmb.RecoverToString(";"), cmb.Forbidden(), cmb.RecoverToString(",")),
+			input:     "ab,c;",
+			wantWaste: 2,
+		}, {
+			name:      "RecoverAny should fail if all fail",
+			recoverer: cmb.RecoverAny(cmb.RecoverToString(";"), cmb.Forbidden()),
+			input:     "abc",
+			wantWaste: comb.RecoverWasteTooMuch,
+		}, {
+			name:      "RecoverAny should never recover if all are forbidden",
+			recoverer: cmb.RecoverAny(cmb.Forbidden(), cmb.Forbidden()),
+			input:     "abc",
+			wantWaste: comb.RecoverNever,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotWaste, _ := tc.recoverer(comb.NewFromString(tc.input, 10), nil)
+			if gotWaste != tc.wantWaste {
+				t.Errorf("got waste %d, want %d", gotWaste, tc.wantWaste)
+			}
+		})
+	}
+}
@@ -15,28 +15,86 @@ import (
 //
 // The parser will fail if both parsers together accepted an empty input
 // to prevent infinite loops.
+//
+// The number of elements is limited by comb.Limits.MaxElements.
+// Fatal errors of the element or separator parser are never ignored.
 func SeparatedMN[Output any, S comb.Separator](
 	parser comb.Parser[Output], separator comb.Parser[S],
 	atLeast, atMost int,
 	parseSeparatorAtEnd bool,
 ) comb.Parser[[]Output] {
-	if atLeast < 0 {
-		panic("SeparatedMN is unable to handle negative `atLeast`")
+	expected := "SeparatedMN"
+	if separator == nil {
+		expected = "ManyMN"
 	}
-	if atMost < 0 {
-		panic("SeparatedMN is unable to handle negative `atMost`")
+	policy := ForbidTrailing
+	if parseSeparatorAtEnd {
+		policy = AllowTrailing
 	}
+	return newSeparated(expected, parser, separator, atLeast, atMost, policy, false)
+}
 
-	expected := "SeparatedMN"
+// SeparatorPolicy configures the handling of leading and trailing separators
+// by SeparatedList.
+// ForbidTrailing, AllowTrailing and RequireTrailing are exclusive and
+// can be combined with AllowLeading (e.g. `AllowLeading|AllowTrailing`).
+type SeparatorPolicy uint8
+
+// ForbidTrailing makes a separator after the last element an error.
+const ForbidTrailing SeparatorPolicy = 0
+
+const (
+	// AllowTrailing consumes an optional separator after the last element.
+	AllowTrailing SeparatorPolicy = 1 << iota
+	// RequireTrailing makes a missing separator after the last element an error.
+	RequireTrailing
+	// AllowLeading consumes an optional separator before the first element.
+	AllowLeading
+)
+
+// SeparatedList applies an element parser and a separator parser repeatedly in order
+// to produce a slice of elements just like SeparatedMN.
+// But leading and trailing separators are handled according to the policy
+// with targeted error messages (e.g. "unexpected trailing ','").
+//
+// A leading separator is only consumed if an element follows it.
+// Please note that no element after a separator counts as a trailing separator
+// only if at least `atLeast` elements have been parsed already.
+func SeparatedList[Output any, S comb.Separator](
+	parser comb.Parser[Output], separator comb.Parser[S],
+	atLeast, atMost int,
+	policy SeparatorPolicy,
+) comb.Parser[[]Output] {
 	if separator == nil {
-		expected = "ManyMN"
+		panic("SeparatedList is unable to handle a nil `separator`")
+	}
+	if policy&AllowTrailing != 0 && policy&RequireTrailing != 0 {
+		panic("SeparatedList is unable to handle both AllowTrailing and RequireTrailing")
 	}
+	return newSeparated("SeparatedList", parser, separator, atLeast, atMost, policy, true)
+}
+
+func newSeparated[Output any, S comb.Separator](
+	expected string,
+	parser comb.Parser[Output], separator comb.Parser[S],
+	atLeast, atMost int,
+	policy SeparatorPolicy,
+	strict bool,
+) comb.Parser[[]Output] {
+	if atLeast < 0 {
+		panic(expected + " is unable to handle negative `atLeast`")
+	}
+	if atMost < 0 {
+		panic(expected + " is unable to handle negative `atMost`")
+	}
+
 	sd := &separatedData[Output, S]{
-		parser:              parser,
-		separator:           separator,
-		atLeast:             atLeast,
-		atMost:              atMost,
-		parseSeparatorAtEnd: parseSeparatorAtEnd,
+		parser:    parser,
+		separator: separator,
+		atLeast:   atLeast,
+		atMost:    atMost,
+		policy:    policy,
+		strict:    strict,
 	}
 	p := comb.NewBranchParser[[]Output](expected, sd.children, sd.parseAfterChild)
 	sd.id = p.ID
@@ -44,12 +102,13 @@ func SeparatedMN[Output any, S comb.Separator](
 }
 
 type separatedData[Output any, S comb.Separator] struct {
-	id                  func() int32
-	parser              comb.Parser[Output]
-	separator           comb.Parser[S]
-	atLeast             int
-	atMost              int
-	parseSeparatorAtEnd bool
+	id        func() int32
+	parser    comb.Parser[Output]
+	separator comb.Parser[S]
+	atLeast   int
+	atMost    int
+	policy    SeparatorPolicy
+	strict    bool // report policy violations as errors (SeparatedList)
 }
 
 // partialSepResult is internal to the parsing method and methods and functions called by it.
@@ -82,7 +141,7 @@ func (sd *separatedData[Output, S]) parseAfterChild(
 	}
 
 	if childErr != nil {
-		if sd.atLeast > len(partRes.outs) || childStartState.SafeSpotMoved(childState) { // fail
+		if sd.atLeast > len(partRes.outs) || childStartState.SafeSpotMoved(childState) || childErr.Fatal() { // fail
 			return childState, partRes.outs, childErr, partRes
 		}
 		return childState, partRes.outs, nil, nil
@@ -100,22 +159,39 @@ func (sd *separatedData[Output, S]) parseAfterChild(
 		partRes.outs = append(partRes.outs, out)
 		count++
 	}
+	maxElements := childState.Limits().MaxElements
 
 	endState := childState    // state including separator
 	resultState := childState // state for the result (probably without separator)
+	if childID < 0 && sd.strict && sd.policy&AllowLeading != 0 {
+		sepState, _, sepErr := sd.separator.ParseAny(sd.id(), childState)
+		if sepErr != nil && (childState.SafeSpotMoved(sepState) || sepErr.Fatal()) {
+			return sepState, partRes.outs, sepErr, partRes
+		}
+		if sepErr == nil {
+			endState = sepState // resultState stays before the separator until an element follows
+		}
+	}
 	for {
 		if count >= sd.atMost {
 			return resultState, partRes.outs, nil, nil
 		}
+		if maxElements > 0 && count > maxElements {
+			return resultState, partRes.outs, resultState.NewLimitError("number of elements", maxElements), partRes
+		}
 
 		if childID != sd.parser.ID() {
 			childStartState = endState
 			childState, childOut, childErr = sd.parser.ParseAny(sd.id(), childStartState)
 			out, _ := childOut.(Output) // in some rare cases out is important
 			if childErr != nil {
-				if sd.atLeast > count || childStartState.SafeSpotMoved(childState) { // fail
+				if sd.atLeast > count || childStartState.SafeSpotMoved(childState) || childErr.Fatal() { // fail
 					return childState, append(partRes.outs, out), childErr, partRes
 				}
+				if sd.strict && count > 0 && sd.policy&(AllowTrailing|RequireTrailing) == 0 {
+					childErr = resultState.NewSemanticError("unexpected trailing %s", sd.separator.Expected())
+					return endState, partRes.outs, childErr, partRes
+				}
 				return resultState, partRes.outs, nil, nil // ignore error: we have enough output
 			}
 			partRes.outs = append(partRes.outs, out)
@@ -131,13 +207,17 @@ func (sd *separatedData[Output, S]) parseAfterChild(
 			sepState := childState
 			sepState, childOut, childErr = sd.separator.ParseAny(sd.id(), childState)
 			if childErr != nil {
-				if sd.atLeast > count || childState.SafeSpotMoved(sepState) { // fail
+				if sd.atLeast > count || childState.SafeSpotMoved(sepState) || childErr.Fatal() { // fail
 					return sepState, partRes.outs, childErr, partRes
 				}
+				if sd.strict && sd.policy&RequireTrailing != 0 {
+					childErr = childState.NewSyntaxError("trailing %s", sd.separator.Expected())
+					return childState, partRes.outs, childErr, partRes
+				}
 				return childState, partRes.outs, nil, nil // ignore error: we have enough output
 			}
 			endState = sepState
-			if sd.parseSeparatorAtEnd {
+			if sd.policy&(AllowTrailing|RequireTrailing) != 0 {
 				resultState = sepState
 			}
 		}
@@ -1,6 +1,7 @@
 package cmb
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/flowdev/comb"
@@ -76,3 +77,100 @@ func TestSeparatedMN(t *testing.T) {
 		})
 	}
 }
+
+func TestSeparatedList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		policy        SeparatorPolicy
+		input         string
+		wantElements  int
+		wantErr       string
+		wantRemaining string
+	}{
+		{
+			name:         "forbidden trailing separator",
+			policy:       ForbidTrailing,
+			input:        "abc,abc,;",
+			wantElements: 2,
+			wantErr:      "unexpected trailing ','",
+		}, {
+			name:          "forbidden trailing separator is absent",
+			policy:        ForbidTrailing,
+			input:         "abc,abc;",
+			wantElements:  2,
+			wantRemaining: ";",
+		}, {
+			name:          "allowed trailing separator",
+			policy:        AllowTrailing,
+			input:         "abc,abc,;",
+			wantElements:  2,
+			wantRemaining: ";",
+		}, {
+			name:          "allowed trailing separator is absent",
+			policy:        AllowTrailing,
+			input:         "abc,abc;",
+			wantElements:  2,
+			wantRemaining: ";",
+		}, {
+			name:          "required trailing separator",
+			policy:        RequireTrailing,
+			input:         "abc,abc,;",
+			wantElements:  2,
+			wantRemaining: ";",
+		}, {
+			name:         "required trailing separator is missing",
+			policy:       RequireTrailing,
+			input:        "abc,abc;",
+			wantElements: 2,
+			wantErr:      "expected trailing ','",
+		}, {
+			name:          "allowed leading separator",
+			policy:        AllowLeading,
+			input:         ",abc,abc;",
+			wantElements:  2,
+			wantRemaining: ";",
+		}, {
+			name:          "forbidden leading separator",
+			policy:        ForbidTrailing,
+			input:         ",abc,abc;",
+			wantElements:  0,
+			wantRemaining: ",abc,abc;",
+		}, {
+			name:          "allowed leading separator without elements",
+			policy:        AllowLeading | AllowTrailing,
+			input:         ",;",
+			wantElements:  0,
+			wantRemaining: ",;",
+		}, {
+			name:          "allowed leading and trailing separators",
+			policy:        AllowLeading | AllowTrailing,
+			input:         ",abc,;",
+			wantElements:  1,
+			wantRemaining: ";",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // needed for truly different test cases!
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := SeparatedList[string, rune](String("abc"), Char(','), 0, 5, tt.policy)
+			nState, gotOut, err := p.Parse(comb.NewFromString(tt.input, 9))
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("got unexpected error %v", err)
+			} else if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("got error %v, want: %q", err, tt.wantErr)
+			}
+			if tt.wantErr == "" {
+				if got, want := nState.CurrentString(), tt.wantRemaining; got != want {
+					t.Errorf("got remaining input %q, want: %q", got, want)
+				}
+			}
+			if got, want := len(gotOut), tt.wantElements; got != want {
+				t.Errorf("got %d elements, want: %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package cmb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// S-Expressions
+//
+
+// SExprNode is a node of an S-expression: either an atom or a list of nodes.
+type SExprNode[A any] struct {
+	Atom   A              // the atom (only valid if IsList is false)
+	List   []SExprNode[A] // the elements of the list (only valid if IsList is true)
+	IsList bool
+	Span   comb.Span // the input of the node (including the parentheses of lists)
+}
+
+// SExpr parses an S-expression: an atom parsed by `atom` or a list of
+// S-expressions in parentheses separated by white space.
+// The atom parser must not match white space or parentheses.
+//
+// It is an example of a recursive grammar, too:
+// The list refers to the S-expression parser before it is complete.
+// comb.LazyBranchParser works as a forward declaration to break this cycle.
+func SExpr[A any](atom comb.Parser[A]) comb.Parser[SExprNode[A]] {
+	var expr comb.Parser[SExprNode[A]]
+
+	list := MapWithSpan(
+		Between(
+			comb.SafeSpot(Char('(')),
+			Many0(Prefixed(Whitespace0(), comb.LazyBranchParser(func() comb.Parser[SExprNode[A]] {
+				return expr
+			}))),
+			Prefixed(Whitespace0(), Char(')')),
+			0,
+		),
+		func(nodes []SExprNode[A], span comb.Span, _ comb.State) (SExprNode[A], error) {
+			return SExprNode[A]{List: nodes, IsList: true, Span: span}, nil
+		},
+	)
+	atomNode := MapWithSpan(atom, func(a A, span comb.Span, _ comb.State) (SExprNode[A], error) {
+		return SExprNode[A]{Atom: a, Span: span}, nil
+	})
+	expr = FirstSuccessful(list, atomNode)
+	return expr
+}
+
+// String returns the node in a compact form on a single line (e.g. `(+ 1 (* 2 3))`).
+// Atoms are formatted with fmt.Sprint.
+func (n SExprNode[A]) String() string {
+	sb := strings.Builder{}
+	n.writeTo(&sb)
+	return sb.String()
+}
+
+func (n SExprNode[A]) writeTo(sb *strings.Builder) {
+	if !n.IsList {
+		sb.WriteString(fmt.Sprint(n.Atom))
+		return
+	}
+	sb.WriteByte('(')
+	for i, child := range n.List {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		child.writeTo(sb)
+	}
+	sb.WriteByte(')')
+}
+
+// Pretty returns the node pretty-printed so lines don't get longer than
+// `width` if possible.
+// Lists that don't fit on the rest of a line are broken up with one element
+// per line indented by 2 spaces.
+func (n SExprNode[A]) Pretty(width int) string {
+	sb := strings.Builder{}
+	n.prettyTo(&sb, 0, width)
+	return sb.String()
+}
+
+func (n SExprNode[A]) prettyTo(sb *strings.Builder, indent, width int) {
+	compact := n.String()
+	if !n.IsList || len(n.List) == 0 || indent+len(compact) <= width {
+		sb.WriteString(compact)
+		return
+	}
+	sb.WriteByte('(')
+	for i, child := range n.List {
+		if i > 0 {
+			sb.WriteByte('\n')
+			sb.WriteString(strings.Repeat(" ", indent+2))
+		}
+		child.prettyTo(sb, indent+2, width)
+	}
+	sb.WriteByte(')')
+}
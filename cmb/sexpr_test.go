@@ -0,0 +1,87 @@
+package cmb_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSExpr(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[cmb.SExprNode[string]] {
+		atom := cmb.SatisfyMN("atom", 1, math.MaxInt, func(r rune) bool {
+			return r != '(' && r != ')' && r != ' ' && r != '\n'
+		})
+		return cmb.Suffixed(cmb.SExpr(atom), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantString string
+		wantPretty string
+	}{
+		{
+			name:       "atom should succeed",
+			input:      "abc",
+			wantString: "abc",
+			wantPretty: "abc",
+		}, {
+			name:       "empty list should succeed",
+			input:      "( )",
+			wantString: "()",
+			wantPretty: "()",
+		}, {
+			name:       "nested lists should succeed",
+			input:      "(define (square x)\n  (* x x))",
+			wantString: "(define (square x) (* x x))",
+			wantPretty: "(define\n  (square x)\n  (* x x))",
+		}, {
+			name:       "short list should be printed on one line",
+			input:      "(+  1 2 )",
+			wantString: "(+ 1 2)",
+			wantPretty: "(+ 1 2)",
+		}, {
+			name:    "unclosed list should fail",
+			input:   "(a (b c)",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := comb.RunOnString(tc.input, newParser())
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantString, gotOutput.String())
+			assert.Equal(t, tc.wantPretty, gotOutput.Pretty(20))
+			assert.Equal(t, comb.Span{Start: 0, End: len(tc.input)}, gotOutput.Span)
+		})
+	}
+}
+
+func TestSExprSpans(t *testing.T) {
+	t.Parallel()
+
+	atom := cmb.Digit1()
+	got, err := comb.RunOnString("(1 (22))", cmb.SExpr(atom))
+	assert.NoError(t, err)
+	if assert.Len(t, got.List, 2) {
+		assert.Equal(t, "1", got.List[0].Atom)
+		assert.Equal(t, comb.Span{Start: 1, End: 2}, got.List[0].Span)
+		assert.True(t, got.List[1].IsList)
+		assert.Equal(t, comb.Span{Start: 3, End: 7}, got.List[1].Span)
+		assert.Equal(t, comb.Span{Start: 4, End: 6}, got.List[1].List[0].Span)
+	}
+}
@@ -0,0 +1,134 @@
+package cmb
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Skipping Unknown Entries
+//
+
+// SkipUnknown parses the name of an unknown entry (key or section) with
+// `recognizer` and consumes the rest of the entry structurally.
+// This makes forward-compatible config formats easy: entries of newer versions
+// are skipped and reported instead of failing the parse.
+//
+// The rest of the entry ends at the end of the line, at a closing bracket
+// without matching opening bracket (the end of the enclosing section) or
+// at the end of the input.
+// Brackets ('(', '[' and '{') are balanced, so blocks can span multiple lines.
+// Strings in double quotes, single quotes or backticks are skipped as a whole, so brackets
+// and line ends in them don't count.
+// Quotes only start a string at the start of a value (not after a letter, digit or '_'),
+// so apostrophes in words like `note = don't` are plain text.
+// The line end and the unmatched closing bracket aren't consumed.
+//
+// Every skipped entry is reported as a warning (see comb.State.Warn).
+// `reporter` is called with the name and the span of the whole entry
+// after the input has been parsed successfully (see comb.State.Defer).
+// So entries of alternatives that have been given up aren't reported.
+// `reporter` can be nil.
+// The output is the name of the entry.
+//
+// NOTE:
+//   - Even though SkipUnknown accepts a parser as argument, it behaves like a leaf parser
+//     to the outside world. Errors of `recognizer` look as if coming from SkipUnknown itself.
+//   - There is no optimized recoverer.
+func SkipUnknown(recognizer comb.Parser[string], reporter func(name string, span comb.Span)) comb.Parser[string] {
+	expected := "unknown " + recognizer.Expected()
+
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		nState, aName, err := recognizer.ParseAny(comb.ParentUnknown, state)
+		if err != nil {
+			return state, "", comb.ClaimError(err)
+		}
+		name, _ := aName.(string)
+
+		n, err := skipEntry(nState)
+		if err != nil {
+			return state, "", err
+		}
+		nState = nState.Warn("skipping unknown entry %q", name).MoveBy(n)
+		span := state.SpanTo(nState)
+		if reporter != nil {
+			nState = nState.Defer(func() error {
+				reporter(name, span)
+				return nil
+			})
+		}
+		return nState, name, nil
+	}
+
+	return comb.NewParser[string](expected, parse, nil)
+}
+
+// skipEntry returns the number of bytes of the rest of an unknown entry
+// (see SkipUnknown).
+func skipEntry(state comb.State) (int, *comb.ParserError) {
+	const closers = ")]}"
+	const openers = "([{"
+
+	input := state.CurrentString()
+	var stack []byte // expected closing brackets
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '\n' && len(stack) == 0:
+			return i, nil
+		case (c == '"' || c == '\'' || c == '`') && (i == 0 || !isWordByte(input[i-1])):
+			end := skipQuoted(input[i:])
+			if end < 0 {
+				return 0, state.MoveBy(len(input)).NewSyntaxError(
+					"closing %q of string starting at byte %d", c, state.CurrentPos()+i,
+				)
+			}
+			i += end - 1
+		case strings.IndexByte(openers, c) >= 0:
+			stack = append(stack, closers[strings.IndexByte(openers, c)])
+		case strings.IndexByte(closers, c) >= 0:
+			if len(stack) == 0 {
+				return i, nil
+			}
+			if want := stack[len(stack)-1]; c != want {
+				return 0, state.MoveBy(i).NewSyntaxError("%q (got %q)", want, c)
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		return 0, state.MoveBy(len(input)).NewSyntaxError("%q (at EOF)", stack[len(stack)-1])
+	}
+	return len(input), nil
+}
+
+// isWordByte returns true for ASCII letters, digits and '_' and
+// all bytes of non-ASCII runes.
+func isWordByte(c byte) bool {
+	return c == '_' || c >= utf8.RuneSelf ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// skipQuoted returns the length of the quoted string at the start of the input
+// including both quotes or -1 if it isn't closed.
+// Backslashes escape the next character except in backtick strings.
+func skipQuoted(input string) int {
+	quote := input[0]
+	for i := 1; i < len(input); i++ {
+		switch input[i] {
+		case quote:
+			return i + 1
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case '\n':
+			if quote != '`' {
+				return -1
+			}
+		}
+	}
+	return -1
+}
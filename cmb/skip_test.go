@@ -0,0 +1,105 @@
+package cmb_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipUnknown(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		input        string
+		wantErr      bool
+		wantOutput   string
+		wantRest     string
+		wantReported []comb.Span
+	}{
+		{
+			name:         "a simple entry should be skipped until the end of the line",
+			input:        "timeout = 10s\nport = 80",
+			wantOutput:   "timeout",
+			wantRest:     "\nport = 80",
+			wantReported: []comb.Span{{Start: 0, End: 13}},
+		}, {
+			name:         "a block should be skipped as a whole",
+			input:        "limits {\n  cpu = [1, 2]\n}\nport = 80",
+			wantOutput:   "limits",
+			wantRest:     "\nport = 80",
+			wantReported: []comb.Span{{Start: 0, End: 25}},
+		}, {
+			name:         "brackets in strings shouldn't count",
+			input:        `name = "a}b\"{" # ok`,
+			wantOutput:   "name",
+			wantRest:     "",
+			wantReported: []comb.Span{{Start: 0, End: 20}},
+		}, {
+			name:         "apostrophes in words shouldn't start strings",
+			input:        "note = don't [x]\nport = 80",
+			wantOutput:   "note",
+			wantRest:     "\nport = 80",
+			wantReported: []comb.Span{{Start: 0, End: 16}},
+		}, {
+			name:         "the end of the enclosing section should end the entry",
+			input:        "extra: 1}",
+			wantOutput:   "extra",
+			wantRest:     "}",
+			wantReported: []comb.Span{{Start: 0, End: 8}},
+		}, {
+			name:    "an unclosed block should fail",
+			input:   "limits { cpu = 1",
+			wantErr: true,
+		}, {
+			name:    "mismatched brackets should fail",
+			input:   "limits { cpu = [1 }",
+			wantErr: true,
+		}, {
+			name:    "an unclosed string should fail",
+			input:   "name = \"abc\n",
+			wantErr: true,
+		}, {
+			name:    "a missing name should fail",
+			input:   "= 1",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var reported []comb.Span
+			p := cmb.SkipUnknown(cmb.Alpha1(), func(name string, span comb.Span) {
+				assert.Equal(t, tc.wantOutput, name)
+				reported = append(reported, span)
+			})
+			var warnings []error
+			state := comb.NewFromString(tc.input, 0).WithWarningHandler(func(warning error) {
+				warnings = append(warnings, warning)
+			})
+			gotOutput, gotErr := comb.RunOnState(state, comb.NewPreparedParser(
+				cmb.Suffixed(p, cmb.SatisfyMN("rest", 0, math.MaxInt, func(rune) bool { return true })),
+			))
+			if tc.wantErr {
+				assert.Error(t, gotErr)
+				return
+			}
+			if assert.NoError(t, gotErr) {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+				assert.Equal(t, tc.wantReported, reported)
+				if assert.Len(t, warnings, 1) {
+					assert.ErrorContains(t, warnings[0], "skipping unknown entry")
+				}
+			}
+
+			nState, _, _ := p.Parse(comb.NewFromString(tc.input, 0))
+			assert.Equal(t, tc.wantRest, nState.CurrentString())
+		})
+	}
+}
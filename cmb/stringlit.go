@@ -0,0 +1,232 @@
+package cmb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/flowdev/comb"
+)
+
+// StringConfig configures the StringLiteral parser.
+// The zero value allows double-quoted strings on a single line with escapes.
+type StringConfig struct {
+	Quotes    string // allowed quote characters (e.g. "\"'"); the empty string means `"`
+	NoEscapes bool   // a backslash is an ordinary character (raw strings)
+	Multiline bool   // line ends are allowed inside the string
+}
+
+// StringLit is a string literal.
+type StringLit struct {
+	Raw   string // the whole literal including quotes
+	Value string // the content with all escape sequences replaced
+	Quote rune   // the quote character used
+	Start int    // position of the literal in the input
+
+	// Offsets contains the position in the input of every byte of Value.
+	// All bytes produced by an escape sequence have the position of the backslash.
+	Offsets []int
+}
+
+// SourcePos returns the position in the input of the byte at index i of
+// the value.
+// The index len(Value) is the position of the closing quote.
+// So semantic errors inside the string (e.g. bad format verbs) can be reported
+// at the exact source column.
+func (lit StringLit) SourcePos(i int) int {
+	if i >= 0 && i < len(lit.Offsets) {
+		return lit.Offsets[i]
+	}
+	return lit.Start + len(lit.Raw) - utf8.RuneLen(lit.Quote)
+}
+
+// StringLiteral parses a string literal as configured.
+// The escape sequences are `\n`, `\r`, `\t`, `\0`, `\\`, `\'`, `\"`,
+// `\xHH` (a byte), `\uHHHH` and `\u{H...}` (a Unicode code point)
+// and a backslash followed by the quote character.
+// The output keeps the position of every byte of the value in the input
+// (see StringLit.SourcePos).
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+func StringLiteral(cfg StringConfig) comb.Parser[StringLit] {
+	quotes := cfg.Quotes
+	if quotes == "" {
+		quotes = `"`
+	}
+	expected := "string literal"
+
+	parse := func(state comb.State) (comb.State, StringLit, *comb.ParserError) {
+		quote, size := state.CurrentRune()
+		if size == 0 || !strings.ContainsRune(quotes, quote) {
+			if size == 0 {
+				return state, StringLit{}, comb.MarkIncomplete(state.NewSyntaxError(expected))
+			}
+			return state, StringLit{}, state.NewSyntaxError(expected)
+		}
+
+		input := state.CurrentString()
+		start := state.CurrentPos()
+		value := strings.Builder{}
+		var offsets []int
+		for i := size; i < len(input); {
+			r, rsize := state.DecodeRune(input[i:])
+			switch {
+			case r == quote:
+				nState := state.MoveBy(i + rsize)
+				lit := StringLit{Raw: input[:i+rsize], Value: value.String(), Quote: quote, Start: start, Offsets: offsets}
+				return nState, lit, nil
+			case (r == '\n' || r == '\r') && !cfg.Multiline:
+				return state, StringLit{}, state.MoveBy(i).NewSyntaxError("%q to close the %s", quote, expected)
+			case r == '\\' && !cfg.NoEscapes:
+				n0 := value.Len()
+				n, err := unescape(state, input[i:], quote, &value)
+				if err != "" {
+					if i+n >= len(input) {
+						return state, StringLit{}, comb.MarkIncomplete(state.MoveBy(i).NewSyntaxError(err))
+					}
+					return state, StringLit{}, state.MoveBy(i).NewSyntaxError(err)
+				}
+				for range value.Len() - n0 {
+					offsets = append(offsets, start+i)
+				}
+				i += n
+			default:
+				value.WriteString(input[i : i+rsize])
+				for j := range rsize {
+					offsets = append(offsets, start+i+j)
+				}
+				i += rsize
+			}
+		}
+		return state, StringLit{}, comb.MarkIncomplete(
+			state.MoveBy(len(input)).NewSyntaxError("%q to close the %s", quote, expected),
+		)
+	}
+
+	stops := make([]rune, 0, len(quotes))
+	for _, q := range quotes {
+		stops = append(stops, q)
+	}
+	return comb.NewParser[StringLit](expected, parse, IndexOfAny(stops...))
+}
+
+// unescape writes the value of the escape sequence at the start of the input
+// to the value and returns the size of the escape sequence.
+// The error message is non-empty if the escape sequence is invalid.
+func unescape(state comb.State, input string, quote rune, value *strings.Builder) (int, string) {
+	if len(input) < 2 {
+		return len(input), "escape sequence"
+	}
+	switch c := input[1]; c {
+	case 'n':
+		value.WriteByte('\n')
+	case 'r':
+		value.WriteByte('\r')
+	case 't':
+		value.WriteByte('\t')
+	case '0':
+		value.WriteByte(0)
+	case '\\', '\'', '"':
+		value.WriteByte(c)
+	case 'x':
+		if len(input) < 4 {
+			return len(input), "2 hex digits after `\\x`"
+		}
+		b, err := strconv.ParseUint(input[2:4], 16, 8)
+		if err != nil {
+			return 2, "2 hex digits after `\\x`"
+		}
+		value.WriteByte(byte(b))
+		return 4, ""
+	case 'u':
+		digits, n := input[2:], 0
+		if strings.HasPrefix(digits, "{") {
+			end := strings.IndexByte(digits, '}')
+			if end < 0 {
+				return len(input), "'}' to close `\\u{`"
+			}
+			digits, n = digits[1:end], 4+end-1
+		} else {
+			if len(digits) < 4 {
+				return len(input), "4 hex digits after `\\u`"
+			}
+			digits, n = digits[:4], 6
+		}
+		r, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil || digits == "" || !utf8.ValidRune(rune(r)) {
+			return 2, "valid Unicode code point after `\\u`"
+		}
+		value.WriteRune(rune(r))
+		return n, ""
+	default:
+		r, size := state.DecodeRune(input[1:])
+		if r != quote {
+			return 1, "valid escape sequence after '\\'"
+		}
+		value.WriteString(input[1 : 1+size])
+		return 1 + size, ""
+	}
+	return 2, ""
+}
+
+// RawString parses a raw string literal enclosed in fences of the delimiter
+// (e.g. "```" in Markdown).
+// The opening fence has to be at least `minCount` delimiters long and
+// the closing fence has to be exactly as long as the opening one.
+// So shorter or longer runs of the delimiter are part of the content
+// (e.g. "````a ``` b````" has the content "a ``` b").
+// The content can span multiple lines and contains no escape sequences.
+// The output is the content without the fences.
+// This parser is a good candidate for SafeSpot and has an optimized recoverer.
+func RawString(delim rune, minCount int) comb.Parser[string] {
+	if minCount < 1 {
+		panic("RawString is unable to handle `minCount` < 1")
+	}
+	expected := "raw string"
+	if minCount > 1 {
+		expected = fmt.Sprintf("raw string (fence of at least %d %q)", minCount, delim)
+	}
+
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		count, start := fenceLen(state, input, delim)
+		if count < minCount {
+			if start == len(input) {
+				return state, "", comb.MarkIncomplete(state.NewSyntaxError(expected))
+			}
+			return state, "", state.NewSyntaxError(expected)
+		}
+
+		for i := start; i < len(input); {
+			r, size := state.DecodeRune(input[i:])
+			if r != delim {
+				i += size
+				continue
+			}
+			n, end := fenceLen(state, input[i:], delim)
+			if n == count {
+				return state.MoveBy(i + end), input[start:i], nil
+			}
+			i += end
+		}
+		return state, "", comb.MarkIncomplete(state.MoveBy(len(input)).NewSyntaxError(
+			"%q to close the raw string", input[:start],
+		))
+	}
+
+	return comb.NewParser[string](expected, parse, IndexOfAny(delim))
+}
+
+// fenceLen returns the number of delimiters at the start of the input and
+// their size in bytes.
+func fenceLen(state comb.State, input string, delim rune) (count, size int) {
+	for size < len(input) {
+		r, rsize := state.DecodeRune(input[size:])
+		if r != delim {
+			break
+		}
+		count++
+		size += rsize
+	}
+	return count, size
+}
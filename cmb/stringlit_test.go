@@ -0,0 +1,134 @@
+package cmb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringLiteral(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		cfg         cmb.StringConfig
+		input       string
+		wantValue   string
+		wantOffsets []int
+		wantEnd     int
+		wantErr     bool
+	}{
+		{
+			name:        "plain characters should map to their own positions",
+			input:       `x "ab"`,
+			wantValue:   "ab",
+			wantOffsets: []int{3, 4},
+			wantEnd:     5,
+		}, {
+			name:        "escape sequences should map to the backslash",
+			input:       `x "a\tb\u00e4c"`,
+			wantValue:   "a\tbäc",
+			wantOffsets: []int{3, 4, 6, 7, 7, 13},
+			wantEnd:     14,
+		}, {
+			name:        "multi-byte characters should map every byte",
+			cfg:         cmb.StringConfig{Quotes: "'"},
+			input:       "x 'äb'",
+			wantValue:   "äb",
+			wantOffsets: []int{3, 4, 5},
+			wantEnd:     6,
+		}, {
+			name:      "empty strings should have the closing quote only",
+			input:     `x ""`,
+			wantValue: "",
+			wantEnd:   3,
+		}, {
+			name:    "invalid escapes should fail",
+			input:   `x "a\qb"`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, got, err := cmb.StringLiteral(tc.cfg).Parse(comb.NewFromString(tc.input, 10).MoveBy(2))
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tc.wantValue, got.Value)
+			assert.Equal(t, tc.wantOffsets, got.Offsets)
+			for i, want := range tc.wantOffsets {
+				assert.Equal(t, want, got.SourcePos(i))
+			}
+			assert.Equal(t, tc.wantEnd, got.SourcePos(len(got.Value)))
+		})
+	}
+}
+
+func TestRawString(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		minCount      int
+		input         string
+		wantOutput    string
+		wantRemaining string
+		wantErr       string
+	}{
+		{
+			name:          "a single delimiter should work",
+			minCount:      1,
+			input:         "`a\\nb`c",
+			wantOutput:    "a\\nb",
+			wantRemaining: "c",
+		}, {
+			name:          "shorter fences should be content",
+			minCount:      3,
+			input:         "````\na ``` b\n````\nrest",
+			wantOutput:    "\na ``` b\n",
+			wantRemaining: "\nrest",
+		}, {
+			name:          "longer fences should be content",
+			minCount:      1,
+			input:         "``a ``` b``",
+			wantOutput:    "a ``` b",
+			wantRemaining: "",
+		}, {
+			name:     "too short opening fences should fail",
+			minCount: 3,
+			input:    "``a``",
+			wantErr:  "expected raw string (fence of at least 3 '`') [1:1] ▶``a``",
+		}, {
+			name:     "missing closing fences should fail",
+			minCount: 3,
+			input:    "````a```",
+			wantErr:  "expected \"````\" to close the raw string [1:9] ````a```▶",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state, got, err := cmb.RawString('`', tc.minCount).Parse(comb.NewFromString(tc.input, 10))
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error %v", err)
+			}
+			assert.Equal(t, tc.wantOutput, got)
+			assert.Equal(t, tc.wantRemaining, state.CurrentString())
+		})
+	}
+}
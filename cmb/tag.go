@@ -0,0 +1,92 @@
+package cmb
+
+import (
+	"github.com/flowdev/comb"
+)
+
+// Tag is the result of the TagPair parser.
+type Tag[A, T any] struct {
+	Name        string
+	Attrs       A
+	Body        T    // zero value for self-closing tags
+	SelfClosing bool // the tag has been closed with "/>"
+}
+
+// openTag is the opening tag together with its position for error messages.
+type openTag[A any] struct {
+	name        string
+	attrs       A
+	selfClosing bool
+	line, col   int
+}
+
+// TagPair parses an XML-ish tag pair like `<a href="x">...</a>` or
+// a self-closing tag like `<br/>`.
+// The name and the attributes of the opening tag are parsed by `name` and `attrs`.
+// White space is allowed before the closing `>` or `/>` of both tags.
+// The body is parsed by the parser that `bodyFor` returns for the name
+// of the opening tag.
+// So the content can depend on the tag (e.g. raw text inside of `<script>`).
+//
+// The name of the closing tag has to match the name of the opening tag.
+// A mismatch is reported together with the position of the opening tag
+// (e.g.: `expected </a> (got </b>, unclosed <a> opened at 1:1)`).
+// After the opening tag has been parsed, errors can't be avoided by trying
+// other alternatives. So for nested tags the innermost unclosed tag is reported.
+//
+// The body parsers are created at runtime (see comb.Bind).
+// So error recovery only works as usual inside of the opening tag.
+func TagPair[A, T any](name comb.Parser[string], attrs comb.Parser[A], bodyFor func(name string) comb.Parser[T],
+) comb.Parser[Tag[A, T]] {
+	if bodyFor == nil {
+		panic("TagPair: bodyFor is nil")
+	}
+
+	open := MapWithSpan(
+		Map4(Char('<'), name, attrs, Prefixed(Whitespace0(), FirstSuccessful(String("/>"), String(">"))),
+			func(_ rune, name string, attrs A, end string) (openTag[A], error) {
+				return openTag[A]{name: name, attrs: attrs, selfClosing: end == "/>"}, nil
+			},
+		),
+		func(tag openTag[A], span comb.Span, state comb.State) (openTag[A], error) {
+			tag.line, tag.col = state.MoveBackTo(span.Start).LineCol()
+			return tag, nil
+		},
+	)
+	closeTag := Delimited(String("</"), name, Prefixed(Whitespace0(), Char('>')))
+
+	return comb.Bind(open, func(tag openTag[A]) comb.Parser[Tag[A, T]] {
+		result := Tag[A, T]{Name: tag.name, Attrs: tag.attrs, SelfClosing: tag.selfClosing}
+		if tag.selfClosing {
+			return comb.NewParser[Tag[A, T]]("self-closing tag", func(state comb.State) (comb.State, Tag[A, T], *comb.ParserError) {
+				return state, result, nil
+			}, nil)
+		}
+		body := bodyFor(tag.name)
+		if body == nil {
+			return nil
+		}
+		expected := "</" + tag.name + ">"
+		return comb.NewParser[Tag[A, T]](expected, func(state comb.State) (comb.State, Tag[A, T], *comb.ParserError) {
+			bodyState, bodyOut, err := body.ParseAny(comb.ParentUnknown, state)
+			result.Body, _ = bodyOut.(T)
+			if err != nil { // the parents (e.g. the body of an enclosing tag) mustn't hide the error
+				return bodyState.MoveSafeSpot(), result, err
+			}
+			nState, closeOut, err := closeTag.ParseAny(comb.ParentUnknown, bodyState)
+			if err != nil {
+				unclosed := bodyState.NewSyntaxError("%s (unclosed <%s> opened at %d:%d)",
+					expected, tag.name, tag.line, tag.col)
+				if err.Incomplete() {
+					unclosed = comb.MarkIncomplete(unclosed)
+				}
+				return bodyState.MoveSafeSpot(), result, unclosed
+			}
+			if closeName, _ := closeOut.(string); closeName != tag.name {
+				return bodyState.MoveSafeSpot(), result, bodyState.NewSyntaxError("%s (got </%s>, unclosed <%s> opened at %d:%d)",
+					expected, closeName, tag.name, tag.line, tag.col)
+			}
+			return nState, result, nil
+		}, nil)
+	})
+}
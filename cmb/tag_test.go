@@ -0,0 +1,91 @@
+package cmb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagPair(t *testing.T) {
+	t.Parallel()
+
+	attrs := cmb.Many0(cmb.Prefixed(cmb.Whitespace1(), cmb.Alpha1()))
+	bodyFor := func(name string) comb.Parser[string] {
+		switch name {
+		case "script":
+			return cmb.SatisfyMN("script", 0, 1000, func(r rune) bool { return r != '<' })
+		case "a": // mixed content with nested tags
+			nested := cmb.TagPair(cmb.Alpha1(), cmb.Many0(cmb.Prefixed(cmb.Whitespace1(), cmb.Alpha1())),
+				func(string) comb.Parser[string] { return cmb.Alphanumeric0() })
+			return cmb.Map(cmb.Many0(cmb.FirstSuccessful(
+				cmb.Alphanumeric1(),
+				cmb.Map(nested, func(tag cmb.Tag[[]string, string]) (string, error) {
+					return "<" + tag.Name + ">" + tag.Body + "</" + tag.Name + ">", nil
+				}),
+			)), func(parts []string) (string, error) {
+				return strings.Join(parts, ""), nil
+			})
+		}
+		return cmb.Alphanumeric0()
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    string
+		wantOutput cmb.Tag[[]string, string]
+	}{
+		{
+			name:       "matching tags should succeed",
+			input:      "<b>bold</b>",
+			wantOutput: cmb.Tag[[]string, string]{Name: "b", Attrs: []string{}, Body: "bold"},
+		}, {
+			name:       "attributes and white space should succeed",
+			input:      "<a x y >link</a >",
+			wantOutput: cmb.Tag[[]string, string]{Name: "a", Attrs: []string{"x", "y"}, Body: "link"},
+		}, {
+			name:       "body should depend on the name",
+			input:      "<script>a = 1;</script>",
+			wantOutput: cmb.Tag[[]string, string]{Name: "script", Attrs: []string{}, Body: "a = 1;"},
+		}, {
+			name:       "self-closing tag should succeed",
+			input:      "<br />",
+			wantOutput: cmb.Tag[[]string, string]{Name: "br", Attrs: []string{}, SelfClosing: true},
+		}, {
+			name:       "nested tags should succeed",
+			input:      "<a>x<b>y</b>z</a>",
+			wantOutput: cmb.Tag[[]string, string]{Name: "a", Attrs: []string{}, Body: "x<b>y</b>z"},
+		}, {
+			name:    "an unclosed nested tag should be reported",
+			input:   "<a>x<b>y</a>",
+			wantErr: "expected </b> (got </a>, unclosed <b> opened at 1:5)",
+		}, {
+			name:    "mismatched closing tag should fail",
+			input:   "<a>x</b>",
+			wantErr: "expected </a> (got </b>, unclosed <a> opened at 1:1)",
+		}, {
+			name:    "missing closing tag should fail",
+			input:   "\n  <i>x!",
+			wantErr: "expected </i> (unclosed <i> opened at 2:3)",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := cmb.Prefixed(cmb.Whitespace0(), cmb.TagPair(cmb.Alpha1(), attrs, bodyFor))
+			gotOutput, err := comb.RunOnState(comb.NewFromString(tc.input, 0), comb.NewPreparedParser(parser))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantOutput, gotOutput)
+		})
+	}
+}
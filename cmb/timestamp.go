@@ -0,0 +1,294 @@
+package cmb
+
+import (
+	"strings"
+	"time"
+
+	"github.com/flowdev/comb"
+)
+
+// ============================================================================
+// Timestamps
+//
+
+// TimestampMatch is the result of the Timestamp parser.
+type TimestampMatch struct {
+	Time   time.Time
+	Layout string // the layout that matched
+}
+
+// Timestamp parses a timestamp in one of the layouts of the time package
+// (e.g. time.RFC3339 or "2006-01-02 15:04:05.000").
+// The layouts are analyzed in advance, so the input is only given to
+// time.Parse for layouts it fits.
+// If the input fits multiple layouts, the longest match wins and
+// then the layout given first.
+// Time zones are honored as in time.Parse (offsets and zone abbreviations).
+// Timestamps without time zone are in UTC.
+// Timestamp panics if no layout is given.
+func Timestamp(layouts ...string) comb.Parser[TimestampMatch] {
+	var p comb.Parser[TimestampMatch]
+
+	if len(layouts) == 0 {
+		panic("Timestamp: no layouts given")
+	}
+	shapes := make([][]layoutElem, len(layouts))
+	for i, layout := range layouts {
+		shapes[i] = analyzeLayout(layout)
+	}
+
+	expected := "timestamp"
+
+	parse := func(state comb.State) (comb.State, TimestampMatch, *comb.ParserError) {
+		input := state.CurrentString()
+		best := TimestampMatch{}
+		bestEnd := -1
+		var parseErr error
+		for i, shape := range shapes {
+			end := matchLayout(shape, input)
+			if end <= bestEnd {
+				continue
+			}
+			t, err := time.Parse(layouts[i], input[:end])
+			if err != nil {
+				parseErr = err
+				continue
+			}
+			best, bestEnd = TimestampMatch{Time: t, Layout: layouts[i]}, end
+		}
+		if bestEnd >= 0 {
+			return state.MoveBy(bestEnd), best, nil
+		}
+		if parseErr != nil {
+			return state, best, state.NewSyntaxError("%s (%v)", expected, parseErr)
+		}
+		if input == "" {
+			return state, best, comb.MarkIncomplete(state.NewSyntaxError("%s (at EOF)", expected))
+		}
+		r, _ := state.DecodeRune(input)
+		return state, best, state.NewSyntaxError("%s (got %q)", expected, r)
+	}
+
+	recoverer := func(state comb.State, _ interface{}) (int, interface{}) {
+		input := state.CurrentString()
+		for i := 0; i < len(input); {
+			for _, shape := range shapes {
+				if matchLayout(shape, input[i:]) >= 0 {
+					return i, nil
+				}
+			}
+			_, size := state.DecodeRune(input[i:])
+			i += size
+		}
+		return comb.RecoverWasteTooMuch, nil
+	}
+
+	p = comb.NewParser[TimestampMatch](expected, parse, recoverer)
+	return p
+}
+
+// layoutElemKind is the kind of input an element of a layout matches.
+type layoutElemKind int
+
+const (
+	elemLiteral  layoutElemKind = iota // the literal text
+	elemDigits                         // min to max ASCII digits
+	elemSpaceNum                       // a space or digit followed by a digit ("_2")
+	elemLetters                        // min to max ASCII letters
+	elemZone                           // offset of a time zone (with 'Z' for UTC if z is set)
+	elemFraction                       // '.' or ',' followed by min to max digits (optional if min is 0)
+)
+
+type layoutElem struct {
+	kind     layoutElemKind
+	text     string // literal text
+	min, max int    // number of digits or letters
+	colons   bool   // the zone offset contains colons
+	z        bool   // the zone offset can be 'Z'
+	seconds  bool   // the zone offset contains seconds
+	optFrac  bool   // an optional fraction of seconds may follow (like time.Parse accepts)
+}
+
+// layoutChunks are the elements of layouts known by the time package.
+// Longer chunks have to come before their prefixes.
+var layoutChunks = []struct {
+	chunk string
+	elem  layoutElem
+}{
+	{"January", layoutElem{kind: elemLetters, min: 3, max: 9}},
+	{"Jan", layoutElem{kind: elemLetters, min: 3, max: 3}},
+	{"Monday", layoutElem{kind: elemLetters, min: 6, max: 9}},
+	{"Mon", layoutElem{kind: elemLetters, min: 3, max: 3}},
+	{"MST", layoutElem{kind: elemLetters, min: 3, max: 5}},
+	{"2006", layoutElem{kind: elemDigits, min: 4, max: 4}},
+	{"002", layoutElem{kind: elemDigits, min: 3, max: 3}},
+	{"__2", layoutElem{kind: elemDigits, min: 1, max: 3}},
+	{"_2", layoutElem{kind: elemSpaceNum}},
+	{"01", layoutElem{kind: elemDigits, min: 2, max: 2}},
+	{"02", layoutElem{kind: elemDigits, min: 2, max: 2}},
+	{"03", layoutElem{kind: elemDigits, min: 2, max: 2}},
+	{"04", layoutElem{kind: elemDigits, min: 2, max: 2}},
+	{"05", layoutElem{kind: elemDigits, min: 2, max: 2, optFrac: true}},
+	{"06", layoutElem{kind: elemDigits, min: 2, max: 2}},
+	{"15", layoutElem{kind: elemDigits, min: 2, max: 2}},
+	{"1", layoutElem{kind: elemDigits, min: 1, max: 2}},
+	{"2", layoutElem{kind: elemDigits, min: 1, max: 2}},
+	{"3", layoutElem{kind: elemDigits, min: 1, max: 2}},
+	{"4", layoutElem{kind: elemDigits, min: 1, max: 2}},
+	{"5", layoutElem{kind: elemDigits, min: 1, max: 2, optFrac: true}},
+	{"PM", layoutElem{kind: elemLetters, min: 2, max: 2}},
+	{"pm", layoutElem{kind: elemLetters, min: 2, max: 2}},
+	{"Z07:00:00", layoutElem{kind: elemZone, z: true, colons: true, seconds: true}},
+	{"-07:00:00", layoutElem{kind: elemZone, colons: true, seconds: true}},
+	{"Z070000", layoutElem{kind: elemZone, z: true, seconds: true}},
+	{"-070000", layoutElem{kind: elemZone, seconds: true}},
+	{"Z07:00", layoutElem{kind: elemZone, z: true, colons: true}},
+	{"-07:00", layoutElem{kind: elemZone, colons: true}},
+	{"Z0700", layoutElem{kind: elemZone, z: true}},
+	{"-0700", layoutElem{kind: elemZone}},
+	{"Z07", layoutElem{kind: elemZone, z: true, min: 1}},
+	{"-07", layoutElem{kind: elemZone, min: 1}},
+}
+
+// analyzeLayout splits a layout into its elements.
+func analyzeLayout(layout string) []layoutElem {
+	var elems []layoutElem
+	literal := strings.Builder{}
+	addElem := func(elem layoutElem) {
+		if literal.Len() > 0 {
+			elems = append(elems, layoutElem{kind: elemLiteral, text: literal.String()})
+			literal.Reset()
+		}
+		elems = append(elems, elem)
+	}
+
+Outer:
+	for i := 0; i < len(layout); {
+		if c := layout[i]; (c == '.' || c == ',') && i+1 < len(layout) && (layout[i+1] == '0' || layout[i+1] == '9') {
+			j := i + 1
+			for j < len(layout) && layout[j] == layout[i+1] {
+				j++
+			}
+			if j == len(layout) || layout[j] < '0' || layout[j] > '9' {
+				n := j - i - 1
+				if layout[i+1] == '0' {
+					addElem(layoutElem{kind: elemFraction, min: n, max: n})
+				} else {
+					addElem(layoutElem{kind: elemFraction, min: 0, max: n})
+				}
+				i = j
+				continue
+			}
+		}
+		for _, lc := range layoutChunks {
+			if strings.HasPrefix(layout[i:], lc.chunk) {
+				addElem(lc.elem)
+				i += len(lc.chunk)
+				continue Outer
+			}
+		}
+		literal.WriteByte(layout[i])
+		i++
+	}
+	if literal.Len() > 0 {
+		elems = append(elems, layoutElem{kind: elemLiteral, text: literal.String()})
+	}
+	return elems
+}
+
+// matchLayout returns the length of the input that fits the elements of a layout
+// or -1 if it doesn't fit.
+func matchLayout(elems []layoutElem, input string) int {
+	n := 0
+	for i, elem := range elems {
+		m := elem.match(input[n:])
+		if m < 0 {
+			return -1
+		}
+		n += m
+		if elem.optFrac && (i+1 >= len(elems) || elems[i+1].kind != elemFraction) {
+			n += layoutElem{kind: elemFraction, max: 9}.match(input[n:])
+		}
+	}
+	return n
+}
+
+// match returns the length of the input matched by the element or -1.
+func (e layoutElem) match(input string) int {
+	switch e.kind {
+	case elemLiteral:
+		if strings.HasPrefix(input, e.text) {
+			return len(e.text)
+		}
+		return -1
+	case elemDigits:
+		return countClass(input, e.min, e.max, isASCIIDigit)
+	case elemSpaceNum:
+		if len(input) >= 2 && (input[0] == ' ' || isASCIIDigit(input[0])) && isASCIIDigit(input[1]) {
+			return 2
+		}
+		return countClass(input, 1, 1, isASCIIDigit)
+	case elemLetters:
+		return countClass(input, e.min, e.max, isASCIIAlpha)
+	case elemZone:
+		if e.z && strings.HasPrefix(input, "Z") {
+			return 1
+		}
+		if input == "" || (input[0] != '+' && input[0] != '-') {
+			return -1
+		}
+		if countClass(input[1:], 2, 2, isASCIIDigit) < 0 {
+			return -1
+		}
+		n := 3
+		if e.min > 0 { // hours only
+			return n
+		}
+		parts := 1
+		if e.seconds {
+			parts = 2
+		}
+		for ; parts > 0; parts-- {
+			if e.colons {
+				if n >= len(input) || input[n] != ':' {
+					return -1
+				}
+				n++
+			}
+			m := countClass(input[n:], 2, 2, isASCIIDigit)
+			if m < 0 {
+				return -1
+			}
+			n += m
+		}
+		return n
+	default: // elemFraction
+		if input == "" || (input[0] != '.' && input[0] != ',') {
+			if e.min == 0 {
+				return 0
+			}
+			return -1
+		}
+		m := countClass(input[1:], max(e.min, 1), e.max, isASCIIDigit)
+		if m < 0 {
+			if e.min == 0 {
+				return 0
+			}
+			return -1
+		}
+		return 1 + m
+	}
+}
+
+// countClass returns the number of bytes (between min and max) at the start of
+// the input that belong to the class or -1 if there are less than min.
+func countClass(input string, min, max int, inClass func(byte) bool) int {
+	n := 0
+	for n < max && n < len(input) && inClass(input[n]) {
+		n++
+	}
+	if n < min {
+		return -1
+	}
+	return n
+}
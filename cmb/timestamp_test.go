@@ -0,0 +1,100 @@
+package cmb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestamp(t *testing.T) {
+	t.Parallel()
+
+	layouts := []string{"2006-01-02", time.RFC3339Nano, "2006-01-02 15:04:05.000", time.Stamp, "Jan _2 2006 3:04PM MST"}
+	testCases := []struct {
+		name          string
+		input         string
+		wantErr       bool
+		wantTime      string // in RFC3339Nano
+		wantLayout    string
+		wantRemaining string
+	}{
+		{
+			name:          "date only should succeed",
+			input:         "2024-03-09 text",
+			wantTime:      "2024-03-09T00:00:00Z",
+			wantLayout:    "2006-01-02",
+			wantRemaining: " text",
+		}, {
+			name:          "longest match should win",
+			input:         "2024-03-09 12:34:56.789 text",
+			wantTime:      "2024-03-09T12:34:56.789Z",
+			wantLayout:    "2006-01-02 15:04:05.000",
+			wantRemaining: " text",
+		}, {
+			name:          "time zone offset should be honored",
+			input:         "2024-03-09T12:34:56.5+02:00]",
+			wantTime:      "2024-03-09T12:34:56.5+02:00",
+			wantLayout:    time.RFC3339Nano,
+			wantRemaining: "]",
+		}, {
+			name:          "UTC should be honored",
+			input:         "2024-03-09T12:34:56Z",
+			wantTime:      "2024-03-09T12:34:56Z",
+			wantLayout:    time.RFC3339Nano,
+			wantRemaining: "",
+		}, {
+			name:          "names and padded days should succeed",
+			input:         "Mar  9 12:34:56 host",
+			wantTime:      "0000-03-09T12:34:56Z",
+			wantLayout:    time.Stamp,
+			wantRemaining: " host",
+		}, {
+			name:          "zone abbreviations should succeed",
+			input:         "Mar  9 2024 1:04PM UTC;",
+			wantTime:      "2024-03-09T13:04:00Z",
+			wantLayout:    "Jan _2 2006 3:04PM MST",
+			wantRemaining: ";",
+		}, {
+			name:          "invalid date should fail",
+			input:         "2024-13-09",
+			wantErr:       true,
+			wantRemaining: "2024-13-09",
+		}, {
+			name:          "no timestamp should fail",
+			input:         "hello",
+			wantErr:       true,
+			wantRemaining: "hello",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			newState, gotOutput, err := cmb.Timestamp(layouts...).Parse(comb.NewFromString(tc.input, 0))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantRemaining, newState.CurrentString())
+			if tc.wantErr {
+				return
+			}
+			assert.Equal(t, tc.wantTime, gotOutput.Time.Format(time.RFC3339Nano))
+			assert.Equal(t, tc.wantLayout, gotOutput.Layout)
+		})
+	}
+}
+
+func BenchmarkTimestamp(b *testing.B) {
+	parser := cmb.Timestamp(time.RFC3339, time.RFC1123Z, time.Stamp, "2006-01-02 15:04:05.000")
+	input := comb.NewFromString("Mar  9 12:34:56 host", 0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = parser.Parse(input)
+	}
+}
@@ -0,0 +1,64 @@
+// Command combgen generates a static Go parser from a grammar file.
+// The grammar is written in the notation of the comb/ebnf package.
+//
+// Usage with go generate:
+//
+//	//go:generate go run github.com/flowdev/comb/cmd/combgen -pkg calc -o calc.go calc.ebnf
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/flowdev/comb/ebnf"
+)
+
+func main() {
+	pkgName := flag.String("pkg", "", "name of the generated package (default: $GOPACKAGE)")
+	start := flag.String("start", "", "name of the start rule (default: first rule)")
+	output := flag.String("o", "", "output file (default: standard output)")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] grammar-file\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *pkgName == "" {
+		*pkgName = os.Getenv("GOPACKAGE")
+	}
+	if *pkgName == "" {
+		fatalf("the package name is missing")
+	}
+
+	text, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		fatalf("unable to read grammar: %v", err)
+	}
+	g, err := ebnf.Load(string(text))
+	if err != nil {
+		fatalf("unable to load grammar %s: %v", flag.Arg(0), err)
+	}
+	src, err := g.Generate(*pkgName, *start)
+	if err != nil {
+		fatalf("unable to generate parser: %v", err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = os.WriteFile(*output, src, 0o644)
+	}
+	if err != nil {
+		fatalf("unable to write parser: %v", err)
+	}
+}
+
+func fatalf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(os.Stderr, "combgen: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,72 @@
+package comb
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SuggestionKind classifies a completion candidate.
+type SuggestionKind int
+
+const (
+	SuggestRule    SuggestionKind = iota // a class of input like "integer" (there is no text to insert)
+	SuggestKeyword                       // a literal that looks like a word (e.g. "let")
+	SuggestLiteral                       // any other literal (e.g. "(")
+)
+
+func (k SuggestionKind) String() string {
+	switch k {
+	case SuggestKeyword:
+		return "keyword"
+	case SuggestLiteral:
+		return "literal"
+	default:
+		return "rule"
+	}
+}
+
+// Suggestion is a candidate continuation of the input at the cursor.
+type Suggestion struct {
+	Kind     SuggestionKind
+	Text     string // text to insert (empty for rules)
+	Start    int    // position in the input from where Text replaces the input up to the cursor
+	Expected string // description of the parser (Expected())
+}
+
+// Complete parses the input up to the cursor and returns all candidate
+// continuations at the cursor. It is meant for tab-completion in REPLs and
+// interactive shells.
+// Literals (quoted expectations like `"let"` or `'('`) that are partially
+// typed already start at the beginning of the partial input and are only
+// suggested if they match it.
+// All other expectations are suggested as rules.
+func Complete[Output any](parser *PreparedParser[Output], input string, cursor int) []Suggestion {
+	cursor = min(max(cursor, 0), len(input))
+
+	var suggestions []Suggestion
+	for _, step := range parser.expectedSteps(NewFromString(input, FailFast).MoveBy(cursor)) {
+		sug := Suggestion{Kind: SuggestRule, Start: step.Start, Expected: step.Expected}
+		if text, err := strconv.Unquote(step.Expected); err == nil && text != "" {
+			if !strings.HasPrefix(text, input[step.Start:cursor]) {
+				continue
+			}
+			sug.Text = text
+			sug.Kind = SuggestLiteral
+			if isWord(text) {
+				sug.Kind = SuggestKeyword
+			}
+		}
+		suggestions = append(suggestions, sug)
+	}
+	return suggestions
+}
+
+func isWord(text string) bool {
+	for i, r := range text {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
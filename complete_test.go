@@ -0,0 +1,71 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplete(t *testing.T) {
+	t.Parallel()
+
+	parser := comb.NewPreparedParser(cmb.FirstSuccessful(
+		cmb.Prefixed(cmb.String("let"), cmb.Prefixed(cmb.Char(' '), cmb.Alpha1())),
+		cmb.Prefixed(cmb.String("print"), cmb.Prefixed(cmb.Char('('), cmb.Alpha1())),
+		cmb.String("quit"),
+	))
+
+	testCases := []struct {
+		name   string
+		input  string
+		cursor int
+		want   []comb.Suggestion
+	}{
+		{
+			name:   "all keywords",
+			input:  "",
+			cursor: 0,
+			want: []comb.Suggestion{
+				{Kind: comb.SuggestKeyword, Text: "let", Start: 0, Expected: `"let"`},
+				{Kind: comb.SuggestKeyword, Text: "print", Start: 0, Expected: `"print"`},
+				{Kind: comb.SuggestKeyword, Text: "quit", Start: 0, Expected: `"quit"`},
+			},
+		}, {
+			name:   "partial keyword",
+			input:  "pr",
+			cursor: 2,
+			want: []comb.Suggestion{
+				{Kind: comb.SuggestKeyword, Text: "print", Start: 0, Expected: `"print"`},
+			},
+		}, {
+			name:   "literal",
+			input:  "print",
+			cursor: 5,
+			want: []comb.Suggestion{
+				{Kind: comb.SuggestLiteral, Text: "(", Start: 5, Expected: `'('`},
+			},
+		}, {
+			name:   "rule",
+			input:  "print(x",
+			cursor: 6,
+			want: []comb.Suggestion{
+				{Kind: comb.SuggestRule, Start: 6, Expected: cmb.Alpha1().Expected()},
+			},
+		}, {
+			name:   "cursor out of range",
+			input:  "quit",
+			cursor: 10,
+			want:   nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, comb.Complete(parser, tc.input, tc.cursor))
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package comb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ============================================================================
+// Regression Tests With A Corpus Of Input Files
+//
+
+// CorpusStatus is the outcome of comparing the report of an input file
+// with its golden file.
+type CorpusStatus int
+
+const (
+	CorpusMatch     CorpusStatus = iota // the report equals the golden file
+	CorpusMismatch                      // the report differs from the golden file
+	CorpusNewGolden                     // there was no golden file, so the report has been written as one
+)
+
+func (s CorpusStatus) String() string {
+	switch s {
+	case CorpusMatch:
+		return "match"
+	case CorpusMismatch:
+		return "mismatch"
+	case CorpusNewGolden:
+		return "new golden"
+	}
+	return fmt.Sprintf("CorpusStatus(%d)", int(s))
+}
+
+// CorpusResult is the result of parsing a single input file of a corpus.
+type CorpusResult struct {
+	File   string // path of the input file relative to the corpus directory
+	Status CorpusStatus
+	Report string // the output and all errors with their positions
+}
+
+const (
+	goldenSuffix = ".golden"
+	gotSuffix    = ".got"
+)
+
+// RunCorpus parses every file in the directory dir (including subdirectories)
+// and compares a report of each parse run with the golden file of the same
+// relative path plus ".golden" in goldenDir.
+// This allows regression tests for large grammars (including their error
+// recovery) with plain input files instead of Go literals.
+//
+// The report contains the output (formatted with %#v) and all errors
+// with their positions.
+// Files are read like with RunOnFile.
+// A missing golden file is created from the report.
+// For a mismatch the report is written next to the golden file
+// with the suffix ".got", so it can be compared with the usual tools.
+// To update a golden file, just delete it.
+//
+// The returned error joins all mismatches and I/O errors.
+// The parser is prepared by RunCorpus, so it must not have been prepared before.
+func RunCorpus[Output any](dir string, p Parser[Output], goldenDir string) ([]CorpusResult, error) {
+	pp := NewPreparedParser(p)
+	var results []CorpusResult
+	var errs []error
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		out, runErr := RunOnState(newStateFromFile(b, DefaultMaxErrors), pp)
+		result := CorpusResult{File: filepath.ToSlash(rel), Report: corpusReport(out, runErr)}
+		if err = compareGolden(&result, filepath.Join(goldenDir, rel)); err != nil {
+			errs = append(errs, err)
+		}
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return results, errors.Join(errs...)
+}
+
+// corpusReport formats the output and errors of a parse run.
+func corpusReport(out interface{}, err error) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("== output\n%#v\n", out))
+	for _, e := range UnwrapErrors(err) {
+		if pos, ok := PositionOf(e); ok {
+			if pos.Line > 0 {
+				sb.WriteString(fmt.Sprintf("== error at %d:%d\n", pos.Line, pos.Column))
+			} else {
+				sb.WriteString(fmt.Sprintf("== error at byte %d\n", pos.Pos))
+			}
+		} else {
+			sb.WriteString("== error\n")
+		}
+		sb.WriteString(e.Error())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// compareGolden compares the report of the result with the golden file
+// and sets the status of the result.
+// The golden file is created if it doesn't exist yet.
+// The error reports a mismatch or an I/O error.
+func compareGolden(result *CorpusResult, golden string) error {
+	want, err := os.ReadFile(golden + goldenSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		result.Status = CorpusNewGolden
+		if err = os.MkdirAll(filepath.Dir(golden), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(golden+goldenSuffix, []byte(result.Report), 0o644)
+	} else if err != nil {
+		return err
+	}
+
+	if string(want) == result.Report {
+		result.Status = CorpusMatch
+		if err = os.Remove(golden + gotSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	result.Status = CorpusMismatch
+	if err = os.WriteFile(golden+gotSuffix, []byte(result.Report), 0o644); err != nil {
+		return err
+	}
+	return fmt.Errorf("report of %s differs from its golden file (see %s)", result.File, golden+gotSuffix)
+}
@@ -0,0 +1,63 @@
+package comb_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCorpus(t *testing.T) {
+	t.Parallel()
+
+	dir, goldenDir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(dir, "good.txt"), "1,2,3")
+	writeFile(t, filepath.Join(dir, "sub", "bad.txt"), "1,x,3")
+	parser := func() comb.Parser[[]string] {
+		return cmb.Suffixed(cmb.Separated1(cmb.Digit1(), cmb.Char(','), false), cmb.EOF())
+	}
+
+	// the first run creates the golden files
+	results, err := comb.RunCorpus(dir, parser(), goldenDir)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "good.txt", results[0].File)
+		assert.Equal(t, comb.CorpusNewGolden, results[0].Status)
+		assert.Equal(t, "== output\n[]string{\"1\", \"2\", \"3\"}\n", results[0].Report)
+		assert.Equal(t, "sub/bad.txt", results[1].File)
+		assert.Contains(t, results[1].Report, "== error at 1:2\n")
+	}
+	assert.FileExists(t, filepath.Join(goldenDir, "sub", "bad.txt.golden"))
+
+	// the second run compares with them
+	results, err = comb.RunCorpus(dir, parser(), goldenDir)
+	assert.NoError(t, err)
+	for _, result := range results {
+		assert.Equal(t, comb.CorpusMatch, result.Status, result.File)
+	}
+
+	// a changed golden file is a mismatch
+	writeFile(t, filepath.Join(goldenDir, "good.txt.golden"), "== output\n[]string{\"1\"}\n")
+	results, err = comb.RunCorpus(dir, parser(), goldenDir)
+	assert.ErrorContains(t, err, "report of good.txt differs from its golden file")
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, comb.CorpusMismatch, results[0].Status)
+		assert.Equal(t, comb.CorpusMatch, results[1].Status)
+	}
+	got, err := os.ReadFile(filepath.Join(goldenDir, "good.txt.got"))
+	assert.NoError(t, err)
+	assert.Equal(t, results[0].Report, string(got))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
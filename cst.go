@@ -0,0 +1,153 @@
+package comb
+
+// ============================================================================
+// Concrete Syntax Tree (CST)
+//
+
+// RootKind is the kind of the root node of every CST.
+const RootKind = "Root"
+
+// GreenNode is an immutable node of a concrete syntax tree (CST).
+// It doesn't know its position (only its width),
+// so it can be shared between trees.
+// Input that isn't covered by child parsers (like white space or comments
+// consumed by a branch parser) is kept in token nodes with an empty Kind.
+// So the tree is lossless.
+type GreenNode struct {
+	Kind     string       // Expected() of the parser or empty for uncovered text
+	Width    int          // number of bytes covered by the node
+	Children []*GreenNode // empty for leaf parsers and uncovered text
+}
+
+// SyntaxNode (a.k.a. red node) is a GreenNode at a position of the input.
+// It knows its parent and can compute the text it covers.
+type SyntaxNode struct {
+	green  *GreenNode
+	parent *SyntaxNode
+	offset int
+	input  string
+}
+
+// RunWithCST runs a parser on text input like RunOnString and
+// returns the concrete syntax tree of the input, too.
+// The CST records every successful parser that consumed some input.
+// The root node always covers the whole input.
+//
+// NOTE:
+//   - Only parsers called via ParseAny are recorded (all standard combinators do that).
+//   - Parts of the input that have been recovered from errors are uncovered text.
+func RunWithCST[Output any](input string, parse Parser[Output]) (Output, *SyntaxNode, error) {
+	return RunOnStateWithCST(NewFromString(input, DefaultMaxErrors), NewPreparedParser(parse))
+}
+
+// RunOnStateWithCST is the concurrent-safe version of RunWithCST for
+// prepared parsers (see RunOnState).
+// The state has to be created from text input and the CST covers all of it.
+func RunOnStateWithCST[Output any](state State, parser *PreparedParser[Output]) (Output, *SyntaxNode, error) {
+	rec := &cstRecorder{}
+	constant := *state.constant
+	constant.cst = rec
+	state.constant = &constant
+	out, err := RunOnState[Output](state, parser)
+	input := state.constant.text
+	green := rec.buildNode(RootKind, 0, len(input), 0)
+	return out, &SyntaxNode{green: green, input: input}, err
+}
+
+// Green returns the green node.
+func (n *SyntaxNode) Green() *GreenNode {
+	return n.green
+}
+
+// Kind returns the Expected() of the parser or an empty string for uncovered text.
+func (n *SyntaxNode) Kind() string {
+	return n.green.Kind
+}
+
+// IsToken returns true if the node has no children.
+func (n *SyntaxNode) IsToken() bool {
+	return len(n.green.Children) == 0
+}
+
+// Span returns the span of the input covered by the node.
+func (n *SyntaxNode) Span() Span {
+	return Span{Start: n.offset, End: n.offset + n.green.Width}
+}
+
+// Text returns the input covered by the node.
+func (n *SyntaxNode) Text() string {
+	return n.input[n.offset : n.offset+n.green.Width]
+}
+
+// Parent returns the parent node or nil for the root node.
+func (n *SyntaxNode) Parent() *SyntaxNode {
+	return n.parent
+}
+
+// Children returns the child nodes.
+func (n *SyntaxNode) Children() []*SyntaxNode {
+	children := make([]*SyntaxNode, len(n.green.Children))
+	offset := n.offset
+	for i, g := range n.green.Children {
+		children[i] = &SyntaxNode{green: g, parent: n, offset: offset, input: n.input}
+		offset += g.Width
+	}
+	return children
+}
+
+// ============================================================================
+// Recording the CST
+//
+
+type cstPending struct {
+	start, end int
+	green      *GreenNode
+}
+
+// cstRecorder collects the nodes of successful parsers.
+// The nodes of a parser are collected after the mark returned by enter.
+// All methods work with a nil recorder (CST mode is off).
+type cstRecorder struct {
+	pending []cstPending
+}
+
+func (rec *cstRecorder) enter() int {
+	if rec == nil {
+		return -1
+	}
+	return len(rec.pending)
+}
+
+func (rec *cstRecorder) exit(mark int, kind string, start, end int, success bool) {
+	if rec == nil || mark < 0 || mark > len(rec.pending) {
+		return
+	}
+	if !success || end <= start {
+		rec.pending = rec.pending[:mark]
+		return
+	}
+	green := rec.buildNode(kind, start, end, mark)
+	rec.pending = append(rec.pending[:mark], cstPending{start: start, end: end, green: green})
+}
+
+// buildNode builds a node from the pending nodes after the mark.
+// Nodes outside the span or overlapping earlier nodes (because of look ahead or backtracking)
+// are ignored and gaps are filled with uncovered text.
+func (rec *cstRecorder) buildNode(kind string, start, end, mark int) *GreenNode {
+	var children []*GreenNode
+	pos := start
+	for _, pn := range rec.pending[mark:] {
+		if pn.start < pos || pn.end > end {
+			continue
+		}
+		if pn.start > pos {
+			children = append(children, &GreenNode{Width: pn.start - pos})
+		}
+		children = append(children, pn.green)
+		pos = pn.end
+	}
+	if len(children) > 0 && pos < end {
+		children = append(children, &GreenNode{Width: end - pos})
+	}
+	return &GreenNode{Kind: kind, Width: end - start, Children: children}
+}
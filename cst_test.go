@@ -0,0 +1,96 @@
+package comb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithCST(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantOutput []string
+		wantTree   string
+	}{
+		{
+			name:       "all trivia should be kept",
+			input:      " ab ,  cd ",
+			wantOutput: []string{"ab", "cd"},
+			wantTree: `Root " ab ,  cd "
+  Prefixed " ab ,  cd"
+    whitespace " "
+    SeparatedMN "ab ,  cd"
+      Prefixed "ab"
+        letter "ab"
+      Delimited " ,  "
+        whitespace " "
+        ',' ","
+        whitespace "  "
+      Prefixed "cd"
+        letter "cd"
+  "" " "
+`,
+		}, {
+			name:       "unparsed input should be kept",
+			input:      "ab!",
+			wantOutput: []string{"ab"},
+			wantTree: `Root "ab!"
+  Prefixed "ab"
+    SeparatedMN "ab"
+      Prefixed "ab"
+        letter "ab"
+  "" "!"
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := cmb.Prefixed(cmb.Whitespace0(), cmb.Separated1(
+				cmb.Prefixed(cmb.Whitespace0(), cmb.Alpha1()),
+				cmb.Delimited(cmb.Whitespace0(), cmb.Char(','), cmb.Whitespace0()),
+				false,
+			))
+			gotOutput, gotTree, _ := comb.RunWithCST(tc.input, p)
+			assert.Equal(t, tc.wantOutput, gotOutput)
+
+			sb := strings.Builder{}
+			printCST(&sb, gotTree, "")
+			assert.Equal(t, tc.wantTree, sb.String())
+
+			text := strings.Builder{}
+			collectTokens(&text, gotTree)
+			assert.Equal(t, tc.input, text.String(), "the CST has to be lossless")
+		})
+	}
+}
+
+func printCST(sb *strings.Builder, node *comb.SyntaxNode, indent string) {
+	kind := node.Kind()
+	if kind == "" || strings.Contains(kind, " ") {
+		kind = `"` + kind + `"`
+	}
+	sb.WriteString(indent + kind + " \"" + node.Text() + "\"\n")
+	for _, child := range node.Children() {
+		printCST(sb, child, indent+"  ")
+	}
+}
+
+func collectTokens(sb *strings.Builder, node *comb.SyntaxNode) {
+	if node.IsToken() {
+		sb.WriteString(node.Text())
+		return
+	}
+	for _, child := range node.Children() {
+		collectTokens(sb, child)
+	}
+}
@@ -0,0 +1,100 @@
+package comb
+
+// ============================================================================
+// Interactive Debugging
+//
+
+// StepAction tells the parser what to do after Debugger.Before has been called.
+type StepAction int
+
+const (
+	StepContinue StepAction = iota // run the parser and stop at its sub-parsers, too
+	StepSkip                       // run the parser without stopping at its sub-parsers
+	StepAbort                      // stop parsing with a fatal error
+)
+
+// StepResult is the result of a single parser step.
+type StepResult struct {
+	State  State       // state after the parser
+	Output interface{} // output of the parser
+	Err    *ParserError
+}
+
+// ParserInfo describes a parser for a Debugger.
+type ParserInfo interface {
+	ID() int32
+	Expected() string
+}
+
+// Debugger can single-step through a parse (e.g. in a TUI).
+// Before is called before a parser runs and After after it has finished.
+// Both methods are also called during error recovery.
+type Debugger interface {
+	Before(parser ParserInfo, state State) StepAction
+	After(parser ParserInfo, result StepResult)
+}
+
+// RunWithDebugger runs a parser on a given state like RunOnState
+// and calls the debugger for every parser called via ParseAny
+// (all standard combinators do that).
+//
+// NOTE:
+//   - The debugger is registered with the input of the state.
+//     So the state must not be used concurrently in other runs.
+func RunWithDebugger[Output any](state State, parser *PreparedParser[Output], debugger Debugger) (Output, error) {
+	hook := &debugHook{debugger: debugger}
+	state.constant.debugger = hook
+	defer func() {
+		state.constant.debugger = nil
+	}()
+	out, err := RunOnState[Output](state, parser)
+	if err == nil && hook.aborted {
+		err = state.NewSyntaxError(abortMessage)
+	}
+	return out, err
+}
+
+const abortMessage = "parsing aborted by debugger"
+
+// debugHook calls the debugger and keeps track of skipping and aborting.
+// All methods work with a nil hook (debugging is off).
+type debugHook struct {
+	debugger  Debugger
+	depth     int  // nesting depth of parsers
+	skipDepth int  // depth of the parser that is skipped (0 if none)
+	aborted   bool // all parsers fail after aborting
+}
+
+// before returns an error if the parser must not run.
+func (hook *debugHook) before(parser ParserInfo, state State) *ParserError {
+	if hook == nil {
+		return nil
+	}
+	if hook.aborted {
+		return MarkFatal(state.NewSyntaxError(abortMessage))
+	}
+	hook.depth++
+	if hook.skipDepth > 0 {
+		return nil
+	}
+	switch hook.debugger.Before(parser, state) {
+	case StepSkip:
+		hook.skipDepth = hook.depth
+	case StepAbort:
+		hook.aborted = true
+		hook.depth--
+		return MarkFatal(state.NewSyntaxError(abortMessage))
+	}
+	return nil
+}
+
+func (hook *debugHook) after(parser ParserInfo, state State, out interface{}, err *ParserError) {
+	if hook == nil {
+		return
+	}
+	if hook.skipDepth == 0 || hook.skipDepth == hook.depth {
+		hook.skipDepth = 0
+		hook.debugger.After(parser, StepResult{State: state, Output: out, Err: err})
+	}
+	hook.depth--
+}
@@ -0,0 +1,80 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDebugger struct {
+	action  func(expected string) comb.StepAction
+	befores []string
+	afters  []string
+}
+
+func (d *testDebugger) Before(parser comb.ParserInfo, _ comb.State) comb.StepAction {
+	d.befores = append(d.befores, parser.Expected())
+	return d.action(parser.Expected())
+}
+
+func (d *testDebugger) After(parser comb.ParserInfo, _ comb.StepResult) {
+	d.afters = append(d.afters, parser.Expected())
+}
+
+func TestRunWithDebugger(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		action      func(expected string) comb.StepAction
+		wantErr     bool
+		wantBefores []string
+		wantAfters  []string
+	}{
+		{
+			name:        "continue should step into all parsers",
+			action:      func(string) comb.StepAction { return comb.StepContinue },
+			wantBefores: []string{"Prefixed", "whitespace", "letter"},
+			wantAfters:  []string{"whitespace", "letter", "Prefixed"},
+		}, {
+			name: "skip should step over sub-parsers",
+			action: func(expected string) comb.StepAction {
+				if expected == "Prefixed" {
+					return comb.StepSkip
+				}
+				return comb.StepContinue
+			},
+			wantBefores: []string{"Prefixed"},
+			wantAfters:  []string{"Prefixed"},
+		}, {
+			name: "abort should stop parsing",
+			action: func(expected string) comb.StepAction {
+				if expected == "letter" {
+					return comb.StepAbort
+				}
+				return comb.StepContinue
+			},
+			wantErr:     true,
+			wantBefores: []string{"Prefixed", "whitespace", "letter"},
+			wantAfters:  []string{"whitespace", "Prefixed"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := &testDebugger{action: tc.action}
+			p := comb.NewPreparedParser(cmb.Prefixed(cmb.Whitespace0(), cmb.Alpha1()))
+			_, err := comb.RunWithDebugger(comb.NewFromString(" abc", 10), p, d)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantBefores, d.befores)
+			assert.Equal(t, tc.wantAfters, d.afters)
+		})
+	}
+}
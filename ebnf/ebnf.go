@@ -0,0 +1,228 @@
+// Package ebnf loads grammars in an EBNF/PEG like notation at runtime
+// and builds comb parsers for them.
+//
+// The parsers return generic parse trees.
+// Go actions can be attached to the rules to compute values for the nodes.
+// Alternatives are ordered (like in PEG): the first successful alternative wins.
+// Left recursive rules aren't supported.
+//
+// An example grammar:
+//
+//	sum    = number ( '+' number )* ;
+//	number = [0-9]+ ;
+package ebnf
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// Node is a node of the parse tree.
+// Every rule creates exactly one node.
+type Node struct {
+	Rule     string      // name of the rule
+	Span     comb.Span   // span of the input matched by the rule
+	Text     string      // input matched by the rule
+	Children []Node      // nodes of the rules used by the rule
+	Value    interface{} // result of the action of the rule (if any)
+}
+
+// Action computes the value of a node.
+// The values of the children have been computed already.
+// An error is reported as semantic error at the end of the node.
+type Action func(Node) (interface{}, error)
+
+// Grammar is a grammar loaded from text.
+type Grammar struct {
+	rules   map[string]expr
+	order   []string
+	actions map[string]Action
+}
+
+// Load parses the text of a grammar.
+// It returns an error if the text isn't a valid grammar,
+// if a rule is defined twice or if an undefined rule is used.
+func Load(text string) (*Grammar, error) {
+	rules, err := comb.RunOnString(text, grammarParser())
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("grammar without rules")
+	}
+
+	g := &Grammar{rules: make(map[string]expr, len(rules)), actions: make(map[string]Action)}
+	for _, r := range rules {
+		if _, ok := g.rules[r.name]; ok {
+			return nil, fmt.Errorf("rule %q is defined twice", r.name)
+		}
+		g.rules[r.name] = r.expr
+		g.order = append(g.order, r.name)
+	}
+	for _, name := range g.order {
+		if err = g.checkRefs(name, g.rules[name]); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+func (g *Grammar) checkRefs(ruleName string, e expr) error {
+	switch e := e.(type) {
+	case refExpr:
+		if _, ok := g.rules[string(e)]; !ok {
+			return fmt.Errorf("rule %q uses undefined rule %q", ruleName, string(e))
+		}
+	case seqExpr:
+		for _, sub := range e {
+			if err := g.checkRefs(ruleName, sub); err != nil {
+				return err
+			}
+		}
+	case choiceExpr:
+		for _, sub := range e {
+			if err := g.checkRefs(ruleName, sub); err != nil {
+				return err
+			}
+		}
+	case repeatExpr:
+		return g.checkRefs(ruleName, e.expr)
+	case lookExpr:
+		return g.checkRefs(ruleName, e.expr)
+	}
+	return nil
+}
+
+// Rules returns the names of all rules in the order of their definition.
+func (g *Grammar) Rules() []string {
+	return slices.Clone(g.order)
+}
+
+// SetAction attaches an action to a rule.
+// The action is used by all parsers created afterward.
+func (g *Grammar) SetAction(ruleName string, action Action) error {
+	if _, ok := g.rules[ruleName]; !ok {
+		return fmt.Errorf("unknown rule %q", ruleName)
+	}
+	g.actions[ruleName] = action
+	return nil
+}
+
+// Parser creates a new parser starting with the given rule.
+// If `start` is empty, the first rule of the grammar is used.
+// The parser doesn't have to match the whole input
+// (use it together with cmb.EOF for that).
+func (g *Grammar) Parser(start string) (comb.Parser[Node], error) {
+	if start == "" {
+		start = g.order[0]
+	}
+	if _, ok := g.rules[start]; !ok {
+		return nil, fmt.Errorf("unknown rule %q", start)
+	}
+
+	b := &builder{grammar: g, parsers: make(map[string]comb.Parser[[]Node], len(g.rules))}
+	for _, name := range g.order {
+		b.parsers[name] = b.buildRule(name)
+	}
+	return cmb.Map(b.parsers[start], func(nodes []Node) (Node, error) {
+		return nodes[0], nil
+	}), nil
+}
+
+// builder builds the parsers of all rules.
+// All parsers return the nodes of the rules they contain.
+type builder struct {
+	grammar *Grammar
+	parsers map[string]comb.Parser[[]Node]
+}
+
+func (b *builder) buildRule(name string) comb.Parser[[]Node] {
+	action := b.grammar.actions[name]
+	return cmb.MapWithSpan(b.build(b.grammar.rules[name]),
+		func(children []Node, span comb.Span, state comb.State) ([]Node, error) {
+			node := Node{Rule: name, Span: span, Text: state.StringOf(span), Children: children}
+			if action != nil {
+				value, err := action(node)
+				if err != nil {
+					return []Node{node}, err
+				}
+				node.Value = value
+			}
+			return []Node{node}, nil
+		},
+	)
+}
+
+func (b *builder) build(e expr) comb.Parser[[]Node] {
+	switch e := e.(type) {
+	case litExpr:
+		return cmb.Map(cmb.String(string(e)), noNodes[string])
+	case classExpr:
+		return cmb.Map(cmb.Satisfy(classString(e), e.matches), noNodes[rune])
+	case anyExpr:
+		return cmb.Map(cmb.Satisfy("any character", func(rune) bool { return true }), noNodes[rune])
+	case refExpr:
+		name := string(e)
+		return comb.LazyBranchParser(func() comb.Parser[[]Node] { return b.parsers[name] })
+	case seqExpr:
+		p := b.build(e[len(e)-1])
+		for i := len(e) - 2; i >= 0; i-- {
+			p = cmb.Map2(b.build(e[i]), p, func(first, rest []Node) ([]Node, error) {
+				return append(slices.Clip(first), rest...), nil
+			})
+		}
+		return p
+	case choiceExpr:
+		alts := make([]comb.Parser[[]Node], len(e))
+		for i, sub := range e {
+			alts[i] = b.build(sub)
+		}
+		return cmb.FirstSuccessful(alts...)
+	case repeatExpr:
+		p := b.build(e.expr)
+		switch e.op {
+		case '*':
+			return cmb.Map(cmb.Many0(p), flatten)
+		case '+':
+			return cmb.Map(cmb.Many1(p), flatten)
+		default:
+			return cmb.Optional(p)
+		}
+	default: // lookExpr
+		le := e.(lookExpr)
+		p := b.build(le.expr)
+		if le.op == '!' {
+			return cmb.Map(cmb.Not(p), noNodes[bool])
+		}
+		return cmb.Map(cmb.Peek(p), noNodes[[]Node])
+	}
+}
+
+func noNodes[Output any](Output) ([]Node, error) {
+	return nil, nil
+}
+
+func flatten(nodeLists [][]Node) ([]Node, error) {
+	var nodes []Node
+	for _, list := range nodeLists {
+		nodes = append(nodes, list...)
+	}
+	return nodes, nil
+}
+
+func classString(c classExpr) string {
+	s := "["
+	if c.negated {
+		s += "^"
+	}
+	for _, rr := range c.ranges {
+		s += string(rr.from)
+		if rr.to != rr.from {
+			s += "-" + string(rr.to)
+		}
+	}
+	return s + "]"
+}
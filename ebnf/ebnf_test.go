@@ -0,0 +1,177 @@
+package ebnf
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+const calcGrammar = `
+# a simple calculator
+sum     = product ( [+\-] product )* ;
+product <- value ('*' value)*
+value   = number / '(' sum ')'
+number  = [0-9]+ !'.' // no floats
+`
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		grammar   string
+		wantErr   bool
+		wantRules []string
+	}{
+		{
+			name:      "loading a valid grammar should succeed",
+			grammar:   calcGrammar,
+			wantRules: []string{"sum", "product", "value", "number"},
+		}, {
+			name:    "loading an empty grammar should fail",
+			grammar: " # nothing\n",
+			wantErr: true,
+		}, {
+			name:    "loading a grammar with an undefined rule should fail",
+			grammar: "a = b",
+			wantErr: true,
+		}, {
+			name:    "loading a grammar with a duplicate rule should fail",
+			grammar: "a = 'x'; a = 'y'",
+			wantErr: true,
+		}, {
+			name:    "loading a grammar with a syntax error should fail",
+			grammar: "a = [x",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			g, err := Load(tc.grammar)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.wantRules, g.Rules())
+			}
+		})
+	}
+}
+
+func TestParser(t *testing.T) {
+	t.Parallel()
+
+	g, err := Load(calcGrammar)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	p, err := g.Parser("number")
+	assert.NoError(t, err)
+	gotNode, err := comb.RunOnString("42", p)
+	assert.NoError(t, err)
+	assert.Equal(t, Node{Rule: "number", Span: comb.Span{Start: 0, End: 2}, Text: "42"}, gotNode)
+
+	p, err = g.Parser("")
+	assert.NoError(t, err)
+	gotNode, err = comb.RunOnState(comb.NewFromString("1+2*3", 0), comb.NewPreparedParser(p))
+	assert.NoError(t, err)
+	assert.Equal(t, "sum", gotNode.Rule)
+	assert.Equal(t, "1+2*3", gotNode.Text)
+	if assert.Len(t, gotNode.Children, 2) {
+		assert.Equal(t, "1", gotNode.Children[0].Text)
+		assert.Equal(t, "2*3", gotNode.Children[1].Text)
+		assert.Len(t, gotNode.Children[1].Children, 2)
+	}
+
+	_, err = g.Parser("unknown")
+	assert.Error(t, err)
+}
+
+func TestActions(t *testing.T) {
+	t.Parallel()
+
+	g, err := Load(calcGrammar)
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	assert.NoError(t, g.SetAction("number", func(n Node) (interface{}, error) {
+		return strconv.Atoi(n.Text)
+	}))
+	assert.NoError(t, g.SetAction("value", func(n Node) (interface{}, error) {
+		return n.Children[0].Value, nil
+	}))
+	assert.NoError(t, g.SetAction("product", func(n Node) (interface{}, error) {
+		result := 1
+		for _, child := range n.Children {
+			result *= child.Value.(int)
+		}
+		return result, nil
+	}))
+	assert.NoError(t, g.SetAction("sum", func(n Node) (interface{}, error) {
+		result := n.Children[0].Value.(int)
+		for _, child := range n.Children[1:] {
+			op := n.Text[child.Span.Start-n.Span.Start-1]
+			if op == '-' {
+				result -= child.Value.(int)
+			} else {
+				result += child.Value.(int)
+			}
+			if result < 0 {
+				return result, errors.New("negative result")
+			}
+		}
+		return result, nil
+	}))
+	assert.Error(t, g.SetAction("unknown", nil))
+
+	testCases := []struct {
+		name      string
+		input     string
+		wantErr   bool
+		wantValue interface{}
+	}{
+		{
+			name:      "computing with precedence should succeed",
+			input:     "2*(3+4)-5",
+			wantValue: 9,
+		}, {
+			name:    "computing a negative result should fail",
+			input:   "2-5",
+			wantErr: true,
+		}, {
+			name:    "a float should fail",
+			input:   "2.5",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := g.Parser("")
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			// the grammar is recursive, so we don't use error recovery
+			state := comb.NewFromString(tc.input, 0)
+			gotNode, err := comb.RunOnState(state, comb.NewPreparedParser(cmb.Suffixed(p, cmb.EOF())))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.wantValue, gotNode.Value)
+			}
+		})
+	}
+}
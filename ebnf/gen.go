@@ -0,0 +1,225 @@
+package ebnf
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Generate Static Go Code For A Grammar
+//
+
+// Generate creates the Go source code of a package `pkgName`
+// containing a specialized parser for the grammar starting with the given rule.
+// If `start` is empty, the first rule of the grammar is used.
+//
+// The generated code doesn't depend on comb and has no interface dispatch.
+// It provides a `Node` type similar to the one of this package (without `Value`)
+// and a function `Parse(input string) (Node, error)` that has to match the whole input.
+//
+// NOTE:
+//   - Actions aren't part of the generated code.
+//     Walk the returned parse tree instead.
+//   - The generated parser reports only the first error (no error recovery).
+func (g *Grammar) Generate(pkgName, start string) ([]byte, error) {
+	if start == "" {
+		start = g.order[0]
+	}
+	if _, ok := g.rules[start]; !ok {
+		return nil, fmt.Errorf("unknown rule %q", start)
+	}
+
+	gn := &generator{grammar: g, ruleIndex: make(map[string]int, len(g.order))}
+	for i, name := range g.order {
+		gn.ruleIndex[name] = i
+	}
+	gn.printf(genHeader, pkgName, gn.ruleIndex[start])
+	for i, name := range g.order {
+		gn.printf("\n// rule%d parses rule %q.\n", i, name)
+		gn.printf("func (p *parser) rule%d(nodes *[]Node) bool {\n", i)
+		gn.printf("start := p.pos\nvar children []Node\n")
+		gn.printf("if !p.%s(&children) {\nreturn false\n}\n", gn.expr(g.rules[name]))
+		gn.printf("*nodes = append(*nodes, Node{Rule: %q, Start: start, End: p.pos, Text: p.input[start:p.pos], Children: children})\n", name)
+		gn.printf("return true\n}\n")
+	}
+	gn.code.Write(gn.exprs.Bytes())
+
+	src, err := format.Source(gn.code.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// generator generates one method per expression.
+// Every method returns true on success.
+// On failure it leaves the position and the nodes untouched.
+type generator struct {
+	grammar   *Grammar
+	ruleIndex map[string]int
+	code      bytes.Buffer
+	exprs     bytes.Buffer
+	count     int
+}
+
+func (gn *generator) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(&gn.code, format, args...)
+}
+
+// expr generates the method for the expression and returns its name.
+func (gn *generator) expr(e expr) string {
+	if ref, ok := e.(refExpr); ok {
+		return fmt.Sprintf("rule%d", gn.ruleIndex[string(ref)])
+	}
+
+	var body strings.Builder
+	bprintf := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(&body, format, args...)
+	}
+	switch e := e.(type) {
+	case litExpr:
+		bprintf("if strings.HasPrefix(p.input[p.pos:], %q) {\np.pos += %d\nreturn true\n}\n", string(e), len(e))
+		bprintf("p.fail(%q)\nreturn false\n", strconv.Quote(string(e)))
+	case classExpr:
+		bprintf("r, size := utf8.DecodeRuneInString(p.input[p.pos:])\n")
+		bprintf("if size > 0 && %s {\np.pos += size\nreturn true\n}\n", classCondition(e))
+		bprintf("p.fail(%q)\nreturn false\n", classString(e))
+	case anyExpr:
+		bprintf("if p.pos < len(p.input) {\n_, size := utf8.DecodeRuneInString(p.input[p.pos:])\np.pos += size\nreturn true\n}\n")
+		bprintf("p.fail(\"any character\")\nreturn false\n")
+	case seqExpr:
+		names := make([]string, len(e))
+		for i, sub := range e {
+			names[i] = "!p." + gn.expr(sub) + "(nodes)"
+		}
+		bprintf("start, n := p.pos, len(*nodes)\n")
+		bprintf("if %s {\np.pos = start\n*nodes = (*nodes)[:n]\nreturn false\n}\nreturn true\n", strings.Join(names, " || "))
+	case choiceExpr:
+		for _, sub := range e {
+			bprintf("if p.%s(nodes) {\nreturn true\n}\n", gn.expr(sub))
+		}
+		bprintf("return false\n")
+	case repeatExpr:
+		sub := gn.expr(e.expr)
+		switch e.op {
+		case '?':
+			bprintf("p.%s(nodes)\nreturn true\n", sub)
+		default:
+			if e.op == '+' {
+				bprintf("if !p.%s(nodes) {\nreturn false\n}\n", sub)
+			}
+			bprintf("for {\nstart := p.pos\nif !p.%s(nodes) || p.pos == start {\nreturn true\n}\n}\n", sub)
+		}
+	case lookExpr:
+		bprintf("start, n := p.pos, len(*nodes)\np.silent++\nok := p.%s(nodes)\np.silent--\n", gn.expr(e.expr))
+		bprintf("p.pos = start\n*nodes = (*nodes)[:n]\n")
+		if e.op == '!' {
+			bprintf("if ok {\np.fail(%q)\n}\nreturn !ok\n", "not "+describe(e.expr))
+		} else {
+			bprintf("return ok\n")
+		}
+	}
+
+	name := fmt.Sprintf("expr%d", gn.count)
+	gn.count++
+	_, _ = fmt.Fprintf(&gn.exprs, "\nfunc (p *parser) %s(nodes *[]Node) bool {\n%s}\n", name, body.String())
+	return name
+}
+
+// describe returns a short description of the expression for error messages.
+func describe(e expr) string {
+	switch e := e.(type) {
+	case litExpr:
+		return strconv.Quote(string(e))
+	case classExpr:
+		return classString(e)
+	case anyExpr:
+		return "any character"
+	case refExpr:
+		return string(e)
+	default:
+		return "a match"
+	}
+}
+
+func classCondition(c classExpr) string {
+	conds := make([]string, len(c.ranges))
+	for i, rr := range c.ranges {
+		if rr.from == rr.to {
+			conds[i] = "r == " + strconv.QuoteRune(rr.from)
+		} else {
+			conds[i] = "(r >= " + strconv.QuoteRune(rr.from) + " && r <= " + strconv.QuoteRune(rr.to) + ")"
+		}
+	}
+	cond := strings.Join(conds, " || ")
+	if c.negated {
+		return "r != utf8.RuneError && !(" + cond + ")"
+	}
+	return "(" + cond + ")"
+}
+
+const genHeader = `// Code generated by comb/ebnf. DO NOT EDIT.
+
+package %s
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Node is a node of the parse tree.
+// Every rule creates exactly one node.
+type Node struct {
+	Rule     string // name of the rule
+	Start    int    // start position of the input matched by the rule
+	End      int    // end position of the input matched by the rule
+	Text     string // input matched by the rule
+	Children []Node // nodes of the rules used by the rule
+}
+
+// Parse parses the whole input and returns the node of the start rule.
+func Parse(input string) (Node, error) {
+	p := &parser{input: input}
+	var nodes []Node
+	if !p.rule%d(&nodes) {
+		return Node{}, p.error()
+	}
+	if p.pos < len(p.input) {
+		p.fail("end of the input")
+		return Node{}, p.error()
+	}
+	return nodes[0], nil
+}
+
+type parser struct {
+	input    string
+	pos      int
+	farthest int
+	expected []string
+	silent   int // > 0 inside of lookaheads
+}
+
+// fail records what was expected at the current position.
+// Only the farthest position is kept for the error message.
+func (p *parser) fail(expected string) {
+	switch {
+	case p.silent > 0:
+	case p.pos > p.farthest:
+		p.farthest = p.pos
+		p.expected = []string{expected}
+	case p.pos == p.farthest && !slices.Contains(p.expected, expected):
+		p.expected = append(p.expected, expected)
+	}
+}
+
+func (p *parser) error() error {
+	line := 1 + strings.Count(p.input[:p.farthest], "\n")
+	col := 1 + utf8.RuneCountInString(p.input[strings.LastIndexByte(p.input[:p.farthest], '\n')+1:p.farthest])
+	return fmt.Errorf("expected %%s [%%d:%%d]", strings.Join(p.expected, " or "), line, col)
+}
+`
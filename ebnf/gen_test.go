@@ -0,0 +1,32 @@
+package ebnf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	text, err := os.ReadFile("internal/calc/calc.ebnf")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	want, err := os.ReadFile("internal/calc/calc.go")
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+	g, err := Load(string(text))
+	if err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	got, err := g.Generate("calc", "")
+	assert.NoError(t, err)
+	assert.Equal(t, string(want), string(got), "please run `go generate ./...`")
+
+	_, err = g.Generate("calc", "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,255 @@
+package ebnf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+)
+
+// ============================================================================
+// Abstract Syntax Tree Of A Grammar
+//
+
+type (
+	// expr is one of the expression types below.
+	expr interface{}
+
+	rule struct {
+		name string
+		expr expr
+	}
+	litExpr   string // literal text
+	classExpr struct {
+		negated bool
+		ranges  []runeRange
+	}
+	anyExpr    struct{} // any rune
+	refExpr    string   // reference to a rule
+	seqExpr    []expr
+	choiceExpr []expr
+	repeatExpr struct {
+		expr expr
+		op   rune // '*', '+' or '?'
+	}
+	lookExpr struct {
+		expr expr
+		op   rune // '!' or '&'
+	}
+)
+
+type runeRange struct {
+	from, to rune
+}
+
+func (c classExpr) matches(r rune) bool {
+	for _, rr := range c.ranges {
+		if rr.from <= r && r <= rr.to {
+			return !c.negated
+		}
+	}
+	return c.negated
+}
+
+// ============================================================================
+// Parser For Grammars
+//
+
+// grammarParser parses the text of a grammar.
+// The grammar of grammars is:
+//
+//	grammar = { rule } ;
+//	rule    = name ( "=" | "<-" ) choice [ ";" ] ;
+//	choice  = sequence { ( "/" | "|" ) sequence } ;
+//	sequence = prefix { prefix } ;
+//	prefix  = [ "!" | "&" ] suffix ;
+//	suffix  = primary [ "*" | "+" | "?" ] ;
+//	primary = name | literal | class | "." | "(" choice ")" ;
+//
+// Literals are enclosed in single or double quotes and can contain Go escape sequences.
+// Classes look like `[a-z_]` or `[^0-9]`.
+// Comments start with '#' or "//" and end at the end of the line.
+func grammarParser() comb.Parser[[]rule] {
+	var choice comb.Parser[expr]
+
+	primary := cmb.FirstSuccessful(
+		cmb.Map(
+			cmb.Suffixed(token(identifier()), cmb.Not(token(cmb.OneOf("=", "<-")))),
+			func(name string) (expr, error) { return refExpr(name), nil },
+		),
+		cmb.Map(token(literal()), func(s string) (expr, error) { return litExpr(s), nil }),
+		cmb.Map(token(class()), func(c classExpr) (expr, error) { return c, nil }),
+		cmb.Map(token(cmb.Char('.')), func(_ rune) (expr, error) { return anyExpr{}, nil }),
+		cmb.Delimited(
+			token(cmb.Char('(')),
+			comb.LazyBranchParser(func() comb.Parser[expr] { return choice }),
+			token(cmb.Char(')')),
+		),
+	)
+	suffix := cmb.Map2(primary, cmb.Optional(token(cmb.OneOfRunes('*', '+', '?'))),
+		func(e expr, op rune) (expr, error) {
+			if op == 0 {
+				return e, nil
+			}
+			return repeatExpr{expr: e, op: op}, nil
+		},
+	)
+	prefix := cmb.Map2(cmb.Optional(token(cmb.OneOfRunes('!', '&'))), suffix,
+		func(op rune, e expr) (expr, error) {
+			if op == 0 {
+				return e, nil
+			}
+			return lookExpr{expr: e, op: op}, nil
+		},
+	)
+	sequence := cmb.Map(cmb.Many1(prefix), func(es []expr) (expr, error) {
+		if len(es) == 1 {
+			return es[0], nil
+		}
+		return seqExpr(es), nil
+	})
+	choice = cmb.Map(cmb.Separated1(sequence, token(cmb.OneOf("/", "|")), false),
+		func(es []expr) (expr, error) {
+			if len(es) == 1 {
+				return es[0], nil
+			}
+			return choiceExpr(es), nil
+		},
+	)
+	rul := cmb.Map3(token(identifier()), token(cmb.OneOf("=", "<-")),
+		cmb.Suffixed(choice, cmb.Optional(token(cmb.Char(';')))),
+		func(name string, _ string, e expr) (rule, error) {
+			return rule{name: name, expr: e}, nil
+		},
+	)
+	return cmb.Prefixed(space(), cmb.Suffixed(cmb.Many0(rul), cmb.EOF()))
+}
+
+// token parses the token and skips the following space.
+func token[Output any](p comb.Parser[Output]) comb.Parser[Output] {
+	return cmb.Suffixed(p, space())
+}
+
+// space parses white space and comments.
+func space() comb.Parser[string] {
+	return comb.NewParser[string]("space", func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		n := 0
+		for n < len(input) {
+			r, size := utf8.DecodeRuneInString(input[n:])
+			switch {
+			case unicode.IsSpace(r):
+				n += size
+			case r == '#' || strings.HasPrefix(input[n:], "//"):
+				end := strings.IndexByte(input[n:], '\n')
+				if end < 0 {
+					end = len(input) - n
+				}
+				n += end
+			default:
+				return state.MoveBy(n), input[:n], nil
+			}
+		}
+		return state.MoveBy(n), input, nil
+	}, cmb.Forbidden())
+}
+
+func identifier() comb.Parser[string] {
+	return cmb.Map2(
+		cmb.Satisfy("rule name", func(r rune) bool { return unicode.IsLetter(r) || r == '_' }),
+		cmb.SatisfyMN("rule name", 0, math.MaxInt, cmb.IsAlphanumeric),
+		func(first rune, rest string) (string, error) {
+			return string(first) + rest, nil
+		},
+	)
+}
+
+// literal parses a literal in single or double quotes.
+func literal() comb.Parser[string] {
+	expected := "literal"
+	return comb.NewParser[string](expected, func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		if input == "" || (input[0] != '"' && input[0] != '\'') {
+			return state, "", state.NewSyntaxError(expected)
+		}
+		quote := input[0]
+		for i := 1; i < len(input); i++ {
+			switch input[i] {
+			case '\\':
+				i++
+			case quote:
+				inner := input[1:i]
+				if quote == '\'' { // convert to a Go string
+					inner = strings.ReplaceAll(strings.ReplaceAll(inner, `\'`, `'`), `"`, `\"`)
+				}
+				s, err := strconv.Unquote(`"` + inner + `"`)
+				if err != nil {
+					return state, "", state.NewSemanticError("invalid literal: %w", err)
+				}
+				if s == "" {
+					return state, "", state.NewSemanticError("empty literal")
+				}
+				return state.MoveBy(i + 1), s, nil
+			}
+		}
+		return state, "", comb.MarkIncomplete(state.NewSyntaxError("%s with closing %c", expected, quote))
+	}, nil)
+}
+
+// class parses a character class like `[a-z_]` or `[^0-9]`.
+func class() comb.Parser[classExpr] {
+	expected := "character class"
+	return comb.NewParser[classExpr](expected, func(state comb.State) (comb.State, classExpr, *comb.ParserError) {
+		var c classExpr
+
+		input := state.CurrentString()
+		if !strings.HasPrefix(input, "[") {
+			return state, c, state.NewSyntaxError(expected)
+		}
+		n := 1
+		if strings.HasPrefix(input[n:], "^") {
+			c.negated = true
+			n++
+		}
+		nextRune := func() rune {
+			r, size := utf8.DecodeRuneInString(input[n:])
+			if r == '\\' && n+size < len(input) {
+				n += size
+				r, size = utf8.DecodeRuneInString(input[n:])
+				switch r {
+				case 'n':
+					r = '\n'
+				case 'r':
+					r = '\r'
+				case 't':
+					r = '\t'
+				}
+			}
+			n += size
+			return r
+		}
+		for n < len(input) && input[n] != ']' {
+			from := nextRune()
+			to := from
+			if n+1 < len(input) && input[n] == '-' && input[n+1] != ']' {
+				n++
+				to = nextRune()
+			}
+			if to < from {
+				return state, c, state.MoveBy(n).NewSemanticError("invalid range %q-%q", from, to)
+			}
+			c.ranges = append(c.ranges, runeRange{from: from, to: to})
+		}
+		if n >= len(input) {
+			return state, c, comb.MarkIncomplete(state.NewSyntaxError("%s with closing ']'", expected))
+		}
+		if len(c.ranges) == 0 {
+			return state, c, state.NewSemanticError("empty %s", expected)
+		}
+		return state.MoveBy(n + 1), c, nil
+	}, nil)
+}
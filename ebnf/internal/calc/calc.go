@@ -0,0 +1,274 @@
+// Code generated by comb/ebnf. DO NOT EDIT.
+
+package calc
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Node is a node of the parse tree.
+// Every rule creates exactly one node.
+type Node struct {
+	Rule     string // name of the rule
+	Start    int    // start position of the input matched by the rule
+	End      int    // end position of the input matched by the rule
+	Text     string // input matched by the rule
+	Children []Node // nodes of the rules used by the rule
+}
+
+// Parse parses the whole input and returns the node of the start rule.
+func Parse(input string) (Node, error) {
+	p := &parser{input: input}
+	var nodes []Node
+	if !p.rule0(&nodes) {
+		return Node{}, p.error()
+	}
+	if p.pos < len(p.input) {
+		p.fail("end of the input")
+		return Node{}, p.error()
+	}
+	return nodes[0], nil
+}
+
+type parser struct {
+	input    string
+	pos      int
+	farthest int
+	expected []string
+	silent   int // > 0 inside of lookaheads
+}
+
+// fail records what was expected at the current position.
+// Only the farthest position is kept for the error message.
+func (p *parser) fail(expected string) {
+	switch {
+	case p.silent > 0:
+	case p.pos > p.farthest:
+		p.farthest = p.pos
+		p.expected = []string{expected}
+	case p.pos == p.farthest && !slices.Contains(p.expected, expected):
+		p.expected = append(p.expected, expected)
+	}
+}
+
+func (p *parser) error() error {
+	line := 1 + strings.Count(p.input[:p.farthest], "\n")
+	col := 1 + utf8.RuneCountInString(p.input[strings.LastIndexByte(p.input[:p.farthest], '\n')+1:p.farthest])
+	return fmt.Errorf("expected %s [%d:%d]", strings.Join(p.expected, " or "), line, col)
+}
+
+// rule0 parses rule "sum".
+func (p *parser) rule0(nodes *[]Node) bool {
+	start := p.pos
+	var children []Node
+	if !p.expr3(&children) {
+		return false
+	}
+	*nodes = append(*nodes, Node{Rule: "sum", Start: start, End: p.pos, Text: p.input[start:p.pos], Children: children})
+	return true
+}
+
+// rule1 parses rule "product".
+func (p *parser) rule1(nodes *[]Node) bool {
+	start := p.pos
+	var children []Node
+	if !p.expr7(&children) {
+		return false
+	}
+	*nodes = append(*nodes, Node{Rule: "product", Start: start, End: p.pos, Text: p.input[start:p.pos], Children: children})
+	return true
+}
+
+// rule2 parses rule "value".
+func (p *parser) rule2(nodes *[]Node) bool {
+	start := p.pos
+	var children []Node
+	if !p.expr11(&children) {
+		return false
+	}
+	*nodes = append(*nodes, Node{Rule: "value", Start: start, End: p.pos, Text: p.input[start:p.pos], Children: children})
+	return true
+}
+
+// rule3 parses rule "number".
+func (p *parser) rule3(nodes *[]Node) bool {
+	start := p.pos
+	var children []Node
+	if !p.expr16(&children) {
+		return false
+	}
+	*nodes = append(*nodes, Node{Rule: "number", Start: start, End: p.pos, Text: p.input[start:p.pos], Children: children})
+	return true
+}
+
+func (p *parser) expr0(nodes *[]Node) bool {
+	r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+	if size > 0 && (r == '+' || r == '-') {
+		p.pos += size
+		return true
+	}
+	p.fail("[+-]")
+	return false
+}
+
+func (p *parser) expr1(nodes *[]Node) bool {
+	start, n := p.pos, len(*nodes)
+	if !p.expr0(nodes) || !p.rule1(nodes) {
+		p.pos = start
+		*nodes = (*nodes)[:n]
+		return false
+	}
+	return true
+}
+
+func (p *parser) expr2(nodes *[]Node) bool {
+	for {
+		start := p.pos
+		if !p.expr1(nodes) || p.pos == start {
+			return true
+		}
+	}
+}
+
+func (p *parser) expr3(nodes *[]Node) bool {
+	start, n := p.pos, len(*nodes)
+	if !p.rule1(nodes) || !p.expr2(nodes) {
+		p.pos = start
+		*nodes = (*nodes)[:n]
+		return false
+	}
+	return true
+}
+
+func (p *parser) expr4(nodes *[]Node) bool {
+	if strings.HasPrefix(p.input[p.pos:], "*") {
+		p.pos += 1
+		return true
+	}
+	p.fail("\"*\"")
+	return false
+}
+
+func (p *parser) expr5(nodes *[]Node) bool {
+	start, n := p.pos, len(*nodes)
+	if !p.expr4(nodes) || !p.rule2(nodes) {
+		p.pos = start
+		*nodes = (*nodes)[:n]
+		return false
+	}
+	return true
+}
+
+func (p *parser) expr6(nodes *[]Node) bool {
+	for {
+		start := p.pos
+		if !p.expr5(nodes) || p.pos == start {
+			return true
+		}
+	}
+}
+
+func (p *parser) expr7(nodes *[]Node) bool {
+	start, n := p.pos, len(*nodes)
+	if !p.rule2(nodes) || !p.expr6(nodes) {
+		p.pos = start
+		*nodes = (*nodes)[:n]
+		return false
+	}
+	return true
+}
+
+func (p *parser) expr8(nodes *[]Node) bool {
+	if strings.HasPrefix(p.input[p.pos:], "(") {
+		p.pos += 1
+		return true
+	}
+	p.fail("\"(\"")
+	return false
+}
+
+func (p *parser) expr9(nodes *[]Node) bool {
+	if strings.HasPrefix(p.input[p.pos:], ")") {
+		p.pos += 1
+		return true
+	}
+	p.fail("\")\"")
+	return false
+}
+
+func (p *parser) expr10(nodes *[]Node) bool {
+	start, n := p.pos, len(*nodes)
+	if !p.expr8(nodes) || !p.rule0(nodes) || !p.expr9(nodes) {
+		p.pos = start
+		*nodes = (*nodes)[:n]
+		return false
+	}
+	return true
+}
+
+func (p *parser) expr11(nodes *[]Node) bool {
+	if p.rule3(nodes) {
+		return true
+	}
+	if p.expr10(nodes) {
+		return true
+	}
+	return false
+}
+
+func (p *parser) expr12(nodes *[]Node) bool {
+	r, size := utf8.DecodeRuneInString(p.input[p.pos:])
+	if size > 0 && (r >= '0' && r <= '9') {
+		p.pos += size
+		return true
+	}
+	p.fail("[0-9]")
+	return false
+}
+
+func (p *parser) expr13(nodes *[]Node) bool {
+	if !p.expr12(nodes) {
+		return false
+	}
+	for {
+		start := p.pos
+		if !p.expr12(nodes) || p.pos == start {
+			return true
+		}
+	}
+}
+
+func (p *parser) expr14(nodes *[]Node) bool {
+	if strings.HasPrefix(p.input[p.pos:], ".") {
+		p.pos += 1
+		return true
+	}
+	p.fail("\".\"")
+	return false
+}
+
+func (p *parser) expr15(nodes *[]Node) bool {
+	start, n := p.pos, len(*nodes)
+	p.silent++
+	ok := p.expr14(nodes)
+	p.silent--
+	p.pos = start
+	*nodes = (*nodes)[:n]
+	if ok {
+		p.fail("not \".\"")
+	}
+	return !ok
+}
+
+func (p *parser) expr16(nodes *[]Node) bool {
+	start, n := p.pos, len(*nodes)
+	if !p.expr13(nodes) || !p.expr15(nodes) {
+		p.pos = start
+		*nodes = (*nodes)[:n]
+		return false
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+package calc
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		input        string
+		wantErr      string
+		wantRules    string
+		wantChildren int
+	}{
+		{
+			name:         "parsing a valid expression should succeed",
+			input:        "2*(3+4)-5",
+			wantRules:    "sum",
+			wantChildren: 2,
+		}, {
+			name:    "parsing a float should fail",
+			input:   "2.5",
+			wantErr: `expected [0-9] or not "." [1:2]`,
+		}, {
+			name:    "parsing an unclosed parenthesis should fail",
+			input:   "2*(3+4",
+			wantErr: `expected [0-9] or "*" or [+-] or ")" [1:7]`,
+		}, {
+			name:    "parsing trailing input should fail",
+			input:   "2 ",
+			wantErr: `expected [0-9] or "*" or [+-] or end of the input [1:2]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotNode, err := Parse(tc.input)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Errorf("got error %v, want error %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			if gotNode.Rule != tc.wantRules || gotNode.Text != tc.input {
+				t.Errorf("got node %q with text %q, want node %q with text %q", gotNode.Rule, gotNode.Text, tc.wantRules, tc.input)
+			}
+			if len(gotNode.Children) != tc.wantChildren {
+				t.Errorf("got %d children, want %d", len(gotNode.Children), tc.wantChildren)
+			}
+		})
+	}
+}
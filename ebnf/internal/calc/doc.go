@@ -0,0 +1,5 @@
+// Package calc contains a parser generated from calc.ebnf.
+// It is used to test the code generated by the ebnf package.
+package calc
+
+//go:generate go run github.com/flowdev/comb/cmd/combgen -o calc.go calc.ebnf
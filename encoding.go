@@ -0,0 +1,317 @@
+package comb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is the character encoding of some input.
+type Encoding int
+
+const (
+	EncodingUnknown Encoding = iota // binary data or unknown encoding
+	EncodingASCII
+	EncodingUTF8
+	EncodingUTF16LE
+	EncodingUTF16BE
+	EncodingUTF32LE
+	EncodingUTF32BE
+)
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingASCII:
+		return "ASCII"
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingUTF32LE:
+		return "UTF-32LE"
+	case EncodingUTF32BE:
+		return "UTF-32BE"
+	default:
+		return "unknown"
+	}
+}
+
+// boms are the byte-order-marks of the encodings.
+// UTF-32LE has to be checked before UTF-16LE because the BOM of UTF-16LE is a prefix of it.
+var boms = []struct {
+	encoding Encoding
+	bom      []byte
+}{
+	{EncodingUTF8, []byte{0xEF, 0xBB, 0xBF}},
+	{EncodingUTF32LE, []byte{0xFF, 0xFE, 0x00, 0x00}},
+	{EncodingUTF32BE, []byte{0x00, 0x00, 0xFE, 0xFF}},
+	{EncodingUTF16LE, []byte{0xFF, 0xFE}},
+	{EncodingUTF16BE, []byte{0xFE, 0xFF}},
+}
+
+// SniffEncoding guesses the encoding of the input and returns it together
+// with the confidence of the guess (between 0 and 1).
+// A byte-order-mark (BOM) is always trusted (confidence 1).
+// Without a BOM, UTF-32 and UTF-16 are detected by the patterns of zero bytes,
+// and ASCII and UTF-8 by validity and the share of printable characters.
+func SniffEncoding(b []byte) (Encoding, float64) {
+	if enc, _ := bomEncoding(b); enc != EncodingUnknown {
+		return enc, 1
+	}
+	if len(b) == 0 {
+		return EncodingASCII, 1
+	}
+
+	if bytes.IndexByte(b, 0) >= 0 { // text in UTF-8 doesn't contain zero bytes
+		if score := utf32Score(b, binary.LittleEndian); score >= 0.9 {
+			return EncodingUTF32LE, score
+		}
+		if score := utf32Score(b, binary.BigEndian); score >= 0.9 {
+			return EncodingUTF32BE, score
+		}
+		if score := utf16Score(b, binary.LittleEndian); score >= 0.5 {
+			return EncodingUTF16LE, score
+		}
+		if score := utf16Score(b, binary.BigEndian); score >= 0.5 {
+			return EncodingUTF16BE, score
+		}
+	}
+
+	if !utf8.Valid(b) {
+		return EncodingUnknown, 0
+	}
+	enc := EncodingUTF8
+	if isASCII(b) {
+		enc = EncodingASCII
+	}
+	return enc, textScore(string(b))
+}
+
+// RunOnFile reads the file, sniffs its encoding (see SniffEncoding) and
+// runs the parser on it.
+// Text in any of the Unicode encodings is converted to UTF-8 (without BOM)
+// and parsed like with RunOnString. So all positions refer to the converted text.
+// Input of unknown encoding is parsed like with RunOnBytes.
+func RunOnFile[Output any](path string, parse Parser[Output]) (Output, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ZeroOf[Output](), err
+	}
+	return RunOnState(newStateFromFile(b, DefaultMaxErrors), NewPreparedParser(parse))
+}
+
+// newStateFromFile creates a new parser state from the content of a file
+// like RunOnFile does.
+func newStateFromFile(b []byte, maxErrors int) State {
+	enc, _ := SniffEncoding(b)
+	if enc == EncodingUnknown {
+		return NewFromBytes(b, maxErrors)
+	}
+	return NewFromString(decodeToUTF8(b, enc), maxErrors)
+}
+
+// WithStrictUTF8 returns the state with the text input validated up front.
+// Every invalid byte is reported as a (recoverable) error and replaced with
+// utf8.RuneError (U+FFFD) for parsing.
+// So leaf parsers don't fail with generic UTF-8 errors at arbitrary positions.
+// All positions refer to the text with the replacements.
+// Binary input and input in Latin-1 mode (see WithLatin1) aren't changed.
+// It has to be called before parsing starts.
+func (st State) WithStrictUTF8() State {
+	text := st.constant.text
+	if st.constant.binary || st.constant.latin1 || utf8.ValidString(text) {
+		return st
+	}
+
+	type invalidByte struct {
+		pos int // position in the valid text
+		b   byte
+	}
+	var invalid []invalidByte
+	valid := strings.Builder{}
+	valid.Grow(len(text) + 8)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r == utf8.RuneError && size == 1 {
+			invalid = append(invalid, invalidByte{pos: valid.Len(), b: text[i]})
+			valid.WriteRune(utf8.RuneError)
+		} else {
+			valid.WriteString(text[i : i+size])
+		}
+		i += size
+	}
+	constant := *st.constant
+	constant.text, constant.n = valid.String(), valid.Len()
+	if constant.byteIndex != nil { // the replacements moved the indexed bytes
+		constant.byteIndex = constant.byteIndex.of(&constant)
+	}
+	st.constant = &constant
+
+	start := st
+	for _, ib := range invalid {
+		st = st.SaveError(start.MoveBy(ib.pos).NewSemanticError(
+			"invalid UTF-8 byte 0x%02x (replaced by U+FFFD)", ib.b))
+		if st.AtEnd() { // too many errors
+			break
+		}
+	}
+	return st
+}
+
+// WithLatin1 returns the state with every byte of the text input treated as
+// a character (ISO 8859-1 a.k.a. Latin-1).
+// So the character-class parsers (e.g. cmb.Satisfy or cmb.Alpha1) work on
+// single bytes without UTF-8 decoding for legacy formats
+// (e.g. old game data files or some network protocols).
+// The output strings contain the bytes of the input unchanged
+// but error messages are converted to UTF-8.
+// Leaf parsers should use State.CurrentRune, State.DecodeRune and
+// State.EncodeString to support this mode.
+// Normalizers (see WithNormalizer) aren't applied in this mode.
+// Binary input isn't changed.
+// It has to be called before parsing starts.
+func (st State) WithLatin1() State {
+	if st.constant.binary {
+		return st
+	}
+	constant := *st.constant
+	constant.latin1 = true
+	st.constant = &constant
+	return st
+}
+
+// EncodeString returns the UTF-8 string s in the encoding of the text input.
+// In Latin-1 mode (see WithLatin1) every character is converted to its byte.
+// It returns false if s contains characters that aren't part of Latin-1.
+// So leaf parsers can match their tokens in both modes.
+func (st State) EncodeString(s string) (string, bool) {
+	if !st.constant.latin1 || strings.IndexFunc(s, isNotASCII) < 0 {
+		return s, true
+	}
+	result := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 0xFF {
+			return "", false
+		}
+		result = append(result, byte(r))
+	}
+	return string(result), true
+}
+
+func isNotASCII(r rune) bool {
+	return r >= utf8.RuneSelf
+}
+
+// latin1ToUTF8 converts the Latin-1 encoded s to UTF-8.
+func latin1ToUTF8(s string) string {
+	result := strings.Builder{}
+	result.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		result.WriteRune(rune(s[i]))
+	}
+	return result.String()
+}
+
+// decodeToUTF8 converts the input from the encoding to UTF-8.
+// A byte-order-mark is removed.
+// Invalid input is replaced by utf8.RuneError and
+// input of unknown encoding is returned unchanged.
+func decodeToUTF8(b []byte, enc Encoding) string {
+	if bomEnc, n := bomEncoding(b); bomEnc == enc {
+		b = b[n:]
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	switch enc {
+	case EncodingUTF16BE, EncodingUTF32BE:
+		order = binary.BigEndian
+	}
+
+	switch enc {
+	case EncodingASCII, EncodingUTF8:
+		return strings.ToValidUTF8(string(b), string(utf8.RuneError))
+	case EncodingUTF16LE, EncodingUTF16BE:
+		units := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			units = append(units, order.Uint16(b[i:]))
+		}
+		return string(utf16.Decode(units))
+	case EncodingUTF32LE, EncodingUTF32BE:
+		sb := strings.Builder{}
+		sb.Grow(len(b) / 4)
+		for i := 0; i+3 < len(b); i += 4 {
+			sb.WriteRune(rune(order.Uint32(b[i:]))) // invalid runes are written as utf8.RuneError
+		}
+		return sb.String()
+	default:
+		return string(b)
+	}
+}
+
+// bomEncoding returns the encoding and the length of the byte-order-mark
+// at the start of the input (or EncodingUnknown and 0).
+func bomEncoding(b []byte) (Encoding, int) {
+	for _, bom := range boms {
+		if bytes.HasPrefix(b, bom.bom) {
+			return bom.encoding, len(bom.bom)
+		}
+	}
+	return EncodingUnknown, 0
+}
+
+// utf32Score returns the share of 4-byte units that are valid, non-zero runes.
+func utf32Score(b []byte, order binary.ByteOrder) float64 {
+	if len(b)%4 != 0 {
+		return 0
+	}
+	valid := 0
+	for i := 0; i < len(b); i += 4 {
+		r := order.Uint32(b[i:])
+		if r != 0 && r <= unicode.MaxRune && !utf16.IsSurrogate(rune(r)) {
+			valid++
+		}
+	}
+	return float64(valid) / float64(len(b)/4)
+}
+
+// utf16Score returns the share of 2-byte units that are non-zero ASCII characters.
+func utf16Score(b []byte, order binary.ByteOrder) float64 {
+	if len(b)%2 != 0 {
+		return 0
+	}
+	ascii := 0
+	for i := 0; i < len(b); i += 2 {
+		u := order.Uint16(b[i:])
+		if u != 0 && u < utf8.RuneSelf {
+			ascii++
+		}
+	}
+	return float64(ascii) / float64(len(b)/2)
+}
+
+// textScore returns the share of printable characters and white space.
+func textScore(text string) float64 {
+	n, printable := 0, 0
+	for _, r := range text {
+		n++
+		if unicode.IsPrint(r) || unicode.IsSpace(r) {
+			printable++
+		}
+	}
+	return float64(printable) / float64(n)
+}
+
+func isASCII(b []byte) bool {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,295 @@
+package comb_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniffEncoding(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		input          []byte
+		wantEncoding   comb.Encoding
+		wantConfidence float64
+	}{
+		{
+			name:           "empty",
+			input:          []byte{},
+			wantEncoding:   comb.EncodingASCII,
+			wantConfidence: 1,
+		}, {
+			name:           "ASCII",
+			input:          []byte("abc"),
+			wantEncoding:   comb.EncodingASCII,
+			wantConfidence: 1,
+		}, {
+			name:           "UTF-8",
+			input:          []byte("äöü"),
+			wantEncoding:   comb.EncodingUTF8,
+			wantConfidence: 1,
+		}, {
+			name:           "UTF-8 BOM",
+			input:          []byte("\xEF\xBB\xBFabc"),
+			wantEncoding:   comb.EncodingUTF8,
+			wantConfidence: 1,
+		}, {
+			name:           "UTF-16LE BOM",
+			input:          []byte("\xFF\xFEa\x00"),
+			wantEncoding:   comb.EncodingUTF16LE,
+			wantConfidence: 1,
+		}, {
+			name:           "UTF-16BE BOM",
+			input:          []byte("\xFE\xFF\x00a"),
+			wantEncoding:   comb.EncodingUTF16BE,
+			wantConfidence: 1,
+		}, {
+			name:           "UTF-32LE BOM",
+			input:          []byte("\xFF\xFE\x00\x00a\x00\x00\x00"),
+			wantEncoding:   comb.EncodingUTF32LE,
+			wantConfidence: 1,
+		}, {
+			name:           "UTF-32BE BOM",
+			input:          []byte("\x00\x00\xFE\xFF\x00\x00\x00a"),
+			wantEncoding:   comb.EncodingUTF32BE,
+			wantConfidence: 1,
+		}, {
+			name:           "UTF-16LE without BOM",
+			input:          []byte("a\x00b\x00"),
+			wantEncoding:   comb.EncodingUTF16LE,
+			wantConfidence: 1,
+		}, {
+			name:           "UTF-32BE without BOM",
+			input:          []byte("\x00\x00\x00a\x00\x00\x00b"),
+			wantEncoding:   comb.EncodingUTF32BE,
+			wantConfidence: 1,
+		}, {
+			name:           "binary",
+			input:          []byte{0x80, 0x81, 0xFF},
+			wantEncoding:   comb.EncodingUnknown,
+			wantConfidence: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			gotEncoding, gotConfidence := comb.SniffEncoding(tc.input)
+			assert.Equal(t, tc.wantEncoding, gotEncoding, "encoding")
+			assert.InDelta(t, tc.wantConfidence, gotConfidence, 0.001, "confidence")
+		})
+	}
+}
+
+func TestRunOnFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		content []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "UTF-8",
+			content: []byte("abc"),
+			want:    "abc",
+		}, {
+			name:    "UTF-8 BOM",
+			content: []byte("\xEF\xBB\xBFabc"),
+			want:    "abc",
+		}, {
+			name:    "UTF-16BE BOM",
+			content: []byte("\xFE\xFF\x00a\x00b\x00c"),
+			want:    "abc",
+		}, {
+			name:    "UTF-32LE BOM",
+			content: []byte("\xFF\xFE\x00\x00a\x00\x00\x00b\x00\x00\x00"),
+			want:    "ab",
+		}, {
+			name:    "not text",
+			content: []byte("1"),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "input.txt")
+			if err := os.WriteFile(path, tc.content, 0o600); err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			got, err := comb.RunOnFile(path, cmb.Alpha1())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr {
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+		_, err := comb.RunOnFile(filepath.Join(t.TempDir(), "missing.txt"), cmb.Alpha1())
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestWithStrictUTF8(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantOutput string
+		wantErrs   []string
+	}{
+		{
+			name:       "valid input",
+			input:      "aäb",
+			wantOutput: "aäb",
+		}, {
+			name:       "invalid bytes",
+			input:      "a\xffb\xc3",
+			wantOutput: "a�b�",
+			wantErrs: []string{
+				"invalid UTF-8 byte 0xff (replaced by U+FFFD) [1:2] a▶�b�",
+				"invalid UTF-8 byte 0xc3 (replaced by U+FFFD) [1:4] a�b▶�",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := comb.NewFromString(tc.input, 10).WithStrictUTF8()
+			got, err := comb.RunOnState(state, comb.NewPreparedParser(
+				cmb.SatisfyMN("any", 0, 10, func(rune) bool { return true }),
+			))
+			assert.Equal(t, tc.wantOutput, got)
+			var gotErrs []string
+			for _, e := range comb.UnwrapErrors(err) {
+				gotErrs = append(gotErrs, e.Error())
+			}
+			assert.Equal(t, tc.wantErrs, gotErrs)
+		})
+	}
+}
+
+func TestWithLatin1(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantOutput []string
+		wantErr    string
+	}{
+		{
+			name:       "every byte is a character",
+			input:      "caf\xe9 na\xefve",
+			wantOutput: []string{"caf\xe9", "na\xefve"},
+		}, {
+			name:       "error messages are UTF-8",
+			input:      "\xe9t\xe9!",
+			wantOutput: []string{"\xe9t\xe9"},
+			wantErr:    "expected end of the input (still 1 bytes of input left) [1:4] été▶!",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := comb.NewFromString(tc.input, comb.FailFast).WithLatin1()
+			got, err := comb.RunOnState(state, comb.NewPreparedParser(
+				cmb.Suffixed(cmb.Separated1(cmb.Alpha1(), cmb.Space(), false), cmb.EOF()),
+			))
+			assert.Equal(t, tc.wantOutput, got)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestLatin1Recovery(t *testing.T) {
+	t.Parallel()
+
+	input := "caf\xe9 n\xe9; 12 \xe0 propos; \xc9t\xe9 n\xe9;"
+	testCases := []struct {
+		name   string
+		parser comb.AnyParser
+		pos    int
+		want   int
+	}{
+		{
+			name:   "Char",
+			parser: cmb.Char('é'),
+			pos:    0,
+			want:   3,
+		}, {
+			name:   "String",
+			parser: cmb.String("né"),
+			pos:    1,
+			want:   4,
+		}, {
+			name:   "OneOf",
+			parser: cmb.OneOf("à", "É"),
+			pos:    0,
+			want:   12,
+		}, {
+			name:   "OneOfRunes",
+			parser: cmb.OneOfRunes('à', 'É'),
+			pos:    13,
+			want:   9,
+		}, {
+			name:   "Satisfy",
+			parser: cmb.Satisfy("upper case letter", unicode.IsUpper),
+			pos:    0,
+			want:   22,
+		}, {
+			name:   "character not in Latin-1",
+			parser: cmb.Char('€'),
+			pos:    0,
+			want:   comb.RecoverWasteTooMuch,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := comb.NewFromString(input, 10).WithLatin1().MoveBy(tc.pos)
+			waste, _ := tc.parser.Recover(state, nil)
+			assert.Equal(t, tc.want, waste)
+			if waste >= 0 {
+				_, _, err := tc.parser.ParseAny(comb.ParentUnknown, state.MoveBy(waste))
+				assert.Nil(t, err)
+			}
+		})
+	}
+
+	got, err := comb.RunOnState(comb.NewFromString("ab\xa7 12\xa7cd\xa7", 10).WithLatin1(), comb.NewPreparedParser(
+		cmb.Suffixed(cmb.Many1(cmb.Suffixed(cmb.Alpha1(), comb.SafeSpot(cmb.Char('§')))), cmb.EOF()),
+	))
+	assert.ErrorContains(t, err, `" 12"`)
+	assert.Contains(t, got, "cd", "parsing should continue after the safe spot")
+}
@@ -0,0 +1,39 @@
+package comb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// EquivalentParsers is a test helper that runs both parsers over all inputs
+// of the corpus and reports the first divergence in output, consumption or
+// error position. It returns nil if the parsers behave the same.
+// This is useful for proving that a refactored grammar (e.g., for better
+// performance) still behaves like the original one.
+// The parsers are run without error recovery.
+// They are prepared by EquivalentParsers, so they must not have been
+// prepared before and must not share any parsers.
+func EquivalentParsers(p1, p2 AnyParser, corpus []string) error {
+	pp1 := newPreparedParser[interface{}](p1)
+	pp2 := newPreparedParser[interface{}](p2)
+	if len(pp1.parsers) == 0 || len(pp2.parsers) == 0 {
+		return fmt.Errorf("parsers must not have been prepared before")
+	}
+
+	for i, input := range corpus {
+		state1, out1, err1 := pp1.parsers[0].ParseAny(ParentUnknown, NewFromString(input, FailFast))
+		state2, out2, err2 := pp2.parsers[0].ParseAny(ParentUnknown, NewFromString(input, FailFast))
+		switch {
+		case (err1 == nil) != (err2 == nil):
+			return fmt.Errorf("input %d (%q): error differs: %v != %v", i, input, err1, err2)
+		case err1 != nil && err1.pos != err2.pos:
+			return fmt.Errorf("input %d (%q): error position differs: %d != %d", i, input, err1.pos, err2.pos)
+		case state1.CurrentPos() != state2.CurrentPos():
+			return fmt.Errorf("input %d (%q): consumption differs: %d != %d bytes",
+				i, input, state1.CurrentPos(), state2.CurrentPos())
+		case err1 == nil && !reflect.DeepEqual(out1, out2):
+			return fmt.Errorf("input %d (%q): output differs: %#v != %#v", i, input, out1, out2)
+		}
+	}
+	return nil
+}
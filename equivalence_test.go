@@ -0,0 +1,71 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEquivalentParsers(t *testing.T) {
+	t.Parallel()
+
+	corpus := []string{"abc", "abc123", "123", ""}
+
+	testCases := []struct {
+		name      string
+		newParser func() comb.AnyParser
+		wantErr   string
+	}{
+		{
+			name: "equivalent parsers",
+			newParser: func() comb.AnyParser {
+				return cmb.Map(cmb.Many1(cmb.Satisfy("letter", isLetter)), func(runes []rune) (string, error) {
+					return string(runes), nil
+				})
+			},
+		}, {
+			name: "different output",
+			newParser: func() comb.AnyParser {
+				return cmb.Map(cmb.Alpha1(), func(s string) (string, error) {
+					return s + "!", nil
+				})
+			},
+			wantErr: "output differs",
+		}, {
+			name: "different consumption",
+			newParser: func() comb.AnyParser {
+				return cmb.Map(cmb.Alphanumeric1(), func(s string) (string, error) {
+					return s, nil
+				})
+			},
+			wantErr: "consumption differs",
+		}, {
+			name: "different error",
+			newParser: func() comb.AnyParser {
+				return cmb.Map(cmb.Alpha0(), func(s string) (string, error) {
+					return s, nil
+				})
+			},
+			wantErr: "error differs",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := comb.EquivalentParsers(cmb.Alpha1(), tc.newParser(), corpus)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
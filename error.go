@@ -2,8 +2,12 @@ package comb
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -17,35 +21,161 @@ const SyntaxErrorStart = "expected "
 // ParserError is an error message from the parser.
 // It consists of the text itself and the position in the input where it happened.
 type ParserError struct {
-	text       string                // the error message from the parser
+	text       string                // the error message from the parser (see message)
+	format     string                // format of the message if it hasn't been formatted yet
+	args       []interface{}         // arguments of the format
 	pos        int                   // pos is the byte index in the input (state.pos)
-	line, col  int                   // col is the 0-based byte index within srcLine; convert to 1-based rune index for user
-	srcLine    string                // line of the source code containing the error or bytes around the error in binary case
+	at         State                 // location of the error until line, col, srcLine and context are computed (see locate)
+	line, col  int                   // col is the 0-based byte index within srcLine
+	column     int                   // 1-based rune index of the error within the full source line (text only)
+	srcLine    string                // (clipped) line of the source code containing the error or bytes around the error in binary case
+	source     string                // name of the input (only set for included input, see PushInput)
 	binary     bool                  // are we in binary or text mode?
 	parserID   int32                 // ID of the parser reporting the error
+	parserName string                // Expected() of the parser reporting the error
 	parserData map[int32]interface{} // temporary (partial) data from parsers
+	incomplete bool                  // more input might fix the error
+	fatal      bool                  // error recovery isn't allowed
+	kind       ErrorKind             // classification by the error recovery
+	waste      int                   // number of bytes skipped by the error recovery
+	safeSpot   bool                  // the error recovery used a safe spot parser
+	wrapped    []error               // errors wrapped with %w
+	context    string                // sanitized input around the error (see Context)
+	budget     *timeBudget           // time budget of the subtree the error happened in (see WithTimeout)
+	lazy       sync.Mutex            // guards the lazy computations (see locate and message)
+}
+
+// syntaxMessages interns the messages of syntax errors including SyntaxErrorStart.
+// So the hot error path doesn't concatenate strings for the `expected`
+// descriptors of parsers.
+var syntaxMessages = struct {
+	sync.RWMutex
+	m map[string]string
+}{m: make(map[string]string)}
+
+// maxSyntaxMessages limits the number of interned messages
+// because messages can be generated dynamically.
+const maxSyntaxMessages = 4096
+
+// internSyntaxMessage returns the message with SyntaxErrorStart prepended.
+func internSyntaxMessage(msg string) string {
+	syntaxMessages.RLock()
+	full, ok := syntaxMessages.m[msg]
+	syntaxMessages.RUnlock()
+	if ok {
+		return full
+	}
+	full = SyntaxErrorStart + msg
+	syntaxMessages.Lock()
+	if len(syntaxMessages.m) < maxSyntaxMessages {
+		syntaxMessages.m[msg] = full
+	}
+	syntaxMessages.Unlock()
+	return full
+}
+
+// ErrorKind classifies a syntax error by the repair that error recovery used.
+type ErrorKind int
+
+const (
+	ErrorUnclassified ErrorKind = iota // no recovery (yet) or not a syntax error
+	ErrorMissing                       // the expected input is missing (repaired by insertion)
+	ErrorUnexpected                    // unexpected input before the expected one (repaired by deletion)
+	ErrorSubstituted                   // unexpected input instead of the expected one (repaired by substitution)
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorMissing:
+		return "missing"
+	case ErrorUnexpected:
+		return "unexpected"
+	case ErrorSubstituted:
+		return "substituted"
+	default:
+		return "unclassified"
+	}
 }
 
 func (e *ParserError) Error() string {
+	e.locate()
 	fullMsg := strings.Builder{}
-	fullMsg.WriteString(e.text)
+	fullMsg.WriteString(e.message())
 	if e.binary {
 		fullMsg.WriteString(formatBinaryLine(e.line, e.col, e.srcLine))
 	} else {
-		fullMsg.WriteString(formatSrcLine(e.line, e.col, e.srcLine))
+		fullMsg.WriteString(formatSrcLine(e.source, e.line, e.column, e.col, e.srcLine))
 	}
 	return fullMsg.String()
 }
 
+// Unwrap returns the errors wrapped with %w (see State.NewSemanticError).
+// So errors.Is and errors.As work through parser errors.
+func (e *ParserError) Unwrap() []error {
+	return e.wrapped
+}
+
+// Context returns the input around the error position with a marker (▶)
+// at the position itself (see State.WithErrorContext).
+// Non-printable characters are escaped like in Go strings.
+// So log-only environments without the input at hand still show
+// what was actually seen.
+func (e *ParserError) Context() string {
+	e.locate()
+	return e.context
+}
+
+// Message returns the error message without position and source line.
+func (e *ParserError) Message() string {
+	return e.message()
+}
+
+// locate computes the line, column, source line and context of the error
+// the first time they are needed.
+// So errors of branches that are merely probing (e.g. alternatives)
+// stay cheap.
+// It is safe for concurrent use, so errors can be reported from many goroutines.
+func (e *ParserError) locate() {
+	e.lazy.Lock()
+	defer e.lazy.Unlock()
+	at := e.at
+	if at.constant == nil {
+		return
+	}
+	e.at = State{}
+	if at.constant.binary { // the rare binary case is misusing the text case data a bit...
+		e.line, e.col, e.srcLine = at.bytesAround(e.pos)
+	} else {
+		e.line, e.column, e.col, e.srcLine = at.clippedTextAround(e.pos)
+	}
+	e.context = at.contextAround(e.pos)
+}
+
+// message formats the message the first time it is needed.
+// So errors that are never reported don't cost any fmt.Sprintf calls.
+// It is safe for concurrent use like locate.
+func (e *ParserError) message() string {
+	e.lazy.Lock()
+	defer e.lazy.Unlock()
+	if e.format != "" {
+		e.text = fmt.Sprintf(e.format, e.args...)
+		e.format, e.args = "", nil
+	}
+	return e.text
+}
+
 func (e *ParserError) ParserData(parserID int32) interface{} {
 	return e.parserData[parserID]
 }
 func (e *ParserError) StoreParserData(parserID int32, data interface{}) {
+	if e.parserData == nil {
+		e.parserData = make(map[int32]interface{})
+	}
 	e.parserData[parserID] = data
 }
 
 func (e *ParserError) PatchMessage(subMsg string) {
-	if strings.Contains(e.text, subMsg) {
+	if strings.Contains(e.message(), subMsg) {
 		return
 	}
 	if strings.HasPrefix(e.text, SyntaxErrorStart) {
@@ -55,6 +185,98 @@ func (e *ParserError) PatchMessage(subMsg string) {
 	}
 }
 
+// Position returns the byte index of the error in the input.
+func (e *ParserError) Position() int {
+	return e.pos
+}
+
+// ParserName returns the description (Expected()) of the parser that
+// reported the error.
+// It is empty if the error hasn't been returned by a parser yet.
+func (e *ParserError) ParserName() string {
+	return e.parserName
+}
+
+// Waste returns the number of bytes skipped by the error recovery.
+// It is 0 if the error hasn't been recovered from (yet) or the expected
+// input has been inserted (see ErrorMissing).
+func (e *ParserError) Waste() int {
+	return e.waste
+}
+
+// SafeSpotUsed returns true if the error recovery continued with a safe
+// spot parser (see SafeSpot).
+// So the parser skipped input up to a well-known anchor.
+func (e *ParserError) SafeSpotUsed() bool {
+	return e.safeSpot
+}
+
+// Kind returns the classification of the error by the error recovery.
+func (e *ParserError) Kind() ErrorKind {
+	return e.kind
+}
+
+// classify sets the kind of a syntax error and adapts its message.
+// `wasted` is the input skipped by the error recovery.
+// `before` is the expectation of the parser that continued after deleted
+// input; it is empty if that is the failed parser itself.
+func (e *ParserError) classify(kind ErrorKind, wasted, before string) {
+	if !strings.HasPrefix(e.message(), SyntaxErrorStart) || e.kind != ErrorUnclassified {
+		return
+	}
+	e.kind = kind
+	expected := e.text[len(SyntaxErrorStart):]
+	if i := strings.LastIndex(expected, " (got "); i > 0 && strings.HasSuffix(expected, ")") {
+		expected = expected[:i] // the wasted input is more helpful
+	}
+	const maxWasted = 20
+	if len(wasted) > maxWasted {
+		wasted = wasted[:maxWasted] + "..."
+	}
+	switch kind {
+	case ErrorMissing:
+		e.text = "missing " + expected + " (inserted)"
+	case ErrorUnexpected:
+		if before != "" {
+			expected = before
+		}
+		e.text = fmt.Sprintf("unexpected %q before %s (deleted)", wasted, expected)
+	case ErrorSubstituted:
+		e.text = fmt.Sprintf("%s%s instead of %q (substituted)", SyntaxErrorStart, expected, wasted)
+	}
+}
+
+// Incomplete returns true if the error happened because the end of the
+// input has been reached. So more input might fix the error.
+func (e *ParserError) Incomplete() bool {
+	return e.incomplete
+}
+
+// MarkIncomplete marks an error as caused by missing input.
+// Leaf parsers should use it if they hit the end of the input before
+// they could decide about success or failure (e.g., "ab" for the token "abc").
+// Syntax errors created at the end of the input are marked automatically.
+func MarkIncomplete(err *ParserError) *ParserError {
+	if err != nil {
+		err.incomplete = true
+	}
+	return err
+}
+
+// Fatal returns true if no error recovery should be tried for the error.
+func (e *ParserError) Fatal() bool {
+	return e.fatal
+}
+
+// MarkFatal marks an error as fatal.
+// So parsing stops with this error instead of trying to recover from it.
+func MarkFatal(err *ParserError) *ParserError {
+	if err != nil {
+		err.fatal = true
+	}
+	return err
+}
+
 // ClaimError takes over an error from a sub-parser.
 // This is used for sub-parsers that aren't reported as children.
 func ClaimError(err *ParserError) *ParserError {
@@ -64,10 +286,151 @@ func ClaimError(err *ParserError) *ParserError {
 	return err
 }
 
+// ErrorPosition describes where an error of a parser run happened.
+type ErrorPosition struct {
+	Source string // name of the input (only set for included input, see PushInput)
+	Pos    int    // byte index in the input
+	Line   int    // line number (starting at 1); only for text input
+	Column int    // column (starting at 1) in runes for text input or bytes for binary input
+}
+
+// savedError is an error that has been saved in the state.
+// It keeps the position and diagnostic of the error for tools.
+type savedError struct {
+	msg     string
+	text    string // the message without position and source line (see ParserError.Message)
+	diag    ErrorDiagnostic
+	context string
+	wrapped []error
+}
+
+func newSavedError(err *ParserError) *savedError {
+	err.locate()
+	pos := ErrorPosition{Source: err.source, Pos: err.pos, Line: err.line, Column: err.col + 1}
+	if !err.binary {
+		pos.Column = err.column
+	} else {
+		pos.Line = 0
+	}
+	diag := ErrorDiagnostic{
+		ErrorPosition: pos,
+		ParserName:    err.parserName,
+		Kind:          err.kind,
+		Waste:         err.waste,
+		SafeSpotUsed:  err.safeSpot,
+		Incomplete:    err.incomplete,
+		Fatal:         err.fatal,
+	}
+	return &savedError{msg: err.Error(), text: err.message(), diag: diag, context: err.context, wrapped: err.wrapped}
+}
+
+func (e *savedError) Error() string {
+	return e.msg
+}
+
+func (e *savedError) Unwrap() []error {
+	return e.wrapped
+}
+
+// PositionOf returns the position of an error returned by a parser run.
+// The errors of a run should be separated first with UnwrapErrors
+// (else the position of the first error is returned).
+// It returns false if the error doesn't know its position.
+func PositionOf(err error) (ErrorPosition, bool) {
+	var se *savedError
+	if errors.As(err, &se) {
+		return se.diag.ErrorPosition, true
+	}
+	var pe *ParserError
+	if errors.As(err, &pe) {
+		return newSavedError(pe).diag.ErrorPosition, true
+	}
+	return ErrorPosition{}, false
+}
+
+// ErrorDiagnostic is the machine-readable description of an error of a
+// parser run (see the methods of ParserError with the same names).
+// So servers can return structured diagnostics and decide about retrying
+// or rejecting input without parsing error messages.
+type ErrorDiagnostic struct {
+	ErrorPosition
+	ParserName   string    // Expected() of the parser that reported the error
+	Kind         ErrorKind // classification by the error recovery
+	Waste        int       // number of bytes skipped by the error recovery
+	SafeSpotUsed bool      // the error recovery continued with a safe spot parser
+	Incomplete   bool      // more input might fix the error
+	Fatal        bool      // error recovery wasn't allowed
+}
+
+// DiagnosticOf returns the diagnostic of an error returned by a parser run.
+// The errors of a run should be separated first with UnwrapErrors
+// (else the diagnostic of the first error is returned).
+// It returns false if the error isn't a parser error.
+func DiagnosticOf(err error) (ErrorDiagnostic, bool) {
+	var se *savedError
+	if errors.As(err, &se) {
+		return se.diag, true
+	}
+	var pe *ParserError
+	if errors.As(err, &pe) {
+		return newSavedError(pe).diag, true
+	}
+	return ErrorDiagnostic{}, false
+}
+
+// ContextOf returns the context of an error returned by a parser run
+// (see ParserError.Context).
+// The errors of a run should be separated first with UnwrapErrors
+// (else the context of the first error is returned).
+// It returns false if the error doesn't know its context.
+func ContextOf(err error) (string, bool) {
+	var se *savedError
+	if errors.As(err, &se) {
+		return se.context, true
+	}
+	var pe *ParserError
+	if errors.As(err, &pe) {
+		return pe.Context(), true
+	}
+	return "", false
+}
+
 // ============================================================================
 // Error Reporting
 //
 
+// sanitizeText escapes all non-printable characters and invalid UTF-8 like Go strings do.
+func sanitizeText(s string) string {
+	result := strings.Builder{}
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		switch {
+		case r == utf8.RuneError && size == 1:
+			_, _ = fmt.Fprintf(&result, `\x%02x`, s[0])
+		case unicode.IsPrint(r):
+			result.WriteString(s[:size])
+		default:
+			quoted := strconv.QuoteRune(r)
+			result.WriteString(quoted[1 : len(quoted)-1])
+		}
+		s = s[size:]
+	}
+	return result.String()
+}
+
+// sanitizeBytes escapes all bytes that aren't printable ASCII characters.
+func sanitizeBytes(b []byte) string {
+	result := strings.Builder{}
+	for _, c := range b {
+		if c >= ' ' && c <= '~' {
+			result.WriteByte(c)
+		} else {
+			_, _ = fmt.Fprintf(&result, `\x%02x`, c)
+		}
+	}
+	return result.String()
+}
+
 func formatBinaryLine(line, col int, srcLine string) string {
 	start := line
 	text := hex.Dump([]byte(srcLine))
@@ -87,15 +450,32 @@ func formatBinaryLine(line, col int, srcLine string) string {
 		start, text[:m1], errorMarker, text[m1:m2], errorMarker, text[m2:len(text)-1])
 }
 
-func formatSrcLine(line, col int, srcLine string) string {
-	result := strings.Builder{}
-	lineStart := srcLine[:col]
-	srcLine = srcLine[col:]
-	result.WriteString(lastNRunes(lineStart, 10))
-	result.WriteRune(errorMarker)
-	result.WriteString(firstNRunes(srcLine, 20))
-	return fmt.Sprintf(` [%d:%d] %s`,
-		line, utf8.RuneCountInString(lineStart)+1, result.String()) // columns for the user start at 1
+func formatSrcLine(source string, line, column, col int, srcLine string) string {
+	if source != "" {
+		source += ":"
+	}
+	return fmt.Sprintf(` [%s%d:%d] %s%c%s`,
+		source, line, column, srcLine[:col], errorMarker, srcLine[col:])
+}
+
+// ellipsis marks the parts of source lines that have been cut off.
+const ellipsis = "…"
+
+// clipLine returns the part of the source line around the byte index col
+// that is `width` runes long and the byte index of col within it.
+// A third of the runes is taken before col.
+// Cut off parts are marked with an ellipsis.
+func clipLine(srcLine string, col, width int) (string, int) {
+	before := width / 3
+	head := lastNRunes(srcLine[:col], before)
+	tail := firstNRunes(srcLine[col:], width-before)
+	if len(head) < col {
+		head = ellipsis + head
+	}
+	if len(tail) < len(srcLine)-col {
+		tail += ellipsis
+	}
+	return head + tail, len(head)
 }
 func firstNRunes(s string, n int) string {
 	l := len(s)
@@ -1,7 +1,11 @@
 package comb
 
 import (
+	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"unsafe"
 )
 
 func TestPatchMessage(t *testing.T) {
@@ -47,6 +51,74 @@ func TestPatchMessage(t *testing.T) {
 	}
 }
 
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	state := NewFromString("source", 0)
+	tailMsg := " [1:1] ▶source"
+
+	tests := []struct {
+		name     string
+		err      *ParserError
+		kind     ErrorKind
+		wasted   string
+		before   string
+		wantKind ErrorKind
+		wantMsg  string
+	}{
+		{
+			name:     "missing",
+			err:      state.NewSyntaxError("';' (got 's')"),
+			kind:     ErrorMissing,
+			wantKind: ErrorMissing,
+			wantMsg:  "missing ';' (inserted)",
+		}, {
+			name:     "unexpected",
+			err:      state.NewSyntaxError("';'"),
+			kind:     ErrorUnexpected,
+			wasted:   "source",
+			wantKind: ErrorUnexpected,
+			wantMsg:  `unexpected "source" before ';' (deleted)`,
+		}, {
+			name:     "unexpected before another parser",
+			err:      state.NewSyntaxError("';'"),
+			kind:     ErrorUnexpected,
+			wasted:   "sour",
+			before:   "'('",
+			wantKind: ErrorUnexpected,
+			wantMsg:  `unexpected "sour" before '(' (deleted)`,
+		}, {
+			name:     "substituted",
+			err:      state.NewSyntaxError("';'"),
+			kind:     ErrorSubstituted,
+			wasted:   "sour",
+			wantKind: ErrorSubstituted,
+			wantMsg:  `expected ';' instead of "sour" (substituted)`,
+		}, {
+			name:     "semantic error",
+			err:      state.NewSemanticError("no source"),
+			kind:     ErrorMissing,
+			wantKind: ErrorUnclassified,
+			wantMsg:  "no source",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // needed for truly different test cases!
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt.err.classify(tt.kind, tt.wasted, tt.before)
+
+			if got := tt.err.Kind(); got != tt.wantKind {
+				t.Errorf("got kind %s, want: %s", got, tt.wantKind)
+			}
+			if got, want := tt.err.Error(), tt.wantMsg+tailMsg; got != want {
+				t.Errorf("got message %q, want: %q", got, want)
+			}
+		})
+	}
+}
+
 func TestClaimError(t *testing.T) {
 	t.Parallel()
 
@@ -85,6 +157,265 @@ func TestClaimError(t *testing.T) {
 		})
 	}
 }
+
+func TestIncomplete(t *testing.T) {
+	t.Parallel()
+
+	atEnd := NewFromString("source", 0).MoveBy(6)
+	tests := []struct {
+		name           string
+		err            *ParserError
+		wantIncomplete bool
+	}{
+		{
+			name:           "syntax error at the end",
+			err:            atEnd.NewSyntaxError("more"),
+			wantIncomplete: true,
+		}, {
+			name:           "syntax error before the end",
+			err:            NewFromString("source", 0).NewSyntaxError("more"),
+			wantIncomplete: false,
+		}, {
+			name:           "semantic error at the end",
+			err:            atEnd.NewSemanticError("number too big"),
+			wantIncomplete: false,
+		}, {
+			name:           "marked semantic error",
+			err:            MarkIncomplete(atEnd.NewSemanticError("number too big")),
+			wantIncomplete: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // needed for truly different test cases!
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.err.Incomplete(); got != tt.wantIncomplete {
+				t.Errorf("got incomplete %t, want %t", got, tt.wantIncomplete)
+			}
+		})
+	}
+}
+
+func TestPositionOf(t *testing.T) {
+	t.Parallel()
+
+	state := NewFromString("line1\nlä2", 10).MoveBy(9)
+	err := state.SaveError(state.NewSyntaxError("'3'")).Errors()
+	errs := UnwrapErrors(err)
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors, want 1", len(errs))
+	}
+	got, ok := PositionOf(errs[0])
+	if want := (ErrorPosition{Pos: 9, Line: 2, Column: 3}); !ok || got != want {
+		t.Errorf("got %+v (ok=%t), want %+v", got, ok, want)
+	}
+	if _, ok = PositionOf(errors.New("no position")); ok {
+		t.Errorf("plain errors shouldn't have a position")
+	}
+}
+
+func TestErrorContext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		state       State
+		pos         int
+		wantContext string
+	}{
+		{
+			name:        "text around the error",
+			state:       NewFromString("key = value;\nnext", 10).WithErrorContext(4),
+			pos:         12,
+			wantContext: "lue;▶\\nnex",
+		}, {
+			name:        "runes aren't split",
+			state:       NewFromString("aäb\x00c", 10).WithErrorContext(2),
+			pos:         3,
+			wantContext: "ä▶b\\x00",
+		}, {
+			name:        "invalid UTF-8 is escaped",
+			state:       NewFromString("a\xffb", 10),
+			pos:         2,
+			wantContext: "a\\xff▶b",
+		}, {
+			name:        "binary input",
+			state:       NewFromBytes([]byte{'a', 0, 1, 'b'}, 10).WithErrorContext(2),
+			pos:         2,
+			wantContext: "a\\x00▶\\x01b",
+		}, {
+			name:        "turned off",
+			state:       NewFromString("abc", 10).WithErrorContext(0),
+			pos:         1,
+			wantContext: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // needed for truly different test cases!
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pErr := tt.state.MoveBy(tt.pos).NewSyntaxError("something")
+			if got := pErr.Context(); got != tt.wantContext {
+				t.Errorf("got context %q, want %q", got, tt.wantContext)
+			}
+			err := tt.state.SaveError(pErr).Errors()
+			if got, ok := ContextOf(err); !ok || got != tt.wantContext {
+				t.Errorf("got context %q (ok=%t) of the saved error, want %q", got, ok, tt.wantContext)
+			}
+		})
+	}
+	if _, ok := ContextOf(errors.New("no context")); ok {
+		t.Errorf("plain errors shouldn't have a context")
+	}
+}
+
+func TestErrorLineWidth(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("x", 1000)
+	tests := []struct {
+		name       string
+		state      State
+		pos        int
+		wantMsg    string
+		wantColumn int
+	}{
+		{
+			name:       "short line is kept",
+			state:      NewFromString("a = 1", 10),
+			pos:        4,
+			wantMsg:    "expected something [1:5] a = ▶1",
+			wantColumn: 5,
+		}, {
+			name:       "long line is cut off on both sides",
+			state:      NewFromString(long+"{}"+long, 10).WithErrorLineWidth(9),
+			pos:        1001,
+			wantMsg:    "expected something [1:1002] …xx{▶}xxxxx…",
+			wantColumn: 1002,
+		}, {
+			name:       "start of a long line is kept",
+			state:      NewFromString("{"+long, 10).WithErrorLineWidth(6),
+			pos:        1,
+			wantMsg:    "expected something [1:2] {▶xxxx…",
+			wantColumn: 2,
+		}, {
+			name:       "end of a long line is kept",
+			state:      NewFromString(long+"äöü\nnext", 10).WithErrorLineWidth(6),
+			pos:        1002,
+			wantMsg:    "expected something [1:1002] …xä▶öü",
+			wantColumn: 1002,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // needed for truly different test cases!
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			pErr := tt.state.MoveBy(tt.pos).NewSyntaxError("something")
+			if got := pErr.Error(); got != tt.wantMsg {
+				t.Errorf("got message %q, want %q", got, tt.wantMsg)
+			}
+			err := tt.state.SaveError(pErr).Errors()
+			if got, ok := PositionOf(err); !ok || got.Column != tt.wantColumn {
+				t.Errorf("got column %d (ok=%t) of the saved error, want %d", got.Column, ok, tt.wantColumn)
+			}
+		})
+	}
+}
+
+func TestWrappedError(t *testing.T) {
+	t.Parallel()
+
+	errUser := errors.New("user error")
+	state := NewFromString("source", 10)
+
+	pErr := state.NewSemanticError("mapping failed: %w", errUser)
+	if !errors.Is(pErr, errUser) {
+		t.Errorf("errors.Is should find the wrapped error in %v", pErr)
+	}
+	if got, want := pErr.Message(), "mapping failed: user error"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	if err := state.SaveError(pErr).Errors(); !errors.Is(err, errUser) {
+		t.Errorf("errors.Is should find the wrapped error in the saved error %v", err)
+	}
+	if pErr = state.NewSemanticError("%v", errUser); errors.Is(pErr, errUser) {
+		t.Errorf("errors.Is shouldn't find an error formatted with %%v")
+	}
+}
+
+func TestLazyMessage(t *testing.T) {
+	t.Parallel()
+
+	state := NewFromString("source", 10)
+	tests := []struct {
+		name     string
+		err      *ParserError
+		wantMsg  string
+		wantLazy bool
+	}{
+		{
+			name:    "plain syntax errors should be interned",
+			err:     state.NewSyntaxError("digit"),
+			wantMsg: "expected digit",
+		}, {
+			name:     "arguments should be formatted lazily",
+			err:      state.NewSyntaxError("%q (got %q)", 'a', 's'),
+			wantMsg:  "expected 'a' (got 's')",
+			wantLazy: true,
+		}, {
+			name:     "escaped percent signs should be formatted",
+			err:      state.NewSemanticError("100%% wrong"),
+			wantMsg:  "100% wrong",
+			wantLazy: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if lazy := tt.err.format != ""; lazy != tt.wantLazy {
+				t.Errorf("the message shouldn't be formatted before it is needed")
+			}
+			if got := tt.err.Message(); got != tt.wantMsg {
+				t.Errorf("got message %q, want %q", got, tt.wantMsg)
+			}
+			if got := tt.err.Error(); got != tt.wantMsg+" [1:1] ▶source" {
+				t.Errorf("got error %q, want message %q", got, tt.wantMsg)
+			}
+		})
+	}
+
+	if a, b := internSyntaxMessage("digit"), internSyntaxMessage("digit"); unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Errorf("interned messages should share their memory")
+	}
+}
+
+func TestLazyLocation(t *testing.T) {
+	// no t.Parallel() because of testing.AllocsPerRun
+
+	state := NewFromString("line 1\nline 2", 10).MoveBy(9)
+	if allocs := testing.AllocsPerRun(100, func() {
+		_ = state.NewSyntaxError("digit")
+	}); allocs > 1 {
+		t.Errorf("creating an error should allocate only the error itself, got %v allocations", allocs)
+	}
+
+	err := state.NewSyntaxError("digit")
+	if err.srcLine != "" || err.context != "" {
+		t.Errorf("the location shouldn't be computed before it is needed")
+	}
+	if got, want := err.Error(), "expected digit [2:3] li▶ne 2"; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+	if got, want := err.Context(), "line 1\\nli▶ne 2"; got != want {
+		t.Errorf("got context %q, want %q", got, want)
+	}
+}
+
 func TestFirstNRunes(t *testing.T) {
 	t.Parallel()
 
@@ -200,3 +531,63 @@ func TestLastNRunes(t *testing.T) {
 		})
 	}
 }
+
+func TestDiagnosticOf(t *testing.T) {
+	t.Parallel()
+
+	runePlusRune := func(out1 rune, out2 rune) (string, error) {
+		return string([]rune{out1, out2}), nil
+	}
+	_, err := RunOnString("b;", Map2(Char('a'), SafeSpot(Char(';')), runePlusRune))
+	errs := UnwrapErrors(err)
+	if len(errs) != 1 {
+		t.Fatalf("got %d errors, want 1", len(errs))
+	}
+	got, ok := DiagnosticOf(errs[0])
+	want := ErrorDiagnostic{
+		ErrorPosition: ErrorPosition{Pos: 0, Line: 1, Column: 1},
+		ParserName:    "'a'",
+		Kind:          ErrorSubstituted,
+		Waste:         1,
+		SafeSpotUsed:  true,
+	}
+	if !ok || got != want {
+		t.Errorf("got %+v (ok=%t), want %+v", got, ok, want)
+	}
+	if _, ok = DiagnosticOf(errors.New("no diagnostic")); ok {
+		t.Errorf("plain errors shouldn't have a diagnostic")
+	}
+
+	state := NewFromString("ab", 0)
+	_, _, pErr := Char('b').Parse(state)
+	if pErr == nil {
+		t.Fatalf("expected an error")
+	}
+	if got, want := pErr.Position(), 0; got != want {
+		t.Errorf("got position %d, want %d", got, want)
+	}
+	if got, want := pErr.ParserName(), "'b'"; got != want {
+		t.Errorf("got parser name %q, want %q", got, want)
+	}
+	if pErr.Waste() != 0 || pErr.SafeSpotUsed() {
+		t.Errorf("got waste %d and safe spot used %t for an error without recovery", pErr.Waste(), pErr.SafeSpotUsed())
+	}
+}
+
+func TestConcurrentErrorFormatting(t *testing.T) {
+	t.Parallel()
+
+	err := NewFromString("line 1\nline 2", 10).MoveBy(9).NewSyntaxError("%s", "digit")
+	want := "expected digit [2:3] li▶ne 2"
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := err.Error(); got != want {
+				t.Errorf("got error %q, want %q", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+}
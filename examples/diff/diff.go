@@ -0,0 +1,182 @@
+// Package diff demonstrates the usage of the comb package to parse patches
+// in the [unified diff format].
+//
+// The number of lines of a hunk is given in its header, so the hunk body
+// is parsed with a data dependent parser (comb.Bind).
+// Every hunk header is a safe spot, so an error in one hunk doesn't stop
+// the parsing of the following hunks.
+//
+// [unified diff format]: https://www.gnu.org/software/diffutils/manual/html_node/Detailed-Unified.html
+package diff
+
+import (
+	"math"
+	"strings"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	. "github.com/flowdev/comb/cute"
+)
+
+// File is the patch of a single file.
+type File struct {
+	Headers []string // extended header lines before the file names (e.g. "diff --git a/x b/x")
+	OldName string   // name after "--- " without a timestamp
+	NewName string   // name after "+++ " without a timestamp
+	Hunks   []Hunk
+}
+
+// Hunk is a block of changed lines together with its context.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Section            string // text after the closing "@@" (e.g. the enclosing function)
+	Lines              []Line
+}
+
+// LineKind is the kind of line in a hunk.
+type LineKind rune
+
+const (
+	Context LineKind = ' '
+	Removed LineKind = '-'
+	Added   LineKind = '+'
+)
+
+// Line is a single line of a hunk.
+type Line struct {
+	Kind      LineKind
+	Text      string // without the kind and the line end
+	NoNewline bool   // followed by "\ No newline at end of file"
+}
+
+// ParsePatch parses a patch consisting of the unified diffs of one or more files.
+// All errors found are returned together with the files parsed so far.
+func ParsePatch(input string) ([]File, error) {
+	return comb.RunOnString(input, cmb.Suffixed(cmb.Many1(file()), cmb.EOF()))
+}
+
+func file() comb.Parser[File] {
+	return cmb.Map4(
+		cmb.Many0(headerLine()),
+		cmb.Prefixed(cmb.SOL(), cmb.Prefixed(SaveSpot(S("--- ")), fileName())),
+		cmb.Prefixed(S("+++ "), fileName()),
+		cmb.Many1(hunk()),
+		func(headers []string, oldName, newName string, hunks []Hunk) (File, error) {
+			return File{Headers: headers, OldName: oldName, NewName: newName, Hunks: hunks}, nil
+		},
+	)
+}
+
+// headerLine parses an extended header line (any line before the file names).
+func headerLine() comb.Parser[string] {
+	return cmb.Prefixed(cmb.Not(S("--- ")), line())
+}
+
+// fileName parses the rest of the line and removes an optional timestamp
+// (separated by a tab).
+func fileName() comb.Parser[string] {
+	return cmb.Map(line(), func(text string) (string, error) {
+		name, _, _ := strings.Cut(text, "\t")
+		return name, nil
+	})
+}
+
+// line parses a non-empty rest of a line including the line end.
+// The last line of the input doesn't need a line end.
+func line() comb.Parser[string] {
+	text := func() comb.Parser[string] {
+		return cmb.SatisfyMN("text", 0, math.MaxInt, func(r rune) bool { return r != '\n' })
+	}
+	return cmb.FirstSuccessful(
+		cmb.Suffixed(text(), C('\n')),
+		cmb.Suffixed(cmb.Verify(text(), func(s string) bool { return s != "" }, "text"), cmb.EOF()),
+	)
+}
+
+func hunk() comb.Parser[Hunk] {
+	return comb.Bind(hunkHeader(), hunkBody)
+}
+
+// hunkHeader parses a hunk header like "@@ -1,3 +1,4 @@ func main() {".
+func hunkHeader() comb.Parser[Hunk] {
+	lineRange := func(sign string) comb.Parser[[2]int] {
+		return cmb.Map2(
+			cmb.Prefixed(S(sign), cmb.Int[int64](false, 10)),
+			cmb.Optional(cmb.Map(cmb.Prefixed(C(','), cmb.Int[int64](false, 10)), func(count int64) (int64, error) {
+				return count + 1, nil // so 0 means that the count is missing
+			})),
+			func(start, count int64) ([2]int, error) {
+				if count == 0 { // the count is 1 if it's missing
+					return [2]int{int(start), 1}, nil
+				}
+				return [2]int{int(start), int(count - 1)}, nil
+			},
+		)
+	}
+	return cmb.Map4(
+		cmb.Prefixed(SaveSpot(S("@@ ")), lineRange("-")),
+		cmb.Prefixed(C(' '), lineRange("+")),
+		S(" @@"),
+		cmb.FirstSuccessful(line(), cmb.Assign("", C('\n'))),
+		func(oldRange, newRange [2]int, _ string, section string) (Hunk, error) {
+			return Hunk{
+				OldStart: oldRange[0], OldLines: oldRange[1],
+				NewStart: newRange[0], NewLines: newRange[1],
+				Section: strings.TrimPrefix(section, " "),
+			}, nil
+		},
+	)
+}
+
+// hunkBody parses exactly as many lines as the hunk header announces.
+// Context lines count for the old and the new file.
+func hunkBody(h Hunk) comb.Parser[Hunk] {
+	return comb.NewParser[Hunk]("hunk lines", func(state comb.State) (comb.State, Hunk, *comb.ParserError) {
+		oldLeft, newLeft := h.OldLines, h.NewLines
+		h.Lines = make([]Line, 0, max(oldLeft, newLeft))
+		current := state
+		for oldLeft > 0 || newLeft > 0 || strings.HasPrefix(current.CurrentString(), `\`) {
+			input := current.CurrentString()
+			if input == "" {
+				return current, h, comb.MarkIncomplete(current.NewSyntaxError(
+					"hunk line (%d old and %d new lines missing)", oldLeft, newLeft,
+				))
+			}
+			text, _, _ := strings.Cut(input, "\n")
+			n := min(len(text)+1, len(input))
+			kind := Context // an empty line is a context line
+			if text != "" {
+				kind = LineKind(text[0])
+			}
+			switch kind {
+			case Context:
+				oldLeft--
+				newLeft--
+			case Removed:
+				oldLeft--
+			case Added:
+				newLeft--
+			case '\\':
+				if len(h.Lines) > 0 {
+					h.Lines[len(h.Lines)-1].NoNewline = true
+				}
+				current = current.MoveBy(n)
+				continue
+			default:
+				return current, h, current.NewSyntaxError(
+					"hunk line starting with ' ', '-' or '+' (%d old and %d new lines missing)", oldLeft, newLeft,
+				)
+			}
+			if oldLeft < 0 {
+				return current, h, current.NewSyntaxError("end of hunk (more old lines than announced)")
+			}
+			if newLeft < 0 {
+				return current, h, current.NewSyntaxError("end of hunk (more new lines than announced)")
+			}
+			h.Lines = append(h.Lines, Line{Kind: kind, Text: text[min(1, len(text)):]})
+			current = current.MoveBy(n)
+		}
+		return current, h, nil
+	}, nil)
+}
@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const gitPatch = `diff --git a/hello.go b/hello.go
+index 3b18e51..a042389 100644
+--- a/hello.go	2024-03-09 12:00:00
++++ b/hello.go
+@@ -1,3 +1,4 @@ package main
+ import "fmt"
+-func main() { fmt.Println("hi") }
++func main() {
++	fmt.Println("hello")
+ }
+\ No newline at end of file
+@@ -10,0 +12 @@
++// The End
+--- a/other.txt
++++ b/other.txt
+@@ -1 +1 @@
+-old
++new
+`
+
+func TestParsePatch(t *testing.T) {
+	t.Parallel()
+
+	files, err := ParsePatch(gitPatch)
+	assert.NoError(t, err)
+	want := []File{
+		{
+			Headers: []string{"diff --git a/hello.go b/hello.go", "index 3b18e51..a042389 100644"},
+			OldName: "a/hello.go",
+			NewName: "b/hello.go",
+			Hunks: []Hunk{
+				{
+					OldStart: 1, OldLines: 3, NewStart: 1, NewLines: 4, Section: "package main",
+					Lines: []Line{
+						{Kind: Context, Text: `import "fmt"`},
+						{Kind: Removed, Text: `func main() { fmt.Println("hi") }`},
+						{Kind: Added, Text: `func main() {`},
+						{Kind: Added, Text: `	fmt.Println("hello")`},
+						{Kind: Context, Text: `}`, NoNewline: true},
+					},
+				}, {
+					OldStart: 10, OldLines: 0, NewStart: 12, NewLines: 1,
+					Lines: []Line{{Kind: Added, Text: "// The End"}},
+				},
+			},
+		}, {
+			Headers: []string{},
+			OldName: "a/other.txt",
+			NewName: "b/other.txt",
+			Hunks: []Hunk{
+				{
+					OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1,
+					Lines: []Line{{Kind: Removed, Text: "old"}, {Kind: Added, Text: "new"}},
+				},
+			},
+		},
+	}
+	assert.Equal(t, want, files)
+}
+
+func TestParsePatchRecovery(t *testing.T) {
+	t.Parallel()
+
+	files, err := ParsePatch("--- a\n+++ b\n@@ -1 +1 @@\n*x\n@@ -5 +5 @@\n-y\n+z\n")
+	assert.ErrorContains(t, err, "[4:1]")
+	if assert.Len(t, files, 1) && assert.Len(t, files[0].Hunks, 1) {
+		assert.Equal(t, 5, files[0].Hunks[0].OldStart)
+		assert.Equal(t, []Line{{Kind: Removed, Text: "y"}, {Kind: Added, Text: "z"}}, files[0].Hunks[0].Lines)
+	}
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "missing lines should fail",
+			input:   "--- a\n+++ b\n@@ -1,2 +1,2 @@\n x\n",
+			wantErr: "expected hunk line (1 old and 1 new lines missing)",
+		}, {
+			name:    "too many lines should fail",
+			input:   "--- a\n+++ b\n@@ -1 +1 @@\n-x\n-y\n+z\n",
+			wantErr: "expected end of hunk (more old lines than announced)",
+		}, {
+			name:    "invalid line should fail",
+			input:   "--- a\n+++ b\n@@ -1 +1 @@\n*x\n",
+			wantErr: "expected hunk line starting with ' ', '-' or '+'",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParsePatch(tc.input)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,374 @@
+// Package markdown implements a parser for a subset of Markdown.
+//
+// It is a simple, incomplete, example of how to use the comb
+// parser combinator library to build a line oriented parser targeting the
+// format described in [CommonMark].
+// Supported are ATX headings, paragraphs, bullet and ordered lists,
+// fenced code blocks and the inlines emphasis, strong emphasis,
+// code spans and links.
+//
+// The markers of headings, list items and code blocks are safe spots,
+// so parsing continues with the next block after a broken one.
+//
+// [CommonMark]: https://spec.commonmark.org/
+package markdown
+
+import (
+	"math"
+	"strings"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	. "github.com/flowdev/comb/cute"
+)
+
+type (
+	// Block is one of the block types: Heading, Paragraph, List or CodeBlock.
+	Block interface{}
+
+	// Heading is an ATX heading like `## Title`.
+	Heading struct {
+		Level int
+		Text  []Inline
+	}
+
+	// Paragraph is a sequence of text lines.
+	// Lines are separated by a "\n" in the text.
+	Paragraph struct {
+		Text []Inline
+	}
+
+	// List is a bullet list (`-`, `+` or `*`) or an ordered list (`1.`).
+	// The type of the list is decided by its first item.
+	List struct {
+		Ordered bool
+		Items   [][]Inline
+	}
+
+	// CodeBlock is a fenced code block with an optional info string for the language.
+	CodeBlock struct {
+		Lang string
+		Code string
+	}
+
+	// Inline is one of the inline types: Text, Emphasis, Code or Link.
+	Inline interface{}
+
+	// Text is plain text.
+	Text string
+
+	// Emphasis is emphasized text (`*text*`) or strongly emphasized text (`**text**`).
+	Emphasis struct {
+		Strong bool
+		Text   string
+	}
+
+	// Code is a code span like `code`.
+	Code string
+
+	// Link is an inline link like `[text](url)`.
+	Link struct {
+		Text string
+		URL  string
+	}
+)
+
+// ParseMarkdown parses a Markdown document into a list of blocks.
+func ParseMarkdown(input string) ([]Block, error) {
+	return comb.RunOnString(input, document())
+}
+
+// document parses any number of blocks separated by blank lines.
+func document() comb.Parser[[]Block] {
+	return cmb.Suffixed(
+		cmb.Many0(cmb.Prefixed(cmb.Many0(blankLine()), block())),
+		cmb.Prefixed(cmb.Many0(blankLine()), cmb.EOF()),
+	)
+}
+
+func block() comb.Parser[Block] {
+	return cmb.Prefixed(cmb.SOL(), cmb.FirstSuccessful(
+		heading(),
+		codeBlock(),
+		list(),
+		paragraph(),
+	))
+}
+
+// heading parses an ATX heading.
+// After the heading marker has been found, a missing text is an error.
+func heading() comb.Parser[Block] {
+	return cmb.Map2(
+		SaveSpot(headingMarker()),
+		cmb.Suffixed(inlineLine(), lineEnd()),
+		func(marker string, text []Inline) (Block, error) {
+			return Heading{Level: strings.Count(marker, "#"), Text: text}, nil
+		},
+	)
+}
+
+// codeBlock parses a fenced code block.
+// After the opening fence has been found, a missing closing fence is an error.
+func codeBlock() comb.Parser[Block] {
+	return cmb.Suffixed(
+		cmb.Between(
+			SaveSpot(S("```")),
+			cmb.Map2(
+				cmb.Suffixed(cmb.SatisfyMN("language", 0, math.MaxInt, isTextRune), lineEnd()),
+				codeLines(),
+				func(lang string, code string) (Block, error) {
+					return CodeBlock{Lang: strings.TrimSpace(lang), Code: code}, nil
+				},
+			),
+			S("```"),
+			0,
+		),
+		lineEnd(),
+	)
+}
+
+// codeLines parses all lines until a line starts with a fence or the input ends.
+func codeLines() comb.Parser[string] {
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		current := state
+		for !current.AtEnd() && !strings.HasPrefix(current.CurrentString(), "```") {
+			input := current.CurrentString()
+			i := strings.IndexByte(input, '\n')
+			if i < 0 {
+				i = len(input) - 1
+			}
+			current = current.MoveBy(i + 1)
+		}
+		return current, state.StringTo(current), nil
+	}
+	return comb.NewParser[string]("code lines", parse, cmb.Forbidden())
+}
+
+// list parses a list.
+// After the marker of an item has been found, a missing text is an error.
+func list() comb.Parser[Block] {
+	return cmb.Map(
+		cmb.Many1(cmb.Map2(
+			cmb.Map(SaveSpot(listMarker()), isOrdered),
+			cmb.Suffixed(inlineLine(), lineEnd()),
+			func(ordered bool, text []Inline) (listItem, error) {
+				return listItem{ordered: ordered, text: text}, nil
+			},
+		)),
+		func(items []listItem) (Block, error) {
+			l := List{Ordered: items[0].ordered, Items: make([][]Inline, len(items))}
+			for i, item := range items {
+				l.Items[i] = item.text
+			}
+			return l, nil
+		},
+	)
+}
+
+type listItem struct {
+	ordered bool
+	text    []Inline
+}
+
+// headingMarker parses the marker of a heading including the following spaces.
+func headingMarker() comb.Parser[string] {
+	return marker("heading marker", func(input string) int {
+		i := 0
+		for i < len(input) && i < 6 && input[i] == '#' {
+			i++
+		}
+		if i == 0 {
+			return -1
+		}
+		return spacesEnd(input, i)
+	})
+}
+
+// listMarker parses the marker of a list item (`-`, `+`, `*` or `1.`)
+// including the following spaces.
+func listMarker() comb.Parser[string] {
+	return marker("list marker", func(input string) int {
+		if input != "" && strings.IndexByte("-+*", input[0]) >= 0 {
+			return spacesEnd(input, 1)
+		}
+		i := 0
+		for i < len(input) && '0' <= input[i] && input[i] <= '9' {
+			i++
+		}
+		if i == 0 || i >= len(input) || input[i] != '.' {
+			return -1
+		}
+		return spacesEnd(input, i+1)
+	})
+}
+
+// isOrdered returns true for markers of ordered lists.
+func isOrdered(marker string) (bool, error) {
+	return marker[0] >= '0' && marker[0] <= '9', nil
+}
+
+// marker returns a leaf parser for a block marker.
+// end returns the end of the marker at the start of the input or -1.
+// The recoverer searches the next line that starts with a marker,
+// so the marker can be used as a safe spot.
+func marker(expected string, end func(input string) int) comb.Parser[string] {
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		n := end(input)
+		if n < 0 {
+			return state, "", state.NewSyntaxError(expected)
+		}
+		return state.MoveBy(n), input[:n], nil
+	}
+
+	recoverer := func(state comb.State, _ interface{}) (int, interface{}) {
+		input := state.CurrentString()
+		for i := 0; i < len(input); i++ {
+			if (i == 0 && state.AtLineStart() || i > 0 && input[i-1] == '\n') && end(input[i:]) >= 0 {
+				return i, nil
+			}
+		}
+		return comb.RecoverWasteTooMuch, nil
+	}
+
+	return comb.NewParser[string](expected, parse, recoverer)
+}
+
+// spacesEnd returns the end of the spaces starting at i or -1 if there are none.
+func spacesEnd(input string, i int) int {
+	j := i
+	for j < len(input) && isSpace(rune(input[j])) {
+		j++
+	}
+	if j == i {
+		return -1
+	}
+	return j
+}
+
+func paragraph() comb.Parser[Block] {
+	return cmb.Map(
+		cmb.Many1(cmb.Prefixed(
+			cmb.Not(blockStart()),
+			cmb.Suffixed(inlineLine(), lineEnd()),
+		)),
+		func(lines [][]Inline) (Block, error) {
+			text := make([]Inline, 0, len(lines)*2)
+			for i, line := range lines {
+				if i > 0 {
+					text = append(text, Text("\n"))
+				}
+				text = append(text, line...)
+			}
+			return Paragraph{Text: mergeTexts(text)}, nil
+		},
+	)
+}
+
+// blockStart parses the start of any block that interrupts a paragraph.
+func blockStart() comb.Parser[bool] {
+	return cmb.FirstSuccessful(
+		cmb.Assign(true, blankLine()),
+		cmb.Assign(true, headingMarker()),
+		cmb.Assign(true, S("```")),
+		cmb.Assign(true, listMarker()),
+	)
+}
+
+// inlineLine parses the inlines of a single line.
+func inlineLine() comb.Parser[[]Inline] {
+	return cmb.Map(
+		cmb.Many1(cmb.FirstSuccessful(
+			cmb.Map(plainText(), toInline[Text]),
+			cmb.Map(cmb.Delimited(S("**"), emphasisText(), S("**")), func(text string) (Inline, error) {
+				return Emphasis{Strong: true, Text: text}, nil
+			}),
+			cmb.Map(cmb.Delimited(C('*'), emphasisText(), C('*')), func(text string) (Inline, error) {
+				return Emphasis{Text: text}, nil
+			}),
+			cmb.Map(cmb.Delimited(C('`'), textWithout('`'), C('`')), toInline[Code]),
+			cmb.Map2(
+				cmb.Delimited(C('['), textWithout(']'), S("](")),
+				cmb.Suffixed(textWithout(')'), C(')')),
+				func(text, url string) (Inline, error) {
+					return Link{Text: text, URL: url}, nil
+				},
+			),
+			cmb.Map(cmb.Satisfy("special character", isTextRune), func(r rune) (Inline, error) {
+				return Text(string(r)), nil // a lonely special character is just text
+			}),
+		)),
+		func(inlines []Inline) ([]Inline, error) {
+			return mergeTexts(inlines), nil
+		},
+	)
+}
+
+// plainText parses text without any inline markup.
+// Its recoverer stays in the current line, so a broken block doesn't
+// swallow the next one.
+func plainText() comb.Parser[string] {
+	p := cmb.SatisfyMN("text", 1, math.MaxInt, func(r rune) bool {
+		return isTextRune(r) && !strings.ContainsRune("*`[", r)
+	})
+	p.SwapRecoverer(func(state comb.State, _ interface{}) (int, interface{}) {
+		input := state.CurrentString()
+		for i, r := range input {
+			if !isTextRune(r) {
+				break
+			}
+			if !strings.ContainsRune("*`[", r) {
+				return i, nil
+			}
+		}
+		return comb.RecoverWasteTooMuch, nil
+	})
+	return p
+}
+
+func emphasisText() comb.Parser[string] {
+	return textWithout('*')
+}
+
+func textWithout(stop rune) comb.Parser[string] {
+	return cmb.SatisfyMN("text", 1, math.MaxInt, func(r rune) bool {
+		return isTextRune(r) && r != stop
+	})
+}
+
+func toInline[T ~string](s string) (Inline, error) {
+	return T(s), nil
+}
+
+// mergeTexts merges adjacent texts into one.
+func mergeTexts(inlines []Inline) []Inline {
+	merged := make([]Inline, 0, len(inlines))
+	for _, inline := range inlines {
+		if text, ok := inline.(Text); ok && len(merged) > 0 {
+			if last, ok := merged[len(merged)-1].(Text); ok {
+				merged[len(merged)-1] = last + text
+				continue
+			}
+		}
+		merged = append(merged, inline)
+	}
+	return merged
+}
+
+// blankLine parses a line containing only spaces and tabs.
+func blankLine() comb.Parser[string] {
+	return cmb.Suffixed(cmb.SatisfyMN("blank", 0, math.MaxInt, isSpace), cmb.LF())
+}
+
+// lineEnd parses the end of a line including the line break (if any).
+func lineEnd() comb.Parser[string] {
+	return cmb.Prefixed(cmb.EOL(), cmb.Optional(cmb.FirstSuccessful(cmb.CRLF(), S("\n"))))
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+func isTextRune(r rune) bool {
+	return r != '\n' && r != '\r'
+}
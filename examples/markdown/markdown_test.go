@@ -0,0 +1,140 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantErrMsg string
+		wantOutput []Block
+	}{
+		{
+			name:       "parsing an empty document should succeed",
+			input:      "",
+			wantErr:    false,
+			wantOutput: []Block{},
+		}, {
+			name:    "parsing headings should succeed",
+			input:   "# Title\n\n### Sub *title*\n",
+			wantErr: false,
+			wantOutput: []Block{
+				Heading{Level: 1, Text: []Inline{Text("Title")}},
+				Heading{Level: 3, Text: []Inline{Text("Sub "), Emphasis{Text: "title"}}},
+			},
+		}, {
+			name:    "parsing a paragraph with inlines should succeed",
+			input:   "Some **bold** and `code`\nwith a [link](https://example.com) * star\n",
+			wantErr: false,
+			wantOutput: []Block{
+				Paragraph{Text: []Inline{
+					Text("Some "), Emphasis{Strong: true, Text: "bold"}, Text(" and "), Code("code"),
+					Text("\nwith a "), Link{Text: "link", URL: "https://example.com"}, Text(" * star"),
+				}},
+			},
+		}, {
+			name:    "parsing lists should succeed",
+			input:   "- one\n- two\n\n1. first\n2. second",
+			wantErr: false,
+			wantOutput: []Block{
+				List{Ordered: false, Items: [][]Inline{{Text("one")}, {Text("two")}}},
+				List{Ordered: true, Items: [][]Inline{{Text("first")}, {Text("second")}}},
+			},
+		}, {
+			name:    "a paragraph should be interrupted by other blocks",
+			input:   "text\n# heading\ntext\r\n* item\n",
+			wantErr: false,
+			wantOutput: []Block{
+				Paragraph{Text: []Inline{Text("text")}},
+				Heading{Level: 1, Text: []Inline{Text("heading")}},
+				Paragraph{Text: []Inline{Text("text")}},
+				List{Ordered: false, Items: [][]Inline{{Text("item")}}},
+			},
+		}, {
+			name:    "parsing a code block should succeed",
+			input:   "```go\nfunc main() {\n\t# no heading\n}\n```\nafter\n",
+			wantErr: false,
+			wantOutput: []Block{
+				CodeBlock{Lang: "go", Code: "func main() {\n\t# no heading\n}\n"},
+				Paragraph{Text: []Inline{Text("after")}},
+			},
+		}, {
+			name:    "too many heading markers should be a paragraph",
+			input:   "####### no heading\n",
+			wantErr: false,
+			wantOutput: []Block{
+				Paragraph{Text: []Inline{Text("####### no heading")}},
+			},
+		}, {
+			name:       "an unclosed code block should fail",
+			input:      "# Code\n```\ncode\n",
+			wantErr:    true,
+			wantErrMsg: "expected \"```\" opened at 2:1 to be closed by \"```\" [4:1] ▶",
+			wantOutput: nil,
+		}, {
+			name:       "parsing should continue after a broken heading",
+			input:      "# Title\n# \n# Next\ntext\n",
+			wantErr:    true,
+			wantErrMsg: "unexpected \"\\n\" before heading marker (deleted) [2:3] # ▶",
+			wantOutput: []Block{
+				Heading{Level: 1, Text: []Inline{Text("Title")}},
+				Heading{Level: 1, Text: []Inline{Text("Next")}},
+				Paragraph{Text: []Inline{Text("text")}},
+			},
+		}, {
+			name:       "parsing should continue after a broken list item",
+			input:      "- one\n- \n- three\n\npara\n",
+			wantErr:    true,
+			wantErrMsg: "unexpected \"\\n\" before list marker (deleted) [2:3] - ▶",
+			wantOutput: []Block{
+				List{Ordered: false, Items: [][]Inline{{Text("one")}, {Text("three")}}},
+				Paragraph{Text: []Inline{Text("para")}},
+			},
+		}, {
+			name:       "parsing should continue with the next block after a broken list",
+			input:      "- \n# a\n\n- b\n",
+			wantErr:    true,
+			wantErrMsg: "unexpected \"\\n\" before heading marker (deleted) [1:3] - ▶",
+			wantOutput: []Block{
+				Heading{Level: 1, Text: []Inline{Text("a")}},
+				List{Ordered: false, Items: [][]Inline{{Text("b")}}},
+			},
+		}, {
+			name:       "parsing should continue with a code block after a broken heading",
+			input:      "# \n\n```go\ncode\n```\n# ok\n",
+			wantErr:    true,
+			wantErrMsg: "expected text (need 1, found 0, got '\\n') instead of \"\\n\\n\" (substituted) [1:3] # ▶",
+			wantOutput: []Block{
+				CodeBlock{Lang: "go", Code: "code\n"},
+				Heading{Level: 1, Text: []Inline{Text("ok")}},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, gotErr := ParseMarkdown(tc.input)
+			if (gotErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error %v", gotErr, tc.wantErr)
+			}
+			if tc.wantErrMsg != "" && gotErr != nil && gotErr.Error() != tc.wantErrMsg {
+				t.Errorf("got error message %q, want %q", gotErr.Error(), tc.wantErrMsg)
+			}
+
+			assert.Equal(t,
+				tc.wantOutput,
+				gotOutput,
+				"got output %v, want output %v", gotOutput, tc.wantOutput,
+			)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Package nmea demonstrates the usage of the comb package to parse
+// [NMEA 0183] sentences as sent by GPS receivers and other marine electronics.
+//
+// A sentence looks like this:
+//
+//	$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47
+//
+// The checksum after the '*' is the XOR of all bytes between '$' and '*'
+// in hexadecimal.
+//
+// [NMEA 0183]: https://en.wikipedia.org/wiki/NMEA_0183
+package nmea
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	. "github.com/flowdev/comb/cute"
+)
+
+// MaxSentenceLen is the maximum length of a sentence without line ending
+// according to the standard.
+const MaxSentenceLen = 82
+
+// Sentence is a parsed NMEA 0183 sentence.
+type Sentence struct {
+	Talker string   // e.g. "GP" for GPS or "P" for proprietary sentences
+	Type   string   // e.g. "GGA" or "GRME" for proprietary sentences
+	Fields []string // the data fields (empty fields are kept)
+}
+
+// ParseSentence parses a single NMEA 0183 sentence without line ending.
+func ParseSentence(input string) (Sentence, error) {
+	return comb.RunOnString(input, SentenceParser())
+}
+
+// ParseSentences parses the NMEA 0183 sentences in r line by line and hands
+// each sentence to `handle` together with its line number.
+// A broken sentence (e.g. with a bad checksum) is reported to `handle`
+// with its error and doesn't influence the following sentences.
+// Parsing stops as soon as `handle` returns false.
+// The returned error is a read error of r.
+func ParseSentences(r io.Reader, handle func(lineNo int, s Sentence, err error) bool) error {
+	return comb.RunLines(r, SentenceParser(), handle)
+}
+
+// SentenceParser returns a parser for a single NMEA 0183 sentence
+// including the mandatory checksum.
+// Sentences with a bad checksum are rejected as a whole.
+func SentenceParser() comb.Parser[Sentence] {
+	body := cmb.Map2(Address(), cmb.Many0(cmb.Prefixed(C(','), Field())),
+		func(address [2]string, fields []string) (Sentence, error) {
+			return Sentence{Talker: address[0], Type: address[1], Fields: fields}, nil
+		},
+	)
+	sentence := cmb.Checksummed(body, cmb.Prefixed(C('*'), Checksum()), verifyChecksum)
+	return cmb.Suffixed(cmb.Prefixed(C('$'), sentence), cmb.EOF())
+}
+
+// Address parses the address field of a sentence and returns the talker ID
+// and the sentence type.
+// Proprietary sentences start with 'P' followed by the manufacturer code
+// and the sentence type.
+func Address() comb.Parser[[2]string] {
+	return cmb.Map(cmb.SatisfyMN("address (talker ID and sentence type)", 4, 6, isAddressChar),
+		func(address string) ([2]string, error) {
+			if strings.HasPrefix(address, "P") {
+				return [2]string{"P", address[1:]}, nil
+			}
+			if len(address) != 5 {
+				return [2]string{}, fmt.Errorf("address %q should have 5 characters", address)
+			}
+			return [2]string{address[:2], address[2:]}, nil
+		},
+	)
+}
+
+// Field parses a (possibly empty) data field of a sentence.
+func Field() comb.Parser[string] {
+	return cmb.SatisfyMN("data field", 0, MaxSentenceLen, isFieldChar)
+}
+
+// Checksum parses the two hexadecimal digits of the checksum.
+func Checksum() comb.Parser[byte] {
+	return cmb.Map(cmb.SatisfyMN("checksum (2 hex digits)", 2, 2, cmb.IsHexDigit),
+		func(hex string) (byte, error) {
+			sum, err := strconv.ParseUint(hex, 16, 8)
+			return byte(sum), err
+		},
+	)
+}
+
+func verifyChecksum(body []byte, sum byte) error {
+	var want byte
+	for _, b := range body {
+		want ^= b
+	}
+	if want != sum {
+		return fmt.Errorf("got %02X, want %02X", sum, want)
+	}
+	return nil
+}
+
+func isAddressChar(r rune) bool {
+	return r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// isFieldChar reports whether r may be part of a data field.
+// Reserved characters and control characters aren't allowed.
+func isFieldChar(r rune) bool {
+	return r >= ' ' && r < 0x7f && !strings.ContainsRune("$*,!\\^~", r)
+}
@@ -0,0 +1,80 @@
+package nmea
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSentence(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    string
+		wantOutput Sentence
+	}{
+		{
+			name:  "GGA sentence should succeed",
+			input: "$GPGGA,123519,4807.038,N,01131.000,E,1,08,0.9,545.4,M,46.9,M,,*47",
+			wantOutput: Sentence{Talker: "GP", Type: "GGA", Fields: []string{
+				"123519", "4807.038", "N", "01131.000", "E", "1", "08", "0.9", "545.4", "M", "46.9", "M", "", "",
+			}},
+		}, {
+			name:       "proprietary sentence should succeed",
+			input:      "$PGRME,15.0,M,45.0,M,25.0,M*1C",
+			wantOutput: Sentence{Talker: "P", Type: "GRME", Fields: []string{"15.0", "M", "45.0", "M", "25.0", "M"}},
+		}, {
+			name:    "bad checksum should fail",
+			input:   "$PGRME,15.0,M,45.0,M,25.0,M*1D",
+			wantErr: "checksum mismatch: got 1D, want 1C",
+		}, {
+			name:    "missing checksum should fail",
+			input:   "$PGRME,15.0,M,45.0,M,25.0,M",
+			wantErr: "expected '*' (at EOF)",
+		}, {
+			name:    "bad address should fail",
+			input:   "$GPGG,1*0A",
+			wantErr: `address "GPGG" should have 5 characters`,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := ParseSentence(tc.input)
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantOutput, gotOutput)
+		})
+	}
+}
+
+func TestParseSentences(t *testing.T) {
+	t.Parallel()
+
+	input := "$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*6A\r\n" +
+		"$GPRMC,123519,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W*00\r\n" +
+		"$PGRME,15.0,M,45.0,M,25.0,M*1C\r\n"
+
+	var types []string
+	var errLines []int
+	err := ParseSentences(strings.NewReader(input), func(lineNo int, s Sentence, err error) bool {
+		if err != nil {
+			assert.ErrorContains(t, err, "[2:")
+			errLines = append(errLines, lineNo)
+			return true
+		}
+		types = append(types, s.Type)
+		return true
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"RMC", "GRME"}, types)
+	assert.Equal(t, []int{2}, errLines)
+}
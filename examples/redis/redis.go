@@ -313,7 +313,7 @@ func Array() comb.Parser[RESPMessage] {
 func sizePrefix(prefix comb.Parser[string]) comb.Parser[int64] {
 	return cmb.Delimited(
 		prefix,
-		cmb.Int64(true, 10),
+		cmb.Int[int64](true, 10),
 		cmb.CRLF(),
 	)
 }
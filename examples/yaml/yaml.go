@@ -0,0 +1,355 @@
+// Package yaml implements a parser for a small subset of YAML.
+//
+// It is a simple, incomplete, example of how to use the comb
+// parser combinator library for an indentation sensitive format.
+// Supported are block mappings (with plain keys), block sequences
+// (including compact mappings like `- a: 1`), comments, plain and
+// double-quoted scalars and flow collections.
+// Flow collections are a superset of JSON, so every JSON value can be used.
+// A nested block has to be indented more than its parent
+// (even sequences in mappings).
+//
+// The indentation of a block is measured while parsing and the parsers for
+// the block are created with comb.Bind for exactly that indentation.
+// Error recovery resynchronizes at the next key of the top level mapping.
+package yaml
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/flowdev/comb/cmb/lang"
+)
+
+type (
+	// Value is one of the value types: Mapping, Sequence, string, int64,
+	// float64, bool or nil.
+	Value interface{}
+
+	// Mapping is a mapping with its pairs in the order of the input.
+	Mapping []Pair
+
+	// Pair is a key with its value.
+	Pair struct {
+		Key   string
+		Value Value
+	}
+
+	// Sequence is a sequence of values.
+	Sequence []Value
+)
+
+// Parse parses a YAML document.
+func Parse(input string) (Value, error) {
+	return comb.RunOnString(input, document())
+}
+
+// grammar holds the parsers shared by the whole document.
+type grammar struct {
+	flow comb.Parser[Value] // flow node (recursive)
+}
+
+func document() comb.Parser[Value] {
+	g := &grammar{}
+	g.flow = comb.LazyBranchParser(g.flowNode)
+	return cmb.Delimited(
+		blankLines(),
+		g.node(0),
+		cmb.Prefixed(blankLines(), cmb.Prefixed(comment(), cmb.EOF())),
+	)
+}
+
+// node parses a block mapping, a block sequence or a single line value
+// at the current position with the indentation `indent`.
+func (g *grammar) node(indent int) comb.Parser[Value] {
+	return cmb.FirstSuccessful(g.blockSequence(indent), g.blockMapping(indent), g.inline())
+}
+
+func (g *grammar) blockMapping(indent int) comb.Parser[Value] {
+	pair := func(safeSpot bool) comb.Parser[Pair] { // every parser can only have one parent
+		key := mappingKey()
+		if safeSpot {
+			key = comb.SafeSpot(key)
+		}
+		return cmb.Map2(key, g.mappingValue(indent), func(key string, value Value) (Pair, error) {
+			return Pair{Key: key, Value: value}, nil
+		})
+	}
+	// Keys are safe spots, so errors in their values aren't hidden by alternatives.
+	// Only the top level keys are used for recovery (nested blocks are created by comb.Bind).
+	// The first top level key isn't a safe spot, so recovery continues in the loop and
+	// keeps the pairs before the error.
+	return cmb.Map2(pair(indent > 0), cmb.Many0(cmb.Prefixed(nextLine(indent), pair(true))),
+		func(first Pair, rest []Pair) (Value, error) {
+			return append(Mapping{first}, rest...), nil
+		},
+	)
+}
+
+// mappingValue parses the value after the colon of a key.
+func (g *grammar) mappingValue(indent int) comb.Parser[Value] {
+	return cmb.FirstSuccessful(
+		cmb.Prefixed(spaces(1), g.inline()),
+		g.nestedBlock(indent),
+		emptyValue(),
+	)
+}
+
+func (g *grammar) blockSequence(indent int) comb.Parser[Value] {
+	item := cmb.Prefixed(cmb.Char('-'), cmb.FirstSuccessful(
+		cmb.Prefixed(spaces(1), g.compactNode()),
+		g.nestedBlock(indent),
+		emptyValue(),
+	))
+	return cmb.Map2(item, cmb.Many0(cmb.Prefixed(nextLine(indent), item)),
+		func(first Value, rest []Value) (Value, error) {
+			return append(Sequence{first}, rest...), nil
+		},
+	)
+}
+
+// compactNode parses a node that starts on the line of a sequence entry.
+// Its indentation is the column it starts at.
+func (g *grammar) compactNode() comb.Parser[Value] {
+	return comb.Bind(column(), func(col int) comb.Parser[Value] {
+		return g.node(col)
+	})
+}
+
+// nestedBlock parses a node on the following lines that is indented
+// more than its parent.
+func (g *grammar) nestedBlock(parentIndent int) comb.Parser[Value] {
+	return cmb.Prefixed(lineEnd(), cmb.Prefixed(blankLines(), comb.Bind(
+		cmb.Peek(spaces(0)),
+		func(ind string) comb.Parser[Value] {
+			if len(ind) <= parentIndent {
+				return indentationError(parentIndent)
+			}
+			return cmb.Prefixed(indentation(len(ind)), g.node(len(ind)))
+		},
+	)))
+}
+
+// inline parses a value that is contained in a single line
+// (except for flow collections).
+func (g *grammar) inline() comb.Parser[Value] {
+	return cmb.FirstSuccessful(g.flowCollection(), quoted(), plainScalar(false))
+}
+
+func (g *grammar) flowNode() comb.Parser[Value] {
+	return cmb.FirstSuccessful(g.flowCollection(), quoted(), plainScalar(true))
+}
+
+func (g *grammar) flowCollection() comb.Parser[Value] {
+	ws := cmb.Whitespace0()
+	item := cmb.Delimited(ws, g.flow, ws)
+	sequence := cmb.Map(
+		cmb.Delimited(cmb.Char('['), cmb.Separated0(item, cmb.Char(','), false), cmb.Prefixed(ws, cmb.Char(']'))),
+		func(items []Value) (Value, error) {
+			return append(Sequence{}, items...), nil
+		},
+	)
+	key := cmb.Map(cmb.Delimited(ws, cmb.FirstSuccessful(quoted(), plainScalar(true)), ws),
+		func(key Value) (string, error) {
+			if s, ok := key.(string); ok {
+				return s, nil
+			}
+			return formatScalar(key), nil
+		},
+	)
+	pair := cmb.Map2(cmb.Suffixed(key, cmb.Char(':')), item, func(key string, value Value) (Pair, error) {
+		return Pair{Key: key, Value: value}, nil
+	})
+	mapping := cmb.Map(
+		cmb.Delimited(cmb.Char('{'), cmb.Separated0(pair, cmb.Char(','), false), cmb.Prefixed(ws, cmb.Char('}'))),
+		func(pairs []Pair) (Value, error) {
+			return append(Mapping{}, pairs...), nil
+		},
+	)
+	return cmb.FirstSuccessful(sequence, mapping)
+}
+
+func quoted() comb.Parser[Value] {
+	return cmb.Map(lang.String(lang.StringConfig{}), func(tok lang.Token[lang.StringLit]) (Value, error) {
+		return tok.Value.Value, nil
+	})
+}
+
+// plainScalar parses an unquoted scalar and resolves its type
+// (null, bool, int64, float64 or string).
+// It stops at a colon followed by a space (that belongs to a key) and
+// in flow collections at ',', ':', ']' and '}', too.
+func plainScalar(flow bool) comb.Parser[Value] {
+	expected := "plain scalar"
+
+	parse := func(state comb.State) (comb.State, Value, *comb.ParserError) {
+		input := state.CurrentString()
+		if input == "" {
+			return state, nil, comb.MarkIncomplete(state.NewSyntaxError(expected))
+		}
+		if strings.IndexByte("[]{},:#\"' \t\r\n", input[0]) >= 0 ||
+			input[0] == '-' && (len(input) == 1 || strings.IndexByte(" \t\r\n", input[1]) >= 0) {
+			return state, nil, state.NewSyntaxError(expected)
+		}
+		end := 0
+		for end < len(input) {
+			c := input[end]
+			if c == '\n' || c == '\r' || c == '#' && end > 0 && input[end-1] == ' ' ||
+				c == ':' && (end+1 == len(input) || strings.IndexByte(" \t\r\n", input[end+1]) >= 0) ||
+				flow && strings.IndexByte(",:]}", c) >= 0 {
+				break
+			}
+			end++
+		}
+		text := strings.TrimRight(input[:end], " \t")
+		if text == "" {
+			return state, nil, state.NewSyntaxError(expected)
+		}
+		return state.MoveBy(len(text)), resolveScalar(text), nil
+	}
+
+	return comb.NewParser[Value](expected, parse, cmb.Forbidden())
+}
+
+func resolveScalar(text string) Value {
+	switch text {
+	case "null", "~":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !strings.ContainsAny(text, "xXpP_") {
+		return f
+	}
+	return text
+}
+
+func formatScalar(v Value) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// mappingKey parses a plain key including its colon.
+// Its recoverer finds the next key at the start of a line without indentation.
+func mappingKey() comb.Parser[string] {
+	expected := "mapping key"
+
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		end := keyEnd(input)
+		if end < 0 {
+			return state, "", state.NewSyntaxError(expected)
+		}
+		return state.MoveBy(end + 1), strings.TrimRight(input[:end], " \t"), nil
+	}
+
+	recoverer := func(state comb.State, _ interface{}) (int, interface{}) {
+		input := state.CurrentString()
+		for i := 0; i < len(input); i++ {
+			if (i == 0 && state.AtLineStart() || i > 0 && input[i-1] == '\n') && keyEnd(input[i:]) >= 0 {
+				return i, nil
+			}
+		}
+		return comb.RecoverWasteTooMuch, nil
+	}
+
+	return comb.NewParser[string](expected, parse, recoverer)
+}
+
+// keyEnd returns the position of the colon after a plain key at the start
+// of the input or -1.
+func keyEnd(input string) int {
+	if input == "" || strings.IndexByte("-[]{},:#\"' \t\r\n", input[0]) >= 0 {
+		return -1
+	}
+	for i := 1; i < len(input); i++ {
+		switch c := input[i]; {
+		case c == '\n' || c == '\r' || c == '#' && input[i-1] == ' ':
+			return -1
+		case c == ':' && (i+1 == len(input) || strings.IndexByte(" \t\r\n", input[i+1]) >= 0):
+			return i
+		}
+	}
+	return -1
+}
+
+// emptyValue succeeds at the end of a line without consuming it.
+func emptyValue() comb.Parser[Value] {
+	return cmb.Assign[Value](nil, cmb.Peek(lineEnd()))
+}
+
+// column returns the column of the current position (starting at 0)
+// without consuming any input.
+func column() comb.Parser[int] {
+	return comb.NewParser[int]("column", func(state comb.State) (comb.State, int, *comb.ParserError) {
+		_, col := state.LineCol()
+		return state, col - 1, nil
+	}, cmb.Forbidden())
+}
+
+// indentation parses exactly n spaces that are followed by content.
+func indentation(n int) comb.Parser[string] {
+	expected := "indentation of " + strconv.Itoa(n) + " spaces"
+
+	parse := func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		if len(input) <= n || strings.TrimLeft(input[:n], " ") != "" ||
+			strings.IndexByte(" \t\r\n", input[n]) >= 0 {
+			return state, "", state.NewSyntaxError(expected)
+		}
+		return state.MoveBy(n), input[:n], nil
+	}
+
+	return comb.NewParser[string](expected, parse, cmb.Forbidden())
+}
+
+func indentationError(parentIndent int) comb.Parser[Value] {
+	expected := "block indented more than " + strconv.Itoa(parentIndent) + " spaces"
+	return comb.NewParser[Value](expected, func(state comb.State) (comb.State, Value, *comb.ParserError) {
+		return state, nil, state.NewSyntaxError(expected)
+	}, cmb.Forbidden())
+}
+
+// nextLine parses the end of the current line, blank lines and
+// the indentation of the next entry of a block.
+func nextLine(indent int) comb.Parser[string] {
+	return cmb.Prefixed(lineEnd(), cmb.Prefixed(blankLines(), indentation(indent)))
+}
+
+// lineEnd parses optional trailing spaces and a comment and the line break.
+func lineEnd() comb.Parser[string] {
+	return cmb.Prefixed(comment(), cmb.OneOf("\r\n", "\n"))
+}
+
+func blankLines() comb.Parser[[]string] {
+	return cmb.Many0(cmb.Prefixed(comment(), cmb.OneOf("\r\n", "\n")))
+}
+
+// comment parses optional spaces and an optional comment up to the line break.
+func comment() comb.Parser[string] {
+	return cmb.Prefixed(spaces(0), cmb.Optional(cmb.Prefixed(cmb.Char('#'),
+		cmb.SatisfyMN("comment", 0, math.MaxInt, func(r rune) bool { return r != '\n' && r != '\r' }),
+	)))
+}
+
+func spaces(atLeast int) comb.Parser[string] {
+	return cmb.SatisfyMN("space", atLeast, math.MaxInt, func(r rune) bool { return r == ' ' || r == '\t' })
+}
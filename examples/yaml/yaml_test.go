@@ -0,0 +1,99 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput Value
+	}{
+		{
+			name:       "parsing a scalar should succeed",
+			input:      "hello world # comment\n",
+			wantOutput: "hello world",
+		}, {
+			name:  "parsing a block mapping should succeed",
+			input: "# config\nname: comb\nversion: 1.5\nstable: true\nempty:\nquoted: \"a\\tb\"\n",
+			wantOutput: Mapping{
+				{Key: "name", Value: "comb"},
+				{Key: "version", Value: 1.5},
+				{Key: "stable", Value: true},
+				{Key: "empty", Value: nil},
+				{Key: "quoted", Value: "a\tb"},
+			},
+		}, {
+			name:  "parsing nested blocks should succeed",
+			input: "server:\n  host: localhost\n\n  ports:\n    - 80\n    - 443\nlog: ~\n",
+			wantOutput: Mapping{
+				{Key: "server", Value: Mapping{
+					{Key: "host", Value: "localhost"},
+					{Key: "ports", Value: Sequence{int64(80), int64(443)}},
+				}},
+				{Key: "log", Value: nil},
+			},
+		}, {
+			name:  "parsing compact mappings in sequences should succeed",
+			input: "- name: a\n  id: 1\n-   name: b\n    id: 2\n- -1\n",
+			wantOutput: Sequence{
+				Mapping{{Key: "name", Value: "a"}, {Key: "id", Value: int64(1)}},
+				Mapping{{Key: "name", Value: "b"}, {Key: "id", Value: int64(2)}},
+				int64(-1),
+			},
+		}, {
+			name:  "parsing flow collections should succeed",
+			input: "json: {\"a\": [1, 2.5, null], b: {}}\nlist: [x, \"y\", [ ]]\n",
+			wantOutput: Mapping{
+				{Key: "json", Value: Mapping{
+					{Key: "a", Value: Sequence{int64(1), 2.5, nil}},
+					{Key: "b", Value: Mapping{}},
+				}},
+				{Key: "list", Value: Sequence{"x", "y", Sequence{}}},
+			},
+		}, {
+			name:    "parsing a wrongly indented block should fail",
+			input:   "a:\n  b: 1\n c: 2\n",
+			wantErr: true,
+		}, {
+			name:    "recovery should go on with the next top level key",
+			input:   "a: 1\nb:\n  c: [1, 2\nd: 4\n",
+			wantErr: true,
+			wantOutput: Mapping{ // the broken pair is dropped
+				{Key: "a", Value: int64(1)},
+				{Key: "d", Value: int64(4)},
+			},
+		}, {
+			name:    "recovery should keep the pairs around the broken one",
+			input:   "a: 1\nb: 2\nc: {x: 1\nd:\n  e: 5\nf: 6\n",
+			wantErr: true,
+			wantOutput: Mapping{
+				{Key: "a", Value: int64(1)},
+				{Key: "b", Value: int64(2)},
+				{Key: "d", Value: Mapping{{Key: "e", Value: int64(5)}}},
+				{Key: "f", Value: int64(6)},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := Parse(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			if !tc.wantErr || tc.wantOutput != nil {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+			}
+		})
+	}
+}
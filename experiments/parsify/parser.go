@@ -47,7 +47,7 @@ func Map3[PO1, PO2, PO3, MO any, ParserPO1 Parserish[PO1], ParserPO2 Parserish[P
 
 		mapped, err := fn(output1, output2, output3)
 		if err != nil {
-			return state, zero, state.NewSemanticError(err.Error())
+			return state, zero, state.NewSemanticError("%w", err)
 		}
 
 		return newState3, mapped, nil
@@ -0,0 +1,170 @@
+package comb
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// ============================================================================
+// Explaining Error Recovery
+//
+
+// ErrorReport explains the error recovery of a run (see ExplainErrors).
+// It can be serialized (e.g. to JSON) or rendered with DOT and HTML.
+type ErrorReport struct {
+	Errors []ErrorExplanation `json:"errors"`
+}
+
+// ErrorExplanation explains the recovery from a single error.
+type ErrorExplanation struct {
+	Message    string              `json:"message"`
+	Pos        int                 `json:"pos"` // position of the error in the input
+	ParserID   int32               `json:"id"`  // parser that reported the error
+	Expected   string              `json:"expected"`
+	Candidates []RecoveryCandidate `json:"candidates"` // the failed parser and all safe spots
+	Chosen     int                 `json:"chosen"`     // index of the chosen candidate or -1 if none could recover
+}
+
+// RecoveryCandidate is a parser that has been considered for recovering
+// from an error.
+type RecoveryCandidate struct {
+	ParserID int32  `json:"id"`
+	Name     string `json:"name,omitempty"` // name of the parser in the global registry (see Register)
+	Expected string `json:"expected"`
+	SafeSpot bool   `json:"safeSpot,omitempty"`
+	Step     bool   `json:"step,omitempty"` // recovers by trying to parse at every position
+	Waste    int    `json:"waste"`          // number of bytes skipped or RecoverWasteTooMuch
+}
+
+// ExplainErrors runs the parser on the state like RunOnState and
+// explains the recovery from every error.
+// For each error all candidates (the failed parser and all safe spots)
+// are listed with their waste (the number of bytes they would skip).
+// Step recoverers are only tried up to the waste of the chosen candidate.
+// This helps to place safe spots in big grammars.
+// Explaining is slow because all candidates are evaluated completely.
+func ExplainErrors[Output any](state State, parser *PreparedParser[Output]) (Output, *ErrorReport, error) {
+	report := &ErrorReport{}
+	constant := *state.constant
+	constant.explain = report
+	state.constant = &constant
+	out, err := RunOnState[Output](state, parser)
+	return out, report, err
+}
+
+// explainRecovery adds the explanation of the recovery from `err`
+// to the report of the run.
+func (pp *PreparedParser[Output]) explainRecovery(state State, err *ParserError, waste int, chosen AnyParser) {
+	report := state.constant.explain
+	if report == nil {
+		return
+	}
+	exp := ErrorExplanation{
+		Message:  err.Error(),
+		Pos:      state.CurrentPos(),
+		ParserID: err.parserID,
+		Expected: expectedOf(pp.parsers[err.parserID]),
+		Chosen:   -1,
+	}
+	add := func(rec AnyParser, w int) {
+		if waste >= 0 && rec == chosen {
+			exp.Chosen = len(exp.Candidates)
+			w = waste
+		}
+		exp.Candidates = append(exp.Candidates, RecoveryCandidate{
+			ParserID: rec.ID(), Name: NameOf(rec), Expected: expectedOf(rec),
+			SafeSpot: rec.IsSafeSpot(), Step: rec.IsStepRecoverer(), Waste: max(w, RecoverWasteTooMuch),
+		})
+	}
+
+	failed := pp.parsers[err.parserID]
+	stepRecs := pp.stepRecoverers
+	if failed.IsStepRecoverer() && !failed.IsSafeSpot() {
+		stepRecs = append([]AnyParser{failed}, pp.stepRecoverers...)
+	} else if !failed.IsStepRecoverer() {
+		w, _ := recoverWithBudget(failed, state, err.ParserData(failed.ID()))
+		add(failed, w)
+	}
+	for _, rec := range pp.recoverers {
+		if rec == failed {
+			continue
+		}
+		w, _ := recoverWithBudget(rec, state, err.ParserData(rec.ID()))
+		add(rec, w)
+	}
+	maxWaste := state.BytesRemaining()
+	if waste >= 0 {
+		maxWaste = waste
+	}
+	for _, sr := range stepRecs {
+		w := RecoverWasteTooMuch
+		for cur := state; state.ByteCount(cur) <= maxWaste; cur = cur.Delete1() {
+			if stepRecoverAt([]AnyParser{sr}, cur, err) != nil {
+				w = state.ByteCount(cur)
+				break
+			}
+			if cur.AtEnd() {
+				break
+			}
+		}
+		add(sr, w)
+	}
+	report.Errors = append(report.Errors, exp)
+}
+
+// DOT renders the report in the DOT language of Graphviz.
+// Every error points to its candidates; the chosen one is drawn bold.
+func (r *ErrorReport) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph recovery {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for i, exp := range r.Errors {
+		fmt.Fprintf(&b, "\te%d [label=%q, color=red];\n", i, fmt.Sprintf("error at %d: %s", exp.Pos, exp.Message))
+		for j, c := range exp.Candidates {
+			style := ""
+			if j == exp.Chosen {
+				style = ", style=bold"
+			}
+			fmt.Fprintf(&b, "\te%dc%d [label=%q%s];\n", i, j, c.label(), style)
+			fmt.Fprintf(&b, "\te%d -> e%dc%d [label=%q%s];\n", i, i, j, c.wasteText(), style)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// HTML renders the report as an HTML fragment with a table per error.
+// The row of the chosen candidate has the class "chosen".
+func (r *ErrorReport) HTML() string {
+	var b strings.Builder
+	b.WriteString("<div class=\"error-report\">\n")
+	for _, exp := range r.Errors {
+		fmt.Fprintf(&b, "<h3>error at %d: %s</h3>\n", exp.Pos, html.EscapeString(exp.Message))
+		b.WriteString("<table>\n<tr><th>ID</th><th>Parser</th><th>Safe spot</th><th>Step</th><th>Waste</th></tr>\n")
+		for j, c := range exp.Candidates {
+			class := ""
+			if j == exp.Chosen {
+				class = ` class="chosen"`
+			}
+			fmt.Fprintf(&b, "<tr%s><td>%d</td><td>%s</td><td>%t</td><td>%t</td><td>%s</td></tr>\n",
+				class, c.ParserID, html.EscapeString(c.label()), c.SafeSpot, c.Step, c.wasteText())
+		}
+		b.WriteString("</table>\n")
+	}
+	b.WriteString("</div>\n")
+	return b.String()
+}
+
+func (c RecoveryCandidate) label() string {
+	if c.Name != "" {
+		return c.Name + ": " + c.Expected
+	}
+	return c.Expected
+}
+
+func (c RecoveryCandidate) wasteText() string {
+	if c.Waste < 0 {
+		return "too much"
+	}
+	return fmt.Sprintf("waste=%d", c.Waste)
+}
@@ -0,0 +1,39 @@
+package comb_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainErrors(t *testing.T) {
+	t.Parallel()
+
+	pp := comb.NewPreparedParser(cmb.Suffixed(
+		cmb.Separated1(cmb.Int[int](false, 10), comb.SafeSpot(cmb.Char(';')), false),
+		cmb.EOF(),
+	))
+	_, report, err := comb.ExplainErrors(comb.NewFromString("1;2x;3", 10), pp)
+	assert.Error(t, err)
+	if !assert.Len(t, report.Errors, 1) {
+		return
+	}
+	exp := report.Errors[0]
+	if assert.GreaterOrEqual(t, exp.Chosen, 0) {
+		chosen := exp.Candidates[exp.Chosen]
+		assert.Equal(t, "';'", chosen.Expected)
+		assert.True(t, chosen.SafeSpot)
+		assert.Equal(t, 1, chosen.Waste)
+	}
+	assert.Contains(t, report.DOT(), `e0 -> e0c1 [label="waste=1", style=bold];`)
+	assert.Contains(t, report.HTML(), `<tr class="chosen"><td>3</td><td>&#39;;&#39;</td>`)
+	_, err = json.Marshal(report)
+	assert.NoError(t, err)
+
+	_, report, err = comb.ExplainErrors(comb.NewFromString("1;2;3", 10), pp)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Errors)
+}
@@ -0,0 +1,131 @@
+package comb
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrFeederClosed is returned by Feeder.Write and Feeder.Close
+// if the Feeder has been closed already.
+var ErrFeederClosed = errors.New("feeder is closed")
+
+// feederMaxBackoff is the maximum number of bytes a Feeder waits for
+// before it tries to parse an incomplete result again.
+const feederMaxBackoff = 4096
+
+// ============================================================================
+// Feeder: push-based parsing
+//
+
+// Feeder is an io.Writer and io.Closer that parses the input pushed into it.
+// It is meant for event-driven servers that receive their input in chunks
+// (e.g., from a websocket) instead of reading it from an io.Reader.
+//
+// Every time the root parser completes a result, the result is handed to the
+// callback given to NewFeeder and the consumed input is dropped.
+// A result is considered complete as soon as the parser stopped before the end
+// of the input received so far.
+// Everything left over is parsed one last time by Close.
+//
+// If the parser needs more input, it tries again as soon as new input arrives.
+// Only for long incomplete results the next try waits for up to
+// feederMaxBackoff more bytes, so the parsing work doesn't explode.
+//
+// A Feeder isn't concurrency safe.
+type Feeder[Output any] struct {
+	parser    *PreparedParser[Output]
+	binary    bool
+	maxErrors int
+	buf       []byte
+	waitFor   int // minimum length of buf before the next try to parse
+	handle    func(Output, error)
+	closed    bool
+}
+
+// NewFeeder creates a new Feeder for the parser.
+// `binary` decides about binary or text input (this only matters for error messages).
+// `handle` is called for every top-level result completed by the parser.
+func NewFeeder[Output any](parser Parser[Output], binary bool, handle func(Output, error)) *Feeder[Output] {
+	if handle == nil {
+		panic("NewFeeder: handle is nil")
+	}
+	return &Feeder[Output]{
+		parser:    NewPreparedParser(parser),
+		binary:    binary,
+		maxErrors: DefaultMaxErrors,
+		handle:    handle,
+	}
+}
+
+// SetMaxErrors sets the maximum number of errors to recover from
+// for every result (the default is DefaultMaxErrors).
+// FailFast turns off error recovery.
+func (f *Feeder[Output]) SetMaxErrors(maxErrors int) {
+	f.maxErrors = max(maxErrors, FailFast)
+}
+
+// Write appends the chunk to the input of the parser and hands all
+// completed results to the callback of the Feeder.
+// Every result is parsed starting at the end of the previous one and
+// the consumed input is dropped.
+// It always consumes the whole chunk.
+func (f *Feeder[Output]) Write(chunk []byte) (int, error) {
+	if f.closed {
+		return 0, ErrFeederClosed
+	}
+	f.buf = append(f.buf, chunk...)
+	if len(f.buf) < f.waitFor {
+		return len(chunk), nil
+	}
+	start := f.newState()
+	current := start
+	for !current.AtEnd() {
+		nState, out, err := f.parser.parseAll(current)
+		if nState.AtEnd() || !nState.Moved(current) { // the parser might need more input
+			break
+		}
+		f.handle(out, err)
+		current = resumeAt(start, nState)
+	}
+	if pos := current.CurrentPos(); pos > 0 {
+		f.buf = slices.Clone(f.buf[pos:]) // outputs might still reference the old buffer
+	}
+	f.waitFor = len(f.buf) + min(max(len(f.buf)/64, 1), feederMaxBackoff)
+	return len(chunk), nil
+}
+
+// Close parses the input left over (if any) and hands the result to the
+// callback of the Feeder.
+func (f *Feeder[Output]) Close() error {
+	if f.closed {
+		return ErrFeederClosed
+	}
+	f.closed = true
+	start := f.newState()
+	for current := start; !current.AtEnd(); {
+		nState, out, err := f.parser.parseAll(current)
+		f.handle(out, err)
+		if !nState.Moved(current) { // nothing consumed: we would loop forever
+			break
+		}
+		current = resumeAt(start, nState)
+	}
+	f.buf = nil
+	return nil
+}
+
+// resumeAt returns a fresh state (without errors, actions, ...) for the
+// input of `start` at the position of `state`.
+func resumeAt(start, state State) State {
+	start.pos = state.pos
+	start.prevNl = state.prevNl
+	start.line = state.line
+	return start
+}
+
+func (f *Feeder[Output]) newState() State {
+	if f.binary {
+		return NewFromBytes(f.buf, f.maxErrors)
+	}
+	return NewFromString(string(f.buf), f.maxErrors)
+}
@@ -0,0 +1,135 @@
+package comb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeder(t *testing.T) {
+	t.Parallel()
+
+	runePlusRune := func(out1 rune, out2 rune) (string, error) {
+		return string([]rune{out1, out2}), nil
+	}
+
+	testCases := []struct {
+		name        string
+		chunks      []string
+		wantOutputs []string
+		wantErrors  int
+	}{
+		{
+			name:        "one chunk",
+			chunks:      []string{"a;a;a;"},
+			wantOutputs: []string{"a;", "a;", "a;"},
+		}, {
+			name:        "split chunks",
+			chunks:      []string{"a", ";a", ";", "a;"},
+			wantOutputs: []string{"a;", "a;", "a;"},
+		}, {
+			name:        "no chunks",
+			chunks:      nil,
+			wantOutputs: nil,
+		}, {
+			name:        "error in the middle",
+			chunks:      []string{"a;b;", "a;"},
+			wantOutputs: []string{"a;", "\ufffd;", "a;"}, // the parse error results in utf8.RuneError
+			wantErrors:  1,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotOutputs []string
+			gotErrors := 0
+			f := NewFeeder(Map2(Char('a'), SafeSpot(Char(';')), runePlusRune), false, func(out string, err error) {
+				gotOutputs = append(gotOutputs, out)
+				gotErrors += len(UnwrapErrors(err))
+			})
+			for _, chunk := range tc.chunks {
+				n, err := f.Write([]byte(chunk))
+				assert.NoError(t, err)
+				assert.Equal(t, len(chunk), n)
+			}
+			assert.NoError(t, f.Close())
+			assert.Equal(t, tc.wantOutputs, gotOutputs)
+			assert.Equal(t, tc.wantErrors, gotErrors)
+
+			_, err := f.Write([]byte("a;"))
+			assert.ErrorIs(t, err, ErrFeederClosed)
+			assert.ErrorIs(t, f.Close(), ErrFeederClosed)
+		})
+	}
+}
+
+func TestFeederResumes(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	digits := NewParser[string]("digits", func(state State) (State, string, *ParserError) {
+		calls++
+		n := 0
+		for n < state.BytesRemaining() && state.CurrentString()[n] >= '0' && state.CurrentString()[n] <= '9' {
+			n++
+		}
+		nState := state.MoveBy(n)
+		return nState, state.StringTo(nState), nil
+	}, nil)
+	var gotOutputs []string
+	f := NewFeeder(Map2(digits, Char(';'), func(out string, _ rune) (string, error) {
+		return out, nil
+	}), false, func(out string, err error) {
+		assert.NoError(t, err)
+		gotOutputs = append(gotOutputs, out)
+	})
+
+	_, err := f.Write([]byte("1;2;3"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, gotOutputs)
+	assert.Equal(t, "3", string(f.buf), "the consumed input should be dropped")
+
+	calls = 0
+	for i := 0; i < 1000; i++ {
+		_, err = f.Write([]byte("4"))
+		assert.NoError(t, err)
+	}
+	assert.LessOrEqual(t, calls, 300, "the buffered input should only be parsed again after a back-off")
+	_, err = f.Write([]byte(";"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	assert.Equal(t, []string{"1", "2", "3" + strings.Repeat("4", 1000)}, gotOutputs)
+}
+
+func TestFeederDeliversEarly(t *testing.T) {
+	t.Parallel()
+
+	text := NewParser[string]("text", func(state State) (State, string, *ParserError) {
+		n := strings.IndexByte(state.CurrentString(), '\n')
+		if n < 0 {
+			n = state.BytesRemaining()
+		}
+		nState := state.MoveBy(n)
+		return nState, state.StringTo(nState), nil
+	}, nil)
+	line := Map2(text, Char('\n'), func(out string, _ rune) (string, error) {
+		return out, nil
+	})
+	var gotOutputs []string
+	f := NewFeeder(line, false, func(out string, err error) {
+		assert.NoError(t, err)
+		gotOutputs = append(gotOutputs, out)
+	})
+
+	long := strings.Repeat("x", 96)
+	for _, chunk := range []string{long + "\n", "a\n", "b\n"} {
+		_, err := f.Write([]byte(chunk))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{long, "a"}, gotOutputs, "complete lines should be delivered before Close")
+	assert.NoError(t, f.Close())
+	assert.Equal(t, []string{long, "a", "b"}, gotOutputs)
+}
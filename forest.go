@@ -0,0 +1,141 @@
+package comb
+
+// ============================================================================
+// Breadth-First Parsing Of Ambiguous Grammars
+//
+
+// ForestLimits limit the work done by ParseForest.
+// A limit of 0 means the default limit.
+type ForestLimits struct {
+	MaxParses int // maximum number of successful parses (default: 100)
+	MaxRuns   int // maximum number of parse runs including failed ones (default: 1000)
+}
+
+const (
+	defaultMaxForestParses = 100
+	defaultMaxForestRuns   = 1000
+)
+
+// Forest contains all parses of an input found by ParseForest.
+type Forest[Output any] struct {
+	Parses    []Output // outputs of all successful parses in breadth-first order
+	Runs      int      // number of parse runs needed
+	Truncated bool     // a limit has been reached, so more parses might exist
+}
+
+// forestRun records the decisions of a single parse run of ParseForest.
+type forestRun struct {
+	path  []bool // decisions to replay (true means: skip the successful alternative)
+	n     int    // number of decisions made so far
+	forks []int  // decisions after the path that can be skipped
+}
+
+// ForkChoice is used by choices (like cmb.FirstSuccessful) to support
+// ParseForest.
+// It is called after alternatives[chosen] succeeded at the state and reports
+// whether the choice should skip it and try the following alternatives instead.
+// It always reports false outside of ParseForest.
+func (st State) ForkChoice(chosen int, alternatives []AnyParser) bool {
+	run := st.constant.forest
+	if run == nil || chosen >= len(alternatives)-1 {
+		return false
+	}
+	k := run.n
+	run.n++
+	if k < len(run.path) {
+		return run.path[k]
+	}
+	if len(st.succeedingAlternatives(alternatives[chosen+1:])) > 0 {
+		run.forks = append(run.forks, k)
+	}
+	return false
+}
+
+// ParseForest parses the input of the state with all combinations of
+// successful alternatives of the choices (like cmb.FirstSuccessful)
+// instead of committing to the first successful alternative.
+// The combinations are tried breadth-first, so parses that differ
+// in fewer choices from the committed choice parse are found first.
+// This finds all parses of genuinely ambiguous grammars and parses
+// that committed choice misses because the first successful alternative
+// leads to an error later on.
+//
+// Every combination needs its own parse run, so the limits cap the work.
+// The parse runs are done without error recovery.
+// The error of the first run is returned if no parse succeeds.
+func ParseForest[Output any](state State, parser *PreparedParser[Output], limits ForestLimits) (Forest[Output], error) {
+	maxParses, maxRuns := limits.MaxParses, limits.MaxRuns
+	if maxParses <= 0 {
+		maxParses = defaultMaxForestParses
+	}
+	if maxRuns <= 0 {
+		maxRuns = defaultMaxForestRuns
+	}
+
+	forest := Forest[Output]{}
+	var firstErr error
+	queue := [][]bool{nil}
+	for len(queue) > 0 {
+		if forest.Runs >= maxRuns {
+			forest.Truncated = true
+			break
+		}
+		path := queue[0]
+		queue = queue[1:]
+
+		run := &forestRun{path: path}
+		_, out, err := parser.parseAll(state.withForestRun(run))
+		forest.Runs++
+		for _, k := range run.forks {
+			fork := make([]bool, k+1) // all decisions after the path take the first successful alternative
+			copy(fork, path)
+			fork[k] = true
+			queue = append(queue, fork)
+		}
+
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		forest.Parses = append(forest.Parses, out)
+		if len(forest.Parses) >= maxParses {
+			forest.Truncated = len(queue) > 0
+			break
+		}
+	}
+
+	if len(forest.Parses) == 0 {
+		return forest, firstErr
+	}
+	return forest, nil
+}
+
+// withForestRun returns a fresh state for a single parse run of ParseForest.
+func (st State) withForestRun(run *forestRun) State {
+	constant := *st.constant
+	constant.forest = run
+	constant.maxErrors = FailFast
+	constant.parserCache = make(map[int32]interface{})
+	st.constant = &constant
+	return st
+}
+
+// succeedingAlternatives returns the alternatives that succeed at the state
+// without any diagnostic or forest mode.
+func (st State) succeedingAlternatives(alternatives []AnyParser) []AnyParser {
+	probe := st
+	constant := *st.constant
+	constant.ambiguities = nil // findings of alternatives that aren't chosen are irrelevant
+	constant.forest = nil      // alternatives are probed with committed choice
+	probe.constant = &constant
+
+	var succeeded []AnyParser
+	for _, alt := range alternatives {
+		if _, _, err := alt.ParseAny(ParentUnknown, probe); err == nil {
+			succeeded = append(succeeded, alt)
+		}
+	}
+	return succeeded
+}
@@ -0,0 +1,86 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseForest(t *testing.T) {
+	t.Parallel()
+
+	segments := func() comb.Parser[[]string] {
+		return cmb.Suffixed(
+			cmb.Many1(cmb.FirstSuccessful(cmb.String("ab"), cmb.String("a"), cmb.String("b"))),
+			cmb.EOF(),
+		)
+	}
+	committed := func() comb.Parser[string] {
+		return cmb.Suffixed(cmb.FirstSuccessful(cmb.String("a"), cmb.String("ab")), cmb.String("c"))
+	}
+
+	testCases := []struct {
+		name          string
+		parser        func() comb.Parser[[]string]
+		input         string
+		limits        comb.ForestLimits
+		wantErr       bool
+		wantParses    [][]string
+		wantTruncated bool
+	}{
+		{
+			name:   "ambiguous grammar should yield all parses",
+			parser: segments,
+			input:  "abab",
+			wantParses: [][]string{
+				{"ab", "ab"},
+				{"a", "b", "ab"},
+				{"ab", "a", "b"},
+				{"a", "b", "a", "b"},
+			},
+		}, {
+			name:          "max parses should truncate the forest",
+			parser:        segments,
+			input:         "abab",
+			limits:        comb.ForestLimits{MaxParses: 2},
+			wantParses:    [][]string{{"ab", "ab"}, {"a", "b", "ab"}},
+			wantTruncated: true,
+		}, {
+			name:          "max runs should truncate the forest",
+			parser:        segments,
+			input:         "abab",
+			limits:        comb.ForestLimits{MaxRuns: 1},
+			wantParses:    [][]string{{"ab", "ab"}},
+			wantTruncated: true,
+		}, {
+			name: "parse missed by committed choice should be found",
+			parser: func() comb.Parser[[]string] {
+				return cmb.Map(committed(), func(s string) ([]string, error) { return []string{s}, nil })
+			},
+			input:      "abc",
+			wantParses: [][]string{{"ab"}},
+		}, {
+			name:    "input without any parse should fail",
+			parser:  segments,
+			input:   "abc",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			forest, err := comb.ParseForest(comb.NewFromString(tc.input, 10),
+				comb.NewPreparedParser(tc.parser()), tc.limits)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantParses, forest.Parses)
+			assert.Equal(t, tc.wantTruncated, forest.Truncated)
+		})
+	}
+}
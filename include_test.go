@@ -0,0 +1,127 @@
+package comb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushInputGrammar(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{"ok.txt": "b;c;", "broken.txt": "b;c!;d;"}
+	testCases := []struct {
+		name       string
+		input      string
+		wantOutput []string
+		wantErr    string
+		wantPos    comb.ErrorPosition
+	}{
+		{
+			name:       "included input should be parsed in place",
+			input:      `a;include "ok.txt";e;`,
+			wantOutput: []string{"a", "b", "c", "e"},
+		}, {
+			name:       "errors should be reported in the included input and parsing should continue after the include",
+			input:      `a;include "broken.txt";e;`,
+			wantOutput: []string{"a", "e"},
+			wantErr:    "expected end of the input (still 5 bytes of input left) [broken.txt:1:3] b;▶c!;d;",
+			wantPos:    comb.ErrorPosition{Source: "broken.txt", Pos: 2, Line: 1, Column: 3},
+		}, {
+			name:       "the outer input should be skipped after the include",
+			input:      `a;include "broken.txt";!e;f;`,
+			wantOutput: []string{"a", "f"},
+			wantErr:    "expected end of the input (still 5 bytes of input left) [broken.txt:1:3] b;▶c!;d;",
+			wantPos:    comb.ErrorPosition{Source: "broken.txt", Pos: 2, Line: 1, Column: 3},
+		}, {
+			name:       "errors in the include directive should be reported in the outer input",
+			input:      `a;include "ok.txt"e;f;`,
+			wantOutput: []string{"a", "f"},
+			wantErr:    `expected included file instead of "ok.txt\"e;" (substituted) [1:12] …;include "▶ok.txt"e;f;`,
+			wantPos:    comb.ErrorPosition{Pos: 11, Line: 1, Column: 12},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := comb.RunOnString(tc.input, includeGrammar(files))
+			assert.Equal(t, tc.wantOutput, gotOutput)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.wantErr)
+			pos, ok := comb.PositionOf(err)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantPos, pos)
+		})
+	}
+}
+
+// includeGrammar parses statements like `a;` and `include "file";` directives.
+// The included files contain statements only.
+func includeGrammar(files map[string]string) comb.Parser[[]string] {
+	included := cmb.Suffixed(cmb.Many0(comb.SafeSpot(statement())), cmb.EOF())
+	file := comb.NewParser[[]string]("included file", func(state comb.State) (comb.State, []string, *comb.ParserError) {
+		input := state.CurrentString()
+		end := strings.Index(input, `";`)
+		if end < 0 {
+			return state, nil, state.NewSyntaxError("included file")
+		}
+		name := input[:end]
+		iState, out, err := included.ParseAny(comb.ParentUnknown, comb.PushInput(state.MoveBy(end+2), files[name], name))
+		if err != nil { // the error is reported in the included input but recovery continues after the directive
+			return state.MoveBy(end + 2), nil, comb.ClaimError(err)
+		}
+		outer, _ := comb.PopInput(iState)
+		return outer, out.([]string), nil
+	}, nil)
+	include := cmb.Prefixed(comb.SafeSpot(cmb.String(`include "`)), file)
+	return cmb.Suffixed(cmb.Map(cmb.Many0(cmb.FirstSuccessful(
+		include,
+		cmb.Map(comb.SafeSpot(statement()), func(stmt string) ([]string, error) { return []string{stmt}, nil }),
+	)), func(stmts [][]string) ([]string, error) {
+		var all []string
+		for _, s := range stmts {
+			all = append(all, s...)
+		}
+		return all, nil
+	}), cmb.EOF())
+}
+
+// statement parses statements like `a;`.
+// Its recoverer searches the next complete statement.
+func statement() comb.Parser[string] {
+	end := func(input string) int {
+		i := 0
+		for i < len(input) && 'a' <= input[i] && input[i] <= 'z' {
+			i++
+		}
+		if i == 0 || i >= len(input) || input[i] != ';' {
+			return -1
+		}
+		return i
+	}
+	return comb.NewParser[string]("statement", func(state comb.State) (comb.State, string, *comb.ParserError) {
+		input := state.CurrentString()
+		n := end(input)
+		if n < 0 {
+			return state, "", state.NewSyntaxError("statement")
+		}
+		return state.MoveBy(n + 1), input[:n], nil
+	}, func(state comb.State, _ interface{}) (int, interface{}) {
+		input := state.CurrentString()
+		for i := 0; i < len(input); i++ {
+			if (i == 0 || input[i-1] == ';') && end(input[i:]) >= 0 {
+				return i, nil
+			}
+		}
+		return comb.RecoverWasteTooMuch, nil
+	})
+}
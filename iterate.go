@@ -0,0 +1,45 @@
+package comb
+
+import (
+	"iter"
+)
+
+// ============================================================================
+// Iterating Over Repeated Top-Level Results
+//
+
+// Iterate returns an iterator over the results of applying the parser
+// repeatedly to the input of the state.
+// It is meant for inputs that consist of many independent records
+// (log lines, NDJSON, ...):
+//
+//	for out, err := range pp.Iterate(state) {
+//		...
+//	}
+//
+// Every record is parsed like by RunOnState (including error recovery),
+// so errors and warnings of one record don't show up for the next one.
+// The results are produced lazily and breaking out of the loop stops parsing.
+// The iterator can be used multiple times; it always starts with the state.
+// Iteration ends at the end of the input or as soon as the parser doesn't
+// consume any input anymore.
+func (pp *PreparedParser[Output]) Iterate(state State) iter.Seq2[Output, error] {
+	return func(yield func(Output, error) bool) {
+		current := state // every iteration starts at the start of the input
+		for !current.AtEnd() {
+			nState, out, err := pp.parseAll(current)
+			if !yield(out, err) || !nState.Moved(current) {
+				return
+			}
+			current = nState.nextRecord()
+		}
+	}
+}
+
+// nextRecord returns the state cleaned up for parsing the next top-level record.
+func (st State) nextRecord() State {
+	st.errors = nil
+	st.warnings = nil
+	st.deferred = nil
+	return st
+}
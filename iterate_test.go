@@ -0,0 +1,86 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterate(t *testing.T) {
+	t.Parallel()
+
+	runePlusRune := func(out1 rune, out2 rune) (string, error) {
+		return string([]rune{out1, out2}), nil
+	}
+
+	testCases := []struct {
+		name        string
+		input       string
+		stopAfter   int
+		wantOutputs []string
+		wantErrors  []int
+	}{
+		{
+			name:        "empty input",
+			input:       "",
+			wantOutputs: nil,
+			wantErrors:  nil,
+		}, {
+			name:        "many records",
+			input:       "a;a;a;",
+			wantOutputs: []string{"a;", "a;", "a;"},
+			wantErrors:  []int{0, 0, 0},
+		}, {
+			name:        "error in the middle",
+			input:       "a;b;a;",
+			wantOutputs: []string{"a;", "\ufffd;", "a;"}, // the parse error results in utf8.RuneError
+			wantErrors:  []int{0, 1, 0},
+		}, {
+			name:        "early break",
+			input:       "a;a;a;",
+			stopAfter:   2,
+			wantOutputs: []string{"a;", "a;"},
+			wantErrors:  []int{0, 0},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			pp := NewPreparedParser(Map2(Char('a'), SafeSpot(Char(';')), runePlusRune))
+			var gotOutputs []string
+			var gotErrors []int
+			for out, err := range pp.Iterate(NewFromString(tc.input, DefaultMaxErrors)) {
+				gotOutputs = append(gotOutputs, out)
+				gotErrors = append(gotErrors, len(UnwrapErrors(err)))
+				if len(gotOutputs) == tc.stopAfter {
+					break
+				}
+			}
+			assert.Equal(t, tc.wantOutputs, gotOutputs)
+			assert.Equal(t, tc.wantErrors, gotErrors)
+		})
+	}
+}
+
+func TestIterateAgain(t *testing.T) {
+	t.Parallel()
+
+	pp := NewPreparedParser(Map2(Char('a'), SafeSpot(Char(';')), func(out1 rune, out2 rune) (string, error) {
+		return string([]rune{out1, out2}), nil
+	}))
+	seq := pp.Iterate(NewFromString("a;a;a;", DefaultMaxErrors))
+	count := 0
+	for range seq {
+		count++
+		if count == 2 {
+			break
+		}
+	}
+	count = 0
+	for range seq {
+		count++
+	}
+	assert.Equal(t, 3, count, "a second iteration should start at the start of the input")
+}
@@ -0,0 +1,96 @@
+package comb
+
+// ============================================================================
+// Lexeme Parsers And Their Skip Parser
+//
+
+// skipData is the data of the WithLexeme parser during error recovery.
+type skipData struct {
+	skip AnyParser // skip parser of the outer subtree
+}
+
+// WithLexeme makes skip the active skip parser for all Lexeme parsers in the
+// subtree of p. After p the skip parser of the outer subtree is active again.
+// This way the skipping of white space and comments can be changed for parts
+// of the grammar (e.g., inside string interpolation or an embedded regex
+// literal spaces matter).
+// A nil skip parser turns skipping off.
+// The skip parser should never fail. Errors of it are ignored.
+func WithLexeme[Output any](p Parser[Output], skip AnyParser) Parser[Output] {
+	var wl Parser[Output]
+
+	children := []AnyParser{p}
+	if skip != nil {
+		children = append(children, skip)
+	}
+
+	wl = NewBranchParser[Output](
+		"WithLexeme",
+		func() []AnyParser {
+			return children
+		}, func(
+			childID int32,
+			childStartState, childState State,
+			childOut interface{},
+			childErr *ParserError,
+			data interface{},
+		) (State, Output, *ParserError, interface{}) {
+			Debugf("WithLexeme.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			outer := skipData{skip: childState.skip}
+			if childID >= 0 { // bottom-up
+				if sd, ok := data.(skipData); ok {
+					outer = sd
+				}
+			} else { // top-down
+				childState.skip = skip
+				childState, childOut, childErr = p.ParseAny(wl.ID(), childState)
+			}
+			out, _ := childOut.(Output)
+			childState.skip = outer.skip
+			if childErr != nil {
+				return childState, out, childErr, outer
+			}
+			return childState, out, nil, nil
+		},
+	)
+	return wl
+}
+
+// Lexeme runs the active skip parser (see WithLexeme) and parser p afterward.
+// Without an active skip parser it behaves like p.
+func Lexeme[Output any](p Parser[Output]) Parser[Output] {
+	var lp Parser[Output]
+
+	lp = NewBranchParser[Output](
+		p.Expected(),
+		func() []AnyParser {
+			return []AnyParser{p}
+		}, func(
+			childID int32,
+			childStartState, childState State,
+			childOut interface{},
+			childErr *ParserError,
+			data interface{},
+		) (State, Output, *ParserError, interface{}) {
+			Debugf("Lexeme.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				childState, childOut, childErr = p.ParseAny(lp.ID(), childState.skipActive())
+			}
+			out, _ := childOut.(Output)
+			return childState, out, childErr, nil
+		},
+	)
+	return lp
+}
+
+// skipActive runs the active skip parser (if any).
+func (st State) skipActive() State {
+	if st.skip == nil {
+		return st
+	}
+	nState, _, err := st.skip.ParseAny(ParentUnknown, st)
+	if err != nil {
+		return st
+	}
+	return nState
+}
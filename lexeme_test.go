@@ -0,0 +1,71 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLexeme(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[[]string] {
+		str := cmb.Map(
+			cmb.Delimited(
+				comb.Lexeme(cmb.Char('"')),
+				comb.WithLexeme(cmb.Many0(comb.Lexeme(cmb.Satisfy("no quote", func(r rune) bool {
+					return r != '"'
+				}))), nil),
+				cmb.Char('"'),
+			),
+			func(runes []rune) (string, error) {
+				return string(runes), nil
+			},
+		)
+		return comb.WithLexeme(
+			cmb.Suffixed(cmb.Many1(cmb.FirstSuccessful(comb.Lexeme(cmb.Alpha1()), str)), comb.Lexeme(cmb.EOF())),
+			cmb.Whitespace0(),
+		)
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput []string
+	}{
+		{
+			name:       "words should skip spaces",
+			input:      " ab  cd ",
+			wantOutput: []string{"ab", "cd"},
+		}, {
+			name:       "strings should keep spaces",
+			input:      `ab " x y " cd`,
+			wantOutput: []string{"ab", " x y ", "cd"},
+		}, {
+			name:       "skipping should be restored after a string",
+			input:      `"x"   cd`,
+			wantOutput: []string{"x", "cd"},
+		}, {
+			name:       "invalid input should fail",
+			input:      "ab 1",
+			wantErr:    true,
+			wantOutput: []string{"ab"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := comb.RunOnString(tc.input, newParser())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantOutput, gotOutput)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package comb
+
+// ============================================================================
+// Resource Limits For Untrusted Input
+//
+
+// Limits are hard resource caps for parsing untrusted input.
+// A limit of 0 means no limit.
+// Exceeding a limit is a fatal error, so no error recovery is tried.
+type Limits struct {
+	MaxElements  int // maximum number of elements of one repetition (e.g. cmb.Many0)
+	MaxStringLen int // maximum length in bytes of one string matched by a predicate (e.g. cmb.SatisfyMN)
+}
+
+// Limits returns the resource limits of the parse run (see WithLimits).
+func (st State) Limits() Limits {
+	return st.constant.limits
+}
+
+// WithLimits returns the state with the resource limits set.
+// The combinators of the cmb package that collect elements or strings honor them.
+// It has to be called before parsing starts.
+func (st State) WithLimits(limits Limits) State {
+	constant := *st.constant
+	constant.limits = limits
+	st.constant = &constant
+	return st
+}
+
+// NewLimitError returns a fatal error for exceeding the limit of `what`.
+func (st State) NewLimitError(what string, limit int) *ParserError {
+	return MarkFatal(st.NewSemanticError("%s exceeds the limit of %d", what, limit))
+}
+
+// MaxOutputLen guards parser p so it can't consume more than n bytes.
+// Since the output is built from the consumed input, this limits the size
+// of the output, too.
+// p only sees the next n+1 bytes of the input, so it can't spend time or
+// memory on more of it.
+// Exceeding the limit is a fatal error (see Limits).
+func MaxOutputLen[Output any](n int, p Parser[Output]) Parser[Output] {
+	var mp Parser[Output]
+
+	if n < 0 {
+		panic("MaxOutputLen is unable to handle negative `n`")
+	}
+
+	mp = NewBranchParser[Output](
+		p.Expected(),
+		func() []AnyParser {
+			return []AnyParser{p}
+		}, func(
+			childID int32,
+			childStartState, childState State,
+			childOut interface{},
+			childErr *ParserError,
+			data interface{},
+		) (State, Output, *ParserError, interface{}) {
+			Debugf("MaxOutputLen.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				childStartState = childState
+				childState, childOut, childErr = parseCut(n+1, p, mp.ID(), childStartState)
+				if childErr != nil && childErr.pos > childStartState.pos+n && childErr.pos < childStartState.constant.n {
+					// the child needs input beyond the cut
+					return childStartState, ZeroOf[Output](), childStartState.NewLimitError("output of "+p.Expected(), n), nil
+				}
+			}
+			out, _ := childOut.(Output)
+			if childErr == nil && childStartState.ByteCount(childState) > n {
+				return childStartState, ZeroOf[Output](), childStartState.NewLimitError("output of "+p.Expected(), n), nil
+			}
+			return childState, out, childErr, nil
+		},
+	)
+	return mp
+}
+
+// parseCut parses `p` with the input cut `n` bytes after the current position
+// of the state. The returned state and error aren't cut anymore.
+func parseCut[Output any](n int, p Parser[Output], parentID int32, state State) (State, interface{}, *ParserError) {
+	cState := state.truncated(n)
+	nState, out, err := p.ParseAny(parentID, cState)
+	if nState.constant == cState.constant {
+		nState.constant = state.constant
+	}
+	if err != nil && err.at.constant == cState.constant {
+		err.at.constant = state.constant
+	}
+	return nState, out, err
+}
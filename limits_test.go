@@ -0,0 +1,110 @@
+package comb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	t.Parallel()
+
+	words := func() comb.Parser[[]string] {
+		return cmb.Suffixed(cmb.Separated0(cmb.Alpha1(), cmb.Char(','), false), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name       string
+		parser     comb.Parser[[]string]
+		limits     comb.Limits
+		input      string
+		wantErr    string
+		wantOutput []string
+	}{
+		{
+			name:       "input within the limits should succeed",
+			parser:     words(),
+			limits:     comb.Limits{MaxElements: 2, MaxStringLen: 3},
+			input:      "abc,de",
+			wantOutput: []string{"abc", "de"},
+		}, {
+			name:    "too many elements should fail",
+			parser:  words(),
+			limits:  comb.Limits{MaxElements: 2},
+			input:   "a,b,c,d",
+			wantErr: "number of elements exceeds the limit of 2",
+		}, {
+			name:    "too long string should fail",
+			parser:  words(),
+			limits:  comb.Limits{MaxStringLen: 3},
+			input:   "ab,cdef",
+			wantErr: "exceeds the limit of 3",
+		}, {
+			name:    "limit inside Optional should fail",
+			parser:  cmb.Optional(words()),
+			limits:  comb.Limits{MaxElements: 2},
+			input:   "a,b,c,d",
+			wantErr: "number of elements exceeds the limit of 2",
+		}, {
+			name: "limit inside FirstSuccessful should fail",
+			parser: cmb.FirstSuccessful(words(), cmb.Map(cmb.Alpha0(), func(s string) ([]string, error) {
+				return []string{s}, nil
+			})),
+			limits:  comb.Limits{MaxElements: 2},
+			input:   "a,b,c,d",
+			wantErr: "number of elements exceeds the limit of 2",
+		}, {
+			name:       "output within MaxOutputLen should succeed",
+			parser:     comb.MaxOutputLen(5, words()),
+			input:      "ab,cd",
+			wantOutput: []string{"ab", "cd"},
+		}, {
+			name:    "output beyond MaxOutputLen should fail",
+			parser:  comb.MaxOutputLen(5, words()),
+			input:   "ab,cde",
+			wantErr: "exceeds the limit of 5",
+		}, {
+			name: "error at the cut of MaxOutputLen should fail with the limit",
+			parser: comb.MaxOutputLen(5, cmb.Map(cmb.Suffixed(cmb.Alpha1(), cmb.Char(';')), func(s string) ([]string, error) {
+				return []string{s}, nil
+			})),
+			input:   "abcdefgh;",
+			wantErr: "exceeds the limit of 5",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			state := comb.NewFromString(tc.input, 10).WithLimits(tc.limits)
+			gotOutput, err := comb.RunOnState(state, comb.NewPreparedParser(tc.parser))
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantOutput, gotOutput)
+		})
+	}
+}
+
+func TestMaxOutputLenCutsInput(t *testing.T) {
+	t.Parallel()
+
+	seen := 0
+	rest := comb.NewParser[string]("rest", func(state comb.State) (comb.State, string, *comb.ParserError) {
+		seen = state.BytesRemaining()
+		nState := state.MoveBy(seen)
+		return nState, state.StringTo(nState), nil
+	}, nil)
+
+	state := comb.NewFromString(strings.Repeat("x", 1000), 10)
+	_, err := comb.RunOnState(state, comb.NewPreparedParser(comb.MaxOutputLen(5, rest)))
+	assert.ErrorContains(t, err, "exceeds the limit of 5")
+	assert.Equal(t, 6, seen, "the parser should only see the input up to the limit")
+}
@@ -0,0 +1,57 @@
+package comb
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// ============================================================================
+// Running a Parser on Every Line of a Reader
+//
+
+// RunLines runs the line parser on every line read from r and hands each
+// result to `handle` together with the line number (starting at 1).
+// This is the typical shape of log processing and JSON lines (NDJSON).
+// Every line is parsed independently (including error recovery), so an
+// error in one line doesn't influence the others.
+// Error messages report the line number within r.
+// Line endings ("\n" or "\r\n") aren't part of the input of the line parser.
+//
+// Only one line is kept in memory at a time, so huge inputs are no problem.
+// The options are applied to the state of every line.
+// Reading stops as soon as `handle` returns false.
+// The returned error is a read error of r (never a parser error).
+func RunLines[Output any](
+	r io.Reader,
+	lineParser Parser[Output],
+	handle func(lineNo int, out Output, err error) bool,
+	opts ...RunOption,
+) error {
+	if handle == nil {
+		panic("RunLines is unable to handle a nil `handle` function")
+	}
+	pp := NewPreparedParser(lineParser)
+	br := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, rerr := br.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			state := NewFromString(line, DefaultMaxErrors)
+			for _, opt := range opts {
+				state = opt(state)
+			}
+			state.line = lineNo
+			out, err := RunOnState(state, pp)
+			if !handle(lineNo, out, err) {
+				return nil
+			}
+		}
+		if rerr == io.EOF {
+			return nil
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+}
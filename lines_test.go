@@ -0,0 +1,82 @@
+package comb_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunLines(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[int64] {
+		return cmb.Suffixed(cmb.Int64(true, 10), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name        string
+		input       string
+		stopAfter   int
+		wantOutputs []int64
+		wantErrLine []int
+	}{
+		{
+			name:        "empty input",
+			input:       "",
+			wantOutputs: nil,
+		}, {
+			name:        "last line without line ending",
+			input:       "1\r\n-2\n3",
+			wantOutputs: []int64{1, -2, 3},
+		}, {
+			name:        "errors should be reported for their line only",
+			input:       "1\nx\n3\n4y\n",
+			wantOutputs: []int64{1, 0, 3, 4},
+			wantErrLine: []int{2, 4},
+		}, {
+			name:        "returning false should stop reading",
+			input:       "1\n2\n3\n",
+			stopAfter:   2,
+			wantOutputs: []int64{1, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotOutputs []int64
+			var gotErrLine []int
+			err := comb.RunLines(strings.NewReader(tc.input), newParser(), func(lineNo int, out int64, err error) bool {
+				assert.Equal(t, len(gotOutputs)+1, lineNo)
+				gotOutputs = append(gotOutputs, out)
+				if err != nil {
+					gotErrLine = append(gotErrLine, lineNo)
+					pos, ok := comb.PositionOf(err)
+					assert.True(t, ok)
+					assert.Equal(t, lineNo, pos.Line, "line number of the error")
+				}
+				return len(gotOutputs) != tc.stopAfter
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantOutputs, gotOutputs)
+			assert.Equal(t, tc.wantErrLine, gotErrLine)
+		})
+	}
+}
+
+func TestRunLinesReadError(t *testing.T) {
+	t.Parallel()
+
+	readErr := errors.New("read error")
+	err := comb.RunLines(iotest.ErrReader(readErr), cmb.Int64(true, 10), func(int, int64, error) bool {
+		return true
+	})
+	assert.ErrorIs(t, err, readErr)
+}
@@ -0,0 +1,78 @@
+package comb
+
+import (
+	"sync/atomic"
+)
+
+// ============================================================================
+// Metrics
+//
+
+// Metrics receives operational events of a PreparedParser.
+// It can be bridged to expvar, Prometheus or similar systems.
+// Implementations have to be concurrency safe because
+// a PreparedParser can be used concurrently.
+type Metrics interface {
+	ParseStarted()               // a run of the parser has started
+	ErrorFound()                 // the run found an error
+	Recovered(waste int)         // the run recovered from an error wasting `waste` bytes
+	RecoverCacheLookup(hit bool) // a recoverer result was looked up in the cache
+}
+
+// SetMetrics sets the metrics that receive the events of the parser.
+// It has to be called before the parser is used (it isn't concurrency safe).
+func (pp *PreparedParser[Output]) SetMetrics(metrics Metrics) {
+	pp.metrics = metrics
+}
+
+// Counters is a simple concurrency safe implementation of Metrics.
+type Counters struct {
+	parses      atomic.Int64
+	errors      atomic.Int64
+	recoveries  atomic.Int64
+	wasted      atomic.Int64
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+}
+
+func (c *Counters) ParseStarted() {
+	c.parses.Add(1)
+}
+func (c *Counters) ErrorFound() {
+	c.errors.Add(1)
+}
+func (c *Counters) Recovered(waste int) {
+	c.recoveries.Add(1)
+	c.wasted.Add(int64(waste))
+}
+func (c *Counters) RecoverCacheLookup(hit bool) {
+	if hit {
+		c.cacheHits.Add(1)
+	} else {
+		c.cacheMisses.Add(1)
+	}
+}
+
+// CacheHitRate returns the hit rate of the recoverer cache (between 0 and 1).
+func (c *Counters) CacheHitRate() float64 {
+	hits := c.cacheHits.Load()
+	total := hits + c.cacheMisses.Load()
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+// Snapshot returns the current values of all counters.
+// It can be published directly with `expvar.Func`.
+func (c *Counters) Snapshot() map[string]interface{} {
+	return map[string]interface{}{
+		"parses":       c.parses.Load(),
+		"errors":       c.errors.Load(),
+		"recoveries":   c.recoveries.Load(),
+		"bytesWasted":  c.wasted.Load(),
+		"cacheHits":    c.cacheHits.Load(),
+		"cacheMisses":  c.cacheMisses.Load(),
+		"cacheHitRate": c.CacheHitRate(),
+	}
+}
@@ -0,0 +1,34 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounters(t *testing.T) {
+	t.Parallel()
+
+	counters := &comb.Counters{}
+	p := comb.NewPreparedParser(cmb.Suffixed(
+		cmb.Many1(cmb.Suffixed(cmb.Alpha1(), comb.SafeSpot(cmb.Char(';')))),
+		cmb.EOF(),
+	))
+	p.SetMetrics(counters)
+
+	_, err := comb.RunOnState(comb.NewFromString("ab;cd;", 10), p)
+	assert.NoError(t, err)
+	_, err = comb.RunOnState(comb.NewFromString("ab;12;cd;", 10), p)
+	assert.Error(t, err)
+
+	got := counters.Snapshot()
+	assert.Equal(t, int64(2), got["parses"])
+	assert.Equal(t, int64(1), got["errors"])
+	assert.Equal(t, int64(1), got["recoveries"])
+	assert.Equal(t, int64(2), got["bytesWasted"])
+	assert.GreaterOrEqual(t, got["cacheMisses"], int64(1))
+	rate := counters.CacheHitRate()
+	assert.True(t, rate >= 0 && rate <= 1, "hit rate %f out of range", rate)
+}
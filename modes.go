@@ -0,0 +1,82 @@
+package comb
+
+// ============================================================================
+// Parser Modes (Like Start Conditions Of Lexers)
+//
+
+// DefaultMode is the mode that is active if no mode has been entered.
+const DefaultMode = ""
+
+// Mode returns the current mode (see EnterMode) or DefaultMode.
+func (st State) Mode() string {
+	if len(st.modes) == 0 {
+		return DefaultMode
+	}
+	return st.modes[len(st.modes)-1]
+}
+
+// withMode returns the state with the mode entered.
+func (st State) withMode(mode string) State {
+	st.modes = append(st.modes[:len(st.modes):len(st.modes)], mode) // never share the backing array
+	return st
+}
+
+// InMode runs parser p only if the mode is active (see EnterMode).
+// Otherwise, it fails without consuming any input.
+// So constructs like "inside a string literal use these rules" can be
+// expressed declaratively (like start conditions of lexers).
+func InMode[Output any](mode string, p Parser[Output]) Parser[Output] {
+	var ip Parser[Output]
+
+	ip = NewBranchParser[Output](
+		p.Expected(),
+		func() []AnyParser {
+			return []AnyParser{p}
+		}, func(
+			childID int32,
+			childStartState, childState State,
+			childOut interface{},
+			childErr *ParserError,
+			data interface{},
+		) (State, Output, *ParserError, interface{}) {
+			Debugf("InMode.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				if childState.Mode() != mode {
+					return childState, ZeroOf[Output](), childState.NewSyntaxError("%s (in mode %q)", p.Expected(), mode), nil
+				}
+				childState, childOut, childErr = p.ParseAny(ip.ID(), childState)
+			}
+			out, _ := childOut.(Output)
+			return childState, out, childErr, nil
+		},
+	)
+	return ip
+}
+
+// EnterMode enters the mode. It doesn't consume any input and always succeeds.
+// The output is the mode.
+// The modes are kept on a stack. So ExitMode returns to the mode active
+// before EnterMode.
+func EnterMode(mode string) Parser[string] {
+	return NewParser[string]("EnterMode", func(state State) (State, string, *ParserError) {
+		return state.withMode(mode), mode, nil
+	}, neverRecover)
+}
+
+// ExitMode returns to the mode that has been active before the last EnterMode.
+// It doesn't consume any input and fails only if no mode has been entered.
+// The output is the mode that has been exited.
+func ExitMode() Parser[string] {
+	return NewParser[string]("ExitMode", func(state State) (State, string, *ParserError) {
+		if len(state.modes) == 0 {
+			return state, "", state.NewSemanticError("no mode to exit")
+		}
+		mode := state.Mode()
+		state.modes = state.modes[:len(state.modes)-1]
+		return state, mode, nil
+	}, neverRecover)
+}
+
+func neverRecover(_ State, _ interface{}) (int, interface{}) {
+	return RecoverNever, nil
+}
@@ -0,0 +1,68 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModes(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[[]string] {
+		const stringMode = "string"
+		return cmb.Suffixed(cmb.Many1(cmb.FirstSuccessful(
+			comb.InMode(comb.DefaultMode, cmb.Alpha1()),
+			comb.InMode(comb.DefaultMode, cmb.Whitespace1()),
+			cmb.Suffixed(comb.InMode(comb.DefaultMode, cmb.String(`"`)), comb.EnterMode(stringMode)),
+			comb.InMode(stringMode, cmb.SatisfyMN("string content", 1, 1000, func(r rune) bool {
+				return r != '"'
+			})),
+			cmb.Suffixed(comb.InMode(stringMode, cmb.String(`"`)), comb.ExitMode()),
+		)), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    bool
+		wantOutput []string
+	}{
+		{
+			name:       "default mode only",
+			input:      "ab cd",
+			wantOutput: []string{"ab", " ", "cd"},
+		}, {
+			name:       "string mode",
+			input:      `ab "1 2" cd`,
+			wantOutput: []string{"ab", " ", `"`, "1 2", `"`, " ", "cd"},
+		}, {
+			name:       "wrong mode should fail",
+			input:      `ab 12`,
+			wantErr:    true,
+			wantOutput: []string{"ab", " "},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := comb.RunOnString(tc.input, newParser())
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			assert.Equal(t, tc.wantOutput, gotOutput)
+		})
+	}
+}
+
+func TestExitModeWithoutMode(t *testing.T) {
+	t.Parallel()
+
+	_, err := comb.RunOnString("", comb.ExitMode())
+	assert.ErrorContains(t, err, "no mode to exit")
+}
@@ -0,0 +1,82 @@
+package comb
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// Unicode Normalization For Matching
+//
+
+// Normalizer normalizes text for matching.
+// E.g., `norm.NFC.String` or `norm.NFKC.String` of the
+// package `golang.org/x/text/unicode/norm`.
+type Normalizer func(string) string
+
+// Normalizer returns the normalizer for matching or nil if it's turned off.
+func (st State) Normalizer() Normalizer {
+	return st.constant.normalize
+}
+
+// WithNormalizer returns the state with normalization for matching.
+// Literal parsers (like cmb.Char, cmb.String and cmb.OneOf) compare the
+// normalized input with their normalized literal then.
+// So identifiers and operators typed on different platforms match.
+// All positions still refer to the original input.
+// A nil normalizer turns normalization off (the default).
+// It has to be called before parsing starts.
+func (st State) WithNormalizer(normalize Normalizer) State {
+	constant := *st.constant
+	constant.normalize = normalize
+	st.constant = &constant
+	return st
+}
+
+// MatchNormalized compares the input at the current position with the token
+// using the normalizer of the state (or plain comparison without normalizer).
+// It returns the number of bytes of the original input that match the token
+// or -1 if the input doesn't match.
+// incomplete is true if the input ended before a decision was possible.
+// In Latin-1 mode (see WithLatin1) the token is encoded to Latin-1 and
+// compared without normalizer.
+func (st State) MatchNormalized(token string) (n int, incomplete bool) {
+	input := st.CurrentString()
+	if st.constant.latin1 {
+		var ok bool
+		if token, ok = st.EncodeString(token); !ok {
+			return -1, false
+		}
+	}
+	if st.constant.normalize == nil || st.constant.latin1 {
+		if strings.HasPrefix(input, token) {
+			return len(token), false
+		}
+		return -1, strings.HasPrefix(token, input)
+	}
+	return matchNormalized(st.constant.normalize, input, token)
+}
+
+func matchNormalized(normalize Normalizer, input, token string) (int, bool) {
+	want := normalize(token)
+	if want == "" {
+		return 0, false
+	}
+	maxLen := 4*len(want) + utf8.UTFMax // composing sequences are longer than their normalized form
+	for i := 0; i < len(input) && i <= maxLen; {
+		_, size := utf8.DecodeRuneInString(input[i:])
+		i += size
+		if normalize(input[:i]) != want {
+			continue
+		}
+		if i == len(input) {
+			return i, false
+		}
+		_, size = utf8.DecodeRuneInString(input[i:])
+		if strings.HasPrefix(normalize(input[:i+size]), want) { // the next rune doesn't combine with the token
+			return i, false
+		}
+		return -1, false
+	}
+	return -1, len(input) <= maxLen && strings.HasPrefix(want, normalize(input))
+}
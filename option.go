@@ -0,0 +1,54 @@
+package comb
+
+// ============================================================================
+// Optional Values And Alternatives
+//
+
+// Option is an optional value.
+// It distinguishes a value that matched empty input (Ok is true)
+// from no value at all (see cmb.Maybe).
+type Option[T any] struct {
+	Value T
+	Ok    bool // true if the value is present
+}
+
+// Some returns an option with the value present.
+func Some[T any](value T) Option[T] {
+	return Option[T]{Value: value, Ok: true}
+}
+
+// None returns an option without a value.
+func None[T any]() Option[T] {
+	return Option[T]{}
+}
+
+// Get returns the value and true if it is present.
+func (o Option[T]) Get() (T, bool) {
+	return o.Value, o.Ok
+}
+
+// OrElse returns the value if it is present and the default value otherwise.
+func (o Option[T]) OrElse(def T) T {
+	if o.Ok {
+		return o.Value
+	}
+	return def
+}
+
+// Either is the value of exactly one of two alternatives.
+// IsRight tells which one it is (see cmb.Or2).
+type Either[A, B any] struct {
+	Left    A
+	Right   B
+	IsRight bool // true if Right is the value
+}
+
+// Left returns an Either with the value of the first (left) alternative.
+func Left[A, B any](value A) Either[A, B] {
+	return Either[A, B]{Left: value}
+}
+
+// Right returns an Either with the value of the second (right) alternative.
+func Right[A, B any](value B) Either[A, B] {
+	return Either[A, B]{Right: value, IsRight: true}
+}
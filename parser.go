@@ -14,17 +14,32 @@ const (
 // It enables registering of all parsers and error recovery.
 type ParserIDs struct {
 	id, parent int32
+	stable     uint64
 }
 
 func (pids *ParserIDs) ID() int32 {
 	return pids.id
 }
+
+// StableID returns an ID that doesn't depend on the construction order of the parsers.
+// It stays the same across program runs and minor grammar edits,
+// so caches, traces and serialized recovery data remain valid.
+// It is set when the parser is prepared (see NewPreparedParser).
+func (pids *ParserIDs) StableID() uint64 {
+	return pids.stable
+}
 func (pids *ParserIDs) setID(id int32) {
 	pids.id = id
 }
+func (pids *ParserIDs) setStableID(id uint64) {
+	pids.stable = id
+}
 func (pids *ParserIDs) setParent(id int32) {
 	pids.parent = id
 }
+func (pids *ParserIDs) parentID() int32 {
+	return pids.parent
+}
 
 // ============================================================================
 // Leaf Parser
@@ -47,6 +62,7 @@ func NewParser[Output any](
 	parse func(State) (State, Output, *ParserError),
 	recover Recoverer,
 ) Parser[Output] {
+	internSyntaxMessage(expected) // precomputed for the hot error path
 	p := &prsr[Output]{
 		ParserIDs: ParserIDs{id: -1, parent: ParentUndefined},
 		expected:  expected,
@@ -69,6 +85,7 @@ func NewParserWithData[Output any](
 	parse func(State, interface{}) (State, Output, *ParserError, interface{}),
 	recover Recoverer,
 ) Parser[Output] {
+	internSyntaxMessage(expected) // precomputed for the hot error path
 	p := &prsr[Output]{
 		ParserIDs:     ParserIDs{id: -1, parent: ParentUndefined},
 		expected:      expected,
@@ -88,6 +105,7 @@ func (p *prsr[Output]) Parse(state State) (State, Output, *ParserError) {
 	}
 	if err != nil && err.parserID < 0 {
 		err.parserID = p.ID()
+		err.parserName = p.expected
 	}
 	return nState, out, err
 }
@@ -95,15 +113,39 @@ func (p *prsr[Output]) ParseAny(parent int32, state State) (State, interface{},
 	if parent >= 0 {
 		p.setParent(parent)
 	}
-	return p.Parse(state)
+	state.syncActions() // undo actions of discarded results before anybody can see them
+	if err := state.canceled(); err != nil {
+		return state, nil, err
+	}
+	if err := state.constant.debugger.before(p, state); err != nil {
+		return state, nil, err
+	}
+	mark := state.constant.cst.enter()
+	step := state.constant.tracer.enter(p, p.expected, false, 0, state.pos)
+	nState, out, err := p.Parse(state)
+	state.constant.tracer.exit(step, nState.pos, err)
+	state.constant.cst.exit(mark, p.expected, state.pos, nState.pos, err == nil)
+	state.constant.debugger.after(p, nState, out, err)
+	return nState, out, err
 }
 func (p *prsr[Output]) parseAnyAfterError(err *ParserError, state State) (int32, State, interface{}, *ParserError) {
+	state.syncActions()
+	if cErr := state.canceled(); cErr != nil {
+		return p.ParserIDs.parent, state, nil, cErr
+	}
+	if dbgErr := state.constant.debugger.before(p, state); dbgErr != nil {
+		return p.ParserIDs.parent, state, nil, dbgErr
+	}
+	step := state.constant.tracer.enter(p, p.expected, true, p.ID(), state.pos)
 	nState, out, newErr, data := p.parseWithData(state, err.ParserData(p.ID()))
+	state.constant.tracer.exit(step, nState.pos, newErr)
+	state.constant.debugger.after(p, nState, out, newErr)
 	if newErr != nil {
 		newErr.StoreParserData(p.ID(), data)
 	}
 	if newErr != nil && newErr.parserID < 0 {
 		newErr.parserID = p.ID()
+		newErr.parserName = p.expected
 	}
 	return p.ParserIDs.parent, nState, out, newErr
 }
@@ -111,7 +153,18 @@ func (p *prsr[Output]) IsSafeSpot() bool {
 	return p.safeSpot
 }
 func (p *prsr[Output]) setSafeSpot() {
+	if p.safeSpot {
+		return
+	}
 	p.safeSpot = true
+	parse := p.parseWithData
+	p.parseWithData = func(state State, data interface{}) (State, Output, *ParserError, interface{}) {
+		nState, out, err, data2 := parse(state, data)
+		if err == nil {
+			return nState.MoveSafeSpot(), out, nil, data2
+		}
+		return nState, out, err, data2
+	}
 }
 func (p *prsr[Output]) Recover(state State, data interface{}) (int, interface{}) {
 	return p.recoverer(state, data)
@@ -164,22 +217,50 @@ func (bp *brnchprsr[Output]) ParseAny(parentID int32, state State) (State, inter
 	if parentID >= 0 {
 		bp.setParent(parentID)
 	}
+	state.syncActions()
+	if err := state.canceled(); err != nil {
+		return state, nil, err
+	}
+	if err := state.constant.debugger.before(bp, state); err != nil {
+		return state, nil, err
+	}
+	mark := state.constant.cst.enter()
+	step := state.constant.tracer.enter(bp, bp.expected, false, 0, state.pos)
 	nState, out, err, data := bp.prsAfterChild(-1, state, state, nil, nil, nil)
+	state.constant.tracer.exit(step, nState.pos, err)
+	state.constant.cst.exit(mark, bp.expected, state.pos, nState.pos, err == nil)
+	state.constant.debugger.after(bp, nState, out, err)
 	if err != nil && data != nil {
 		err.StoreParserData(bp.ID(), data)
 	}
+	if err != nil && err.parserID < 0 {
+		err.parserID = bp.ID()
+		err.parserName = bp.expected
+	}
 	return nState, out, err
 }
 func (bp *brnchprsr[Output]) parseAfterError(
 	err *ParserError, childID int32, childStartState, childState State, childOut interface{}, childErr *ParserError,
 ) (int32, State, interface{}, *ParserError) {
 	bp.ensureIDs()
+	if budget := childState.budget; budget == nil || budget.owner != bp.ID() { // the owner of a time budget handles it itself
+		if cErr := childState.canceled(); cErr != nil {
+			return bp.ParserIDs.parent, childState, nil, cErr
+		}
+	}
+	if dbgErr := childState.constant.debugger.before(bp, childState); dbgErr != nil {
+		return bp.ParserIDs.parent, childState, nil, dbgErr
+	}
+	step := childState.constant.tracer.enter(bp, bp.expected, true, childID, childStartState.pos)
 	nState, out, nErr, data := bp.prsAfterChild(childID, childStartState, childState, childOut, childErr, err.ParserData(bp.ID()))
+	childState.constant.tracer.exit(step, nState.pos, nErr)
+	childState.constant.debugger.after(bp, nState, out, nErr)
 	if nErr != nil && data != nil {
 		nErr.StoreParserData(bp.ID(), data)
 	}
 	if nErr != nil && nErr.parserID < 0 {
 		nErr.parserID = bp.ID()
+		nErr.parserName = bp.expected
 	}
 	return bp.ParserIDs.parent, nState, out, nErr
 }
@@ -205,10 +286,23 @@ func (bp *brnchprsr[Output]) children() []AnyParser {
 	return bp.childs()
 }
 func (bp *brnchprsr[Output]) ensureIDs() { // only needed if Parse was called directly
-	if bp.ID() < 0 { // ensure sane IDs
-		bp.id = 0
-		for i, child := range bp.childs() {
-			child.setID(int32(i + 1))
+	if bp.ID() < 0 { // ensure sane IDs for the whole unregistered subtree
+		next := int32(0)
+		assignIDs(bp, &next)
+	}
+}
+
+// assignIDs numbers all parsers of a subtree that haven't got an ID yet.
+// Registered parsers (and thus their children) are left alone.
+func assignIDs(ap AnyParser, next *int32) {
+	if ap.ID() >= 0 {
+		return
+	}
+	ap.setID(*next)
+	*next++
+	if bp, ok := ap.(BranchParser); ok {
+		for _, child := range bp.children() {
+			assignIDs(child, next)
 		}
 	}
 }
@@ -294,10 +388,22 @@ func (lp *lazyprsr[Output]) setID(id int32) {
 	lp.once.Do(lp.ensurePrsr)
 	lp.cachedPrsr.setID(id)
 }
+func (lp *lazyprsr[Output]) StableID() uint64 {
+	lp.once.Do(lp.ensurePrsr)
+	return lp.cachedPrsr.StableID()
+}
+func (lp *lazyprsr[Output]) setStableID(id uint64) {
+	lp.once.Do(lp.ensurePrsr)
+	lp.cachedPrsr.setStableID(id)
+}
 func (lp *lazyprsr[Output]) setParent(id int32) {
 	lp.once.Do(lp.ensurePrsr)
 	lp.cachedPrsr.setParent(id)
 }
+func (lp *lazyprsr[Output]) parentID() int32 {
+	lp.once.Do(lp.ensurePrsr)
+	return lp.cachedPrsr.parentID()
+}
 
 // ============================================================================
 // Save Spot Parser
@@ -327,10 +433,8 @@ func (lp *lazyprsr[Output]) setParent(id int32) {
 //     Any error will look as if coming from SafeSpot itself.
 func SafeSpot[Output any](p Parser[Output]) Parser[Output] {
 	// call Recoverer to find a Forbidden recoverer during the construction phase and panic
-	recoverer := p.Recover
-	tstState := NewFromBytes([]byte{}, 0)
-	if recoverer != nil {
-		waste, _ := recoverer(tstState, nil)
+	if !p.IsStepRecoverer() { // step recoverers don't have a Recoverer
+		waste, _ := p.Recover(NewFromBytes([]byte{}, 0), nil)
 		if waste == RecoverNever {
 			panic("can't make parser with Forbidden recoverer a safe spot")
 		}
@@ -341,13 +445,5 @@ func SafeSpot[Output any](p Parser[Output]) Parser[Output] {
 		panic("SafeSpot can only be applied to leaf parsers")
 	}
 	pp.setSafeSpot()
-	parse := pp.parseWithData
-	pp.parseWithData = func(state State, data interface{}) (State, Output, *ParserError, interface{}) {
-		nState, out, err, data2 := parse(state, data)
-		if err == nil {
-			return nState.MoveSafeSpot(), out, nil, data2
-		}
-		return nState, out, err, data2
-	}
 	return pp
 }
@@ -100,3 +100,240 @@ func BenchmarkSaveSpot(b *testing.B) {
 		_, _, _ = p.Parse(input)
 	}
 }
+
+func TestRecoverBudget(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		budget         int
+		wantRecoveries int64
+	}{
+		{
+			name:           "unlimited budget should recover",
+			budget:         0,
+			wantRecoveries: 1,
+		}, {
+			name:           "big budget should recover",
+			budget:         20,
+			wantRecoveries: 1,
+		}, {
+			name:           "small budget should give up",
+			budget:         4,
+			wantRecoveries: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			counters := &comb.Counters{}
+			p := comb.NewPreparedParser(cmb.Suffixed(
+				cmb.Many1(cmb.Suffixed(cmb.Alpha1(), comb.SafeSpot(cmb.Char(';')))),
+				cmb.EOF(),
+			))
+			p.SetMetrics(counters)
+
+			state := comb.NewFromString("ab;12345678;cd;", 10).WithRecoverBudget(tc.budget)
+			if got := state.RecoverBudget(); got != tc.budget {
+				t.Errorf("got budget %d, want %d", got, tc.budget)
+			}
+			if _, err := comb.RunOnState(state, p); err == nil {
+				t.Errorf("got no error, want one")
+			}
+			if got := counters.Snapshot()["recoveries"]; got != tc.wantRecoveries {
+				t.Errorf("got %v recoveries, want %d", got, tc.wantRecoveries)
+			}
+		})
+	}
+}
+
+func TestInferSafeSpots(t *testing.T) {
+	t.Parallel()
+
+	stmt := cmb.Suffixed(
+		cmb.Prefixed(cmb.String("let"), cmb.Prefixed(cmb.Whitespace1(), cmb.Alpha1())),
+		cmb.Char(';'),
+	)
+	list := cmb.Map(cmb.Delimited(
+		cmb.Char('('),
+		cmb.Separated0(cmb.Alpha1(), cmb.Char(','), false),
+		cmb.Char(')'),
+	), func(items []string) (string, error) {
+		return strings.Join(items, "+"), nil
+	})
+	p := comb.NewPreparedParser(cmb.Many1(cmb.FirstSuccessful(stmt, list, cmb.Map(cmb.Char(','), func(r rune) (string, error) {
+		return string(r), nil
+	}))))
+
+	gotReport := p.InferSafeSpots()
+	gotExpected := make([]string, len(gotReport))
+	for i, r := range gotReport {
+		gotExpected[i] = r.Expected
+	}
+	wantExpected := []string{`"let"`, `';'`, `'('`, `')'`}
+	if strings.Join(gotExpected, " ") != strings.Join(wantExpected, " ") {
+		t.Errorf("got safe spots %q, want %q", gotExpected, wantExpected)
+	}
+
+	gotOutput, err := comb.RunOnState(comb.NewFromString("let a;(b,c),", 10), p)
+	if err != nil {
+		t.Errorf("got unexpected error: %v", err)
+	}
+	if want := []string{"a", "b+c", ","}; strings.Join(gotOutput, " ") != strings.Join(want, " ") {
+		t.Errorf("got output %q, want %q", gotOutput, want)
+	}
+
+	if got := p.InferSafeSpots(); len(got) != 0 {
+		t.Errorf("got safe spots %v on second call, want none", got)
+	}
+
+	semicolon := cmb.Char(';') // the same instance in two roles
+	shared := comb.NewPreparedParser(cmb.FirstSuccessful(
+		cmb.Suffixed(cmb.String("a"), semicolon),
+		cmb.Prefixed(semicolon, cmb.String("b")),
+	))
+	for _, r := range shared.InferSafeSpots() {
+		if r.Expected == "';'" {
+			t.Errorf("shared parser %s shouldn't be made a safe spot", r.Expected)
+		}
+	}
+	if got, err := comb.RunOnState(comb.NewFromString(";b", 10), shared); err != nil || got != "b" {
+		t.Errorf("got output %q and error %v, want %q", got, err, "b")
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "missing token",
+			input:   "(ab(cd)",
+			wantErr: "missing ')' (inserted) [1:4] (ab▶(cd)",
+		}, {
+			name:    "unexpected input",
+			input:   "(ab)(cd ef)",
+			wantErr: `unexpected " ef" before ')' (deleted) [1:8] (ab)(cd▶ ef)`,
+		}, {
+			name:    "unexpected input before a later safe spot",
+			input:   "(ab)x(cd)",
+			wantErr: `unexpected "x" before '(' (deleted) [1:5] (ab)▶x(cd)`,
+		}, {
+			name:    "substituted input",
+			input:   "(ab)(12)",
+			wantErr: `expected letter (need 1, found 0, got '1') instead of "12" (substituted) [1:6] (ab)(▶12)`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := cmb.Suffixed(cmb.Many1(cmb.Delimited(
+				comb.SafeSpot(cmb.Char('(')), cmb.Alpha1(), comb.SafeSpot(cmb.Char(')')),
+			)), cmb.EOF())
+			_, err := comb.RunOnString(tc.input, p)
+			if err == nil || err.Error() != tc.wantErr {
+				t.Errorf("got error %v, want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestStableID(t *testing.T) {
+	t.Parallel()
+
+	x1, y1 := cmb.String("x"), cmb.String("y")
+	pp1 := comb.NewPreparedParser(cmb.FirstSuccessful(x1, y1))
+
+	w2, x2, y2 := cmb.String("w"), cmb.String("x"), cmb.String("y")
+	pp2 := comb.NewPreparedParser(cmb.FirstSuccessful(w2, x2, y2))
+
+	if y1.ID() == y2.ID() {
+		t.Fatalf("IDs should differ, got %d for both", y1.ID())
+	}
+	if got, want := y2.StableID(), y1.StableID(); got != want {
+		t.Errorf("got stable ID %x, want %x", got, want)
+	}
+	if x1.StableID() == y1.StableID() {
+		t.Errorf("stable IDs of different parsers should differ, got %x for both", x1.StableID())
+	}
+	if id, ok := pp2.ParserID(y1.StableID()); !ok || id != y2.ID() {
+		t.Errorf("got ID %d (found=%t), want %d", id, ok, y2.ID())
+	}
+	if _, ok := pp1.ParserID(w2.StableID()); ok {
+		t.Errorf("unexpected parser for stable ID %x", w2.StableID())
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	grammar := func(keyword string, safeSpot bool) comb.Parser[string] {
+		x := cmb.String(keyword)
+		if safeSpot {
+			x = comb.SafeSpot(x)
+		}
+		return cmb.FirstSuccessful(x, cmb.String("y"))
+	}
+	fingerprint := func(p comb.Parser[string]) uint64 {
+		return comb.NewPreparedParser(p).Fingerprint()
+	}
+
+	want := fingerprint(grammar("x", false))
+	if got := fingerprint(grammar("x", false)); got != want {
+		t.Errorf("got fingerprint %x for the same grammar, want %x", got, want)
+	}
+	if got := fingerprint(grammar("z", false)); got == want {
+		t.Errorf("fingerprint should change with an expected message, got %x for both", got)
+	}
+	if got := fingerprint(grammar("x", true)); got == want {
+		t.Errorf("fingerprint should change with a safe spot, got %x for both", got)
+	}
+
+	y := cmb.String("y")
+	shared := fingerprint(cmb.Prefixed(y, y))
+	if got := fingerprint(cmb.Prefixed(cmb.String("y"), cmb.String("y"))); got == shared {
+		t.Errorf("fingerprint should change with shared parsers, got %x for both", got)
+	}
+}
+
+func TestParseAssignsIDs(t *testing.T) {
+	t.Parallel()
+
+	a, b, c := cmb.String("a"), cmb.String("b"), cmb.String("c")
+	p := cmb.Prefixed(a, cmb.Suffixed(b, c))
+	if _, _, err := p.Parse(comb.NewFromString("abc", 10)); err != nil {
+		t.Fatalf("got unexpected error: %v", err)
+	}
+
+	ids := map[int32]bool{p.ID(): true}
+	for _, q := range []comb.Parser[string]{a, b, c} {
+		if q.ID() < 0 || ids[q.ID()] {
+			t.Errorf("parser %q should have a unique ID, got %d", q.Expected(), q.ID())
+		}
+		ids[q.ID()] = true
+	}
+}
+
+func TestSharesParsers(t *testing.T) {
+	t.Parallel()
+
+	y := cmb.String("y")
+	own := comb.NewPreparedParser(cmb.Prefixed(y, y))
+	if own.SharesParsers() {
+		t.Errorf("parser used twice in the same grammar shouldn't be shared")
+	}
+	other := comb.NewPreparedParser(cmb.Prefixed(cmb.String("x"), y))
+	if !other.SharesParsers() {
+		t.Errorf("parser prepared before as part of another grammar should be shared")
+	}
+}
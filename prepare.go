@@ -1,6 +1,9 @@
 package comb
 
 import (
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"math"
 	"slices"
 )
@@ -14,14 +17,17 @@ import (
 // (slices, maps, ...).
 type AnyParser interface {
 	ID() int32
+	StableID() uint64
 	ParseAny(parentID int32, state State) (State, interface{}, *ParserError) // top -> down
 	parseAnyAfterError(err *ParserError, state State,
 	) (lastParentID int32, newState State, output interface{}, newErr *ParserError) // used by parseAll (bottom -> up)
 	IsSafeSpot() bool
 	Recover(State, interface{}) (int, interface{})
 	IsStepRecoverer() bool
-	setID(int32)     // only sets own ID
-	setParent(int32) // sets initial parent ID
+	setID(int32)        // only sets own ID
+	setStableID(uint64) // only sets own stable ID
+	setParent(int32)    // sets initial parent ID
+	parentID() int32    // returns the initial parent ID
 }
 
 // BranchParser is a more internal interface used by orchestrators.
@@ -42,34 +48,63 @@ type PreparedParser[Output any] struct {
 	parsers        []AnyParser
 	recoverers     []AnyParser
 	stepRecoverers []AnyParser
+	stableIDs      map[uint64]int32
+	metrics        Metrics
+	shared         bool // some parsers belong to another prepared parser
 }
 
 // NewPreparedParser prepares a parser for error recovery.
 // Call this directly if you have a parser that you want to run on many inputs.
 // You can use this together with RunOnState.
 func NewPreparedParser[Output any](p Parser[Output]) *PreparedParser[Output] {
+	return newPreparedParser[Output](p)
+}
+
+// NewPreparedAnyParser prepares a parser that is only known as AnyParser
+// (e.g., from the registry, see Lookup).
+// Its output is returned as interface{}.
+func NewPreparedAnyParser(p AnyParser) *PreparedParser[interface{}] {
+	return newPreparedParser[interface{}](p)
+}
+
+func newPreparedParser[Output any](ap AnyParser) *PreparedParser[Output] {
 	pp := &PreparedParser[Output]{
 		parsers:        make([]AnyParser, 0, 64),
 		recoverers:     make([]AnyParser, 0, 64),
 		stepRecoverers: make([]AnyParser, 0, 64),
+		stableIDs:      make(map[uint64]int32, 64),
 	}
-	pp.registerParsers(p, -1)
+	pp.registerParsers(ap, -1, 0)
 	return pp
 }
 
-func (pp *PreparedParser[Output]) registerParsers(ap AnyParser, parentID int32) {
+func (pp *PreparedParser[Output]) registerParsers(ap AnyParser, parentID int32, occurrence int) {
 	if ap.ID() >= 0 {
 		Debugf("registerParsers - parser (ID: %d) is already registered with parent %d", ap.ID(), parentID)
+		if int(ap.ID()) >= len(pp.parsers) || pp.parsers[ap.ID()] != ap {
+			pp.shared = true
+		}
 		return
 	}
 	id := int32(len(pp.parsers))
 	ap.setID(id)
 	ap.setParent(parentID)
 	pp.parsers = append(pp.parsers, ap)
+	var parentStableID uint64
+	if parentID >= 0 {
+		parentStableID = pp.parsers[parentID].StableID()
+	}
+	ap.setStableID(stableID(ap, parentStableID, occurrence))
+	if _, ok := pp.stableIDs[ap.StableID()]; !ok {
+		pp.stableIDs[ap.StableID()] = id
+	}
 
 	if bp, ok := ap.(BranchParser); ok {
+		occurrences := make(map[string]int)
 		for _, cp := range bp.children() {
-			pp.registerParsers(cp, id)
+			expected := expectedOf(cp)
+			pp.registerParsers(cp, id, occurrences[expected])
+			occurrences[expected]++
 		}
 	} else if ap.IsSafeSpot() {
 		if ap.IsStepRecoverer() {
@@ -80,14 +115,160 @@ func (pp *PreparedParser[Output]) registerParsers(ap AnyParser, parentID int32)
 	}
 }
 
+// stableID derives an ID that doesn't depend on the construction order.
+// Parsers registered by name (see Register) get an ID derived from the name only.
+// All others get an ID derived from their path in the grammar:
+// the stable ID of the parent, the expected message and the number of
+// siblings with the same expected message before them.
+func stableID(ap AnyParser, parentStableID uint64, occurrence int) uint64 {
+	h := fnv.New64a()
+	if name := NameOf(ap); name != "" {
+		_, _ = h.Write([]byte(name))
+		return h.Sum64()
+	}
+	_, _ = fmt.Fprintf(h, "%x/%s#%d", parentStableID, expectedOf(ap), occurrence)
+	return h.Sum64()
+}
+
+func expectedOf(ap AnyParser) string {
+	if ep, ok := ap.(interface{ Expected() string }); ok {
+		return ep.Expected()
+	}
+	return ""
+}
+
+// SharesParsers returns true if the parser (or one of its sub-parsers)
+// has been prepared before as part of another parser.
+// Such parsers are missing from this prepared parser,
+// so error recovery doesn't work reliably.
+func (pp *PreparedParser[Output]) SharesParsers() bool {
+	return pp.shared
+}
+
+// ParserID returns the (run specific) ID of the parser with the stable ID.
+// So data serialized with stable IDs (e.g., traces) can be mapped back to
+// the parsers of this run.
+func (pp *PreparedParser[Output]) ParserID(stableID uint64) (int32, bool) {
+	id, ok := pp.stableIDs[stableID]
+	return id, ok
+}
+
+// Fingerprint returns a stable hash of the grammar.
+// It covers the structure of the parser graph (including shared and
+// recursive parsers), the expected messages, the names (see Register) and
+// the safe spots of all parsers.
+// So tools that persist memo tables, golden files or serialized results can
+// detect grammar changes and invalidate derived data.
+// Changes of the Go code inside leaf parsers aren't detected.
+func (pp *PreparedParser[Output]) Fingerprint() uint64 {
+	h := fnv.New64a()
+	for _, ap := range pp.parsers {
+		_, _ = fmt.Fprintf(h, "%q %q %t %t", expectedOf(ap), NameOf(ap), ap.IsSafeSpot(), ap.IsStepRecoverer())
+		if bp, ok := ap.(BranchParser); ok {
+			_, _ = h.Write([]byte{'('})
+			for _, cp := range bp.children() {
+				_, _ = fmt.Fprintf(h, " %d", cp.ID())
+			}
+			_, _ = h.Write([]byte{')'})
+		}
+		_, _ = h.Write([]byte{'\n'})
+	}
+	return h.Sum64()
+}
+
+// InferredSafeSpot describes a parser that has been made a SafeSpot by InferSafeSpots.
+type InferredSafeSpot struct {
+	ID       int32
+	Expected string
+}
+
+// InferSafeSpots analyzes the grammar and makes unambiguous anchor tokens safe spots.
+// Anchor tokens are leaf parsers for literals (like keywords, semicolons or
+// closing brackets) that have an optimized recoverer.
+// They are unambiguous if no other leaf parser expects the same literal and
+// they are used at a single position of the grammar
+// (parser instances shared by several parents aren't unambiguous).
+// It returns a report of the parsers that have been made safe spots.
+//
+// NOTE:
+//   - InferSafeSpots has to be called before the parser is used (it isn't concurrency safe).
+//   - Safe spots prevent backtracking. So alternatives shouldn't share the
+//     same parser instance for their first token.
+func (pp *PreparedParser[Output]) InferSafeSpots() []InferredSafeSpot {
+	type candidate struct {
+		parser   AnyParser
+		expected string
+	}
+	var candidates []candidate
+	counts := make(map[string]int) // number of grammar positions per literal
+	emptyState := NewFromBytes([]byte{}, 0)
+
+	uses := make([]int, len(pp.parsers)) // number of parent/child edges per parser
+	for _, ap := range pp.parsers {
+		if bp, ok := ap.(BranchParser); ok {
+			for _, cp := range bp.children() {
+				uses[cp.ID()]++
+			}
+		}
+	}
+
+	for _, ap := range pp.parsers {
+		if _, ok := ap.(BranchParser); ok {
+			continue
+		}
+		ep, ok := ap.(interface{ Expected() string })
+		if !ok {
+			continue
+		}
+		expected := ep.Expected()
+		if !isQuotedLiteral(expected) {
+			continue
+		}
+		counts[expected] += max(uses[ap.ID()], 1)
+		if ap.IsSafeSpot() || ap.IsStepRecoverer() {
+			continue
+		}
+		if waste, _ := ap.Recover(emptyState, nil); waste == RecoverNever {
+			continue
+		}
+		candidates = append(candidates, candidate{parser: ap, expected: expected})
+	}
+
+	var report []InferredSafeSpot
+	for _, c := range candidates {
+		if counts[c.expected] > 1 {
+			continue
+		}
+		sp, ok := c.parser.(interface{ setSafeSpot() })
+		if !ok {
+			continue
+		}
+		sp.setSafeSpot()
+		pp.recoverers = append(pp.recoverers, c.parser)
+		report = append(report, InferredSafeSpot{ID: c.parser.ID(), Expected: c.expected})
+	}
+	return report
+}
+
+// isQuotedLiteral is true for the expected messages of literal parsers like
+// `';'` or `"func"`.
+func isQuotedLiteral(expected string) bool {
+	n := len(expected)
+	return n >= 3 && (expected[0] == '\'' || expected[0] == '"') && expected[n-1] == expected[0]
+}
+
 // ============================================================================
 // PreparedParser: parseAll
 //
 
-func (pp *PreparedParser[Output]) parseAll(state State) (Output, error) {
+func (pp *PreparedParser[Output]) parseAll(state State) (State, Output, error) {
 	var id int32 = 0 // this is always the root parser
 	recoverCache := slices.Repeat([]int{RecoverWasteUnknown}, len(pp.parsers))
 	p := pp.parsers[id]
+	state = state.withActionLog()
+	if pp.metrics != nil {
+		pp.metrics.ParseStarted()
+	}
 
 	// TOP->DOWN: Normal parsing starts with the root parser (ID=0)
 	// and goes all the way down to the leaf parsers until an error is found.
@@ -97,15 +278,22 @@ func (pp *PreparedParser[Output]) parseAll(state State) (Output, error) {
 	nextID := id
 	for err != nil {
 		Debugf("parseAll - got Error=%v", err)
+		if pp.metrics != nil {
+			pp.metrics.ErrorFound()
+		}
 		nState = nState.SaveError(err)
-		if nState.AtEnd() || nState.constant.maxErrors <= 0 { // give up
-			Debugf("parseAll - at EOF or recovery is turned off")
-			return out, nState.Errors()
+		if nState.AtEnd() || nState.constant.maxErrors <= 0 || err.Fatal() { // give up
+			Debugf("parseAll - at EOF, recovery is turned off or fatal error")
+			nState.syncActions()
+			nState.reportWarnings()
+			return nState, out, nState.Errors()
 		}
 		nState, nextID = pp.handleError(nState, err, recoverCache)
 		if nextID < 0 { // give up
 			Debugf("parseAll - no recoverer found")
-			return out, nState.Errors()
+			nState.syncActions()
+			nState.reportWarnings()
+			return nState, out, nState.Errors()
 		}
 		p = pp.parsers[nextID]
 
@@ -132,38 +320,207 @@ func (pp *PreparedParser[Output]) parseAll(state State) (Output, error) {
 		err = nextErr
 	}
 	out, _ = aOut.(Output)
-	return out, nState.Errors()
+	nState = nState.resolveDeferred()
+	nState.syncActions()
+	nState.reportWarnings()
+	return nState, out, nState.Errors()
+}
+
+// ============================================================================
+// PreparedParser: ParsePrefix
+//
+
+// ErrIncomplete is returned by ParsePrefix if the input ended before
+// the parser could decide about success or failure.
+var ErrIncomplete = errors.New("incomplete input")
+
+// ParsePrefix parses the input of the state as a prefix of the full input.
+// It distinguishes between errors that can't be fixed by more input and
+// errors that happened because the input ended too early (ErrIncomplete).
+// In the latter case the original state is returned, so protocol servers can
+// read more bytes and try again.
+// There is no error recovery because the rest of the input is missing anyway.
+func (pp *PreparedParser[Output]) ParsePrefix(state State) (State, Output, error) {
+	if pp.metrics != nil {
+		pp.metrics.ParseStarted()
+	}
+	nState, aOut, err := pp.parsers[0].ParseAny(ParentUnknown, state)
+	out, _ := aOut.(Output)
+	if err == nil {
+		nState = nState.resolveDeferred()
+		nState.syncActions()
+		nState.reportWarnings()
+		return nState, out, nState.Errors()
+	}
+	if pp.metrics != nil {
+		pp.metrics.ErrorFound()
+	}
+	if err.Incomplete() {
+		state.syncActions()
+		return state, out, ErrIncomplete
+	}
+	nState.syncActions()
+	nState.reportWarnings()
+	return nState, out, err
+}
+
+// ExpectedAt returns the descriptions (Expected()) of all leaf parsers that
+// could continue the input at the position of the state.
+// This is useful for completion in IDEs and for better error messages.
+// The parser is run on the input up to the position (without error recovery and metrics)
+// and all leaf parsers that reached the position are reported in order.
+// Leaf parsers that are used directly by other leaf parsers aren't visible.
+func (pp *PreparedParser[Output]) ExpectedAt(state State) []string {
+	steps := pp.expectedSteps(state)
+	expected := make([]string, 0, len(steps))
+	for _, step := range steps {
+		expected = append(expected, step.Expected)
+	}
+	if len(expected) == 0 {
+		return nil
+	}
+	return expected
+}
+
+// expectedSteps returns the first trace step of every leaf parser that
+// could continue the input at the position of the state.
+func (pp *PreparedParser[Output]) expectedSteps(state State) []TraceStep {
+	start := State{constant: state.constant, safeSpot: -1, pos: 0, prevNl: -1, line: 1}
+	return expectedStepsOf(pp.parsers[0], start, state)
+}
+
+// ExpectedOf returns what the parser p started at the start state could
+// accept at the position of state (like PreparedParser.ExpectedAt).
+// So branch parsers can report the follow set of a child parser
+// (e.g., when input is left after it).
+// p has to be prepared already (it has to be part of a PreparedParser).
+func ExpectedOf(p AnyParser, start, state State) []string {
+	steps := expectedStepsOf(p, start, state)
+	if len(steps) == 0 {
+		return nil
+	}
+	expected := make([]string, 0, len(steps))
+	for _, step := range steps {
+		expected = append(expected, step.Expected)
+	}
+	return expected
+}
+
+// expectedStepsOf runs the parser p from the start state on the input up to
+// the position of state and returns the leaf parser steps that ended
+// at that position or couldn't decide because the input ended there.
+func expectedStepsOf(p AnyParser, start, state State) []TraceStep {
+	tr := &tracer{}
+	constant := *state.constant
+	if constant.binary {
+		constant.bytes, constant.text = constant.bytes[:state.pos], ""
+	} else {
+		constant.bytes, constant.text = nil, constant.text[:state.pos]
+	}
+	constant.n = state.pos
+	constant.maxErrors = FailFast
+	constant.parserCache = make(map[int32]interface{})
+	constant.cst, constant.debugger, constant.ambiguities, constant.forest = nil, nil, nil, nil
+	constant.tracer = tr
+	constant.actions = &actionLog{}
+	probe := start
+	probe.constant = &constant
+	_, _, _ = p.ParseAny(ParentUnknown, probe)
+	probe.syncActions() // the probe mustn't have side effects
+
+	branches := make(map[int32]bool)
+	collectBranchIDs(p, branches, make(map[AnyParser]bool))
+	var steps []TraceStep
+	for _, step := range tr.steps {
+		if branches[step.ParserID] {
+			continue
+		}
+		if step.Start != state.pos && !step.Incomplete {
+			continue
+		}
+		if !slices.ContainsFunc(steps, func(s TraceStep) bool { return s.Expected == step.Expected }) {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+func collectBranchIDs(p AnyParser, branches map[int32]bool, seen map[AnyParser]bool) {
+	if seen[p] {
+		return
+	}
+	seen[p] = true
+	if bp, ok := p.(BranchParser); ok {
+		branches[p.ID()] = true
+		for _, cp := range bp.children() {
+			collectBranchIDs(cp, branches, seen)
+		}
+	}
 }
 
 func (pp *PreparedParser[Output]) handleError(state State, err *ParserError, recoverCache []int,
 ) (newState State, nextID int32) {
 	Debugf("handleError - parserID=%d, pos=%d, Error=%v", err.parserID, state.CurrentPos(), err)
 
+	state.budget = nil // the time budgets are checked per recoverer
 	minWaste, minRec := pp.findMinWaste(err, state, recoverCache)
 
 	if minWaste < 0 {
 		Debugf("handleError - no recoverer found")
+		pp.explainRecovery(state, err, minWaste, nil)
 		return state.MoveBy(state.BytesRemaining()), RecoverWasteTooMuch
 	}
 	Debugf("handleError - best recoverer: ID=%d, waste=%d", minRec.ID(), minWaste)
+	next := state.MoveBy(minWaste)
+	switch wasted := state.StringTo(next); {
+	case err.source != state.constant.source: // the wasted input isn't the input of the error (see PushInput)
+	case minWaste == 0:
+		err.classify(ErrorMissing, "", "")
+	case minRec.ID() == err.parserID:
+		err.classify(ErrorUnexpected, wasted, "")
+	case minRec.ID() < err.parserID && !next.AtEnd(): // the recoverer comes earlier in the grammar (e.g. the next item of a loop)
+		err.classify(ErrorUnexpected, wasted, expectedOf(minRec))
+	default: // the wasted input took the place of the failed parser
+		err.classify(ErrorSubstituted, wasted, "")
+	}
+	err.waste = minWaste
+	err.safeSpot = minRec.IsSafeSpot()
+	pp.explainRecovery(state, err, minWaste, minRec)
+	state = state.replaceLastError(err)
+	state.budget = pp.budgetOf(minRec, err.budget) // parsing continues inside the subtree of the recoverer
+	if pp.metrics != nil {
+		pp.metrics.Recovered(minWaste)
+	}
 	return state.MoveBy(minWaste), minRec.ID()
 }
 
 func (pp *PreparedParser[Output]) findMinWaste(pe *ParserError, state State, recoverCache []int,
 ) (minWaste int, minRec AnyParser) {
 	failed := false
+	var fastRecs []AnyParser         // all fast recoverers that have been tried
 	minRec = pp.parsers[pe.parserID] // try the failed parser first
 	minWaste = math.MaxInt
-	if !minRec.IsStepRecoverer() {
+	outOfTime := func(rec AnyParser) bool { // no recovery inside subtrees that exceeded their time budget
+		return pp.budgetOf(rec, pe.budget).expired()
+	}
+	if outOfTime(minRec) {
+		Debugf("findMinWaste - failed parser is out of time: ID=%d", pe.parserID)
+		failed = true
+	} else if !minRec.IsStepRecoverer() {
 		minWaste = pp.recover(pe, state, minRec, recoverCache)
 		Debugf("findMinWaste - failed parser has fast recoverer: ID=%d, waste=%d", pe.parserID, minWaste)
 		if minWaste < 0 { // recoverer is either forbidden or unsuccessful
 			minWaste = math.MaxInt
 		}
 		failed = true
+		fastRecs = append(fastRecs, minRec)
 	}
 	for _, rec := range pp.recoverers { // try all fast recoverers
-		waste, data := rec.Recover(state, pe.ParserData(rec.ID()))
+		if outOfTime(rec) {
+			continue
+		}
+		fastRecs = append(fastRecs, rec)
+		waste, data := recoverWithBudget(rec, state, pe.ParserData(rec.ID()))
 		if data != nil {
 			pe.StoreParserData(rec.ID(), data)
 		}
@@ -178,27 +535,56 @@ func (pp *PreparedParser[Output]) findMinWaste(pe *ParserError, state State, rec
 	}
 	Debugf("findMinWaste - best fast recoverer: ID=%d, waste=%d", minRec.ID(), minWaste)
 	stepRecs := pp.stepRecoverers
+	if pe.budget != nil {
+		stepRecs = slices.DeleteFunc(slices.Clone(stepRecs), outOfTime)
+	}
 	if !failed {
-		stepRecs = make([]AnyParser, len(pp.stepRecoverers)+1)
-		copy(stepRecs, pp.stepRecoverers)
-		stepRecs[len(pp.stepRecoverers)] = pp.parsers[pe.parserID]
+		stepRecs = append(stepRecs[:len(stepRecs):len(stepRecs)], pp.parsers[pe.parserID])
 		Debugf("findMinWaste - failed parser has slow recoverer: ID=%d", pe.parserID)
 	}
+	if len(state.constant.anchors) > 0 { // anchors are preferred to nearer positions
+		maxWaste := state.BytesRemaining()
+		if budget := state.constant.recBudget; budget > 0 {
+			maxWaste = min(maxWaste, budget)
+		}
+		if w, rec, ok := pp.findAnchoredWaste(fastRecs, stepRecs, state, pe, maxWaste); ok {
+			return w, rec
+		}
+	}
 	return pp.findMinStepWaste(stepRecs, state, pe, minWaste, minRec)
 }
 
+// budgetOf returns the innermost time budget of the chain starting with
+// `budget` that belongs to `ap` or one of its ancestors (see WithTimeout).
+func (pp *PreparedParser[Output]) budgetOf(ap AnyParser, budget *timeBudget) *timeBudget {
+	for ; budget != nil; budget = budget.outer {
+		id := ap.ID()
+		for n := 0; id >= 0 && int(id) < len(pp.parsers) && n < len(pp.parsers); n++ { // recursive grammars can have cycles
+			if id == budget.owner {
+				return budget
+			}
+			id = pp.parsers[id].parentID()
+		}
+	}
+	return nil
+}
+
 func (pp *PreparedParser[Output]) recover(pe *ParserError, state State, rec AnyParser, recoverCache []int) int {
 	var data interface{}
 
 	waste := recoverCache[rec.ID()]
+	pos := state.CurrentPos()
+	hit := waste < RecoverWasteUnknown || (waste >= 0 && waste >= pos)
+	if pp.metrics != nil {
+		pp.metrics.RecoverCacheLookup(hit)
+	}
 	if waste < RecoverWasteUnknown {
 		return waste
 	}
-	pos := state.CurrentPos()
-	if waste >= 0 && waste >= pos {
+	if hit {
 		return waste - pos
 	}
-	waste, data = rec.Recover(state, pe.ParserData(rec.ID()))
+	waste, data = recoverWithBudget(rec, state, pe.ParserData(rec.ID()))
 	if data != nil {
 		pe.StoreParserData(rec.ID(), data)
 	}
@@ -209,6 +595,20 @@ func (pp *PreparedParser[Output]) recover(pe *ParserError, state State, rec AnyP
 	return waste
 }
 
+// recoverWithBudget calls the recoverer on input limited by the recover budget.
+// Results at the end of the limited input are unreliable and reported as RecoverWasteTooMuch.
+func recoverWithBudget(rec AnyParser, state State, data interface{}) (int, interface{}) {
+	budget := state.constant.recBudget
+	if budget <= 0 || state.BytesRemaining() <= budget {
+		return rec.Recover(state, data)
+	}
+	waste, newData := rec.Recover(state.truncated(budget), data)
+	if waste >= budget {
+		return RecoverWasteTooMuch, newData
+	}
+	return waste, newData
+}
+
 func (pp *PreparedParser[Output]) findMinStepWaste(
 	stepRecs []AnyParser, state State, err *ParserError, waste int, rec AnyParser,
 ) (minWaste int, minRec AnyParser) {
@@ -216,15 +616,15 @@ func (pp *PreparedParser[Output]) findMinStepWaste(
 	if maxWaste == math.MaxInt {
 		Debugf("findMinStepWaste - ALL fast recoverers failed!")
 	}
+	if budget := state.constant.recBudget; budget > 0 {
+		maxWaste = min(maxWaste, budget)
+	}
 	curState := state
 	minWaste = 0
 	for curState.BytesRemaining() > 0 && minWaste < maxWaste {
-		for _, sr := range stepRecs {
-			_, _, _, nErr := sr.parseAnyAfterError(err, curState)
-			if nErr == nil {
-				Debugf("findMinStepWaste - best slow recoverer: ID=%d, waste=%d", sr.ID(), minWaste)
-				return minWaste, sr
-			}
+		if sr := stepRecoverAt(stepRecs, curState, err); sr != nil {
+			Debugf("findMinStepWaste - best slow recoverer: ID=%d, waste=%d", sr.ID(), minWaste)
+			return minWaste, sr
 		}
 		curState = curState.Delete1()
 		minWaste = state.ByteCount(curState)
@@ -2,6 +2,7 @@ package comb
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
 	"strconv"
 	"strings"
@@ -249,7 +250,7 @@ func TestPreparedParserParseAll(t *testing.T) {
 				)
 			}
 			prepp := NewPreparedParser[string](parser) // this calls ParserToAnyParser
-			gotOutput, err := prepp.parseAll(NewFromString(tt.input, 10))
+			_, gotOutput, err := prepp.parseAll(NewFromString(tt.input, 10))
 			t.Logf("err=%v", err)
 			if got, want := len(UnwrapErrors(err)), tt.wantErrors; got != want {
 				t.Errorf("err=%v, want=%d", err, want)
@@ -618,3 +619,101 @@ func IndexOf[S Separator](stop S) Recoverer {
 		return nil // can never happen because of the `Separator` constraint!
 	}
 }
+
+func TestParsePrefix(t *testing.T) {
+	runePlusRune := func(out1 rune, out2 rune) (string, error) {
+		return string([]rune{out1, out2}), nil
+	}
+
+	tests := []struct {
+		name           string
+		input          string
+		wantOutput     string
+		wantIncomplete bool
+		wantErr        bool
+		wantPos        int
+	}{
+		{
+			name:       "complete input",
+			input:      "ab;",
+			wantOutput: "ab",
+			wantPos:    2,
+		}, {
+			name:           "incomplete input",
+			input:          "a",
+			wantIncomplete: true,
+			wantErr:        true,
+			wantPos:        0,
+		}, {
+			name:           "empty input",
+			input:          "",
+			wantIncomplete: true,
+			wantErr:        true,
+			wantPos:        0,
+		}, {
+			name:    "definitely failed",
+			input:   "ac",
+			wantErr: true,
+			wantPos: 1,
+		},
+	}
+	for _, tc := range tests {
+		tt := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tt.name, func(t *testing.T) {
+			prepp := NewPreparedParser[string](Map2(Char('a'), Char('b'), runePlusRune))
+			nState, gotOutput, err := prepp.ParsePrefix(NewFromString(tt.input, 10))
+			if got, want := err != nil, tt.wantErr; got != want {
+				t.Errorf("err=%v, want error=%t", err, want)
+			}
+			if got, want := errors.Is(err, ErrIncomplete), tt.wantIncomplete; got != want {
+				t.Errorf("incomplete=%t, want=%t", got, want)
+			}
+			if got, want := nState.CurrentPos(), tt.wantPos; got != want {
+				t.Errorf("pos=%d, want=%d", got, want)
+			}
+			if !tt.wantErr && gotOutput != tt.wantOutput {
+				t.Errorf("got output=%q, want=%q", gotOutput, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestExpectedAt(t *testing.T) {
+	runePlusRune := func(out1 rune, out2 rune) (string, error) {
+		return string([]rune{out1, out2}), nil
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		pos   int
+		want  []string
+	}{
+		{
+			name:  "start",
+			input: "ab",
+			pos:   0,
+			want:  []string{"'a'"},
+		}, {
+			name:  "middle",
+			input: "ab",
+			pos:   1,
+			want:  []string{"'b'"},
+		}, {
+			name:  "end",
+			input: "ab",
+			pos:   2,
+			want:  nil,
+		},
+	}
+	for _, tc := range tests {
+		tt := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tt.name, func(t *testing.T) {
+			prepp := NewPreparedParser[string](Map2(Char('a'), Char('b'), runePlusRune))
+			got := prepp.ExpectedAt(NewFromString(tt.input, 10).MoveBy(tt.pos))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got=%q, want=%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package comb
+
+import (
+	"strings"
+)
+
+// ============================================================================
+// Printing Outputs Back To Text
+//
+
+// Printer is a parser that can render its output back to text.
+// So a grammar built from printers can parse text into a value
+// and print the value back into text (e.g. for formatters or code re-writers).
+type Printer[Output any] interface {
+	Parser[Output]
+	Print(out Output, buf *PrintBuffer) error
+}
+
+// PrintBuffer collects the printed text.
+// If it knows the original input, printers can reuse the
+// original formatting of unchanged parts (see OriginalText).
+type PrintBuffer struct {
+	strings.Builder
+	original    string
+	hasOriginal bool
+}
+
+// OriginalText returns the original input of the span.
+// It returns false if there is no original input or the span doesn't fit to it.
+func (buf *PrintBuffer) OriginalText(span Span) (string, bool) {
+	if !buf.hasOriginal || span.Start < 0 || span.End > len(buf.original) || span.Start > span.End {
+		return "", false
+	}
+	return buf.original[span.Start:span.End], true
+}
+
+// Print renders the output with the printer.
+func Print[Output any](p Printer[Output], out Output) (string, error) {
+	buf := &PrintBuffer{}
+	if err := p.Print(out, buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Reprint renders the output with the printer.
+// The output should be the result of parsing `original` (and maybe
+// changing it afterward).
+// Printers can reuse the original formatting for unchanged parts.
+func Reprint[Output any](original string, p Printer[Output], out Output) (string, error) {
+	buf := &PrintBuffer{original: original, hasOriginal: true}
+	if err := p.Print(out, buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// WithPrinter registers the inverse of a parser.
+// The returned parser parses exactly like the given one.
+//
+// NOTE:
+//   - SafeSpot has to be applied to the given parser and not to the returned one.
+func WithPrinter[Output any](p Parser[Output], print func(Output, *PrintBuffer) error) Printer[Output] {
+	if print == nil {
+		panic("WithPrinter: print is nil")
+	}
+	pp := &printr[Output]{Parser: p, print: print}
+	if bp, ok := p.(BranchParser); ok {
+		return &branchPrintr[Output]{printr: pp, branch: bp}
+	}
+	return pp
+}
+
+type printr[Output any] struct {
+	Parser[Output]
+	print func(Output, *PrintBuffer) error
+}
+
+func (pp *printr[Output]) Print(out Output, buf *PrintBuffer) error {
+	return pp.print(out, buf)
+}
+
+// branchPrintr keeps a branch parser visible as branch parser.
+type branchPrintr[Output any] struct {
+	*printr[Output]
+	branch BranchParser
+}
+
+func (bp *branchPrintr[Output]) children() []AnyParser {
+	return bp.branch.children()
+}
+func (bp *branchPrintr[Output]) parseAfterError(
+	err *ParserError, childID int32, childStartState, childState State, childOut interface{}, childErr *ParserError,
+) (int32, State, interface{}, *ParserError) {
+	return bp.branch.parseAfterError(err, childID, childStartState, childState, childOut, childErr)
+}
@@ -0,0 +1,56 @@
+package comb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginalText(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		original string // empty means: no original
+		span     Span
+		wantText string
+		wantOK   bool
+	}{
+		{
+			name:     "no original",
+			span:     Span{Start: 0, End: 1},
+			wantText: "",
+			wantOK:   false,
+		}, {
+			name:     "valid span",
+			original: "hello world",
+			span:     Span{Start: 6, End: 11},
+			wantText: "world",
+			wantOK:   true,
+		}, {
+			name:     "span too long",
+			original: "hello",
+			span:     Span{Start: 3, End: 6},
+			wantText: "",
+			wantOK:   false,
+		}, {
+			name:     "negative span",
+			original: "hello",
+			span:     Span{Start: 3, End: 2},
+			wantText: "",
+			wantOK:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &PrintBuffer{original: tc.original, hasOriginal: tc.original != ""}
+			gotText, gotOK := buf.OriginalText(tc.span)
+			assert.Equal(t, tc.wantText, gotText)
+			assert.Equal(t, tc.wantOK, gotOK)
+		})
+	}
+}
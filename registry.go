@@ -0,0 +1,76 @@
+package comb
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ============================================================================
+// Global Parser Registry
+//
+
+// registry maps names to parsers and back.
+// It is safe for concurrent use.
+var registry = struct {
+	sync.RWMutex
+	parsers map[string]AnyParser
+	names   map[AnyParser]string
+}{
+	parsers: make(map[string]AnyParser),
+	names:   make(map[AnyParser]string),
+}
+
+// Register registers the parser under the name in the global registry.
+// So large projects can organize grammar rules across packages, and
+// tools (like tracing) can refer to rules by stable names instead of
+// numeric IDs that change between runs.
+// Register panics if the name or the parser is already registered
+// (with another parser or name respectively).
+// It should be called during the construction phase (e.g., in `init` functions).
+func Register(name string, p AnyParser) {
+	if name == "" {
+		panic("Register: name is empty")
+	}
+	if p == nil {
+		panic("Register: parser is nil")
+	}
+	registry.Lock()
+	defer registry.Unlock()
+
+	if other, ok := registry.parsers[name]; ok && other != p {
+		panic(fmt.Sprintf("Register: name %q is already registered for another parser", name))
+	}
+	if other, ok := registry.names[p]; ok && other != name {
+		panic(fmt.Sprintf("Register: parser is already registered as %q", other))
+	}
+	registry.parsers[name] = p
+	registry.names[p] = name
+}
+
+// Lookup returns the parser registered under the name.
+func Lookup(name string) (AnyParser, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	p, ok := registry.parsers[name]
+	return p, ok
+}
+
+// NameOf returns the name the parser is registered with or "" if it isn't registered.
+func NameOf(p AnyParser) string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.names[p]
+}
+
+// RegisteredNames returns the names of all registered parsers in sorted order.
+func RegisteredNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.parsers))
+	for name := range registry.parsers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,51 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	word := cmb.Alpha1()
+	comb.Register("test.registry.word", word)
+	comb.Register("test.registry.word", word) // registering twice is fine
+
+	got, ok := comb.Lookup("test.registry.word")
+	assert.True(t, ok)
+	assert.Equal(t, comb.AnyParser(word), got)
+	assert.Equal(t, "test.registry.word", comb.NameOf(word))
+	assert.Contains(t, comb.RegisteredNames(), "test.registry.word")
+
+	_, ok = comb.Lookup("test.registry.unknown")
+	assert.False(t, ok)
+	assert.Equal(t, "", comb.NameOf(cmb.Alpha1()))
+
+	assert.Panics(t, func() {
+		comb.Register("test.registry.word", cmb.Alpha1())
+	}, "same name for another parser")
+	assert.Panics(t, func() {
+		comb.Register("test.registry.other", word)
+	}, "another name for the same parser")
+}
+
+func TestRegistryInTrace(t *testing.T) {
+	t.Parallel()
+
+	digits := cmb.Digit1()
+	comb.Register("test.trace.digits", digits)
+
+	_, trace, err := comb.RecordRun(comb.NewFromString("123", 10), cmb.Suffixed(digits, cmb.EOF()))
+	assert.NoError(t, err)
+	var names []string
+	for _, step := range trace.Steps {
+		if step.Name != "" {
+			names = append(names, step.Name)
+		}
+	}
+	assert.Equal(t, []string{"test.trace.digits"}, names)
+}
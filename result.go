@@ -0,0 +1,310 @@
+package comb
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ============================================================================
+// Serializable Parse Results
+//
+
+// Result is the result of a run in CST mode (see RunWithCST).
+// It can be serialized with MarshalResult, so a parser service can be
+// queried by editors (e.g. over RPC) without sharing Go types.
+type Result struct {
+	Tree *SyntaxNode
+	Err  error
+}
+
+// ResultFormat is the serialization format of a Result.
+type ResultFormat int
+
+const (
+	ResultJSON   ResultFormat = iota // JSON with nested nodes including their spans
+	ResultBinary                     // compact binary form (see MarshalResult)
+)
+
+// resultMagic starts every result in binary form.
+// The last byte is the version of the format.
+var resultMagic = []byte{'C', 'M', 'B', 1}
+
+// MarshalResult serializes the syntax tree (including spans) and
+// the error messages of the result.
+//
+// The JSON form is an object with the fields `input`, `tree` and `errors`.
+// Every node of the tree has the fields `kind`, `start` and `end` and
+// either `children` or (for tokens) `text`.
+//
+// The binary form starts with the bytes "CMB" and the version 1.
+// It contains (all numbers are unsigned varints and strings are prefixed by their length):
+//   - the input,
+//   - the table of all kinds (number of kinds and the kinds),
+//   - the nodes in pre-order (index of the kind, width and number of children),
+//   - the error messages (number of errors and the messages).
+//
+// The spans of the nodes are implied by their widths.
+func MarshalResult(result Result, format ResultFormat) ([]byte, error) {
+	if result.Tree == nil {
+		return nil, errors.New("result has no syntax tree")
+	}
+	switch format {
+	case ResultJSON:
+		return json.Marshal(jsonResult{
+			Input:  result.Tree.input,
+			Tree:   newJSONNode(result.Tree),
+			Errors: errorMessages(result.Err),
+		})
+	case ResultBinary:
+		return marshalBinaryResult(result), nil
+	default:
+		return nil, fmt.Errorf("unknown result format: %d", format)
+	}
+}
+
+// UnmarshalResult deserializes a result serialized by MarshalResult.
+// The errors are restored as plain error messages.
+// Corrupt trees (e.g. with negative widths or spans outside of the input)
+// are rejected.
+func UnmarshalResult(data []byte, format ResultFormat) (Result, error) {
+	switch format {
+	case ResultJSON:
+		var jr jsonResult
+		if err := json.Unmarshal(data, &jr); err != nil {
+			return Result{}, err
+		}
+		if jr.Tree == nil {
+			return Result{}, errors.New("result has no syntax tree")
+		}
+		green := jr.Tree.green()
+		if err := checkTree(green, jr.Input); err != nil {
+			return Result{}, err
+		}
+		return Result{
+			Tree: &SyntaxNode{green: green, input: jr.Input},
+			Err:  joinMessages(jr.Errors),
+		}, nil
+	case ResultBinary:
+		return unmarshalBinaryResult(data)
+	default:
+		return Result{}, fmt.Errorf("unknown result format: %d", format)
+	}
+}
+
+// checkTree returns an error if the tree doesn't fit the input.
+// So the spans and texts of all nodes are valid.
+func checkTree(green *GreenNode, input string) error {
+	if green.Width != len(input) {
+		return errors.New("corrupt result: tree doesn't cover the input")
+	}
+	return checkWidths(green)
+}
+
+func checkWidths(g *GreenNode) error {
+	if g.Width < 0 {
+		return errors.New("corrupt result: negative width")
+	}
+	sum := 0
+	for _, child := range g.Children {
+		if err := checkWidths(child); err != nil {
+			return err
+		}
+		sum += child.Width
+	}
+	if sum > g.Width {
+		return errors.New("corrupt result: children are wider than their parent")
+	}
+	return nil
+}
+
+func errorMessages(err error) []string {
+	if err == nil {
+		return nil
+	}
+	errs := UnwrapErrors(err)
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return msgs
+}
+
+func joinMessages(msgs []string) error {
+	errs := make([]error, len(msgs))
+	for i, msg := range msgs {
+		errs[i] = errors.New(msg)
+	}
+	return errors.Join(errs...)
+}
+
+// ============================================================================
+// JSON Form
+//
+
+type jsonResult struct {
+	Input  string    `json:"input"`
+	Tree   *jsonNode `json:"tree"`
+	Errors []string  `json:"errors,omitempty"`
+}
+
+type jsonNode struct {
+	Kind     string      `json:"kind"`
+	Start    int         `json:"start"`
+	End      int         `json:"end"`
+	Text     string      `json:"text,omitempty"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+func newJSONNode(n *SyntaxNode) *jsonNode {
+	span := n.Span()
+	jn := &jsonNode{Kind: n.Kind(), Start: span.Start, End: span.End}
+	if n.IsToken() {
+		jn.Text = n.Text()
+		return jn
+	}
+	for _, child := range n.Children() {
+		jn.Children = append(jn.Children, newJSONNode(child))
+	}
+	return jn
+}
+
+func (jn *jsonNode) green() *GreenNode {
+	g := &GreenNode{Kind: jn.Kind, Width: jn.End - jn.Start}
+	for _, child := range jn.Children {
+		g.Children = append(g.Children, child.green())
+	}
+	return g
+}
+
+// ============================================================================
+// Binary Form
+//
+
+func marshalBinaryResult(result Result) []byte {
+	kinds := make(map[string]int)
+	var kindTable []string
+	var nodes []byte
+	var addNode func(g *GreenNode)
+	addNode = func(g *GreenNode) {
+		k, ok := kinds[g.Kind]
+		if !ok {
+			k = len(kindTable)
+			kinds[g.Kind] = k
+			kindTable = append(kindTable, g.Kind)
+		}
+		nodes = binary.AppendUvarint(nodes, uint64(k))
+		nodes = binary.AppendUvarint(nodes, uint64(g.Width))
+		nodes = binary.AppendUvarint(nodes, uint64(len(g.Children)))
+		for _, child := range g.Children {
+			addNode(child)
+		}
+	}
+	addNode(result.Tree.green)
+
+	data := append([]byte{}, resultMagic...)
+	data = appendString(data, result.Tree.input)
+	data = binary.AppendUvarint(data, uint64(len(kindTable)))
+	for _, kind := range kindTable {
+		data = appendString(data, kind)
+	}
+	data = append(data, nodes...)
+	msgs := errorMessages(result.Err)
+	data = binary.AppendUvarint(data, uint64(len(msgs)))
+	for _, msg := range msgs {
+		data = appendString(data, msg)
+	}
+	return data
+}
+
+func appendString(data []byte, s string) []byte {
+	data = binary.AppendUvarint(data, uint64(len(s)))
+	return append(data, s...)
+}
+
+// binaryReader reads the binary form of a result.
+// The first error stops all further reading.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (br *binaryReader) uvarint() int {
+	if br.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(br.data)
+	if n <= 0 || v > math.MaxInt32 {
+		br.err = errors.New("corrupt binary result: bad number")
+		return 0
+	}
+	br.data = br.data[n:]
+	return int(v)
+}
+
+// count reads the number of following items.
+// Every item needs at least one byte, so corrupt data can't trigger huge allocations.
+func (br *binaryReader) count() int {
+	n := br.uvarint()
+	if n > len(br.data) {
+		br.err = errors.New("corrupt binary result: count too big")
+		return 0
+	}
+	return n
+}
+
+func (br *binaryReader) string() string {
+	n := br.uvarint()
+	if br.err != nil {
+		return ""
+	}
+	if n > len(br.data) {
+		br.err = errors.New("corrupt binary result: string too long")
+		return ""
+	}
+	s := string(br.data[:n])
+	br.data = br.data[n:]
+	return s
+}
+
+func (br *binaryReader) node(kinds []string) *GreenNode {
+	k, width, n := br.uvarint(), br.uvarint(), br.count()
+	if br.err != nil {
+		return nil
+	}
+	if k >= len(kinds) {
+		br.err = errors.New("corrupt binary result: unknown kind")
+		return nil
+	}
+	g := &GreenNode{Kind: kinds[k], Width: width}
+	for i := 0; i < n && br.err == nil; i++ {
+		g.Children = append(g.Children, br.node(kinds))
+	}
+	return g
+}
+
+func unmarshalBinaryResult(data []byte) (Result, error) {
+	if len(data) < len(resultMagic) || string(data[:len(resultMagic)]) != string(resultMagic) {
+		return Result{}, errors.New("not a binary result of version 1")
+	}
+	br := &binaryReader{data: data[len(resultMagic):]}
+	input := br.string()
+	kinds := make([]string, br.count())
+	for i := range kinds {
+		kinds[i] = br.string()
+	}
+	green := br.node(kinds)
+	msgs := make([]string, br.count())
+	for i := range msgs {
+		msgs[i] = br.string()
+	}
+	if br.err != nil {
+		return Result{}, br.err
+	}
+	if err := checkTree(green, input); err != nil {
+		return Result{}, err
+	}
+	return Result{Tree: &SyntaxNode{green: green, input: input}, Err: joinMessages(msgs)}, nil
+}
@@ -0,0 +1,160 @@
+package comb_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalResult(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		input   string
+		format  comb.ResultFormat
+		wantErr bool
+	}{
+		{
+			name:   "JSON without errors",
+			input:  "ab, cd",
+			format: comb.ResultJSON,
+		}, {
+			name:    "JSON with errors",
+			input:   "ab, 12",
+			format:  comb.ResultJSON,
+			wantErr: true,
+		}, {
+			name:   "binary without errors",
+			input:  "ab, cd",
+			format: comb.ResultBinary,
+		}, {
+			name:    "binary with errors",
+			input:   "ab, 12",
+			format:  comb.ResultBinary,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			p := cmb.Suffixed(
+				cmb.Separated1(cmb.Alpha1(), cmb.Delimited(cmb.Whitespace0(), cmb.Char(','), cmb.Whitespace0()), false),
+				cmb.EOF(),
+			)
+			_, tree, err := comb.RunWithCST(tc.input, p)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got error %v, want error: %t", err, tc.wantErr)
+			}
+
+			data, mErr := comb.MarshalResult(comb.Result{Tree: tree, Err: err}, tc.format)
+			if mErr != nil {
+				t.Fatalf("got unexpected error: %v", mErr)
+			}
+			got, uErr := comb.UnmarshalResult(data, tc.format)
+			if uErr != nil {
+				t.Fatalf("got unexpected error: %v", uErr)
+			}
+
+			want := strings.Builder{}
+			printCST(&want, tree, "")
+			sb := strings.Builder{}
+			printCST(&sb, got.Tree, "")
+			assert.Equal(t, want.String(), sb.String())
+			if tc.wantErr {
+				assert.EqualError(t, got.Err, err.Error())
+			} else {
+				assert.NoError(t, got.Err)
+			}
+		})
+	}
+}
+
+func TestMarshalResultJSONSpans(t *testing.T) {
+	t.Parallel()
+
+	_, tree, err := comb.RunWithCST("ab", cmb.Alpha1())
+	assert.NoError(t, err)
+	data, err := comb.MarshalResult(comb.Result{Tree: tree}, comb.ResultJSON)
+	assert.NoError(t, err)
+
+	var got map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, map[string]interface{}{
+		"input": "ab",
+		"tree": map[string]interface{}{
+			"kind": "Root", "start": 0.0, "end": 2.0, "children": []interface{}{
+				map[string]interface{}{"kind": "letter", "start": 0.0, "end": 2.0, "text": "ab"},
+			},
+		},
+	}, got)
+}
+
+func TestUnmarshalResultCorrupt(t *testing.T) {
+	t.Parallel()
+
+	_, tree, _ := comb.RunWithCST("ab", cmb.Alpha1())
+	data, err := comb.MarshalResult(comb.Result{Tree: tree}, comb.ResultBinary)
+	assert.NoError(t, err)
+
+	for i := 0; i < len(data); i++ {
+		_, err = comb.UnmarshalResult(data[:i], comb.ResultBinary)
+		assert.Error(t, err, "truncated to %d bytes", i)
+	}
+}
+
+func TestUnmarshalResultInvalidTree(t *testing.T) {
+	t.Parallel()
+
+	binaryResult := func(rootWidth, childWidth byte) []byte {
+		return []byte{'C', 'M', 'B', 1, 2, 'a', 'b', 1, 1, 'k', 0, rootWidth, 1, 0, childWidth, 0, 0}
+	}
+
+	testCases := []struct {
+		name   string
+		data   []byte
+		format comb.ResultFormat
+	}{
+		{
+			name:   "JSON: negative width",
+			data:   []byte(`{"input":"ab","tree":{"kind":"k","start":0,"end":2,"children":[{"kind":"k","start":2,"end":1}]}}`),
+			format: comb.ResultJSON,
+		}, {
+			name:   "JSON: children wider than parent",
+			data:   []byte(`{"input":"ab","tree":{"kind":"k","start":0,"end":2,"children":[{"kind":"k","start":0,"end":3}]}}`),
+			format: comb.ResultJSON,
+		}, {
+			name:   "JSON: tree shorter than input",
+			data:   []byte(`{"input":"abc","tree":{"kind":"k","start":0,"end":2}}`),
+			format: comb.ResultJSON,
+		}, {
+			name:   "binary: children wider than parent",
+			data:   binaryResult(2, 3),
+			format: comb.ResultBinary,
+		}, {
+			name:   "binary: tree longer than input",
+			data:   binaryResult(3, 1),
+			format: comb.ResultBinary,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := comb.UnmarshalResult(tc.data, tc.format)
+			assert.ErrorContains(t, err, "corrupt result")
+		})
+	}
+
+	_, err := comb.UnmarshalResult(binaryResult(2, 1), comb.ResultBinary)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,133 @@
+package comb
+
+import (
+	"context"
+	"unsafe"
+)
+
+// ============================================================================
+// Running a Parser With Options
+//
+
+// RunOption configures a run of a parser (see Run).
+type RunOption func(State) State
+
+// Run runs a parser on text input configured by the options and returns
+// the output and error(s).
+// Without options it is the same as RunOnString.
+//
+// Example:
+//
+//	out, err := comb.Run(input, parser, comb.WithMaxErrors(50), comb.WithContext(ctx))
+func Run[Output any](input string, parser Parser[Output], opts ...RunOption) (Output, error) {
+	state := NewFromString(input, DefaultMaxErrors)
+	for _, opt := range opts {
+		state = opt(state)
+	}
+	return RunOnState[Output](state, NewPreparedParser(parser))
+}
+
+// WithMaxErrors sets the maximum number of errors to recover from
+// (see State.WithMaxErrors).
+func WithMaxErrors(maxErrors int) RunOption {
+	return func(st State) State {
+		return st.WithMaxErrors(maxErrors)
+	}
+}
+
+// WithFailFast turns error recovery off (see State.WithFailFast).
+func WithFailFast() RunOption {
+	return func(st State) State {
+		return st.WithFailFast()
+	}
+}
+
+// WithTracer calls `handle` for every parser invocation of the run
+// (including error recovery) after the parser has finished.
+// Only parsers called via ParseAny are traced (all standard combinators do that).
+// The steps are the same as the ones recorded by RecordRun.
+func WithTracer(handle func(TraceStep)) RunOption {
+	if handle == nil {
+		panic("WithTracer is unable to handle a nil `handle` function")
+	}
+	return func(st State) State {
+		constant := *st.constant
+		constant.tracer = &tracer{handle: handle}
+		st.constant = &constant
+		return st
+	}
+}
+
+// WithContext cancels the run if the context is done (see State.WithContext).
+func WithContext(ctx context.Context) RunOption {
+	return func(st State) State {
+		return st.WithContext(ctx)
+	}
+}
+
+// WithZeroCopy shares the memory of the text input with the byte slices
+// given to parsers (see State.WithZeroCopy).
+func WithZeroCopy() RunOption {
+	return func(st State) State {
+		return st.WithZeroCopy()
+	}
+}
+
+// WithByteIndex indexes the positions of the bytes in the input up front
+// to make error recovery fast (see State.WithByteIndex).
+func WithByteIndex(bs ...byte) RunOption {
+	return func(st State) State {
+		return st.WithByteIndex(bs...)
+	}
+}
+
+// WithArena allocates the outputs of Alloc in the arena (see State.WithArena).
+func WithArena(a *Arena) RunOption {
+	return func(st State) State {
+		return st.WithArena(a)
+	}
+}
+
+// WithContext returns the state with a context that cancels parsing.
+// If the context is done, all parsers fail with a fatal error wrapping
+// the error of the context (e.g. context.Canceled).
+// It has to be called before parsing starts.
+func (st State) WithContext(ctx context.Context) State {
+	constant := *st.constant
+	constant.ctx = ctx
+	st.constant = &constant
+	return st
+}
+
+// canceled returns a fatal error if the context of the run is done
+// or the time budget of the current subtree is used up (see WithTimeout).
+func (st State) canceled() *ParserError {
+	if st.budget.expired() {
+		return MarkFatal(st.NewSemanticError("%w", ErrTimeout))
+	}
+	if st.constant.ctx == nil {
+		return nil
+	}
+	select {
+	case <-st.constant.ctx.Done():
+		return MarkFatal(st.NewSemanticError("parsing canceled: %w", st.constant.ctx.Err()))
+	default:
+		return nil
+	}
+}
+
+// WithZeroCopy returns the state with the text input shared with
+// the byte slices given to parsers (e.g. by CurrentBytes).
+// So the text isn't copied for byte oriented parsers.
+// The byte slices must never be modified because strings are immutable.
+// It does nothing for binary input.
+// It has to be called before parsing starts.
+func (st State) WithZeroCopy() State {
+	if st.constant.binary || st.constant.n == 0 {
+		return st
+	}
+	constant := *st.constant
+	constant.bytes = unsafe.Slice(unsafe.StringData(constant.text), len(constant.text))
+	st.constant = &constant
+	return st
+}
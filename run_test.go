@@ -0,0 +1,92 @@
+package comb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[[]string] {
+		return cmb.Suffixed(cmb.Many1(cmb.Suffixed(cmb.Alpha1(), comb.SafeSpot(cmb.Char(';')))), cmb.EOF())
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name       string
+		input      string
+		opts       []comb.RunOption
+		wantErrs   int
+		wantOutput []string
+		wantCancel bool
+	}{
+		{
+			name:       "no options should work like RunOnString",
+			input:      "ab;cd;",
+			wantOutput: []string{"ab", "cd"},
+		}, {
+			name:     "errors should be recovered from by default",
+			input:    "ab;12;cd;34;",
+			wantErrs: 2,
+		}, {
+			name:     "fail fast should stop at the first error",
+			input:    "ab;12;cd;34;",
+			opts:     []comb.RunOption{comb.WithFailFast()},
+			wantErrs: 1,
+		}, {
+			name:     "max errors should abort parsing",
+			input:    "ab;12;cd;34;",
+			opts:     []comb.RunOption{comb.WithMaxErrors(1)},
+			wantErrs: 2, // including ErrTooManyErrors
+		}, {
+			name:       "zero copy should not change the result",
+			input:      "ab;cd;",
+			opts:       []comb.RunOption{comb.WithZeroCopy()},
+			wantOutput: []string{"ab", "cd"},
+		}, {
+			name:       "a canceled context should stop parsing",
+			input:      "ab;cd;",
+			opts:       []comb.RunOption{comb.WithContext(canceled)},
+			wantErrs:   1,
+			wantCancel: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := comb.Run(tc.input, newParser(), tc.opts...)
+			assert.Len(t, comb.UnwrapErrors(err), tc.wantErrs)
+			assert.Equal(t, tc.wantCancel, errors.Is(err, context.Canceled))
+			if tc.wantOutput != nil {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+			}
+		})
+	}
+}
+
+func TestRunWithTracer(t *testing.T) {
+	t.Parallel()
+
+	var steps []comb.TraceStep
+	out, err := comb.Run("ab;cd;", cmb.Many1(cmb.Suffixed(cmb.Alpha1(), cmb.Char(';'))),
+		comb.WithTracer(func(step comb.TraceStep) {
+			steps = append(steps, step)
+		}))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ab", "cd"}, out)
+	if assert.NotEmpty(t, steps) {
+		last := steps[len(steps)-1]
+		assert.Equal(t, 0, last.Start)
+		assert.Equal(t, 6, last.End)
+	}
+}
@@ -0,0 +1,172 @@
+package comb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Selecting Nodes Of A CST With Paths
+//
+
+// selectStep is a single step of a path (see Select).
+type selectStep struct {
+	descendants bool   // `..` searches all descendants instead of the children only
+	anyKind     bool   // `*` or a bare index matches nodes of any kind
+	kind        string // kind of the nodes to match (if not anyKind)
+	hasIndex    bool
+	index       int // index of the node among the matches of each parent (negative counts from the end)
+}
+
+// Select returns all nodes of the CST (see RunWithCST) that are matched by
+// the JSONPath like path.
+// So tools and one-off scripts can extract values without writing a visitor.
+// The path is a sequence of steps relative to the node:
+//
+//   - `$` at the start of the path is the node itself (and can be omitted).
+//   - `.kind` matches the children of the kind (Expected() of the parser;
+//     use cmb.Label to name rules).
+//   - `..kind` matches the descendants of the kind at any depth.
+//   - `*` instead of a kind matches nodes of any kind except uncovered text.
+//   - `["kind"]` is the same as `.kind` for kinds containing '.' or '['
+//     (quoted like Go strings); `..["kind"]` works, too.
+//   - `[n]` after a kind only keeps the n-th match (starting at 0) of each parent;
+//     negative indexes count from the end. A bare `[n]` matches the n-th child.
+//
+// Example: `$..member[0].value` or `..["','"]`.
+// The nodes are returned in the order of the input.
+func Select(node *SyntaxNode, path string) ([]*SyntaxNode, error) {
+	steps, err := parseSelectPath(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*SyntaxNode{node}
+	for _, step := range steps {
+		nodes = step.apply(nodes)
+	}
+	return nodes, nil
+}
+
+func (step selectStep) apply(nodes []*SyntaxNode) []*SyntaxNode {
+	type nodeKey struct {
+		green  *GreenNode
+		offset int
+	}
+	var result []*SyntaxNode
+	seen := make(map[nodeKey]bool) // nested matches of descendants would be found more than once
+	for _, node := range nodes {
+		var matches []*SyntaxNode
+		step.collect(node, &matches)
+		if step.hasIndex {
+			i := step.index
+			if i < 0 {
+				i += len(matches)
+			}
+			if i < 0 || i >= len(matches) {
+				continue
+			}
+			matches = matches[i : i+1]
+		}
+		for _, m := range matches {
+			key := nodeKey{green: m.green, offset: m.offset}
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, m)
+			}
+		}
+	}
+	return result
+}
+
+func (step selectStep) collect(node *SyntaxNode, matches *[]*SyntaxNode) {
+	for _, child := range node.Children() {
+		if step.anyKind && child.Kind() != "" || !step.anyKind && child.Kind() == step.kind {
+			*matches = append(*matches, child)
+		}
+		if step.descendants {
+			step.collect(child, matches)
+		}
+	}
+}
+
+// parseSelectPath parses a path for Select into its steps.
+func parseSelectPath(path string) ([]selectStep, error) {
+	var steps []selectStep
+	pos := 0
+	if strings.HasPrefix(path, "$") {
+		pos++
+	}
+	for pos < len(path) {
+		step := selectStep{}
+		switch {
+		case strings.HasPrefix(path[pos:], ".."):
+			step.descendants = true
+			pos += 2
+		case path[pos] == '.':
+			pos++
+		case strings.HasPrefix(path[pos:], `["`): // quoted kind of the children
+		case path[pos] == '[':
+			n, size, err := parseSelectIndex(path, pos)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, selectStep{anyKind: true, hasIndex: true, index: n})
+			pos += size
+			continue
+		default:
+			return nil, fmt.Errorf("invalid path %q: expected '.' or '[' at position %d", path, pos)
+		}
+
+		// the kind of the step:
+		switch {
+		case strings.HasPrefix(path[pos:], "*"):
+			step.anyKind = true
+			pos++
+		case strings.HasPrefix(path[pos:], `["`):
+			quoted, err := strconv.QuotedPrefix(path[pos+1:])
+			if err != nil || !strings.HasPrefix(path[pos+1+len(quoted):], "]") {
+				return nil, fmt.Errorf("invalid path %q: expected quoted kind and ']' at position %d", path, pos+1)
+			}
+			step.kind, _ = strconv.Unquote(quoted)
+			pos += 1 + len(quoted) + 1
+		default:
+			end := strings.IndexAny(path[pos:], ".[")
+			if end < 0 {
+				end = len(path) - pos
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %q: expected kind at position %d", path, pos)
+			}
+			step.kind = path[pos : pos+end]
+			pos += end
+		}
+
+		// the optional index of the step:
+		if strings.HasPrefix(path[pos:], "[") && !strings.HasPrefix(path[pos:], `["`) {
+			n, size, err := parseSelectIndex(path, pos)
+			if err != nil {
+				return nil, err
+			}
+			step.hasIndex = true
+			step.index = n
+			pos += size
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// parseSelectIndex parses an index like `[-1]` at the position of the path.
+// It returns the index and the number of bytes used.
+func parseSelectIndex(path string, pos int) (int, int, error) {
+	end := strings.IndexByte(path[pos:], ']')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("invalid path %q: missing ']' for '[' at position %d", path, pos)
+	}
+	n, err := strconv.Atoi(path[pos+1 : pos+end])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid path %q: expected index at position %d", path, pos+1)
+	}
+	return n, end + 1, nil
+}
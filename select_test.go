@@ -0,0 +1,93 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	p := cmb.Prefixed(cmb.Whitespace0(), cmb.Separated1(
+		cmb.Prefixed(cmb.Whitespace0(), cmb.Label(cmb.Alpha1(), "word")),
+		cmb.Delimited(cmb.Whitespace0(), cmb.Char(','), cmb.Whitespace0()),
+		false,
+	))
+	_, root, err := comb.RunWithCST(" ab , cd,ef", p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		wantErr  bool
+		wantText []string
+	}{
+		{
+			name:     "descendants should be found at any depth",
+			path:     "$..word",
+			wantText: []string{"ab", "cd", "ef"},
+		}, {
+			name:     "index should select among the matches",
+			path:     "..word[1]",
+			wantText: []string{"cd"},
+		}, {
+			name:     "children should be matched step by step",
+			path:     "$.Prefixed.SeparatedMN.Prefixed[-1].word",
+			wantText: []string{"ef"},
+		}, {
+			name:     "quoted kind should be matched",
+			path:     `..Delimited["','"]`,
+			wantText: []string{",", ","},
+		}, {
+			name:     "bare indexes should select children",
+			path:     "$[0][0]",
+			wantText: []string{" "},
+		}, {
+			name:     "wildcard should match all kinds",
+			path:     "$.Prefixed.SeparatedMN.*",
+			wantText: []string{"ab", " , ", "cd", ",", "ef"},
+		}, {
+			name:     "unknown kind should match nothing",
+			path:     "..number",
+			wantText: nil,
+		}, {
+			name:    "missing kind should fail",
+			path:    "$.",
+			wantErr: true,
+		}, {
+			name:    "invalid index should fail",
+			path:    "..word[x]",
+			wantErr: true,
+		}, {
+			name:    "unterminated quoted kind should fail",
+			path:    `..["word"`,
+			wantErr: true,
+		}, {
+			name:    "missing dot should fail",
+			path:    "word",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			nodes, err := comb.Select(root, tc.path)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tc.wantErr)
+			}
+			var gotText []string
+			for _, node := range nodes {
+				gotText = append(gotText, node.Text())
+			}
+			assert.Equal(t, tc.wantText, gotText)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package serve exposes grammars of the global registry (see comb.Register)
+// as an HTTP parse service.
+// So teams can centralize one canonical grammar implementation and
+// editors or other tools can use it without sharing Go types.
+//
+// A request POSTs the input as body to `/{name}` (name of the registered parser).
+// The response is JSON with the output, the errors (with line and column)
+// and the spans of all tokens for highlighting.
+package serve
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/flowdev/comb"
+)
+
+const DefaultMaxInputSize = 1 << 20     // 1 MiB
+const DefaultTimeout = 10 * time.Second // for parsing a single input
+
+// Options configure the parse service.
+// Zero values are replaced by the defaults.
+type Options struct {
+	MaxInputSize int64         // maximum size of the input in bytes
+	Timeout      time.Duration // maximum time for handling a single request
+	MaxErrors    int           // maximum number of errors to recover from
+	FailFast     bool          // turns error recovery off (MaxErrors is ignored then)
+}
+
+// Response is the JSON body of a successful request.
+type Response struct {
+	Output     json.RawMessage `json:"output,omitempty"`
+	Errors     []Error         `json:"errors,omitempty"`
+	Highlights []Highlight     `json:"highlights,omitempty"`
+}
+
+// Error is a single error of the parser.
+type Error struct {
+	Message string `json:"message"`
+	Pos     int    `json:"pos"`
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+}
+
+// Highlight is the span of a token of the input.
+// Kind is the Expected() of the parser that parsed the token.
+type Highlight struct {
+	Kind  string `json:"kind"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// NewHandler returns an HTTP handler that parses the body of POST requests
+// to `/{name}` with the parser registered under the name.
+// Every parser is prepared when it is requested for the first time
+// (once for all handlers).
+// So registered parsers must not be prepared (or used) elsewhere,
+// and they must not share sub-parsers with each other.
+//
+// NOTE:
+//   - Requests that take longer than the timeout are answered with
+//     `503 Service Unavailable`, and the parser is canceled.
+//   - Inputs that are too big are answered with `413 Request Entity Too Large`.
+//   - Grammars that share parsers with another grammar are answered with
+//     `500 Internal Server Error`.
+//   - Outputs that can't be marshaled to JSON are left out.
+func NewHandler(opts Options) http.Handler {
+	if opts.MaxInputSize <= 0 {
+		opts.MaxInputSize = DefaultMaxInputSize
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.FailFast {
+		opts.MaxErrors = comb.FailFast
+	} else if opts.MaxErrors <= 0 {
+		opts.MaxErrors = comb.DefaultMaxErrors
+	}
+	s := &server{opts: opts}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{name}", s.parse)
+	return http.TimeoutHandler(mux, opts.Timeout, "parsing took too long")
+}
+
+type server struct {
+	opts Options
+}
+
+// prepared holds the prepared parsers of all handlers.
+// A parser can only be prepared once.
+var prepared = struct {
+	sync.Mutex
+	parsers map[string]*comb.PreparedParser[interface{}]
+}{parsers: make(map[string]*comb.PreparedParser[interface{}])}
+
+func preparedParser(name string) (*comb.PreparedParser[interface{}], bool) {
+	prepared.Lock()
+	defer prepared.Unlock()
+	if pp, ok := prepared.parsers[name]; ok {
+		return pp, true
+	}
+	p, ok := comb.Lookup(name)
+	if !ok {
+		return nil, false
+	}
+	pp := comb.NewPreparedAnyParser(p)
+	prepared.parsers[name] = pp
+	return pp, true
+}
+
+func (s *server) parse(w http.ResponseWriter, r *http.Request) {
+	pp, ok := preparedParser(r.PathValue("name"))
+	if !ok {
+		http.Error(w, "unknown grammar", http.StatusNotFound)
+		return
+	}
+	if pp.SharesParsers() {
+		http.Error(w, "grammar shares parsers with another grammar", http.StatusInternalServerError)
+		return
+	}
+	input, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.opts.MaxInputSize))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			http.Error(w, "input too big", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "unable to read input", http.StatusBadRequest)
+		return
+	}
+
+	state := comb.NewFromString(string(input), s.opts.MaxErrors).WithContext(r.Context())
+	out, tree, err := comb.RunOnStateWithCST(state, pp)
+	resp := Response{Errors: toErrors(err), Highlights: highlights(tree, nil)}
+	if out != nil {
+		if data, jErr := json.Marshal(out); jErr == nil {
+			resp.Output = data
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func toErrors(err error) []Error {
+	var errs []Error
+	for _, e := range comb.UnwrapErrors(err) {
+		pe := Error{Message: e.Error()}
+		if pos, ok := comb.PositionOf(e); ok {
+			pe.Pos, pe.Line, pe.Column = pos.Pos, pos.Line, pos.Column
+		}
+		errs = append(errs, pe)
+	}
+	return errs
+}
+
+func highlights(node *comb.SyntaxNode, hls []Highlight) []Highlight {
+	if node.IsToken() {
+		if node.Kind() != "" && node.Kind() != comb.RootKind {
+			span := node.Span()
+			hls = append(hls, Highlight{Kind: node.Kind(), Start: span.Start, End: span.End})
+		}
+		return hls
+	}
+	for _, child := range node.Children() {
+		hls = highlights(child, hls)
+	}
+	return hls
+}
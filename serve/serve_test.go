@@ -0,0 +1,185 @@
+package serve_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/flowdev/comb/serve"
+	"github.com/stretchr/testify/assert"
+)
+
+var slowSteps atomic.Int32
+
+func init() {
+	comb.Register("serve.test.words", cmb.Suffixed(
+		cmb.Separated1(cmb.Alpha1(), cmb.Delimited(cmb.Whitespace0(), cmb.Char(','), cmb.Whitespace0()), false),
+		cmb.EOF(),
+	))
+
+	number := cmb.Digit1()
+	comb.Register("serve.test.sum", cmb.Separated1(number, cmb.Char('+'), false))
+	comb.Register("serve.test.product", cmb.Separated1(number, cmb.Char('*'), false))
+
+	comb.Register("serve.test.slow", cmb.Many0(comb.NewParser[rune]("slow char", func(state comb.State) (comb.State, rune, *comb.ParserError) {
+		time.Sleep(5 * time.Millisecond)
+		slowSteps.Add(1)
+		if state.AtEnd() {
+			return state, 0, state.NewSyntaxError("slow char")
+		}
+		return state.MoveBy(1), 'x', nil
+	}, nil)))
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	handler := serve.NewHandler(serve.Options{MaxInputSize: 16, Timeout: time.Minute})
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		input        string
+		wantStatus   int
+		wantResponse *serve.Response
+	}{
+		{
+			name:       "valid input",
+			method:     http.MethodPost,
+			path:       "/serve.test.words",
+			input:      "ab, cd",
+			wantStatus: http.StatusOK,
+			wantResponse: &serve.Response{
+				Output: json.RawMessage(`["ab","cd"]`),
+				Highlights: []serve.Highlight{
+					{Kind: "letter", Start: 0, End: 2},
+					{Kind: "','", Start: 2, End: 3},
+					{Kind: "whitespace", Start: 3, End: 4},
+					{Kind: "letter", Start: 4, End: 6},
+				},
+			},
+		}, {
+			name:       "invalid input",
+			method:     http.MethodPost,
+			path:       "/serve.test.words",
+			input:      "ab\n1",
+			wantStatus: http.StatusOK,
+			wantResponse: &serve.Response{
+				Output: json.RawMessage(`["ab"]`),
+				Errors: []serve.Error{{
+					Message: `unexpected "\n1" before end of the input (still 2 bytes of input left) (deleted) [1:3] ab▶`,
+					Pos:     2, Line: 1, Column: 3,
+				}},
+			},
+		}, {
+			name:       "unknown grammar",
+			method:     http.MethodPost,
+			path:       "/serve.test.unknown",
+			wantStatus: http.StatusNotFound,
+		}, {
+			name:       "wrong method",
+			method:     http.MethodGet,
+			path:       "/serve.test.words",
+			wantStatus: http.StatusMethodNotAllowed,
+		}, {
+			name:       "input too big",
+			method:     http.MethodPost,
+			path:       "/serve.test.words",
+			input:      strings.Repeat("a", 17),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.input)))
+			assert.Equal(t, tc.wantStatus, rec.Code, rec.Body.String())
+			if tc.wantResponse == nil {
+				return
+			}
+			var got serve.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			assert.Equal(t, *tc.wantResponse, got)
+		})
+	}
+}
+
+func TestHandlerRejectsSharedParsers(t *testing.T) {
+	t.Parallel()
+
+	handler := serve.NewHandler(serve.Options{})
+	statuses := make(map[int]int)
+	for _, path := range []string{"/serve.test.sum", "/serve.test.product"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, strings.NewReader("1")))
+		statuses[rec.Code]++
+	}
+	assert.Equal(t, map[int]int{http.StatusOK: 1, http.StatusInternalServerError: 1}, statuses)
+}
+
+func TestHandlerCancelsParsing(t *testing.T) {
+	t.Parallel()
+
+	handler := serve.NewHandler(serve.Options{Timeout: 20 * time.Millisecond})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/serve.test.slow", strings.NewReader(strings.Repeat("a", 100))))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	time.Sleep(700 * time.Millisecond) // parsing everything takes at least 500ms
+	assert.Less(t, slowSteps.Load(), int32(50), "parsing should stop after the timeout")
+}
+
+func TestHandlerFailFast(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		opts       serve.Options
+		wantErrors []serve.Error
+	}{
+		{
+			name: "default recovers from errors",
+			opts: serve.Options{},
+			wantErrors: []serve.Error{{
+				Message: `unexpected ",1,cd,2" before end of the input (still 7 bytes of input left) (deleted) [1:3] ab▶,1,cd,2`,
+				Pos:     2, Line: 1, Column: 3,
+			}},
+		}, {
+			name: "fail fast stops at the first error",
+			opts: serve.Options{FailFast: true},
+			wantErrors: []serve.Error{{
+				Message: `expected end of the input (still 7 bytes of input left) [1:3] ab▶,1,cd,2`,
+				Pos:     2, Line: 1, Column: 3,
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := httptest.NewRecorder()
+			serve.NewHandler(tc.opts).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/serve.test.words", strings.NewReader("ab,1,cd,2")))
+			assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			var got serve.Response
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			assert.Equal(t, tc.wantErrors, got.Errors)
+		})
+	}
+}
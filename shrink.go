@@ -0,0 +1,80 @@
+package comb
+
+import (
+	"log"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ShrinkInput is a test helper that reduces an input that makes the parser fail
+// to a minimal input that still triggers the same error (reported by the same parser).
+// It uses delta debugging: first whole lines and then single characters are removed.
+// The minimal input is printed (using `log.Printf`) and returned together
+// with its error for easy bug reports.
+// If the parser doesn't fail on the input at all, the input is returned
+// unchanged with a nil error.
+// The parser is run without error recovery.
+func ShrinkInput[Output any](p Parser[Output], input string) (string, error) {
+	pp := NewPreparedParser(p)
+	run := func(input string) *ParserError {
+		_, _, err := pp.parsers[0].ParseAny(ParentUnknown, NewFromString(input, FailFast))
+		return err
+	}
+
+	origErr := run(input)
+	if origErr == nil {
+		return input, nil
+	}
+	fails := func(units []string) bool {
+		err := run(strings.Join(units, ""))
+		return err != nil && err.parserID == origErr.parserID
+	}
+
+	units := shrinkUnits(strings.SplitAfter(input, "\n"), fails)
+	units = shrinkUnits(splitRunes(strings.Join(units, "")), fails)
+	shrunk := strings.Join(units, "")
+	err := run(shrunk)
+	log.Printf("ShrinkInput: minimal input %q fails with: %v", shrunk, err)
+	return shrunk, err
+}
+
+// shrinkUnits removes as many units as possible while fails stays true.
+// It is the `ddmin` algorithm of delta debugging reduced to complements:
+// chunks of decreasing size are removed until single units can't be removed anymore.
+func shrinkUnits(units []string, fails func([]string) bool) []string {
+	if len(units) > 0 && fails(nil) {
+		return nil
+	}
+	n := 2 // number of chunks
+	for len(units) >= 2 {
+		size := (len(units) + n - 1) / n
+		reduced := false
+		for start := 0; start < len(units); start += size {
+			candidate := slices.Concat(units[:start], units[min(start+size, len(units)):])
+			if fails(candidate) {
+				units = candidate
+				n = max(n-1, 2)
+				reduced = true
+				break
+			}
+		}
+		if !reduced {
+			if n >= len(units) {
+				break
+			}
+			n = min(n*2, len(units))
+		}
+	}
+	return units
+}
+
+func splitRunes(s string) []string {
+	units := make([]string, 0, utf8.RuneCountInString(s))
+	for len(s) > 0 {
+		_, size := utf8.DecodeRuneInString(s)
+		units = append(units, s[:size])
+		s = s[size:]
+	}
+	return units
+}
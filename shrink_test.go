@@ -0,0 +1,51 @@
+package comb_test
+
+import (
+	"testing"
+	"unicode"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShrinkInput(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[[]rune] {
+		noDigit := cmb.Satisfy("no digit", func(r rune) bool {
+			return !unicode.IsDigit(r)
+		})
+		return cmb.Suffixed(cmb.Many0(noDigit), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "no error",
+			input: "ab;cd;",
+			want:  "ab;cd;",
+		}, {
+			name:    "error in the middle",
+			input:   "ab;cd;\nef;1g;\nhi;",
+			want:    "1",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := comb.ShrinkInput(newParser(), tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", err, tc.wantErr)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package comb
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ============================================================================
+// Dispatching On Magic Numbers Of File Formats
+//
+
+// SniffDispatch peeks at the leading bytes of the input and dispatches to the
+// parser registered for the magic number (e.g. "\x89PNG\r\n\x1a\n" or "%PDF-")
+// found there.
+// The longest matching magic number wins. The empty magic number matches
+// any input and can be used for a fallback format.
+// No input is consumed by SniffDispatch itself, so the format parsers see
+// (and should parse) the magic number, too.
+// All parsers are known in advance, so error recovery works as usual
+// inside of them.
+// An unknown format is reported with the leading bytes of the input
+// and the known magic numbers.
+func SniffDispatch[Output any](formats map[string]Parser[Output]) Parser[Output] {
+	if len(formats) == 0 {
+		panic("SniffDispatch: no formats given")
+	}
+	sd := &sniffData[Output]{formats: formats}
+	for magic := range formats {
+		sd.magics = append(sd.magics, magic)
+		sd.maxLen = max(sd.maxLen, len(magic))
+	}
+	slices.Sort(sd.magics) // the order of the children has to be stable
+	hexMagics := make([]string, len(sd.magics))
+	for i, magic := range sd.magics {
+		hexMagics[i] = fmt.Sprintf("%#x", magic)
+	}
+	sd.known = strings.Join(hexMagics, ", ")
+
+	p := NewBranchParser[Output]("SniffDispatch", sd.children, sd.parseAfterChild)
+	sd.id = p.ID
+	return p
+}
+
+type sniffData[Output any] struct {
+	id      func() int32
+	formats map[string]Parser[Output]
+	magics  []string // sorted magic numbers
+	maxLen  int      // length of the longest magic number
+	known   string   // magic numbers in hex for error messages
+}
+
+func (sd *sniffData[Output]) children() []AnyParser {
+	children := make([]AnyParser, 0, len(sd.magics))
+	for _, magic := range sd.magics {
+		children = append(children, sd.formats[magic])
+	}
+	return children
+}
+
+func (sd *sniffData[Output]) parseAfterChild(
+	childID int32,
+	childStartState, childState State,
+	childOut interface{},
+	childErr *ParserError,
+	data interface{},
+) (State, Output, *ParserError, interface{}) {
+	Debugf("SniffDispatch.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+
+	if childID >= 0 { // on the way up from a format parser
+		out, _ := childOut.(Output)
+		return childState, out, childErr, nil
+	}
+
+	input := childState.CurrentBytes()
+	best, incomplete := -1, false
+	for i, magic := range sd.magics {
+		switch {
+		case bytes.HasPrefix(input, []byte(magic)):
+			if best < 0 || len(magic) > len(sd.magics[best]) {
+				best = i
+			}
+		case len(input) < len(magic) && strings.HasPrefix(magic, string(input)):
+			incomplete = true
+		}
+	}
+	if best < 0 {
+		saw := "EOF"
+		if len(input) > 0 {
+			saw = fmt.Sprintf("%#x", input[:min(len(input), sd.maxLen)])
+		}
+		err := childState.NewSyntaxError(
+			"magic number of a known format (one of %s); unknown format, saw %s", sd.known, saw,
+		)
+		if incomplete {
+			err = MarkIncomplete(err)
+		}
+		return childState, ZeroOf[Output](), err, nil
+	}
+	nState, aOut, err := sd.formats[sd.magics[best]].ParseAny(sd.id(), childState)
+	out, _ := aOut.(Output)
+	return nState, out, err, nil
+}
@@ -0,0 +1,100 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSniffDispatch(t *testing.T) {
+	t.Parallel()
+
+	named := func(p comb.Parser[[]byte], name string) comb.Parser[string] {
+		return cmb.Map(p, func([]byte) (string, error) {
+			return name, nil
+		})
+	}
+	format := func(magic, name string) comb.Parser[string] {
+		return named(cmb.Bytes([]byte(magic)), name)
+	}
+	newParser := func(withFallback bool) comb.Parser[string] {
+		formats := map[string]comb.Parser[string]{
+			"\x89PNG": format("\x89PNG", "png"),
+			"GIF8":    format("GIF8", "gif"),
+			"GIF89a":  format("GIF89a", "gif89a"),
+		}
+		if withFallback {
+			formats[""] = named(cmb.Padding(0), "raw")
+		}
+		return comb.SniffDispatch(formats)
+	}
+
+	testCases := []struct {
+		name           string
+		input          string
+		withFallback   bool
+		wantErr        string
+		wantIncomplete bool
+		wantOutput     string
+		wantPos        int
+	}{
+		{
+			name:       "known magic number should dispatch",
+			input:      "\x89PNG\r\n",
+			wantOutput: "png",
+			wantPos:    4,
+		}, {
+			name:       "longest magic number should win",
+			input:      "GIF89a...",
+			wantOutput: "gif89a",
+			wantPos:    6,
+		}, {
+			name:       "shorter magic number should match, too",
+			input:      "GIF87a...",
+			wantOutput: "gif",
+			wantPos:    4,
+		}, {
+			name:    "unknown magic number should fail without consuming input",
+			input:   "\x00\x01\x02\x03\x04\x05\x06\x07",
+			wantErr: "expected magic number of a known format (one of 0x47494638, 0x474946383961, 0x89504e47); unknown format, saw 0x000102030405",
+		}, {
+			name:           "partial magic number should be incomplete",
+			input:          "GIF",
+			wantErr:        "unknown format, saw 0x474946",
+			wantIncomplete: true,
+		}, {
+			name:           "empty input should be incomplete",
+			input:          "",
+			wantErr:        "unknown format, saw EOF",
+			wantIncomplete: true,
+		}, {
+			name:         "fallback should be used for unknown formats",
+			input:        "\x00\x01",
+			withFallback: true,
+			wantOutput:   "raw",
+			wantPos:      0,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			nState, gotOutput, err := newParser(tc.withFallback).Parse(comb.NewFromBytes([]byte(tc.input), 10))
+			if tc.wantErr != "" {
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), tc.wantErr)
+					assert.Equal(t, tc.wantIncomplete, err.Incomplete())
+				}
+				assert.Equal(t, 0, nState.CurrentPos())
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.wantOutput, gotOutput)
+			assert.Equal(t, tc.wantPos, nState.CurrentPos())
+		})
+	}
+}
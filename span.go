@@ -0,0 +1,49 @@
+package comb
+
+// ============================================================================
+// Span Of The Input
+//
+
+// Span is a part of the input given by byte positions.
+// Start is inclusive and End is exclusive.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Len returns the number of bytes in the span.
+func (s Span) Len() int {
+	return s.End - s.Start
+}
+
+// SpanTo returns the span of the input between this state and the remaining one.
+func (st State) SpanTo(remaining State) Span {
+	if remaining.pos < st.pos {
+		return Span{Start: st.pos, End: st.pos}
+	}
+	return Span{Start: st.pos, End: remaining.pos}
+}
+
+// StringOf returns the part of the input covered by the span.
+func (st State) StringOf(span Span) string {
+	start, end := max(0, span.Start), min(span.End, st.constant.n)
+	if start >= end {
+		return ""
+	}
+	if st.constant.binary && len(st.constant.text) < st.constant.n {
+		st.constant.text = string(st.constant.bytes)
+	}
+	return st.constant.text[start:end]
+}
+
+// BytesOf returns the part of the input covered by the span.
+func (st State) BytesOf(span Span) []byte {
+	start, end := max(0, span.Start), min(span.End, st.constant.n)
+	if start >= end {
+		return []byte{}
+	}
+	if !st.constant.binary && len(st.constant.bytes) < st.constant.n {
+		st.constant.bytes = []byte(st.constant.text)
+	}
+	return st.constant.bytes[start:end]
+}
@@ -1,8 +1,10 @@
 package comb
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"unicode/utf8"
 )
@@ -13,12 +15,19 @@ import (
 
 // State represents the current state of a parser.
 type State struct {
-	constant *ConstState
-	pos      int     // current position in the input a.k.a. the *byte* index
-	prevNl   int     // position of the newline preceding 'pos' (-1 for line==1)
-	line     int     // current line number
-	safeSpot int     // mark set by the SafeSpot parser
-	errors   []error // errors that have been handled
+	constant  *ConstState
+	pos       int              // current position in the input a.k.a. the *byte* index
+	prevNl    int              // position of the newline preceding 'pos' (-1 for line==1)
+	line      int              // current line number
+	safeSpot  int              // mark set by the SafeSpot parser
+	errors    []error          // errors that have been handled
+	deferred  []deferredFn     // functions postponed until the whole input has been parsed
+	skip      AnyParser        // active skip parser for Lexeme parsers (see WithLexeme)
+	modes     []string         // stack of entered modes (see EnterMode)
+	warnings  []error          // warnings reported at the end of a run (see Warn)
+	actions   *actionEntry     // semantic actions done (see Action)
+	budget    *timeBudget      // time budget of the current subtree (see WithTimeout)
+	byteOrder binary.ByteOrder // byte order of binary numbers (nil means big endian; see WithByteOrder)
 }
 
 // ============================================================================
@@ -33,6 +42,41 @@ func (st State) BytesRemaining() int {
 	return st.constant.n - st.pos
 }
 
+// IsBinary returns true if the state has been created from bytes
+// (see NewFromBytes).
+// Parsers can use CurrentBytes instead of CurrentString for binary input
+// to avoid converting the whole input.
+func (st State) IsBinary() bool {
+	return st.constant.binary
+}
+
+// IsLatin1 returns true if every byte of the text input is a character
+// (see WithLatin1).
+func (st State) IsLatin1() bool {
+	return st.constant.latin1
+}
+
+// CurrentRune returns the character at the current position and its size in bytes
+// (see DecodeRune).
+// At the end of the input it returns (utf8.RuneError, 0).
+func (st State) CurrentRune() (r rune, size int) {
+	return st.DecodeRune(st.CurrentString())
+}
+
+// DecodeRune returns the first character of s and its size in bytes.
+// It decodes UTF-8 or a single byte in Latin-1 mode (see WithLatin1).
+// So leaf parsers work in both modes.
+// For an empty s it returns (utf8.RuneError, 0).
+func (st State) DecodeRune(s string) (r rune, size int) {
+	if st.constant.latin1 {
+		if s == "" {
+			return utf8.RuneError, 0
+		}
+		return rune(s[0]), 1
+	}
+	return utf8.DecodeRuneInString(s)
+}
+
 func (st State) CurrentString() string {
 	if st.constant.binary && len(st.constant.text) < st.constant.n {
 		st.constant.text = string(st.constant.bytes)
@@ -51,6 +95,35 @@ func (st State) CurrentPos() int {
 	return st.pos
 }
 
+// FrameOffset returns the current position relative to the start of the
+// innermost frame (see Within).
+// Outside of any frame it is the same as CurrentPos.
+func (st State) FrameOffset() int {
+	return st.pos - st.constant.frameStart
+}
+
+// AtLineStart returns true if the current position is at the start of a line.
+// The start of the input is always the start of a line.
+func (st State) AtLineStart() bool {
+	return st.pos == st.prevNl+1
+}
+
+// PreviousRune returns the rune just before the current position and its size in bytes.
+// At the start of the input it returns (utf8.RuneError, 0).
+// This allows parsers to look behind.
+func (st State) PreviousRune() (r rune, size int) {
+	if st.constant.binary {
+		return utf8.DecodeLastRune(st.constant.bytes[:st.pos])
+	}
+	if st.constant.latin1 {
+		if st.pos == 0 {
+			return utf8.RuneError, 0
+		}
+		return rune(st.constant.text[st.pos-1]), 1
+	}
+	return utf8.DecodeLastRuneInString(st.constant.text[:st.pos])
+}
+
 func (st State) StringTo(remaining State) string {
 	if remaining.pos < st.pos {
 		return ""
@@ -101,7 +174,7 @@ func (st State) MoveBy(countBytes int) State {
 		moveText := st.constant.text[pos:n]
 		lastNlPos := strings.LastIndexByte(moveText, '\n') // this is Unicode safe!!!
 		if lastNlPos >= 0 {
-			st.prevNl += lastNlPos + 1 // this works even if '\n' wasn't found at all
+			st.prevNl = pos + lastNlPos
 			st.line += strings.Count(moveText, "\n")
 		}
 	}
@@ -139,19 +212,77 @@ func (st State) Moved(other State) bool {
 }
 
 // Delete1 moves forward in the input, thus simulating deletion of input.
-// For binary input it moves forward by a byte otherwise by a UNICODE rune.
+// For binary input (and in Latin-1 mode) it moves forward by a byte otherwise by a UNICODE rune.
 func (st State) Delete1() State {
 	if st.constant.binary {
 		return st.MoveBy(1)
 	}
 
-	r, size := utf8.DecodeRuneInString(st.CurrentString())
-	if r == utf8.RuneError {
+	r, size := st.CurrentRune()
+	if r == utf8.RuneError && size <= 1 {
 		return st.MoveBy(1) // try to correct the error
 	}
 	return st.MoveBy(size)
 }
 
+// ============================================================================
+// Included Input
+//
+
+// PushInput returns a state for parsing the new (included) text input.
+// It can be used to implement `include "file"` directives:
+// parsing continues in the included input and resumes with the state
+// returned by PopInput afterwards.
+// Errors in the included input are reported with the name of the input.
+// Error recovery continues in the including input then.
+// So the skipped input isn't used to classify these errors.
+// The new state keeps all settings (and errors) of the given state.
+// A byte index (see WithByteIndex) is rebuilt for the included input.
+func PushInput(state State, input string, name string) State {
+	outer := state
+	constant := *state.constant
+	constant.binary, constant.bytes, constant.text, constant.n = false, nil, input, len(input)
+	constant.parserCache = make(map[int32]interface{})
+	constant.source = name
+	constant.outer = &outer
+	constant.frameStart = 0
+	if constant.byteIndex != nil { // the index of the outer input doesn't fit
+		constant.byteIndex = constant.byteIndex.of(&constant)
+	}
+	return State{
+		constant: &constant,
+		safeSpot: -1,
+		pos:      0, prevNl: -1, line: 1,
+		errors:   state.errors,
+		deferred: state.deferred,
+		skip:     state.skip,
+		modes:    state.modes,
+		warnings: state.warnings,
+		actions:  state.actions,
+	}
+}
+
+// PopInput returns the state of the including input at the position of the
+// PushInput call. It keeps all errors of the included input.
+// PopInput returns false if the state isn't for included input.
+func PopInput(state State) (State, bool) {
+	if state.constant.outer == nil {
+		return state, false
+	}
+	outer := *state.constant.outer
+	outer.errors = state.errors
+	outer.deferred = state.deferred
+	outer.warnings = state.warnings
+	outer.actions = state.actions
+	return outer, true
+}
+
+// InputName returns the name of the input given to PushInput or
+// an empty string if the state isn't for included input.
+func (st State) InputName() string {
+	return st.constant.source
+}
+
 // ============================================================================
 // Parser Cache
 //
@@ -164,6 +295,16 @@ func (st State) GetFromCache(pID int32) interface{} {
 	return st.constant.parserCache[pID]
 }
 
+// withOwnCache returns the state with an empty parser cache.
+// Parsers created at runtime are numbered independently of the registered
+// ones, so their IDs would clash in a shared cache.
+func (st State) withOwnCache() State {
+	constant := *st.constant
+	constant.parserCache = make(map[int32]interface{})
+	st.constant = &constant
+	return st
+}
+
 // ============================================================================
 // Handle success and failure
 //
@@ -171,43 +312,274 @@ func (st State) GetFromCache(pID int32) interface{} {
 // SaveError saves an error and returns the new state.
 func (st State) SaveError(err *ParserError) State {
 	if err != nil {
-		st.errors = append(st.errors, errors.New(err.Error()))
+		st.errors = append(st.errors, newSavedError(err))
 	}
 	if st.constant.maxErrors > 0 && len(st.errors) >= st.constant.maxErrors {
-		// always reported by the root parser: too many errors, giving up
-		st.errors = append(st.errors, errors.New(st.NewSemanticError("too many errors, giving up").Error()))
+		// always reported by the root parser: too many errors, aborting
+		st.errors = append(st.errors, tooManyErrors(st.NewSemanticError(ErrTooManyErrors.Error()).Error()))
 		st = st.MoveBy(st.BytesRemaining()) // give up: move to end
 	}
 	return st
 }
 
+// Defer postpones the resolve function until the whole input has been parsed.
+// This enables two-phase grammars: the structure is parsed first and
+// references are resolved later with access to the full parse.
+// The deferred functions are called in the order they have been deferred,
+// but only if the parse completed (possibly with recovered errors).
+// An error returned by resolve is saved as a semantic error at the current position.
+func (st State) Defer(resolve func() error) State {
+	if resolve != nil {
+		st.deferred = append(st.deferred, deferredFn{state: st, resolve: resolve})
+	}
+	return st
+}
+
+// deferredFn is a function postponed by State.Defer.
+type deferredFn struct {
+	state   State // state at the time of deferring (for error positions)
+	resolve func() error
+}
+
+// resolveDeferred calls all deferred functions and saves their errors.
+func (st State) resolveDeferred() State {
+	deferred := st.deferred
+	st.deferred = nil
+	for _, d := range deferred {
+		if err := d.resolve(); err != nil {
+			st = st.SaveError(d.state.NewSemanticError("%w", err))
+		}
+	}
+	return st
+}
+
+// Warn saves a warning with the message and arguments at the current position.
+// Warnings don't stop or fail parsing. They are reported to the warning handler
+// (see WithWarningHandler) at the end of a run.
+// So warnings of alternatives that have been given up aren't reported.
+// Without a warning handler Warn does nothing.
+func (st State) Warn(msg string, args ...interface{}) State {
+	if st.constant.warn == nil {
+		return st
+	}
+	st.warnings = append(st.warnings[:len(st.warnings):len(st.warnings)], newSavedError(st.NewSemanticError(msg, args...)))
+	return st
+}
+
+// Warnings returns all warnings saved so far (see Warn).
+func (st State) Warnings() []error {
+	return st.warnings
+}
+
+// WithWarningHandler returns the state with a handler for warnings (see Warn).
+// The handler is called for every warning at the end of a run.
+// The position of a warning is available with PositionOf.
+// It has to be called before parsing starts.
+func (st State) WithWarningHandler(handle func(warning error)) State {
+	constant := *st.constant
+	constant.warn = handle
+	st.constant = &constant
+	return st
+}
+
+func (st State) reportWarnings() {
+	if st.constant.warn == nil {
+		return
+	}
+	for _, w := range st.warnings {
+		st.constant.warn(w)
+	}
+}
+
+// replaceLastError replaces the last saved error (e.g. after classifying it).
+func (st State) replaceLastError(err *ParserError) State {
+	if len(st.errors) == 0 || err == nil {
+		return st
+	}
+	st.errors = slices.Clone(st.errors)
+	st.errors[len(st.errors)-1] = newSavedError(err)
+	return st
+}
+
+// tooManyErrors is ErrTooManyErrors with position and source line.
+type tooManyErrors string
+
+func (e tooManyErrors) Error() string {
+	return string(e)
+}
+func (e tooManyErrors) Is(target error) bool {
+	return target == ErrTooManyErrors
+}
+
+// MaxErrors returns the maximum number of errors to recover from.
+// FailFast (0) means that parsing stops at the first error.
+func (st State) MaxErrors() int {
+	return st.constant.maxErrors
+}
+
+// WithMaxErrors returns the state with a new maximum number of errors
+// to recover from.
+// If the maximum is reached, ErrTooManyErrors is added to the errors and
+// parsing stops.
+// It has to be called before parsing starts.
+func (st State) WithMaxErrors(maxErrors int) State {
+	constant := *st.constant
+	constant.maxErrors = max(maxErrors, FailFast)
+	st.constant = &constant
+	return st
+}
+
+// RecoverBudget returns the maximum number of bytes scanned by a
+// recoverer per recovery attempt (0 means unlimited).
+func (st State) RecoverBudget() int {
+	return st.constant.recBudget
+}
+
+// WithRecoverBudget returns the state with a maximum number of bytes scanned
+// by a recoverer per recovery attempt.
+// Recoverers that would need more input report RecoverWasteTooMuch instead.
+// This keeps pathological inputs from stalling a service.
+// A budget <= 0 means unlimited (the default).
+// It has to be called before parsing starts.
+func (st State) WithRecoverBudget(maxBytes int) State {
+	constant := *st.constant
+	constant.recBudget = max(maxBytes, 0)
+	st.constant = &constant
+	return st
+}
+
+// truncated returns the state with the input ending n bytes after the current position.
+func (st State) truncated(n int) State {
+	constant := *st.constant
+	end := min(st.pos+n, constant.n)
+	constant.n = end
+	if len(constant.text) > end {
+		constant.text = constant.text[:end]
+	}
+	if len(constant.bytes) > end {
+		constant.bytes = constant.bytes[:end]
+	}
+	st.constant = &constant
+	return st
+}
+
+// WithFailFast returns the state with error recovery turned off.
+// So parsing stops at the first error.
+// It has to be called before parsing starts.
+func (st State) WithFailFast() State {
+	return st.WithMaxErrors(FailFast)
+}
+
+// WithErrorContext returns the state with n bytes before and after the
+// error position kept as context of every error (see ParserError.Context).
+// A value of 0 turns the context off.
+// It has to be called before parsing starts.
+func (st State) WithErrorContext(n int) State {
+	if n < 0 {
+		panic("WithErrorContext is unable to handle negative `n`")
+	}
+	constant := *st.constant
+	constant.errContext = n
+	st.constant = &constant
+	return st
+}
+
+// WithErrorLineWidth returns the state with n characters of the source line
+// shown in error messages (see DefaultErrorLineWidth).
+// A third of them is shown before the error position.
+// Longer lines (e.g. minified JSON) are cut off around the error position
+// and the cut off parts are marked with an ellipsis (…).
+// It has to be called before parsing starts.
+func (st State) WithErrorLineWidth(n int) State {
+	if n < 2 {
+		panic("WithErrorLineWidth is unable to handle `n` < 2")
+	}
+	constant := *st.constant
+	constant.errLineWidth = n
+	st.constant = &constant
+	return st
+}
+
 // NewSyntaxError creates a syntax error with the
 // message and arguments at the current state position.
 // For syntax errors `expected ` is prepended to the message, and the usual
 // position and source line including marker are appended.
 func (st State) NewSyntaxError(msg string, args ...interface{}) *ParserError {
-	return st.NewSemanticError(SyntaxErrorStart+msg, args...)
+	err := st.NewSemanticError(internSyntaxMessage(msg), args...)
+	err.incomplete = st.AtEnd() // more input might bring what is expected
+	return err
 }
 
 // NewSemanticError creates a semantic error
 // with the message and arguments at the current state position.
 // The usual position and source line including marker are appended to the message.
+// Like with fmt.Errorf, errors can be wrapped with the verb %w
+// (see ParserError.Unwrap).
 func (st State) NewSemanticError(msg string, args ...interface{}) *ParserError {
 	newErr := &ParserError{
-		text:       fmt.Sprintf(msg, args...),
-		pos:        st.pos,
-		binary:     st.constant.binary,
-		parserID:   -1,
-		parserData: make(map[int32]interface{}),
-	}
-	if st.constant.binary { // the rare binary case is misusing the text case data a bit...
-		newErr.line, newErr.col, newErr.srcLine = st.bytesAround(st.pos)
-	} else {
-		newErr.line, newErr.col, newErr.srcLine = st.textAround(st.pos)
+		pos:      st.pos,
+		binary:   st.constant.binary,
+		parserID: -1,
+		source:   st.constant.source,
+		budget:   st.budget,
+	}
+	switch {
+	case slices.ContainsFunc(args, isError): // wrapped errors are needed right away
+		err := fmt.Errorf(msg, args...)
+		newErr.text = err.Error()
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			newErr.wrapped = []error{x.Unwrap()}
+		case interface{ Unwrap() []error }:
+			newErr.wrapped = x.Unwrap()
+		}
+	case len(args) > 0 || strings.IndexByte(msg, '%') >= 0:
+		newErr.format, newErr.args = msg, args // formatted only if needed
+	default:
+		newErr.text = msg
 	}
+	newErr.at = st.location() // line, column and context are computed only if needed
 	return newErr
 }
 
+// location returns the state with only the data needed to compute
+// the line, column, source line and context of errors.
+// So errors don't keep the other data of the state alive.
+func (st State) location() State {
+	return State{constant: st.constant, pos: st.pos, prevNl: st.prevNl, line: st.line}
+}
+
+func isError(arg interface{}) bool {
+	_, ok := arg.(error)
+	return ok
+}
+
+// contextAround returns the sanitized input around the position
+// with a marker at the position itself (see ParserError.Context).
+func (st State) contextAround(pos int) string {
+	n := st.constant.errContext
+	if n <= 0 {
+		return ""
+	}
+	start, end := max(0, pos-n), min(st.constant.n, pos+n)
+	if st.constant.binary {
+		return sanitizeBytes(st.constant.bytes[start:pos]) + string(rune(errorMarker)) +
+			sanitizeBytes(st.constant.bytes[pos:end])
+	}
+	text := st.constant.text
+	if st.constant.latin1 {
+		return sanitizeText(latin1ToUTF8(text[start:pos])) + string(rune(errorMarker)) +
+			sanitizeText(latin1ToUTF8(text[pos:end]))
+	}
+	for start < pos && !utf8.RuneStart(text[start]) {
+		start++
+	}
+	for end > pos && end < len(text) && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return sanitizeText(text[start:pos]) + string(rune(errorMarker)) + sanitizeText(text[pos:end])
+}
+
 // HasError returns true if any errors are registered.
 // (Errors that would be returned by State.Errors())
 func (st State) HasError() bool {
@@ -226,8 +598,20 @@ func (st State) CurrentSourceLine() string {
 	if st.constant.binary {
 		return formatBinaryLine(st.bytesAround(st.pos))
 	} else {
-		return formatSrcLine(st.textAround(st.pos))
+		line, column, col, srcLine := st.clippedTextAround(st.pos)
+		return formatSrcLine(st.constant.source, line, column, col, srcLine)
+	}
+}
+
+// LineCol returns the line and column of the current position.
+// Both start at 1 and the column counts runes like in error messages.
+// For binary input the line is always 1 and the column is the byte position plus 1.
+func (st State) LineCol() (line, col int) {
+	if st.constant.binary {
+		return 1, st.pos + 1
 	}
+	line, col, srcLine := st.textAround(st.pos)
+	return line, utf8.RuneCountInString(srcLine[:col]) + 1
 }
 
 func (st State) bytesAround(pos int) (line, col int, srcLine string) {
@@ -240,7 +624,25 @@ func (st State) bytesAround(pos int) (line, col int, srcLine string) {
 	return start, pos - start, srcLine
 }
 
+// clippedTextAround returns the line number and column (in runes, starting
+// at 1) of pos and the source line around it cut to the error line width
+// (see WithErrorLineWidth) together with the byte index of pos within it.
+func (st State) clippedTextAround(pos int) (line, column, col int, srcLine string) {
+	line, col, srcLine = st.textAround(pos)
+	column = utf8.RuneCountInString(srcLine[:col]) + 1
+	srcLine, col = clipLine(srcLine, col, st.constant.errLineWidth)
+	return line, column, col, srcLine
+}
+
 func (st State) textAround(pos int) (line, col int, srcLine string) {
+	line, col, srcLine = st.rawTextAround(pos)
+	if st.constant.latin1 { // error messages are always UTF-8
+		before := latin1ToUTF8(srcLine[:col])
+		return line, len(before), before + latin1ToUTF8(srcLine[col:])
+	}
+	return line, col, srcLine
+}
+func (st State) rawTextAround(pos int) (line, col int, srcLine string) {
 	if pos < 0 {
 		pos = 0
 	}
@@ -1,7 +1,9 @@
 package comb
 
 import (
+	"errors"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -51,3 +53,129 @@ func TestBytesTo(t *testing.T) {
 		})
 	}
 }
+
+func TestSpan(t *testing.T) {
+	t.Parallel()
+
+	textState1 := NewFromString("12345678", 0)
+	textState2 := textState1.MoveBy(2)
+	textState3 := textState1.MoveBy(5)
+	binaryState1 := NewFromBytes([]byte("12345678"), 0)
+	binaryState2 := binaryState1.MoveBy(2)
+	binaryState3 := binaryState1.MoveBy(5)
+
+	textSpan := textState2.SpanTo(textState3)
+	assert.Equal(t, Span{Start: 2, End: 5}, textSpan)
+	assert.Equal(t, 3, textSpan.Len())
+	assert.Equal(t, "345", textState1.StringOf(textSpan))
+	assert.Equal(t, []byte("345"), textState1.BytesOf(textSpan))
+	assert.Equal(t, Span{Start: 5, End: 5}, textState3.SpanTo(textState2))
+
+	binarySpan := binaryState2.SpanTo(binaryState3)
+	assert.Equal(t, "345", binaryState3.StringOf(binarySpan))
+	assert.Equal(t, []byte("345"), binaryState3.BytesOf(binarySpan))
+	assert.Equal(t, "8", binaryState3.StringOf(Span{Start: 7, End: 20}))
+}
+
+func TestLineCol(t *testing.T) {
+	t.Parallel()
+
+	textState := NewFromString("ab\nc€d\n", 0)
+	line, col := textState.LineCol()
+	assert.Equal(t, []int{1, 1}, []int{line, col})
+	line, col = textState.MoveBy(2).LineCol()
+	assert.Equal(t, []int{1, 3}, []int{line, col})
+	line, col = textState.MoveBy(7).LineCol()
+	assert.Equal(t, []int{2, 3}, []int{line, col})
+
+	line, col = NewFromBytes([]byte("ab\ncd"), 0).MoveBy(4).LineCol()
+	assert.Equal(t, []int{1, 5}, []int{line, col})
+}
+
+func TestPreviousRune(t *testing.T) {
+	t.Parallel()
+
+	textState := NewFromString("a€", 0)
+	r, size := textState.PreviousRune()
+	assert.Equal(t, utf8.RuneError, r)
+	assert.Equal(t, 0, size)
+	r, size = textState.MoveBy(4).PreviousRune()
+	assert.Equal(t, '€', r)
+	assert.Equal(t, 3, size)
+
+	r, size = NewFromBytes([]byte("ab"), 0).MoveBy(1).PreviousRune()
+	assert.Equal(t, 'a', r)
+	assert.Equal(t, 1, size)
+}
+
+func TestAtLineStart(t *testing.T) {
+	t.Parallel()
+
+	state := NewFromString("ab\ncd\n", 0)
+	assert.True(t, state.AtLineStart())
+	assert.False(t, state.MoveBy(1).AtLineStart())
+	assert.True(t, state.MoveBy(1).MoveBy(2).AtLineStart())
+	assert.False(t, state.MoveBy(1).MoveBy(3).AtLineStart())
+	assert.True(t, state.MoveBy(4).MoveBy(2).AtLineStart())
+	assert.True(t, state.MoveBy(5).MoveBackTo(3).AtLineStart())
+}
+
+func TestWithMaxErrors(t *testing.T) {
+	t.Parallel()
+
+	state := NewFromString("abcdef", DefaultMaxErrors)
+	limited := state.WithMaxErrors(2)
+	assert.Equal(t, DefaultMaxErrors, state.MaxErrors())
+	assert.Equal(t, 2, limited.MaxErrors())
+	assert.Equal(t, FailFast, state.WithFailFast().MaxErrors())
+	assert.Equal(t, FailFast, state.WithMaxErrors(-1).MaxErrors())
+
+	limited = limited.SaveError(limited.NewSyntaxError("x"))
+	assert.False(t, errors.Is(limited.Errors(), ErrTooManyErrors))
+	assert.False(t, limited.AtEnd())
+
+	limited = limited.MoveBy(1).SaveError(limited.NewSyntaxError("y"))
+	assert.True(t, errors.Is(limited.Errors(), ErrTooManyErrors))
+	assert.ErrorContains(t, limited.Errors(), "too many errors, aborting [1:2]")
+	assert.True(t, limited.AtEnd())
+}
+
+func TestRunOnStateWithCSTKeepsState(t *testing.T) {
+	t.Parallel()
+
+	word := NewParser[string]("word", func(state State) (State, string, *ParserError) {
+		nState := state.MoveBy(state.BytesRemaining())
+		return nState, state.StringTo(nState), nil
+	}, nil)
+	state := NewFromString("abc", DefaultMaxErrors)
+	_, tree, err := RunOnStateWithCST(state, NewPreparedParser(word))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", tree.Text())
+	assert.Nil(t, state.constant.cst, "runs on the state shouldn't record into the CST afterwards")
+}
+
+func TestPushPopInput(t *testing.T) {
+	t.Parallel()
+
+	outer := NewFromString("include x;\nrest", 10).MoveBy(10)
+	inner := PushInput(outer, "ab\ncd", "x.txt")
+	assert.Equal(t, "x.txt", inner.InputName())
+	assert.Equal(t, "ab\ncd", inner.CurrentString())
+
+	inner = inner.MoveBy(4)
+	inner = inner.SaveError(inner.NewSyntaxError("'e'"))
+	assert.EqualError(t, inner.Errors(), "expected 'e' [x.txt:2:2] c▶d")
+	pos, ok := PositionOf(UnwrapErrors(inner.Errors())[0])
+	assert.True(t, ok)
+	assert.Equal(t, ErrorPosition{Source: "x.txt", Pos: 4, Line: 2, Column: 2}, pos)
+
+	got, ok := PopInput(inner)
+	assert.True(t, ok)
+	assert.Equal(t, "", got.InputName())
+	assert.Equal(t, 10, got.CurrentPos())
+	assert.Equal(t, "\nrest", got.CurrentString())
+	assert.Equal(t, inner.Errors(), got.Errors(), "errors of the included input should be kept")
+
+	_, ok = PopInput(got)
+	assert.False(t, ok)
+}
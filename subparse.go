@@ -0,0 +1,84 @@
+package comb
+
+import "errors"
+
+// ============================================================================
+// Sub-Parsing From Within Callbacks
+//
+
+// SubParse runs the prepared parser on `input` detached from the run of
+// `state`. It is meant for two-stage constructs like parsing the contents
+// of a matched string literal from inside a callback that has access to
+// the state (e.g. cmb.MapWithSpan or Action):
+//
+//	inner := comb.NewPreparedParser(template()) // prepare once outside the callback
+//	cmb.MapWithSpan(cmb.StringLiteral(cfg), func(lit cmb.StringLit, _ comb.Span, state comb.State) (Template, error) {
+//		return comb.SubParse(state, lit.Value, inner, lit.SourcePos)
+//	})
+//
+// The sub-run keeps the settings of the outer run (maximum number of errors,
+// recovery budget, limits, normalization, warning handler, context,
+// time budget, arena, error context, error line width and input name).
+// It has its own error recovery and neither sees nor changes the errors,
+// warnings and actions of the outer run.
+// The returned error joins all errors of the sub-run.
+// `sourcePos` maps a position in `input` to its position in the input of
+// `state` (e.g. cmb.StringLit.SourcePos), so the errors are reported at
+// their place in the real input.
+// A nil `sourcePos` keeps the positions in `input`.
+// Returned from the callback, the error becomes part of the errors of the outer run
+// (errors.Is and errors.As work through it).
+//
+// The sub-parser must not share any parsers with the outer grammar.
+func SubParse[Output any](state State, input string, pp *PreparedParser[Output], sourcePos func(int) int,
+) (Output, error) {
+	outer := state.constant
+	sub := newState(false, nil, input, outer.maxErrors)
+	sub.budget = state.budget
+	constant := sub.constant
+	constant.recBudget = outer.recBudget
+	constant.source = outer.source
+	constant.normalize = outer.normalize
+	constant.warn = outer.warn
+	constant.limits = outer.limits
+	constant.errContext = outer.errContext
+	constant.errLineWidth = outer.errLineWidth
+	constant.ctx = outer.ctx
+	constant.arena = outer.arena
+	out, err := RunOnState(sub, pp)
+	if err == nil || sourcePos == nil {
+		return out, err
+	}
+	return out, relocateErrors(state, err, sourcePos)
+}
+
+// relocateErrors moves the errors of a sub-run to their positions in the
+// input of the state.
+func relocateErrors(state State, err error, sourcePos func(int) int) error {
+	errs := UnwrapErrors(err)
+	relocated := make([]error, len(errs))
+	for i, e := range errs {
+		se, ok := e.(*savedError)
+		if !ok { // e.g. ErrTooManyErrors
+			relocated[i] = e
+			continue
+		}
+		pe := &ParserError{
+			text:       se.text,
+			pos:        sourcePos(se.diag.Pos),
+			at:         state,
+			source:     state.constant.source,
+			binary:     state.constant.binary,
+			parserID:   -1,
+			parserName: se.diag.ParserName,
+			incomplete: se.diag.Incomplete,
+			fatal:      se.diag.Fatal,
+			kind:       se.diag.Kind,
+			waste:      se.diag.Waste,
+			safeSpot:   se.diag.SafeSpotUsed,
+			wrapped:    se.wrapped,
+		}
+		relocated[i] = newSavedError(pe)
+	}
+	return errors.Join(relocated...)
+}
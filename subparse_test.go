@@ -0,0 +1,72 @@
+package comb_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubParse(t *testing.T) {
+	t.Parallel()
+
+	// newParser parses a string literal with a comma separated list of integers in it.
+	newParser := func() comb.Parser[[]int64] {
+		inner := comb.NewPreparedParser(cmb.Suffixed(
+			cmb.Separated1(cmb.Int[int64](false, 10), comb.SafeSpot(cmb.Char(',')), false),
+			cmb.EOF(),
+		))
+		return cmb.Suffixed(
+			cmb.MapWithSpan(cmb.StringLiteral(cmb.StringConfig{}), func(lit cmb.StringLit, _ comb.Span, state comb.State) ([]int64, error) {
+				return comb.SubParse(state, lit.Value, inner, lit.SourcePos)
+			}),
+			cmb.EOF(),
+		)
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantOutput []int64
+		wantErr    string // error of the sub-run with its position in the input
+	}{
+		{
+			name:       "valid content should be parsed",
+			input:      `"1,22,333"`,
+			wantOutput: []int64{1, 22, 333},
+		}, {
+			name:    "invalid content should be an error of the outer run",
+			input:   `"1,x,333"`,
+			wantErr: `[1:4] "1,▶x,333"`,
+		}, {
+			name:    "overflow in the content should keep the error type",
+			input:   `"1,99999999999999999999"`,
+			wantErr: `out of range for int64: value out of range [1:4] "1,▶99999999999999999999…`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := comb.RunOnString(tc.input, newParser())
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.wantOutput, got)
+				return
+			}
+			errs := comb.UnwrapErrors(err)
+			assert.Len(t, errs, 1)
+			pos, ok := comb.PositionOf(errs[0])
+			assert.True(t, ok)
+			assert.Equal(t, len(tc.input), pos.Pos, "errors of the sub-run should be reported by MapWithSpan")
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+
+	_, err := comb.RunOnString(`"1,99999999999999999999"`, newParser())
+	assert.ErrorIs(t, err, strconv.ErrRange)
+}
@@ -0,0 +1,84 @@
+package comb
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTimeout is wrapped by the errors of parsers that exceeded their
+// time budget (see WithTimeout).
+var ErrTimeout = errors.New("time budget exceeded")
+
+// ============================================================================
+// Time Budgets For Parts Of A Grammar
+//
+
+// WithTimeout limits the time parser `p` may take to `d`.
+// All parsers of the subtree of `p` fail as soon as the budget is used up.
+// This includes the error recovery inside the subtree.
+// WithTimeout reports an error wrapping ErrTimeout then.
+// That error isn't fatal, so the rest of the input is parsed as usual.
+// This protects interactive tools from pathological rules.
+// Time budgets can be nested; the inner budget can't extend the outer one.
+//
+// NOTE:
+//   - Until the budget is used up, errors inside `p` are recovered from
+//     as usual (e.g. with a SafeSpot inside `p`).
+//   - WithTimeout is never used for error recovery, so after a timeout the input
+//     is skipped up to the next parser that is able to recover (e.g. a SafeSpot).
+func WithTimeout[Output any](p Parser[Output], d time.Duration) Parser[Output] {
+	var tp Parser[Output]
+
+	if d <= 0 {
+		panic("WithTimeout is unable to handle a non-positive duration")
+	}
+	expected := p.Expected()
+
+	tp = NewBranchParser[Output](
+		expected,
+		func() []AnyParser {
+			return []AnyParser{p}
+		}, func(
+			childID int32,
+			childStartState, childState State,
+			childOut interface{},
+			childErr *ParserError,
+			data interface{},
+		) (State, Output, *ParserError, interface{}) {
+			Debugf("WithTimeout.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+			if childID < 0 { // top-down
+				budget := &timeBudget{end: time.Now().Add(d), owner: tp.ID(), outer: childState.budget}
+				if outer := childState.budget; outer != nil && outer.end.Before(budget.end) {
+					budget.end = outer.end
+				}
+				childStartState = childState
+				childState.budget = budget
+				childState, childOut, childErr = p.ParseAny(tp.ID(), childState)
+			}
+			if budget := childState.budget; budget != nil && budget.owner == tp.ID() {
+				childStartState.budget = budget.outer
+				childState.budget = budget.outer
+				if budget.expired() {
+					err := childStartState.NewSemanticError("%s: %w (%s)", expected, ErrTimeout, d)
+					err.budget = budget // no recovery inside the subtree
+					return childStartState, ZeroOf[Output](), err, nil
+				}
+			}
+			out, _ := childOut.(Output)
+			return childState, out, childErr, nil
+		},
+	)
+	return tp
+}
+
+// timeBudget is the time budget of the subtree of a WithTimeout parser.
+type timeBudget struct {
+	end   time.Time
+	owner int32       // ID of the WithTimeout parser
+	outer *timeBudget // budget of the enclosing subtree (if any)
+}
+
+// expired reports whether the budget exists and has been used up.
+func (b *timeBudget) expired() bool {
+	return b != nil && time.Now().After(b.end)
+}
@@ -0,0 +1,116 @@
+package comb_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	// slowA is a pathological rule that takes a millisecond per 'a'.
+	slowA := func() comb.Parser[rune] {
+		return comb.NewParser[rune]("slow 'a'", func(state comb.State) (comb.State, rune, *comb.ParserError) {
+			time.Sleep(time.Millisecond)
+			return cmb.Char('a').Parse(state)
+		}, nil)
+	}
+	newParser := func(d time.Duration) comb.Parser[[]int] {
+		record := func(end rune) comb.Parser[[]rune] {
+			return cmb.Suffixed(comb.WithTimeout(cmb.Many1(slowA()), d), comb.SafeSpot(cmb.Char(end)))
+		}
+		return cmb.Suffixed(cmb.Map3(record(';'), record(','), record('.'), func(r1, r2, r3 []rune) ([]int, error) {
+			return []int{len(r1), len(r2), len(r3)}, nil
+		}), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name        string
+		input       string
+		timeout     time.Duration
+		wantLengths []int
+		wantTimeout bool
+	}{
+		{
+			name:        "fast rules should work as usual",
+			input:       "a;aa,a.",
+			timeout:     time.Second,
+			wantLengths: []int{1, 2, 1},
+		}, {
+			name:        "slow rule should time out and the rest should be parsed",
+			input:       "a;" + strings.Repeat("a", 500) + ",a.",
+			timeout:     20 * time.Millisecond,
+			wantLengths: []int{1, 0, 1},
+			wantTimeout: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			start := time.Now()
+			got, err := comb.RunOnString(tc.input, newParser(tc.timeout))
+			assert.Less(t, time.Since(start), 400*time.Millisecond, "parsing took too long")
+			assert.Equal(t, tc.wantLengths, got)
+			assert.Equal(t, tc.wantTimeout, errors.Is(err, comb.ErrTimeout), "error: %v", err)
+			if tc.wantTimeout {
+				assert.Len(t, comb.UnwrapErrors(err), 1)
+			}
+		})
+	}
+}
+
+func TestWithTimeoutRecovery(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[int] {
+		// slowA is a pathological rule that takes a millisecond per 'a'.
+		slowA := comb.NewParser[rune]("slow 'a'", func(state comb.State) (comb.State, rune, *comb.ParserError) {
+			time.Sleep(time.Millisecond)
+			return cmb.Char('a').Parse(state)
+		}, nil)
+		items := comb.WithTimeout(cmb.Many1(cmb.Suffixed(slowA, comb.SafeSpot(cmb.Char(',')))), 30*time.Millisecond)
+		return cmb.Suffixed(cmb.Map2(items, comb.SafeSpot(cmb.Char(';')), func(as []rune, _ rune) (int, error) {
+			return len(as), nil
+		}), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name        string
+		input       string
+		wantCount   int
+		wantTimeout bool
+	}{
+		{
+			name:      "error inside the subtree should be recovered inside",
+			input:     "a,x,a,;",
+			wantCount: 2,
+		}, {
+			name:        "recovery inside the subtree should stop after the time budget",
+			input:       "x," + strings.Repeat("a,", 500) + ";",
+			wantTimeout: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			start := time.Now()
+			got, err := comb.RunOnString(tc.input, newParser())
+			assert.Less(t, time.Since(start), 400*time.Millisecond, "parsing took too long")
+			assert.Equal(t, tc.wantCount, got)
+			assert.Equal(t, tc.wantTimeout, errors.Is(err, comb.ErrTimeout), "error: %v", err)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package comb
+
+import (
+	"fmt"
+)
+
+// ============================================================================
+// Recording And Replaying Runs
+//
+
+// Trace is the replayable record of a run of a parser.
+// It can be serialized (e.g. to JSON) and attached to bug reports.
+type Trace struct {
+	Binary    bool        `json:"binary,omitempty"`
+	Text      string      `json:"text,omitempty"`  // text input
+	Bytes     []byte      `json:"bytes,omitempty"` // binary input
+	Start     int         `json:"start,omitempty"` // start position in the input
+	MaxErrors int         `json:"maxErrors"`
+	Steps     []TraceStep `json:"steps"`
+}
+
+// TraceStep is a single invocation of a parser.
+// The steps are in the order of the invocations.
+type TraceStep struct {
+	ParserID   int32  `json:"id"`
+	StableID   uint64 `json:"stableID,omitempty"` // see ParserIDs.StableID
+	Name       string `json:"name,omitempty"`     // name of the parser in the global registry (see Register)
+	Expected   string `json:"expected"`
+	Recovery   bool   `json:"recovery,omitempty"` // true during error recovery (bottom -> up)
+	ChildID    int32  `json:"childID,omitempty"`  // child that was recovered (only during error recovery)
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	ErrorText  string `json:"error,omitempty"`
+	Incomplete bool   `json:"incomplete,omitempty"` // more input might fix the error
+}
+
+func (step TraceStep) String() string {
+	phase := "parse"
+	if step.Recovery {
+		phase = fmt.Sprintf("recover(child=%d)", step.ChildID)
+	}
+	result := "ok"
+	if step.ErrorText != "" {
+		result = "error: " + step.ErrorText
+	}
+	id := fmt.Sprintf("ID=%d", step.ParserID)
+	if step.Name != "" {
+		id += ", name=" + step.Name
+	}
+	return fmt.Sprintf("%s %q (%s) %d-%d: %s", phase, step.Expected, id, step.Start, step.End, result)
+}
+
+// RecordRun runs the parser on the state like RunOnState and
+// records all parser invocations (including error recovery) in a trace.
+// Only parsers called via ParseAny are recorded (all standard combinators do that).
+func RecordRun[Output any](state State, p Parser[Output]) (Output, *Trace, error) {
+	trace := &Trace{
+		Binary:    state.constant.binary,
+		Start:     state.pos,
+		MaxErrors: state.constant.maxErrors,
+	}
+	if trace.Binary {
+		trace.Bytes = state.constant.bytes
+	} else {
+		trace.Text = state.constant.text
+	}
+
+	state.constant.tracer = &tracer{}
+	out, err := RunOnState[Output](state, NewPreparedParser(p))
+	trace.Steps = state.constant.tracer.steps
+	state.constant.tracer = nil
+	return out, trace, err
+}
+
+// Replay runs the parser again on the input of the trace and compares
+// the parser invocations with the ones of the trace.
+// It returns an error describing the first difference if the runs diverge.
+// Otherwise, it returns the output and error of the new run.
+// The parser has to be constructed the same way as for the recorded run.
+func Replay[Output any](trace *Trace, p Parser[Output]) (Output, error) {
+	var state State
+	if trace.Binary {
+		state = NewFromBytes(trace.Bytes, trace.MaxErrors)
+	} else {
+		state = NewFromString(trace.Text, trace.MaxErrors)
+	}
+
+	out, newTrace, err := RecordRun(state.MoveBy(trace.Start), p)
+	for i, step := range trace.Steps {
+		if i >= len(newTrace.Steps) {
+			return out, fmt.Errorf("replay diverged at step %d: want %s, got no more steps", i, step)
+		}
+		if newTrace.Steps[i] != step {
+			return out, fmt.Errorf("replay diverged at step %d: want %s, got %s", i, step, newTrace.Steps[i])
+		}
+	}
+	if len(newTrace.Steps) > len(trace.Steps) {
+		i := len(trace.Steps)
+		return out, fmt.Errorf("replay diverged at step %d: want no more steps, got %s", i, newTrace.Steps[i])
+	}
+	return out, err
+}
+
+// tracer records the steps of a run.
+// All methods work with a nil tracer (recording is off).
+type tracer struct {
+	steps  []TraceStep
+	handle func(TraceStep) // called for every finished step (see WithTracer)
+}
+
+func (tr *tracer) enter(p AnyParser, expected string, recovery bool, childID int32, start int) int {
+	if tr == nil {
+		return -1
+	}
+	tr.steps = append(tr.steps, TraceStep{
+		ParserID: p.ID(), StableID: p.StableID(), Name: NameOf(p), Expected: expected, Recovery: recovery, ChildID: childID, Start: start,
+	})
+	return len(tr.steps) - 1
+}
+
+func (tr *tracer) exit(i int, end int, err *ParserError) {
+	if tr == nil || i < 0 {
+		return
+	}
+	tr.steps[i].End = end
+	if err != nil {
+		tr.steps[i].ErrorText = err.Error()
+		tr.steps[i].Incomplete = err.Incomplete()
+	}
+	if tr.handle != nil {
+		tr.handle(tr.steps[i])
+	}
+}
@@ -0,0 +1,75 @@
+package comb_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordRunAndReplay(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[[]string] {
+		return cmb.Suffixed(cmb.Many1(cmb.Suffixed(cmb.Alpha1(), comb.SafeSpot(cmb.Char(';')))), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name         string
+		input        string
+		wantErr      bool
+		wantRecovery bool
+	}{
+		{
+			name:  "recording a successful run",
+			input: "ab;cd;",
+		}, {
+			name:         "recording a run with error recovery",
+			input:        "ab;12;cd;",
+			wantErr:      true,
+			wantRecovery: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			wantOutput, trace, wantErr := comb.RecordRun(comb.NewFromString(tc.input, 10), newParser())
+			if (wantErr != nil) != tc.wantErr {
+				t.Errorf("got error %v, want error: %t", wantErr, tc.wantErr)
+			}
+			if len(trace.Steps) == 0 {
+				t.Fatalf("got no steps")
+			}
+			gotRecovery := false
+			for _, step := range trace.Steps {
+				gotRecovery = gotRecovery || step.Recovery
+			}
+			assert.Equal(t, tc.wantRecovery, gotRecovery)
+
+			jsonTrace, err := json.Marshal(trace)
+			if err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+			var trace2 comb.Trace
+			if err = json.Unmarshal(jsonTrace, &trace2); err != nil {
+				t.Fatalf("got unexpected error: %v", err)
+			}
+
+			gotOutput, gotErr := comb.Replay(&trace2, newParser())
+			assert.Equal(t, wantOutput, gotOutput)
+			if wantErr != nil {
+				assert.EqualError(t, gotErr, wantErr.Error())
+			} else {
+				assert.NoError(t, gotErr)
+			}
+
+			_, gotErr = comb.Replay(&trace2, cmb.Many1(cmb.Suffixed(cmb.Alpha1(), cmb.Char(';'))))
+			assert.ErrorContains(t, gotErr, "replay diverged at step")
+		})
+	}
+}
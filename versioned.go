@@ -0,0 +1,87 @@
+package comb
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Versioned Grammars
+//
+
+// Versioned parses a version marker with `detect` and then dispatches to
+// the parser registered for that version.
+// The version parsers can share sub-rules.
+// All parsers are known in advance, so error recovery works as usual
+// inside of them.
+// An unknown version is reported at the position of the version marker.
+func Versioned[Output any](versions map[string]Parser[Output], detect Parser[string]) Parser[Output] {
+	if len(versions) == 0 {
+		panic("Versioned: no versions given")
+	}
+	vd := &versionedData[Output]{detect: detect, versions: versions}
+	for version := range versions {
+		vd.names = append(vd.names, version)
+	}
+	slices.Sort(vd.names) // the order of the children has to be stable
+	quoted := make([]string, len(vd.names))
+	for i, name := range vd.names {
+		quoted[i] = strconv.Quote(name)
+	}
+	vd.known = strings.Join(quoted, ", ")
+
+	p := NewBranchParser[Output]("Versioned", vd.children, vd.parseAfterChild)
+	vd.id = p.ID
+	return p
+}
+
+type versionedData[Output any] struct {
+	id       func() int32
+	detect   Parser[string]
+	versions map[string]Parser[Output]
+	names    []string // sorted names of the versions
+	known    string   // quoted names of the versions for error messages
+}
+
+func (vd *versionedData[Output]) children() []AnyParser {
+	children := make([]AnyParser, 0, len(vd.names)+1)
+	children = append(children, vd.detect)
+	for _, name := range vd.names {
+		children = append(children, vd.versions[name])
+	}
+	return children
+}
+
+func (vd *versionedData[Output]) parseAfterChild(
+	childID int32,
+	childStartState, childState State,
+	childOut interface{},
+	childErr *ParserError,
+	data interface{},
+) (State, Output, *ParserError, interface{}) {
+	Debugf("Versioned.parseAfterChild - childID=%d, pos=%d", childID, childState.CurrentPos())
+
+	if childID >= 0 && childID != vd.detect.ID() { // on the way up from a version parser
+		out, _ := childOut.(Output)
+		return childState, out, childErr, nil
+	}
+	if childID < 0 { // top-down
+		childStartState = childState
+		childState, childOut, childErr = vd.detect.ParseAny(vd.id(), childStartState)
+	}
+	if childErr != nil {
+		return childState, ZeroOf[Output](), childErr, nil
+	}
+
+	version, _ := childOut.(string)
+	p, ok := vd.versions[version]
+	if !ok {
+		return childStartState, ZeroOf[Output](), childStartState.NewSyntaxError(
+			"known version (one of %s, got %q)", vd.known, version,
+		), nil
+	}
+	nState, aOut, err := p.ParseAny(vd.id(), childState)
+	out, _ := aOut.(Output)
+	return nState, out, err, nil
+}
@@ -0,0 +1,70 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioned(t *testing.T) {
+	t.Parallel()
+
+	newParser := func() comb.Parser[[]int64] {
+		number := cmb.Int64(false, 10) // shared by both versions
+		return cmb.Suffixed(comb.Versioned(
+			map[string]comb.Parser[[]int64]{
+				"1": cmb.Separated1(number, cmb.Char(','), false),
+				"2": cmb.Separated1(number, comb.SafeSpot(cmb.Char(';')), false),
+			},
+			cmb.Delimited(cmb.String("version "), cmb.Digit1(), cmb.Char('\n')),
+		), cmb.EOF())
+	}
+
+	testCases := []struct {
+		name       string
+		input      string
+		wantErr    string
+		wantOutput []int64
+	}{
+		{
+			name:       "version 1 should succeed",
+			input:      "version 1\n1,2,3",
+			wantOutput: []int64{1, 2, 3},
+		}, {
+			name:       "version 2 should succeed",
+			input:      "version 2\n1;2;3",
+			wantOutput: []int64{1, 2, 3},
+		}, {
+			name:    "separator of other version should fail",
+			input:   "version 1\n1;2",
+			wantErr: "end of the input (still 2 bytes of input left)",
+		}, {
+			name:    "unknown version should fail at the marker",
+			input:   "version 3\n1,2",
+			wantErr: `expected known version (one of "1", "2", got "3")`,
+		}, {
+			name:    "errors should be recovered inside of a version",
+			input:   "version 2\n1;x;3",
+			wantErr: "[2:3]",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := comb.RunOnString(tc.input, newParser())
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tc.wantOutput != nil {
+				assert.Equal(t, tc.wantOutput, gotOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package comb
+
+// ============================================================================
+// Within Parsers
+//
+
+// Within restricts the parser `p` to exactly the next `n` bytes of the input.
+// `p` sees the end of the input at the boundary and has to consume all `n` bytes.
+// This keeps the inner syntax of length-prefixed payloads from reading past
+// their frame. Positions of errors are reported in the full input.
+// The frame starts at the current position (see State.FrameOffset).
+//
+// NOTE:
+//   - Even though Within accepts a parser as argument, it behaves like a leaf parser
+//     to the outside world. Errors of `p` look as if coming from Within itself.
+//   - There is no optimized recoverer, so Within is retried one byte/rune
+//     at a time during error recovery.
+func Within[Output any](n int, p Parser[Output]) Parser[Output] {
+	if n < 0 {
+		panic("Within: n is negative")
+	}
+	expected := p.Expected()
+
+	parse := func(state State) (State, Output, *ParserError) {
+		var zero Output
+
+		if n > state.BytesRemaining() {
+			return state, zero, MarkIncomplete(state.NewSyntaxError(
+				"%s of %d bytes (only %d bytes of input left)", expected, n, state.BytesRemaining(),
+			))
+		}
+		return parseWithin(state, n, p)
+	}
+
+	return NewParser[Output](expected, parse, nil)
+}
+
+// WithinDelimited parses `open`, then `p` restricted to the input up to the
+// first match of `close` and finally `close`.
+// `p` sees the end of the input right before `close` and has to consume
+// all of the input up to it.
+// Delimiters can't be nested because the first match of `close` ends the region.
+//
+// NOTE:
+//   - Even though WithinDelimited accepts parsers as arguments, it behaves like
+//     a leaf parser to the outside world.
+//     Errors of the sub-parsers look as if coming from WithinDelimited itself.
+//   - There is no optimized recoverer, so WithinDelimited is retried
+//     one byte/rune at a time during error recovery.
+func WithinDelimited[OP, Output, CP any](open Parser[OP], close Parser[CP], p Parser[Output]) Parser[Output] {
+	expected := p.Expected() + " delimited by " + open.Expected() + " and " + close.Expected()
+
+	parse := func(state State) (State, Output, *ParserError) {
+		var zero Output
+
+		oState, _, err := open.ParseAny(ParentUnknown, state)
+		if err != nil {
+			return state, zero, claimDynamicError(err)
+		}
+		for n := 0; ; {
+			cState, _, err := close.ParseAny(ParentUnknown, oState.MoveBy(n))
+			if err == nil {
+				wState, out, err := parseWithin(oState, n, p)
+				if err != nil {
+					return wState, out, err
+				}
+				return cState, out, nil
+			}
+			if n >= oState.BytesRemaining() {
+				line, col := state.LineCol()
+				return state, zero, MarkIncomplete(oState.MoveBy(n).NewSyntaxError(
+					"%s (unclosed %s opened at %d:%d)", close.Expected(), open.Expected(), line, col,
+				))
+			}
+			size := 1
+			if !state.IsBinary() {
+				_, size = oState.MoveBy(n).CurrentRune()
+			}
+			n += max(size, 1)
+		}
+	}
+
+	return NewParser[Output](expected, parse, nil)
+}
+
+// parseWithin parses `p` restricted to the next `n` bytes of the state.
+// The returned state isn't restricted anymore.
+func parseWithin[Output any](state State, n int, p Parser[Output]) (State, Output, *ParserError) {
+	fState := state.truncated(n)
+	fState.constant.frameStart = state.pos
+	wState, aOut, err := p.ParseAny(ParentUnknown, fState)
+	out, _ := aOut.(Output)
+	if err == nil && !wState.AtEnd() {
+		err = wState.NewSyntaxError("end of the limited input (still %d bytes of it left)", wState.BytesRemaining())
+	}
+	wState.constant = state.constant
+	if err != nil {
+		err = claimDynamicError(err)
+		err.incomplete = false // the input is complete up to the boundary
+		wState.relocateError(err)
+		return wState, out, err
+	}
+	return wState, out, nil
+}
+
+// relocateError lets the error compute its source line and context
+// in the full input (they are cut at the boundary otherwise).
+func (st State) relocateError(err *ParserError) {
+	err.at = st.location()
+}
@@ -0,0 +1,110 @@
+package comb_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/comb"
+	"github.com/flowdev/comb/cmb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithin(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		newParser  func() comb.Parser[string]
+		input      string
+		wantErrMsg string
+		wantOutput string
+	}{
+		{
+			name: "a frame should be parsed completely",
+			newParser: func() comb.Parser[string] {
+				return cmb.Suffixed(comb.Within(3, cmb.Digit1()), cmb.Digit1())
+			},
+			input:      "12345",
+			wantOutput: "123",
+		}, {
+			name: "the inner parser should see the end of the input at the boundary",
+			newParser: func() comb.Parser[string] {
+				return cmb.Suffixed(comb.Within(2, cmb.Suffixed(cmb.Digit1(), cmb.EOF())), cmb.Alpha1())
+			},
+			input:      "12ab",
+			wantOutput: "12",
+		}, {
+			name: "unused input of the frame should fail",
+			newParser: func() comb.Parser[string] {
+				return comb.Within(3, cmb.Digit1())
+			},
+			input:      "12a",
+			wantErrMsg: "expected end of the limited input (still 1 bytes of it left) [1:3] 12▶a",
+		}, {
+			name: "a frame longer than the input should fail",
+			newParser: func() comb.Parser[string] {
+				return comb.Within(4, cmb.Digit1())
+			},
+			input:      "123",
+			wantErrMsg: "expected digit of 4 bytes (only 3 bytes of input left) [1:1] ▶123",
+		}, {
+			name: "a delimited region should be parsed completely",
+			newParser: func() comb.Parser[string] {
+				return cmb.Suffixed(comb.WithinDelimited(cmb.Char('<'), cmb.Char('>'), cmb.Alpha0()), cmb.Digit1())
+			},
+			input:      "<ab>12",
+			wantOutput: "ab",
+		}, {
+			name: "an empty delimited region should work",
+			newParser: func() comb.Parser[string] {
+				return comb.WithinDelimited(cmb.String("/*"), cmb.String("*/"), cmb.Alpha0())
+			},
+			input:      "/**/",
+			wantOutput: "",
+		}, {
+			name: "errors should be reported in the full input",
+			newParser: func() comb.Parser[string] {
+				return comb.WithinDelimited(cmb.Char('<'), cmb.Char('>'), cmb.Alpha0())
+			},
+			input:      "<aé1>",
+			wantErrMsg: "expected end of the limited input (still 1 bytes of it left) [1:4] <aé▶1>",
+		}, {
+			name: "an unclosed region should fail",
+			newParser: func() comb.Parser[string] {
+				return comb.WithinDelimited(cmb.Char('<'), cmb.Char('>'), cmb.Alpha0())
+			},
+			input:      "<ab",
+			wantErrMsg: "expected '>' (unclosed '<' opened at 1:1) [1:4] <ab▶",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc // this is needed for t.Parallel() to work correctly (or the same test case will be executed N times)
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotOutput, err := comb.RunOnString(tc.input, tc.newParser())
+			if tc.wantErrMsg != "" {
+				assert.EqualError(t, err, tc.wantErrMsg)
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, tc.wantOutput, gotOutput)
+		})
+	}
+}
+
+func TestWithinPushInput(t *testing.T) {
+	t.Parallel()
+
+	var offsets []int
+	probe := comb.NewParser[string]("probe", func(state comb.State) (comb.State, string, *comb.ParserError) {
+		offsets = append(offsets, state.FrameOffset(), comb.PushInput(state.MoveBy(1), "x", "included").FrameOffset())
+		return state.MoveBy(2), "", nil
+	}, nil)
+
+	_, err := comb.RunOnString("abcd", cmb.Prefixed(cmb.String("ab"), comb.Within(2, probe)))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{0, 0}, offsets)
+}